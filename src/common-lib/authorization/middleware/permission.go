@@ -52,6 +52,16 @@ func (p *Permission) CommonAssertHandler(handler web.HTTPHandlerFunc, validation
 	return web.HTTPHandlerFunc(p.AssertHandler(http.HandlerFunc(handler), permissionNames, validationType))
 }
 
+// DocumentedAssertHandler is like CommonAssertHandler and also records the permission names on the operation,
+// so the OpenAPI document of the route shows the scopes it requires
+func (p *Permission) DocumentedAssertHandler(op *web.Operation, handler web.HTTPHandlerFunc, validationType permission.ValidationType, permissionNames ...string) web.HTTPHandlerFunc {
+	if op != nil {
+		op.Permissions = permissionNames
+		op.AllPermissions = validationType == permission.AllOf
+	}
+	return p.CommonAssertHandler(handler, validationType, permissionNames...)
+}
+
 // CommonDecodeHandler wraps a common lib handler with a middleware that decodes a token permissions and sets it to the request context
 func (p *Permission) CommonDecodeHandler(handler web.HTTPHandlerFunc) web.HTTPHandlerFunc {
 	return web.HTTPHandlerFunc(p.DecodeHandler(http.HandlerFunc(handler)))
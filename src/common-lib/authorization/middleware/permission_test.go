@@ -12,6 +12,7 @@ import (
 	"gitlab.kksharmadevdev.com/platform/platform-api-model/clients/model/Golang/resourceModel/auth"
 	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/authorization/token/permission"
 	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/runtime/logger"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/web"
 )
 
 func TestPermission_AssertHandler(t *testing.T) {
@@ -467,3 +468,21 @@ func TestTrustingPermission_DecodeHandler(t *testing.T) {
 		})
 	}
 }
+
+func TestPermission_DocumentedAssertHandler(t *testing.T) {
+	log, err := logger.Create(logger.Config{Name: "permission_test_documented", Destination: logger.DISCARD})
+	require.NoError(t, err)
+	target := NewPermission(nil, log)
+
+	op := &web.Operation{Summary: "get devices"}
+	handler := target.DocumentedAssertHandler(op, func(rw http.ResponseWriter, r *http.Request) {
+		rw.WriteHeader(http.StatusOK)
+	}, permission.AllOf, "Device.Manage", "Device.Read")
+
+	assert.Equal(t, []string{"Device.Manage", "Device.Read"}, op.Permissions)
+	assert.True(t, op.AllPermissions)
+
+	rw := httptest.NewRecorder()
+	handler(rw, &http.Request{Header: http.Header{}})
+	assert.Equal(t, http.StatusUnauthorized, rw.Code)
+}
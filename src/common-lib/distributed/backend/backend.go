@@ -0,0 +1,115 @@
+package backend
+
+import (
+	"fmt"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/etcd"
+	leaderelection "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/leader-election"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/lock"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/memory"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/zookeeper"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/runtime/logger"
+)
+
+const (
+	// Zookeeper - coordination through a Zookeeper ensemble
+	Zookeeper = "zookeeper"
+	// Etcd - coordination through an etcd v3 cluster
+	Etcd = "etcd"
+	// Memory - in-process coordination for a single node deployment
+	Memory = "memory"
+)
+
+type (
+	// Config - selects and configures the backend of the distributed primitives
+	Config struct {
+		// Type is one of Zookeeper, Etcd or Memory
+		// Default is Zookeeper
+		Type      string          `json:"type"`
+		Zookeeper ZookeeperConfig `json:"zookeeper"`
+		Etcd      etcd.Config     `json:"etcd"`
+	}
+
+	// ZookeeperConfig - configuration of the Zookeeper backend
+	ZookeeperConfig struct {
+		Hosts    []string `json:"hosts"`
+		BasePath string   `json:"basePath"`
+	}
+
+	// Backend - the distributed primitives of the selected backend
+	Backend struct {
+		LeaderElector    leaderelection.Interface
+		Queue            queue.Interface
+		WorkQueueStore   queue.Store
+		Scheduler        scheduler.Interface
+		SchedulerHistory scheduler.History
+		// NewLock creates a lock with the given name
+		NewLock func(name string) lock.ContextLocker
+		// NewElector creates a leader elector with observable leadership
+		NewElector func() leaderelection.Elector
+		// InitBroadcast returns the broadcast of the instance, instanceID should be unique for each instance of micro-service
+		InitBroadcast func(instanceID string, timeout time.Duration) (distributed.ReplayableBroadcast, error)
+	}
+)
+
+// New initializes the backend selected by the configuration, so the same service runs with either of the backends
+func New(config Config) (*Backend, error) {
+	return NewWithLogger(config, nil)
+}
+
+// NewWithLogger initializes the backend selected by the configuration with custom logger instance
+func NewWithLogger(config Config, logImpl logger.Log) (*Backend, error) {
+	switch config.Type {
+	case Zookeeper, "":
+		if err := zookeeper.InitWithLogger(config.Zookeeper.Hosts, config.Zookeeper.BasePath, logImpl); err != nil {
+			return nil, err
+		}
+		return &Backend{
+			LeaderElector:    zookeeper.LeaderElector,
+			Queue:            zookeeper.Queue,
+			WorkQueueStore:   zookeeper.WorkQueueStore,
+			Scheduler:        zookeeper.Scheduler,
+			SchedulerHistory: zookeeper.SchedulerHistory,
+			NewLock:          zookeeper.NewLock,
+			NewElector:       zookeeper.NewElector,
+			InitBroadcast:    zookeeper.InitBroadcast,
+		}, nil
+
+	case Etcd:
+		if err := etcd.InitWithLogger(config.Etcd, logImpl); err != nil {
+			return nil, err
+		}
+		return &Backend{
+			LeaderElector:    etcd.LeaderElector,
+			Queue:            etcd.Queue,
+			WorkQueueStore:   etcd.WorkQueueStore,
+			Scheduler:        etcd.Scheduler,
+			SchedulerHistory: etcd.SchedulerHistory,
+			NewLock:          etcd.NewLock,
+			NewElector:       etcd.NewLeaderElector,
+			InitBroadcast:    etcd.InitBroadcast,
+		}, nil
+
+	case Memory:
+		cluster := memory.NewCluster()
+		return &Backend{
+			LeaderElector:    cluster.NewLeaderElector(),
+			Queue:            cluster.Queue(),
+			WorkQueueStore:   cluster.WorkQueueStore(),
+			Scheduler:        cluster.Scheduler(),
+			SchedulerHistory: cluster.History(),
+			NewLock:          cluster.NewLock,
+			NewElector:       cluster.NewLeaderElector,
+			InitBroadcast: func(instanceID string, timeout time.Duration) (distributed.ReplayableBroadcast, error) {
+				return cluster.NewBroadcast(instanceID), nil
+			},
+		}, nil
+
+	default:
+		return nil, fmt.Errorf("distributed backend: unknown type %q", config.Type)
+	}
+}
@@ -0,0 +1,50 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+func TestNewMemory(t *testing.T) {
+	b, err := New(Config{Type: Memory})
+	require.NoError(t, err)
+
+	_, acquired, err := b.NewLock("job").TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	_, acquired, err = b.NewLock("job").TryLock()
+	require.NoError(t, err)
+	assert.False(t, acquired, "locks of the same backend must exclude each other")
+
+	_, err = b.Queue.CreateItem([]byte("data"), "queue")
+	require.NoError(t, err)
+	items, err := b.Queue.GetList("queue")
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+
+	_, err = queue.NewWorkQueue(b.WorkQueueStore, "work", queue.WorkQueueConfig{}).Publish([]byte("data"), queue.PublishOptions{})
+	require.NoError(t, err)
+	msg, err := queue.NewWorkQueue(b.WorkQueueStore, "work", queue.WorkQueueConfig{}).Claim()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("data"), msg.Data)
+
+	broadcast, err := b.InitBroadcast("instance", 0)
+	require.NoError(t, err)
+	assert.NotNil(t, broadcast)
+}
+
+func TestNewErrors(t *testing.T) {
+	_, err := New(Config{Type: "consul"})
+	assert.Error(t, err)
+
+	_, err = New(Config{Type: Etcd})
+	assert.Error(t, err, "base path is required")
+
+	_, err = New(Config{Type: Zookeeper})
+	assert.Error(t, err, "hosts are required")
+}
@@ -0,0 +1,12 @@
+package barrier
+
+import "context"
+
+// DoubleBarrier : distributed double barrier, the participants start the computation together once the configured
+// number of them have entered, and finish it together once all of them have left
+type DoubleBarrier interface {
+	// Enter blocks until the configured number of participants have entered or ctx is done
+	Enter(ctx context.Context) error
+	// Leave blocks until all the participants which have entered have left or ctx is done
+	Leave(ctx context.Context) error
+}
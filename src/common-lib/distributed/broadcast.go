@@ -2,14 +2,32 @@ package distributed
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
 	"sync"
+	"time"
 )
 
+// EventsMissed is the type of the event delivered instead of the events which were removed from the broadcast log
+// by the retention before the listener got them, its payload is MissedEvents
+const EventsMissed = "distributed.EventsMissed"
+
 type (
 	// Event type for event
 	Event struct {
 		Type    string
 		Payload interface{}
+		// Sequence is the position of the event in the broadcast log, assigned by CreateEvent starting with 1
+		Sequence int64 `json:",omitempty"`
+		// CreatedAt is the time the event was created
+		CreatedAt time.Time
+	}
+
+	// MissedEvents - payload of the EventsMissed event, the sequences of the first and the last missed events
+	MissedEvents struct {
+		From int64
+		To   int64
 	}
 
 	// BroadcastHandler type for event func
@@ -24,4 +42,47 @@ type (
 		// CreateEvent creating the new event and send to all subscribers
 		CreateEvent(e Event) error
 	}
+
+	// ReplayableBroadcast - broadcast keeping a log of the events, so an instance started late gets the events it has missed
+	ReplayableBroadcast interface {
+		Broadcast
+		// ListenFrom listens input events with a sequence greater than the given one, starting with the ones kept in the log.
+		// Each event is delivered once in the order of the sequence, sequence 0 starts with the oldest event kept in the log
+		ListenFrom(ctx context.Context, wg *sync.WaitGroup, sequence int64)
+		// LastSequence returns the sequence of the latest event, 0 when there is none
+		LastSequence() (int64, error)
+	}
 )
+
+// Decode decodes the payload into v, the payload of an event received from another instance is decoded from JSON
+// into generic maps and slices, Decode turns it into the Go type the event was created with
+func (e *Event) Decode(v interface{}) error {
+	data, err := json.Marshal(e.Payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// TypedHandler returns the handler which decodes the payload into the type of the second argument of fn.
+// fn has to be a func(e *Event, payload T), where T is the type of the payload, e.g. a struct or a pointer to one,
+// TypedHandler panics otherwise. onError is called instead of fn when the payload can't be decoded
+func TypedHandler(fn interface{}, onError func(e *Event, err error)) BroadcastHandler {
+	f := reflect.ValueOf(fn)
+	t := f.Type()
+	if t.Kind() != reflect.Func || t.NumIn() != 2 || t.NumOut() != 0 || t.In(0) != reflect.TypeOf(&Event{}) {
+		panic(fmt.Sprintf("distributed: TypedHandler expects func(*distributed.Event, T), got %v", t))
+	}
+	payloadType := t.In(1)
+
+	return func(e *Event) {
+		payload := reflect.New(payloadType)
+		if err := e.Decode(payload.Interface()); err != nil {
+			if onError != nil {
+				onError(e, err)
+			}
+			return
+		}
+		f.Call([]reflect.Value{reflect.ValueOf(e), payload.Elem()})
+	}
+}
@@ -1 +1,63 @@
 package distributed
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type configChanged struct {
+	Key     string
+	Version int
+}
+
+// received returns the event as the listener gets it, with the payload decoded from JSON
+func received(t *testing.T, e Event) *Event {
+	data, err := json.Marshal(e)
+	require.NoError(t, err)
+	r := new(Event)
+	require.NoError(t, json.Unmarshal(data, r))
+	return r
+}
+
+func TestEventDecode(t *testing.T) {
+	e := received(t, Event{Type: "config", Payload: configChanged{Key: "timeout", Version: 3}, Sequence: 7})
+	assert.Equal(t, int64(7), e.Sequence)
+
+	var payload configChanged
+	require.NoError(t, e.Decode(&payload))
+	assert.Equal(t, configChanged{Key: "timeout", Version: 3}, payload)
+}
+
+func TestTypedHandler(t *testing.T) {
+	var (
+		got    configChanged
+		gotPtr *configChanged
+	)
+	TypedHandler(func(e *Event, payload configChanged) { got = payload }, nil)(
+		received(t, Event{Payload: configChanged{Key: "a", Version: 1}}))
+	assert.Equal(t, configChanged{Key: "a", Version: 1}, got)
+
+	TypedHandler(func(e *Event, payload *configChanged) { gotPtr = payload }, nil)(
+		received(t, Event{Payload: configChanged{Key: "b", Version: 2}}))
+	assert.Equal(t, &configChanged{Key: "b", Version: 2}, gotPtr)
+}
+
+func TestTypedHandlerDecodeError(t *testing.T) {
+	var (
+		called bool
+		failed error
+	)
+	h := TypedHandler(func(e *Event, payload configChanged) { called = true }, func(e *Event, err error) { failed = err })
+	h(received(t, Event{Payload: "not a struct"}))
+
+	assert.False(t, called)
+	assert.Error(t, failed)
+}
+
+func TestTypedHandlerSignature(t *testing.T) {
+	assert.Panics(t, func() { TypedHandler(func(payload configChanged) {}, nil) })
+	assert.Panics(t, func() { TypedHandler("not a func", nil) })
+}
@@ -0,0 +1,13 @@
+package counter
+
+import "context"
+
+// Counter : distributed atomic counter, the value starts with 0
+type Counter interface {
+	// Get returns the current value
+	Get(ctx context.Context) (int64, error)
+	// Add adds delta to the value and returns the new value
+	Add(ctx context.Context, delta int64) (int64, error)
+	// CompareAndSet sets the value only if it is equal to expected, reporting if it was set
+	CompareAndSet(ctx context.Context, expected, value int64) (bool, error)
+}
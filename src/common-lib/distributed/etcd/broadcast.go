@@ -0,0 +1,307 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed"
+)
+
+const (
+	pathForListeners = "broadcast/listeners"
+	pathForEvents    = "broadcast/events"
+	pathForSequence  = "broadcast/sequence"
+	eventPrefix      = "event-"
+	defaultTimeout   = time.Second
+	// trimBatch is the number of the oldest events checked by the retention after each new event
+	trimBatch = 100
+)
+
+var (
+	// Broadcast instance
+	Broadcast distributed.ReplayableBroadcast
+	// BroadcastRetention is the time the broadcast events are kept in the log for the replay
+	BroadcastRetention = 24 * time.Hour
+
+	mutex = &sync.Mutex{}
+)
+
+type broadcastImpl struct {
+	instanceID string
+	timeout    time.Duration
+	mutex      sync.RWMutex
+	handlers   map[string]distributed.BroadcastHandler
+}
+
+// InitBroadcast singleton, thread-safe, returns pointer to *Broadcast
+// instanceID should be unique for each instance of micro-service.
+// Events are kept in a log delivered by a watch, timeout is the interval between
+// the resyncs which pick up the events missed by a broken watch
+func InitBroadcast(instanceID string, timeout time.Duration) (distributed.ReplayableBroadcast, error) {
+	if Client == nil {
+		return nil, ErrEtcdNotInit
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if Broadcast != nil {
+		return Broadcast, nil
+	}
+
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	Broadcast = &broadcastImpl{
+		instanceID: instanceID,
+		timeout:    timeout,
+		handlers:   make(map[string]distributed.BroadcastHandler),
+	}
+	return Broadcast, nil
+}
+
+func (n *broadcastImpl) AddHandler(name string, handler distributed.BroadcastHandler) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+	n.handlers[name] = handler
+}
+
+func (n *broadcastImpl) listenersPath() string {
+	return getPath(pathForListeners)
+}
+
+func (n *broadcastImpl) eventsPath() string {
+	return getPath(pathForEvents) + etcdSeparator
+}
+
+// eventPath returns the key of the event, sequences are zero padded so the keys are ordered by sequence
+func (n *broadcastImpl) eventPath(sequence int64) string {
+	return n.eventsPath() + fmt.Sprintf("%s%020d", eventPrefix, sequence)
+}
+
+// Listen listens the events created from now on
+func (n *broadcastImpl) Listen(ctx context.Context, wg *sync.WaitGroup) {
+	sequence, err := n.LastSequence()
+	if err != nil {
+		Logger().Info(defaultTransaction, "lastSequence error: %s", err)
+		// the latest sequence is taken by the first successful read
+		sequence = -1
+	}
+	n.ListenFrom(ctx, wg, sequence)
+}
+
+// ListenFrom listens the events with a sequence greater than the given one, starting with the ones kept in the log
+func (n *broadcastImpl) ListenFrom(ctx context.Context, wg *sync.WaitGroup, sequence int64) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		last := sequence
+		for {
+			last = n.watch(ctx, last)
+			select {
+			case <-ctx.Done():
+				Logger().Info(defaultTransaction, "stopped by context")
+				return
+			case <-time.After(n.timeout):
+			}
+		}
+	}()
+}
+
+// watch processes the log on every new event until ctx is done or the watch breaks,
+// it returns the sequence of the last delivered event
+func (n *broadcastImpl) watch(ctx context.Context, last int64) int64 {
+	watchCtx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+
+	// the watch is started before the log is read, so no event is missed in between
+	events := Client.Watch(watchCtx, n.eventsPath(), clientv3.WithPrefix(), clientv3.WithFilterDelete())
+	resync := time.NewTicker(n.timeout)
+	defer resync.Stop()
+
+	for {
+		last = n.process(last)
+
+		select {
+		case <-ctx.Done():
+			return last
+		case resp, ok := <-events:
+			if !ok {
+				return last
+			}
+			if err := resp.Err(); err != nil {
+				Logger().Info(defaultTransaction, "watch error: %s", err)
+				return last
+			}
+		case <-resync.C:
+		}
+	}
+}
+
+// LastSequence returns the sequence of the latest event
+func (n *broadcastImpl) LastSequence() (int64, error) {
+	sequence, _, err := n.sequence()
+	return sequence, err
+}
+
+// sequence returns the sequence of the latest event and the revision of its last change
+func (n *broadcastImpl) sequence() (int64, int64, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Get(ctx, getPath(pathForSequence))
+	if err != nil || len(resp.Kvs) == 0 {
+		return 0, 0, err
+	}
+	sequence, err := strconv.ParseInt(string(resp.Kvs[0].Value), 10, 64)
+	return sequence, resp.Kvs[0].ModRevision, err
+}
+
+// process delivers the events following the last one and returns the sequence of the last delivered event
+func (n *broadcastImpl) process(last int64) int64 {
+	if last < 0 {
+		sequence, err := n.LastSequence()
+		if err != nil {
+			Logger().Info(defaultTransaction, "lastSequence error: %s", err)
+			return last
+		}
+		return sequence
+	}
+
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Get(ctx, n.eventPath(last+1), clientv3.WithRange(clientv3.GetPrefixRangeEnd(n.eventsPath())))
+	if err != nil {
+		Logger().Info(defaultTransaction, "getList error: %s", err)
+		return last
+	}
+
+	for _, kv := range resp.Kvs {
+		e := new(distributed.Event)
+		if err := json.Unmarshal(kv.Value, e); err != nil {
+			Logger().Error(defaultTransaction, "Json.UnmarshalFailed", "%v", err)
+			continue
+		}
+
+		// sequence 0 asks for the events kept in the log, so the removed ones are not reported
+		if last > 0 && e.Sequence > last+1 {
+			n.missed(last+1, e.Sequence-1)
+		}
+		last = e.Sequence
+		n.handle(e)
+	}
+	return last
+}
+
+func (n *broadcastImpl) missed(from, to int64) {
+	Logger().Warn(defaultTransaction, "Broadcast events %d-%d were removed before being delivered", from, to)
+	n.handle(&distributed.Event{
+		Type:      distributed.EventsMissed,
+		Payload:   distributed.MissedEvents{From: from, To: to},
+		Sequence:  to,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (n *broadcastImpl) handle(e *distributed.Event) {
+	n.mutex.RLock()
+	handler, ok := n.handlers[e.Type]
+	n.mutex.RUnlock()
+	if ok {
+		handler(e)
+	}
+}
+
+func (n *broadcastImpl) CreateEvent(e distributed.Event) error {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	// getting all subscribers, the listeners of the previous versions are registered there
+	prefix := n.listenersPath() + etcdSeparator
+	resp, err := Client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	if err != nil {
+		return err
+	}
+
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	content, err := n.appendEvent(ctx, e)
+	if err != nil {
+		return err
+	}
+
+	// sending the message to subscribers
+	for _, kv := range resp.Kvs {
+		if _, err := Queue.CreateItem(content, strings.TrimPrefix(string(kv.Key), prefix)); err != nil {
+			return err
+		}
+	}
+
+	if err := n.trimEvents(ctx); err != nil {
+		Logger().Error(defaultTransaction, "Broadcast.TrimEventsFailed", "%v", err)
+	}
+	return nil
+}
+
+// appendEvent adds the event to the log with the next sequence and returns the stored event
+func (n *broadcastImpl) appendEvent(ctx context.Context, e distributed.Event) ([]byte, error) {
+	key := getPath(pathForSequence)
+	for {
+		sequence, revision, err := n.sequence()
+		if err != nil {
+			return nil, err
+		}
+
+		e.Sequence = sequence + 1
+		content, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := Client.Txn(ctx).
+			If(clientv3.Compare(clientv3.ModRevision(key), "=", revision)).
+			Then(clientv3.OpPut(key, strconv.FormatInt(e.Sequence, 10)), clientv3.OpPut(n.eventPath(e.Sequence), string(content))).
+			Commit()
+		if err != nil {
+			return nil, err
+		}
+		if resp.Succeeded {
+			return content, nil
+		}
+		// the sequence is taken by an event created concurrently, try the next one
+	}
+}
+
+// trimEvents removes the events older than BroadcastRetention
+func (n *broadcastImpl) trimEvents(ctx context.Context) error {
+	resp, err := Client.Get(ctx, n.eventsPath(), clientv3.WithPrefix(), clientv3.WithLimit(trimBatch),
+		clientv3.WithSort(clientv3.SortByKey, clientv3.SortAscend))
+	if err != nil {
+		return err
+	}
+
+	oldest := time.Now().Add(-BroadcastRetention)
+	for _, kv := range resp.Kvs {
+		var e distributed.Event
+		if err = json.Unmarshal(kv.Value, &e); err != nil {
+			return err
+		}
+		if !e.CreatedAt.Before(oldest) {
+			return nil
+		}
+		if _, err = Client.Delete(ctx, string(kv.Key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package etcd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed"
+)
+
+func TestBroadcast(t *testing.T) {
+	initTest(t)
+	Broadcast = nil
+	defer func() { Broadcast = nil }()
+
+	b, err := InitBroadcast("instance", 50*time.Millisecond)
+	require.NoError(t, err)
+	same, err := InitBroadcast("another", 0)
+	require.NoError(t, err)
+	assert.Equal(t, b, same, "broadcast must be a singleton")
+
+	var (
+		mutex    sync.Mutex
+		payloads []interface{}
+	)
+	b.AddHandler("event", func(e *distributed.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		payloads = append(payloads, e.Payload)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	b.Listen(ctx, wg)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, b.CreateEvent(distributed.Event{Type: "event", Payload: float64(i)}))
+	}
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(payloads) == 5
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []interface{}{float64(0), float64(1), float64(2), float64(3), float64(4)}, payloads)
+
+	cancel()
+	wg.Wait()
+}
+
+func TestBroadcastReplay(t *testing.T) {
+	initTest(t)
+	Broadcast = nil
+	defer func() { Broadcast = nil }()
+
+	b, err := InitBroadcast("instance", 50*time.Millisecond)
+	require.NoError(t, err)
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, b.CreateEvent(distributed.Event{Type: "event", Payload: float64(i)}))
+	}
+	last, err := b.LastSequence()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), last)
+
+	var (
+		mutex     sync.Mutex
+		sequences []int64
+	)
+	b.AddHandler("event", func(e *distributed.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		sequences = append(sequences, e.Sequence)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	b.ListenFrom(ctx, wg, 1)
+	require.NoError(t, b.CreateEvent(distributed.Event{Type: "event", Payload: float64(4)}))
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(sequences) == 3
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int64{2, 3, 4}, sequences)
+
+	cancel()
+	wg.Wait()
+}
+
+func TestBroadcastRetention(t *testing.T) {
+	initTest(t)
+	Broadcast = nil
+	defer func() { Broadcast = nil }()
+
+	b, err := InitBroadcast("instance", 50*time.Millisecond)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.CreateEvent(distributed.Event{Type: "event", CreatedAt: time.Now().Add(-2 * BroadcastRetention)}))
+	}
+	require.NoError(t, b.CreateEvent(distributed.Event{Type: "event"}))
+
+	ctx, cancel := requestContext()
+	defer cancel()
+	resp, err := Client.Get(ctx, getPath(pathForEvents)+etcdSeparator, clientv3.WithPrefix(), clientv3.WithKeysOnly())
+	require.NoError(t, err)
+	require.Len(t, resp.Kvs, 1)
+
+	var missed []distributed.MissedEvents
+	b.AddHandler(distributed.EventsMissed, func(e *distributed.Event) {
+		missed = append(missed, e.Payload.(distributed.MissedEvents))
+	})
+	impl := b.(*broadcastImpl)
+	assert.Equal(t, int64(3), impl.process(1))
+	assert.Equal(t, []distributed.MissedEvents{{From: 2, To: 2}}, missed)
+}
+
+func TestInitBroadcastNotInitialized(t *testing.T) {
+	_, err := InitBroadcast("instance", 0)
+	assert.Equal(t, ErrEtcdNotInit, err)
+}
@@ -0,0 +1,186 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	leaderelection "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/leader-election"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/runtime/logger"
+)
+
+const (
+	etcdSeparator      = "/"
+	locksNode          = "locks"
+	queueNode          = "queue"
+	workQueueNode      = "workqueue"
+	leaderElectionNode = "leader-election"
+	electionsNode      = "elections"
+
+	defaultDialTimeout    = 5 * time.Second
+	defaultRequestTimeout = 5 * time.Second
+	defaultLeaseTTL       = 10
+)
+
+var (
+	// LeaderElector implementation
+	LeaderElector leaderelection.Interface = &leaderElectorImpl{}
+	// Queue implementation
+	Queue queue.Interface = queueImpl{}
+	// WorkQueueStore implementation, to be used with queue.NewWorkQueue
+	WorkQueueStore queue.Store = workQueueStoreImpl{}
+	// Scheduler implementation
+	Scheduler scheduler.Interface = schedulerImpl{}
+	// SchedulerHistory implementation
+	SchedulerHistory scheduler.History = schedulerImpl{}
+	// Client is the etcd client created by Init
+	Client *clientv3.Client
+	// Logger : Logger instance used for logging
+	// Defaults to Discard
+	Logger = logger.DiscardLogger
+
+	// ErrEtcdNotInit etcd is not initialized error
+	ErrEtcdNotInit = errors.New("etcd is not initialized use etcd.Init")
+	// ErrNoNode is returned when the queue or the queue item does not exist, like zk.ErrNoNode
+	ErrNoNode = errors.New("etcd: node does not exist")
+
+	etcdBasePath   string
+	requestTimeout = defaultRequestTimeout
+	leaseTTL       = defaultLeaseTTL
+	// session holds the lease which takes the place of the Zookeeper session, the keys attached to it
+	// are removed when the process stops renewing the lease like the ephemeral nodes
+	session      *concurrency.Session
+	sessionMutex sync.Mutex
+
+	defaultTransaction = "Etcd"
+)
+
+type (
+	// Config - configuration of the etcd backend
+	Config struct {
+		// Endpoints of the etcd cluster, e.g. "localhost:2379"
+		Endpoints []string `json:"endpoints"`
+		// BasePath is the prefix of all the keys, e.g. "/services/some-service"
+		BasePath string `json:"basePath"`
+		// DialTimeout is the timeout of the connection to the cluster
+		// Default is 5 seconds
+		DialTimeout time.Duration `json:"dialTimeout"`
+		// RequestTimeout is the timeout of a single request to the cluster
+		// Default is 5 seconds
+		RequestTimeout time.Duration `json:"requestTimeout"`
+		// LeaseTTL is the time to live of the process lease in seconds, the locks, peers and listeners
+		// of a stopped process are released once the lease expires
+		// Default is 10 seconds
+		LeaseTTL int `json:"leaseTTL"`
+	}
+
+	queueImpl          struct{}
+	workQueueStoreImpl struct{}
+	schedulerImpl      struct{}
+)
+
+// Init makes etcd client initializations
+func Init(config Config) error {
+	if len(config.BasePath) < 1 {
+		return fmt.Errorf("incorrect base path: %s", config.BasePath)
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaultDialTimeout
+	}
+	if config.RequestTimeout <= 0 {
+		config.RequestTimeout = defaultRequestTimeout
+	}
+	if config.LeaseTTL <= 0 {
+		config.LeaseTTL = defaultLeaseTTL
+	}
+
+	client, err := clientv3.New(clientv3.Config{Endpoints: config.Endpoints, DialTimeout: config.DialTimeout})
+	if err != nil {
+		return err
+	}
+
+	s, err := concurrency.NewSession(client, concurrency.WithTTL(config.LeaseTTL))
+	if err != nil {
+		_ = client.Close()
+		return err
+	}
+
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+	Client = client
+	session = s
+	etcdBasePath = strings.TrimSuffix(config.BasePath, etcdSeparator)
+	requestTimeout = config.RequestTimeout
+	leaseTTL = config.LeaseTTL
+	return nil
+}
+
+// InitWithLogger makes etcd client initializations with custom logger instance
+func InitWithLogger(config Config, logImpl logger.Log) error {
+	initLogger(logImpl)
+	return Init(config)
+}
+
+// Close revokes the process lease, releasing the locks, peers and listeners of the process, and closes the client
+func Close() error {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	if Client == nil {
+		return ErrEtcdNotInit
+	}
+	if err := session.Close(); err != nil {
+		Logger().Warn(defaultTransaction, "Couldn't revoke the lease: %v", err)
+	}
+	err := Client.Close()
+	Client = nil
+	session = nil
+	return err
+}
+
+// getSession returns the process session, a new one is created when the lease has expired
+func getSession() (*concurrency.Session, error) {
+	sessionMutex.Lock()
+	defer sessionMutex.Unlock()
+
+	if Client == nil {
+		return nil, ErrEtcdNotInit
+	}
+
+	select {
+	case <-session.Done():
+		Logger().Warn(defaultTransaction, "Lease %x has expired, creating a new one", session.Lease())
+		s, err := concurrency.NewSession(Client, concurrency.WithTTL(leaseTTL))
+		if err != nil {
+			return nil, err
+		}
+		session = s
+	default:
+	}
+	return session, nil
+}
+
+func requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), requestTimeout)
+}
+
+func getPath(nodes ...string) string {
+	return etcdBasePath + etcdSeparator + strings.Join(nodes, etcdSeparator)
+}
+
+func initLogger(logImpl logger.Log) {
+	if logImpl == nil {
+		return
+	}
+	Logger = func() logger.Log {
+		return logImpl
+	}
+}
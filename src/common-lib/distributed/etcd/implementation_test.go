@@ -0,0 +1,111 @@
+package etcd
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testEndpoint string
+	// testServerErr is the reason the embedded server didn't start, the tests needing a server are skipped
+	testServerErr error
+)
+
+// TestMain runs the tests against the etcd server from ETCD_TEST_ENDPOINT, when it isn't set
+// an embedded server is started by the testserver module which keeps the etcd server out of this module
+func TestMain(m *testing.M) {
+	testEndpoint = os.Getenv("ETCD_TEST_ENDPOINT")
+	if testEndpoint != "" {
+		os.Exit(m.Run())
+	}
+
+	stop, err := startTestServer()
+	if err != nil {
+		testServerErr = err
+		fmt.Printf("the tests needing an etcd server are skipped: %v, set ETCD_TEST_ENDPOINT to run them against a running server\n", err)
+		os.Exit(m.Run())
+	}
+	code := m.Run()
+	stop()
+	os.Exit(code)
+}
+
+// startTestServer runs the testserver module until its standard input is closed,
+// the server prints its client address once it is ready
+func startTestServer() (stop func(), err error) {
+	cmd := exec.Command("go", "run", ".")
+	cmd.Dir = "testserver"
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, err
+	}
+	stop = func() {
+		_ = stdin.Close()
+		_ = cmd.Wait()
+	}
+
+	address := make(chan string, 1)
+	go func() {
+		line, _ := bufio.NewReader(stdout).ReadString('\n')
+		address <- strings.TrimSpace(line)
+		_, _ = io.Copy(ioutil.Discard, stdout)
+	}()
+
+	select {
+	case testEndpoint = <-address:
+		if testEndpoint == "" {
+			stop()
+			return nil, fmt.Errorf("embedded etcd didn't start")
+		}
+		return stop, nil
+	case <-time.After(5 * time.Minute):
+		stop()
+		return nil, fmt.Errorf("embedded etcd didn't start")
+	}
+}
+
+// initTest connects to the embedded server with a base path unique for the test,
+// the test is skipped when the server didn't start
+func initTest(t *testing.T) {
+	if testServerErr != nil {
+		t.Skipf("etcd server is not available: %v", testServerErr)
+	}
+	require.NoError(t, Init(Config{Endpoints: []string{testEndpoint}, BasePath: "/" + t.Name(), LeaseTTL: 5}))
+	t.Cleanup(func() {
+		assert.NoError(t, Close())
+	})
+}
+
+func TestInit(t *testing.T) {
+	t.Run("incorrect_base_path", func(t *testing.T) {
+		assert.Error(t, Init(Config{Endpoints: []string{testEndpoint}}))
+	})
+
+	t.Run("close_not_initialized", func(t *testing.T) {
+		assert.Equal(t, ErrEtcdNotInit, Close())
+	})
+
+	t.Run("success", func(t *testing.T) {
+		initTest(t)
+		assert.NotNil(t, Client)
+		assert.Equal(t, "/TestInit/success/queue/name", getQueuePath("name"))
+	})
+}
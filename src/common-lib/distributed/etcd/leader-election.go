@@ -0,0 +1,344 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	leaderelection "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/leader-election"
+)
+
+const (
+	undefined  = -1
+	nodePrefix = "node-"
+)
+
+// ErrNotRegistered is returned when the candidate is not registered by RegisterCandidate
+var ErrNotRegistered = errors.New("etcd: candidate is not registered")
+
+type leaderElectorImpl struct {
+	mutex sync.Mutex
+	// peer is the key of the peer attached to the process lease, peerID is its creation revision
+	peer   string
+	peerID int
+
+	election  *concurrency.Election
+	session   *concurrency.Session
+	resource  string
+	candidate string
+	cancel    context.CancelFunc
+
+	// leadership and stepDown are set while Run is running, runDone is closed when it returns
+	leadership *leaderelection.Leadership
+	stepDown   chan chan error
+	runDone    chan struct{}
+}
+
+// NewLeaderElector returns a leader elector for RegisterCandidate and StartElection,
+// every candidate holds its own lease while it is registered
+func NewLeaderElector() leaderelection.Elector {
+	return &leaderElectorImpl{}
+}
+
+// BecomeALeader registers the process as a peer on the first call, the oldest active peer is the leader
+func (e *leaderElectorImpl) BecomeALeader() (int, bool, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.peer == "" {
+		if err := e.createPeer(); err != nil {
+			return undefined, false, fmt.Errorf("leader election: couldn't create Peer ID, err: %v", err)
+		}
+	}
+
+	ctx, cancel := requestContext()
+	defer cancel()
+	resp, err := Client.Get(ctx, getLeaderElectionPath()+etcdSeparator, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend))
+	if err != nil {
+		return e.peerID, false, fmt.Errorf("leader election: couldn't get active Peers IDs, err: %v", err)
+	}
+
+	found := false
+	for _, kv := range resp.Kvs {
+		if string(kv.Key) == e.peer {
+			found = true
+		}
+	}
+	if !found {
+		// the lease has expired, a new peer is created on the next call
+		e.peer = ""
+		e.peerID = undefined
+		return undefined, false, nil
+	}
+	return e.peerID, string(resp.Kvs[0].Key) == e.peer, nil
+}
+
+func (e *leaderElectorImpl) createPeer() error {
+	s, err := getSession()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	peer := fmt.Sprintf("%s%s%s%x", getLeaderElectionPath(), etcdSeparator, nodePrefix, s.Lease())
+	resp, err := Client.Txn(ctx).
+		If(clientv3.Compare(clientv3.CreateRevision(peer), "=", 0)).
+		Then(clientv3.OpPut(peer, "", clientv3.WithLease(s.Lease()))).
+		Else(clientv3.OpGet(peer)).
+		Commit()
+	if err != nil {
+		return err
+	}
+
+	revision := resp.Header.Revision
+	if !resp.Succeeded {
+		revision = resp.Responses[0].GetResponseRange().Kvs[0].CreateRevision
+	}
+	e.peer = peer
+	e.peerID = int(revision)
+	return nil
+}
+
+// RegisterCandidate registers the candidate for the election resource
+func (e *leaderElectorImpl) RegisterCandidate(electionResource string, clientName string) error {
+	if Client == nil {
+		return ErrEtcdNotInit
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.resource = electionResource
+	e.candidate = clientName
+	return e.newSession()
+}
+
+// newSession creates the lease of the candidate and its election, it is called with the mutex locked
+func (e *leaderElectorImpl) newSession() error {
+	s, err := concurrency.NewSession(Client, concurrency.WithTTL(leaseTTL))
+	if err != nil {
+		return err
+	}
+	e.session = s
+	e.election = concurrency.NewElection(s, getElectionPath(e.resource))
+	return nil
+}
+
+// StartElection campaigns until the candidate becomes the leader, runs the callback and resigns
+func (e *leaderElectorImpl) StartElection(callback func()) error {
+	e.mutex.Lock()
+	if e.election == nil {
+		e.mutex.Unlock()
+		return ErrNotRegistered
+	}
+	election, session, candidate := e.election, e.session, e.candidate
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	e.mutex.Unlock()
+
+	if err := campaign(ctx, election, session, candidate); err != nil {
+		e.ResignCandidate()
+		return fmt.Errorf("ErrReceivedElectionStatusError : Candidate : %s : Error : %v ", candidate, err)
+	}
+
+	callback()
+	e.ResignCandidate()
+	return nil
+}
+
+// Run campaigns for the leadership and reports its changes until ctx is done or the candidate resigns.
+// The leadership is lost when the lease of the candidate expires, the candidate rejoins the election with a new lease
+func (e *leaderElectorImpl) Run(ctx context.Context, callbacks leaderelection.Callbacks) error {
+	e.mutex.Lock()
+	if e.election == nil {
+		e.mutex.Unlock()
+		return ErrNotRegistered
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	leadership := leaderelection.NewLeadership(ctx, callbacks)
+	stepDown := make(chan chan error)
+	e.cancel = cancel
+	e.leadership = leadership
+	e.stepDown = stepDown
+	e.runDone = make(chan struct{})
+	runDone := e.runDone
+	e.mutex.Unlock()
+
+	defer func() {
+		e.ResignCandidate()
+		e.mutex.Lock()
+		e.leadership = nil
+		e.stepDown = nil
+		e.mutex.Unlock()
+		close(runDone)
+	}()
+
+	for {
+		e.mutex.Lock()
+		election, session, candidate := e.election, e.session, e.candidate
+		e.mutex.Unlock()
+		if election == nil {
+			// resigned
+			return nil
+		}
+
+		if err := campaign(runCtx, election, session, candidate); err != nil {
+			if runCtx.Err() != nil {
+				return nil
+			}
+			if err = e.renewSession(session); err != nil {
+				return err
+			}
+			continue
+		}
+		leadership.Gained()
+
+		select {
+		case <-runCtx.Done():
+			return nil
+
+		case <-session.Done():
+			Logger().Warn(defaultTransaction, "Candidate %s lost the leadership with the lease", candidate)
+			leadership.Lost()
+			if err := e.renewSession(session); err != nil {
+				return err
+			}
+
+		case reply := <-stepDown:
+			// the work of the leader is cancelled before the next candidate takes over
+			leadership.Lost()
+			ctx, cancel := requestContext()
+			reply <- election.Resign(ctx)
+			cancel()
+		}
+	}
+}
+
+// campaign blocks until the candidate becomes the leader, ctx is done or the lease expires
+func campaign(ctx context.Context, election *concurrency.Election, session *concurrency.Session, candidate string) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-session.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := election.Campaign(ctx, candidate); err != nil {
+		return err
+	}
+	select {
+	case <-session.Done():
+		return concurrency.ErrSessionExpired
+	default:
+		return nil
+	}
+}
+
+// renewSession replaces the expired lease of the candidate, it returns an error if the lease hasn't expired
+func (e *leaderElectorImpl) renewSession(expired *concurrency.Session) error {
+	select {
+	case <-expired.Done():
+	default:
+		return fmt.Errorf("ErrCampaignFailed : Candidate : %s", e.candidate)
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.session != expired {
+		return nil
+	}
+	return e.newSession()
+}
+
+// CurrentLeader returns the client name of the leader of the election resource
+func (e *leaderElectorImpl) CurrentLeader() (string, error) {
+	e.mutex.Lock()
+	election := e.election
+	e.mutex.Unlock()
+
+	if election == nil {
+		return "", ErrNotRegistered
+	}
+
+	ctx, cancel := requestContext()
+	defer cancel()
+	resp, err := election.Leader(ctx)
+	if err == concurrency.ErrElectionNoLeader {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(resp.Kvs[0].Value), nil
+}
+
+// StepDown resigns the leadership and campaigns again, the next candidate is already waiting for the leader key deletion
+func (e *leaderElectorImpl) StepDown() error {
+	e.mutex.Lock()
+	leadership, stepDown, runDone := e.leadership, e.stepDown, e.runDone
+	e.mutex.Unlock()
+
+	if leadership == nil || !leadership.IsLeader() {
+		return nil
+	}
+
+	reply := make(chan error, 1)
+	select {
+	case stepDown <- reply:
+		return <-reply
+	case <-runDone:
+		return nil
+	}
+}
+
+// ResignCandidate withdraws the candidate from the election and revokes its lease
+func (e *leaderElectorImpl) ResignCandidate() {
+	e.mutex.Lock()
+	leadership := e.leadership
+	e.mutex.Unlock()
+
+	// the work of the leader is cancelled before the next candidate takes over
+	if leadership != nil {
+		leadership.End()
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.election == nil {
+		return
+	}
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+
+	ctx, cancel := requestContext()
+	defer cancel()
+	if err := e.election.Resign(ctx); err != nil {
+		Logger().Debug(defaultTransaction, "Couldn't resign candidate %s: %v", e.candidate, err)
+	}
+	if err := e.session.Close(); err != nil {
+		Logger().Debug(defaultTransaction, "Couldn't revoke lease of candidate %s: %v", e.candidate, err)
+	}
+	e.election = nil
+	e.session = nil
+}
+
+func getLeaderElectionPath() string {
+	return getPath(leaderElectionNode)
+}
+
+func getElectionPath(resource string) string {
+	return getPath(electionsNode, resource)
+}
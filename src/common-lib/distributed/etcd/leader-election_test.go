@@ -0,0 +1,178 @@
+package etcd
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	leaderelection "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/leader-election"
+)
+
+func TestBecomeALeader(t *testing.T) {
+	initTest(t)
+	first := NewLeaderElector()
+	second := NewLeaderElector()
+
+	firstID, leader, err := first.BecomeALeader()
+	require.NoError(t, err)
+	assert.True(t, leader)
+
+	// both electors share the lease of the process, so they are the same peer
+	secondID, leader, err := second.BecomeALeader()
+	require.NoError(t, err)
+	assert.True(t, leader)
+	assert.Equal(t, firstID, secondID)
+
+	s, err := getSession()
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+
+	id, leader, err := first.BecomeALeader()
+	require.NoError(t, err)
+	assert.False(t, leader)
+	assert.Equal(t, undefined, id, "peer is gone together with the lease")
+
+	id, leader, err = first.BecomeALeader()
+	require.NoError(t, err)
+	assert.True(t, leader)
+	assert.NotEqual(t, firstID, id)
+}
+
+func TestStartElectionOneLeaderAtATime(t *testing.T) {
+	initTest(t)
+	var (
+		mutex           sync.Mutex
+		leaders, rounds int
+		wg              sync.WaitGroup
+	)
+
+	for i := 0; i < 3; i++ {
+		elector := NewLeaderElector()
+		require.NoError(t, elector.RegisterCandidate("resource", "candidate"))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, elector.StartElection(func() {
+				mutex.Lock()
+				leaders++
+				rounds++
+				current := leaders
+				mutex.Unlock()
+
+				assert.Equal(t, 1, current, "more than one leader at a time")
+				time.Sleep(10 * time.Millisecond)
+
+				mutex.Lock()
+				leaders--
+				mutex.Unlock()
+			}))
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 3, rounds)
+}
+
+func TestStartElectionNotRegistered(t *testing.T) {
+	initTest(t)
+	assert.Equal(t, ErrNotRegistered, NewLeaderElector().StartElection(func() {}))
+	assert.Equal(t, ErrNotRegistered, NewLeaderElector().Run(context.Background(), leaderelection.Callbacks{}))
+	_, err := NewLeaderElector().CurrentLeader()
+	assert.Equal(t, ErrNotRegistered, err)
+}
+
+func waitGained(t *testing.T, gained <-chan context.Context) context.Context {
+	select {
+	case ctx := <-gained:
+		return ctx
+	case <-time.After(5 * time.Second):
+		t.Fatal("leadership wasn't gained")
+		return nil
+	}
+}
+
+func TestRunStepDown(t *testing.T) {
+	initTest(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	first, second := NewLeaderElector(), NewLeaderElector()
+	require.NoError(t, first.RegisterCandidate("resource", "first"))
+	require.NoError(t, second.RegisterCandidate("resource", "second"))
+
+	firstGained, secondGained := make(chan context.Context, 1), make(chan context.Context, 1)
+	firstLost := make(chan struct{}, 1)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, first.Run(ctx, leaderelection.Callbacks{
+			OnGained: func(ctx context.Context) { firstGained <- ctx },
+			OnLost:   func() { firstLost <- struct{}{} },
+		}))
+	}()
+	firstCtx := waitGained(t, firstGained)
+
+	go func() {
+		defer wg.Done()
+		assert.NoError(t, second.Run(ctx, leaderelection.Callbacks{
+			OnGained: func(ctx context.Context) { secondGained <- ctx },
+		}))
+	}()
+
+	leader, err := second.CurrentLeader()
+	require.NoError(t, err)
+	assert.Equal(t, "first", leader)
+
+	// the second candidate campaigns only after it has been started, wait for its key
+	time.Sleep(100 * time.Millisecond)
+	require.NoError(t, first.StepDown())
+	<-firstLost
+	assert.Equal(t, context.Canceled, firstCtx.Err(), "leadership context must be cancelled on step down")
+	waitGained(t, secondGained)
+
+	leader, err = first.CurrentLeader()
+	require.NoError(t, err)
+	assert.Equal(t, "second", leader)
+
+	cancel()
+	wg.Wait()
+}
+
+func TestRunLeaseExpired(t *testing.T) {
+	initTest(t)
+	elector := NewLeaderElector().(*leaderElectorImpl)
+	require.NoError(t, elector.RegisterCandidate("resource", "candidate"))
+
+	gained, lost := make(chan context.Context, 2), make(chan struct{}, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- elector.Run(ctx, leaderelection.Callbacks{
+			OnGained: func(ctx context.Context) { gained <- ctx },
+			OnLost:   func() { lost <- struct{}{} },
+		})
+	}()
+	leaderCtx := waitGained(t, gained)
+
+	elector.mutex.Lock()
+	s := elector.session
+	elector.mutex.Unlock()
+	// the lease expired, e.g. the process was partitioned from the cluster
+	require.NoError(t, s.Close())
+
+	select {
+	case <-lost:
+	case <-time.After(5 * time.Second):
+		t.Fatal("leadership wasn't lost with the lease")
+	}
+	assert.Equal(t, context.Canceled, leaderCtx.Err())
+	waitGained(t, gained)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -0,0 +1,202 @@
+package etcd
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.etcd.io/etcd/client/v3/concurrency"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/lock"
+)
+
+var (
+	// ErrDeadlock is returned by Lock when the lock is already held by the same Locker
+	ErrDeadlock = errors.New("etcd lock: lock is already held by this locker")
+	// ErrNotLocked is returned by Unlock when the Locker does not hold the lock
+	ErrNotLocked = errors.New("etcd lock: not locked")
+	// ErrLockLost is returned by Unlock when the lease of the lock has expired
+	ErrLockLost = errors.New("etcd lock: lease is lost")
+)
+
+type lockImpl struct {
+	path  string
+	mutex sync.Mutex
+	// held is the etcd mutex acquired by this locker, its key is attached to the process lease
+	held    *concurrency.Mutex
+	session *concurrency.Session
+	// local is shared by the lockers of the process with the same path, the etcd mutex alone doesn't exclude them
+	// because all of them own the key of the process lease
+	local chan struct{}
+}
+
+var localLocks = struct {
+	sync.Mutex
+	byPath map[string]chan struct{}
+}{byPath: make(map[string]chan struct{})}
+
+// localLock returns the channel holding the lock of the path within the process
+func localLock(path string) chan struct{} {
+	localLocks.Lock()
+	defer localLocks.Unlock()
+
+	local, ok := localLocks.byPath[path]
+	if !ok {
+		local = make(chan struct{}, 1)
+		localLocks.byPath[path] = local
+	}
+	return local
+}
+
+// NewLock creates a lock.ContextLocker with the given name, the lock is released when the process lease expires
+func NewLock(name string) lock.ContextLocker {
+	path := getLockPath(name)
+	return &lockImpl{path: path, local: localLock(path)}
+}
+
+// Lock - blocks until the lock is acquired
+func (l *lockImpl) Lock() error {
+	_, err := l.LockContext(context.Background())
+	return err
+}
+
+// LockContext - blocks until the lock is acquired or ctx is done.
+// The returned fencing token is the revision of the lock key creation, which only grows across the cluster
+func (l *lockImpl) LockContext(ctx context.Context) (int64, error) {
+	return l.acquire(ctx, true)
+}
+
+// TryLock - acquires the lock only if it is not held by another owner
+func (l *lockImpl) TryLock() (int64, bool, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	token, err := l.acquire(ctx, false)
+	if err == concurrency.ErrLocked {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return token, true, nil
+}
+
+func (l *lockImpl) acquire(ctx context.Context, wait bool) (int64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.held != nil {
+		return 0, lock.Error{Code: ErrDeadlock, Action: lock.TryUnlock}
+	}
+
+	if wait {
+		select {
+		case l.local <- struct{}{}:
+		case <-ctx.Done():
+			return 0, lock.Error{Code: ctx.Err(), Action: lock.TryLock}
+		}
+	} else {
+		select {
+		case l.local <- struct{}{}:
+		default:
+			return 0, concurrency.ErrLocked
+		}
+	}
+
+	token, s, m, err := l.acquireMutex(ctx, wait)
+	if err != nil {
+		<-l.local
+		return 0, err
+	}
+
+	l.held = m
+	l.session = s
+	return token, nil
+}
+
+// acquireMutex acquires the etcd mutex of the path with the process lease
+func (l *lockImpl) acquireMutex(ctx context.Context, wait bool) (int64, *concurrency.Session, *concurrency.Mutex, error) {
+	s, err := getSession()
+	if err != nil {
+		return 0, nil, nil, lock.Error{Code: err, Action: lock.TryLock}
+	}
+
+	m := concurrency.NewMutex(s, l.path)
+	if wait {
+		err = m.Lock(ctx)
+	} else {
+		err = m.TryLock(ctx)
+	}
+	if err == concurrency.ErrLocked {
+		return 0, nil, nil, err
+	}
+	if err != nil {
+		return 0, nil, nil, toLockError(err)
+	}
+
+	token, err := createRevision(m.Key())
+	if err != nil {
+		unlockCtx, cancel := requestContext()
+		defer cancel()
+		if unlockErr := m.Unlock(unlockCtx); unlockErr != nil {
+			Logger().Debug(defaultTransaction, "Couldn't unlock %s: %v", m.Key(), unlockErr)
+		}
+		return 0, nil, nil, toLockError(err)
+	}
+	return token, s, m, nil
+}
+
+// Unlock - releases the lock held by this locker
+func (l *lockImpl) Unlock() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.held == nil {
+		return lock.Error{Code: ErrNotLocked, Action: lock.Ignore}
+	}
+
+	select {
+	case <-l.session.Done():
+		// the key is gone together with the lease, the lock was lost before unlocking
+		l.held = nil
+		<-l.local
+		return lock.Error{Code: ErrLockLost, Action: lock.CreateNewLock}
+	default:
+	}
+
+	ctx, cancel := requestContext()
+	defer cancel()
+	if err := l.held.Unlock(ctx); err != nil {
+		return lock.Error{Code: err, Action: lock.TryUnlock}
+	}
+	l.held = nil
+	<-l.local
+	return nil
+}
+
+// toLockError maps an error of the etcd mutex to the lock.Error with the expected action
+func toLockError(err error) lock.Error {
+	if err == concurrency.ErrSessionExpired || err == ErrNoNode {
+		return lock.Error{Code: err, Action: lock.CreateNewLock}
+	}
+	return lock.Error{Code: err, Action: lock.TryLock}
+}
+
+// createRevision returns the revision of the key creation
+func createRevision(key string) (int64, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return 0, ErrNoNode
+	}
+	return resp.Kvs[0].CreateRevision, nil
+}
+
+func getLockPath(name string) string {
+	return getPath(locksNode, name)
+}
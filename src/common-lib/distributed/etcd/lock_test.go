@@ -0,0 +1,113 @@
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/lock"
+)
+
+func assertAction(t *testing.T, err error, code error, action lock.ErrorAction) {
+	le, ok := err.(lock.Error)
+	require.True(t, ok, "expected lock.Error, got %v", err)
+	assert.Equal(t, code, le.Code)
+	assert.Equal(t, action, le.Action)
+}
+
+func TestLockIsExclusive(t *testing.T) {
+	initTest(t)
+	first := NewLock("job")
+	second := NewLock("job")
+
+	require.NoError(t, first.Lock())
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- second.Lock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second locker acquired the lock held by the first one")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Unlock())
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("second locker didn't acquire the released lock")
+	}
+	require.NoError(t, second.Unlock())
+}
+
+func TestLockContext(t *testing.T) {
+	initTest(t)
+	first := NewLock("job")
+	second := NewLock("job")
+
+	firstToken, err := first.LockContext(context.Background())
+	require.NoError(t, err)
+	assert.True(t, firstToken > 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = second.LockContext(ctx)
+	assertAction(t, err, context.DeadlineExceeded, lock.TryLock)
+
+	require.NoError(t, first.Unlock())
+	secondToken, err := second.LockContext(context.Background())
+	require.NoError(t, err)
+	assert.True(t, secondToken > firstToken, "fencing token must grow on every acquisition")
+	require.NoError(t, second.Unlock())
+}
+
+func TestTryLock(t *testing.T) {
+	initTest(t)
+	first := NewLock("job")
+	second := NewLock("job")
+
+	_, acquired, err := first.TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired)
+
+	_, acquired, err = second.TryLock()
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	_, _, err = first.TryLock()
+	assertAction(t, err, ErrDeadlock, lock.TryUnlock)
+
+	require.NoError(t, first.Unlock())
+	_, acquired, err = second.TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	require.NoError(t, second.Unlock())
+}
+
+func TestUnlockNotLocked(t *testing.T) {
+	initTest(t)
+	assertAction(t, NewLock("job").Unlock(), ErrNotLocked, lock.Ignore)
+}
+
+func TestLockLostWithLease(t *testing.T) {
+	initTest(t)
+	locker := NewLock("job")
+	require.NoError(t, locker.Lock())
+
+	s, err := getSession()
+	require.NoError(t, err)
+	// the process stopped renewing the lease, e.g. after a long GC pause
+	require.NoError(t, s.Close())
+
+	assertAction(t, locker.Unlock(), ErrLockLost, lock.CreateNewLock)
+
+	_, acquired, err := NewLock("job").TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired, "lock must be released with the lease")
+}
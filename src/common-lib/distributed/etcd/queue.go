@@ -0,0 +1,118 @@
+package etcd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const queuePrefix = "queue-"
+
+// Create creates a new empty distributed queue key in etcd
+func (queueImpl) Create(queueName string) (string, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	path := getQueuePath(queueName)
+	_, err := Client.Put(ctx, path, "")
+	return path, err
+}
+
+// Exists checks if the distributed queue with provided name already exists in etcd
+func (queueImpl) Exists(queueName string) (bool, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Get(ctx, getQueuePath(queueName), clientv3.WithCountOnly())
+	if err != nil {
+		return false, err
+	}
+	return resp.Count > 0, nil
+}
+
+// GetList returns names of the items in the queue in the order of creation
+func (q queueImpl) GetList(queueName string) ([]string, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	prefix := getQueuePath(queueName) + etcdSeparator
+	resp, err := Client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.Kvs) == 0 {
+		exists, err := q.Exists(queueName)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, ErrNoNode
+		}
+	}
+
+	items := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		items = append(items, strings.TrimPrefix(string(kv.Key), prefix))
+	}
+	return items, nil
+}
+
+// CreateItem creates a new item in the queue, the queue is created if it does not exist
+func (queueImpl) CreateItem(data []byte, queueName string) (string, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	path := getQueuePath(queueName)
+	for {
+		item := path + etcdSeparator + fmt.Sprintf("%s%020d", queuePrefix, time.Now().UnixNano())
+		resp, err := Client.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(item), "=", 0)).
+			Then(clientv3.OpPut(item, string(data)), clientv3.OpPut(path, "")).
+			Commit()
+		if err != nil {
+			return "", err
+		}
+		if resp.Succeeded {
+			return item, nil
+		}
+		// the name is taken by an item created at the same nanosecond, try the next one
+	}
+}
+
+// GetItemData gets item data
+func (queueImpl) GetItemData(queueName, itemName string) ([]byte, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Get(ctx, getQueuePath(queueName)+etcdSeparator+itemName)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, ErrNoNode
+	}
+	return resp.Kvs[0].Value, nil
+}
+
+// RemoveItem drops the item
+func (queueImpl) RemoveItem(queueName, itemName string) error {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Delete(ctx, getQueuePath(queueName)+etcdSeparator+itemName)
+	if err != nil {
+		return err
+	}
+	if resp.Deleted == 0 {
+		return ErrNoNode
+	}
+	return nil
+}
+
+func getQueuePath(queueName string) string {
+	return getPath(queueNode, queueName)
+}
@@ -0,0 +1,58 @@
+package etcd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	initTest(t)
+
+	exists, err := Queue.Exists("jobs")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = Queue.GetList("jobs")
+	assert.Equal(t, ErrNoNode, err)
+
+	_, err = Queue.Create("jobs")
+	require.NoError(t, err)
+	exists, err = Queue.Exists("jobs")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	items, err := Queue.GetList("jobs")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestQueueItems(t *testing.T) {
+	initTest(t)
+
+	for _, data := range []string{"first", "second", "third"} {
+		_, err := Queue.CreateItem([]byte(data), "jobs")
+		require.NoError(t, err)
+	}
+
+	items, err := Queue.GetList("jobs")
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	var data []string
+	for _, item := range items {
+		d, err := Queue.GetItemData("jobs", item)
+		require.NoError(t, err)
+		data = append(data, string(d))
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, data, "items must be listed in the order of creation")
+
+	require.NoError(t, Queue.RemoveItem("jobs", items[0]))
+	assert.Equal(t, ErrNoNode, Queue.RemoveItem("jobs", items[0]))
+	_, err = Queue.GetItemData("jobs", items[0])
+	assert.Equal(t, ErrNoNode, err)
+
+	left, err := Queue.GetList("jobs")
+	require.NoError(t, err)
+	assert.Equal(t, items[1:], left)
+}
@@ -0,0 +1,139 @@
+package etcd
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+)
+
+var (
+	schedulerCron *scheduler.Cron
+	schedulerInit = false
+	scheduledJobs []scheduler.ScheduledJob
+)
+
+// Job is a struct defining the actual scheduled job
+// Implementing `ScheduledJob` from scheduler package, Run is called by the scheduler.Cron
+type Job struct {
+	Name     string
+	Task     string
+	Schedule string
+	// MisfirePolicy - what to do with the runs missed while no scheduler was running
+	MisfirePolicy scheduler.MisfirePolicy
+}
+
+// GetName returns job name
+func (j Job) GetName() string {
+	return j.Name
+}
+
+// GetTask returns job task
+func (j Job) GetTask() string {
+	return j.Task
+}
+
+// GetSchedule returns job schedule
+func (j Job) GetSchedule() string {
+	return j.Schedule
+}
+
+// GetMisfirePolicy returns job misfire policy
+func (j Job) GetMisfirePolicy() scheduler.MisfirePolicy {
+	return j.MisfirePolicy
+}
+
+// Run initial entry point of a Job, sends the job for execution to the job listeners
+func (j Job) Run() {
+	Logger().Info(defaultTransaction, "Scheduling job `%s` for execution", j.GetName())
+	if err := dispatcher().Dispatch(j.GetName(), j.Task, time.Now(), false); err != nil {
+		Logger().Error(defaultTransaction, "Scheduler.DispatchFailed", "Scheduler. %v", err)
+	}
+}
+
+// dispatcher sends the runs through the Queue and keeps them in the history of the scheduler
+func dispatcher() scheduler.Dispatcher {
+	return scheduler.Dispatcher{Queue: Queue, Runs: schedulerImpl{}, Node: SchedulerNodeName}
+}
+
+// DistributedScheduler initializes distributed scheduler
+func (schedulerImpl) DistributedScheduler(ctx context.Context, wg *sync.WaitGroup, jobs []scheduler.ScheduledJob, interval time.Duration) error {
+	scheduledJobs = jobs
+	wg.Add(1)
+
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+				sPeerID, leader, err := LeaderElector.BecomeALeader()
+				if err != nil {
+					Logger().Error(defaultTransaction, "LeaderElector.BecomeALeaderFailed", "become leader got error: %v", err)
+				}
+				if leader && !schedulerInit {
+					// I'm a new leader
+					startScheduler()
+				}
+				if sPeerID == undefined && schedulerInit {
+					stopScheduler()
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func startScheduler() {
+	Logger().Info(defaultTransaction, "I'm a new leader. Initializing scheduler...")
+	schedulerInit = true
+	schedulerCron = scheduler.NewCron()
+
+	for _, sj := range scheduledJobs {
+		job := Job{
+			Name:          sj.GetName(),
+			Task:          sj.GetTask(),
+			Schedule:      sj.GetSchedule(),
+			MisfirePolicy: scheduler.GetMisfirePolicy(sj),
+		}
+		err := createJobQueue(job.Task)
+		if err != nil {
+			Logger().Error(defaultTransaction, "Queue.VerifyQueue", "Couldn't create job queue for job %v in etcd, err: %v", job.GetName(), err)
+			continue
+		}
+		schedule, err := scheduler.Parse(job.GetSchedule())
+		if err != nil {
+			Logger().Error(defaultTransaction, "scheduler.ParseFailed", "Couldn't add job %v with schedule %v, err: %v", job.GetName(), job.GetSchedule(), err)
+			continue
+		}
+		schedulerCron.Add(job.GetName(), schedule, job.Run)
+		if err = dispatcher().CatchUp(job.GetName(), job.Task, schedule, job.MisfirePolicy); err != nil {
+			Logger().Error(defaultTransaction, "Scheduler.CatchUpFailed", "Scheduler. %v", err)
+		}
+	}
+	schedulerCron.Start()
+}
+
+func stopScheduler() {
+	if schedulerInit {
+		Logger().Info(defaultTransaction, "Stopping scheduler...")
+		schedulerInit = false
+		schedulerCron.Stop()
+	}
+}
+
+func createJobQueue(jobName string) error {
+	existing, err := Queue.Exists(jobName)
+	if err != nil {
+		return err
+	}
+
+	if !existing {
+		Logger().Info(defaultTransaction, "Job Queue for job %s does not exist, creating new", jobName)
+		_, err = Queue.Create(jobName)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package etcd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+)
+
+const (
+	schedulerNode = "scheduler"
+	runPrefix     = "run-"
+)
+
+var (
+	// SchedulerNodeName is the node name recorded in the runs made by this scheduler
+	// Defaults to the host name
+	SchedulerNodeName = hostName()
+	// SchedulerHistorySize is the number of runs kept in the history of each job
+	SchedulerHistorySize = 100
+)
+
+// LastRuns returns up to n latest runs of the job, the latest run first
+func (schedulerImpl) LastRuns(jobName string, n int) ([]scheduler.Run, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Get(ctx, getJobPath(jobName)+etcdSeparator, clientv3.WithPrefix(), clientv3.WithLimit(int64(n)),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortDescend))
+	if err != nil {
+		return nil, err
+	}
+
+	runs := make([]scheduler.Run, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		var run scheduler.Run
+		if err = json.Unmarshal(kv.Value, &run); err != nil {
+			return nil, err
+		}
+		run.ID = path.Base(string(kv.Key))
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// LastRun returns the last dispatched run of the job kept as value of the job key, the run is empty when the job has never run
+func (schedulerImpl) LastRun(jobName string) (scheduler.Run, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	var run scheduler.Run
+	resp, err := Client.Get(ctx, getJobPath(jobName))
+	if err != nil || len(resp.Kvs) == 0 || len(resp.Kvs[0].Value) == 0 {
+		return run, err
+	}
+	return run, json.Unmarshal(resp.Kvs[0].Value, &run)
+}
+
+// AddRun keeps the run as the last dispatched run of the job key and adds it to the job history,
+// the ID of the run in the history is returned once it was added
+func (schedulerImpl) AddRun(run scheduler.Run) (string, error) {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	jobPath := getJobPath(run.Job)
+	// the history is ordered by the creation revision, the key name only has to be unique
+	id := fmt.Sprintf("%s%020d", runPrefix, time.Now().UnixNano())
+	if _, err = Client.Txn(ctx).Then(clientv3.OpPut(jobPath, string(data)), clientv3.OpPut(jobPath+etcdSeparator+id, string(data))).Commit(); err != nil {
+		return "", err
+	}
+	return id, trimHistory(jobPath)
+}
+
+// UpdateRun applies the update to the run of the job history, a run already removed by the history trimming is ignored
+func (schedulerImpl) UpdateRun(jobName, id string, update func(*scheduler.Run)) error {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	key := getJobPath(jobName) + etcdSeparator + id
+	resp, err := Client.Get(ctx, key)
+	if err != nil || len(resp.Kvs) == 0 {
+		return err
+	}
+
+	var run scheduler.Run
+	if err = json.Unmarshal(resp.Kvs[0].Value, &run); err != nil {
+		return err
+	}
+	update(&run)
+	data, err := json.Marshal(run)
+	if err != nil {
+		return err
+	}
+
+	// the run is only written while it exists unchanged
+	_, err = Client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision)).
+		Then(clientv3.OpPut(key, string(data))).
+		Commit()
+	return err
+}
+
+// trimHistory removes the oldest runs exceeding SchedulerHistorySize
+func trimHistory(path string) error {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	prefix := path + etcdSeparator
+	resp, err := Client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend))
+	if err != nil || len(resp.Kvs) <= SchedulerHistorySize {
+		return err
+	}
+
+	for _, kv := range resp.Kvs[:len(resp.Kvs)-SchedulerHistorySize] {
+		if _, err = Client.Delete(ctx, string(kv.Key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getJobPath(jobName string) string {
+	return getPath(schedulerNode, jobName)
+}
+
+func hostName() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
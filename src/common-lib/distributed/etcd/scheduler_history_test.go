@@ -0,0 +1,91 @@
+package etcd
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+)
+
+func TestSchedulerHistory(t *testing.T) {
+	initTest(t)
+	size := SchedulerHistorySize
+	SchedulerHistorySize = 3
+	defer func() { SchedulerHistorySize = size }()
+
+	last, err := schedulerImpl{}.LastRun("job")
+	require.NoError(t, err)
+	assert.True(t, last.ScheduledAt.IsZero())
+
+	start := time.Now().Truncate(time.Second)
+	for i := 0; i < 5; i++ {
+		_, err = schedulerImpl{}.AddRun(scheduler.Run{Job: "job", ScheduledAt: start.Add(time.Duration(i) * time.Minute)})
+		require.NoError(t, err)
+	}
+
+	last, err = schedulerImpl{}.LastRun("job")
+	require.NoError(t, err)
+	assert.True(t, start.Add(4*time.Minute).Equal(last.ScheduledAt))
+
+	runs, err := SchedulerHistory.LastRuns("job", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 3)
+	assert.True(t, start.Add(4*time.Minute).Equal(runs[0].ScheduledAt), "the latest run goes first")
+	assert.True(t, start.Add(2*time.Minute).Equal(runs[2].ScheduledAt))
+}
+
+func TestCatchUp(t *testing.T) {
+	initTest(t)
+	lastRunAt := time.Now().Add(-3 * time.Hour).Truncate(time.Hour)
+	_, err := schedulerImpl{}.AddRun(scheduler.Run{Job: "job", ScheduledAt: lastRunAt})
+	require.NoError(t, err)
+
+	schedule, err := scheduler.Parse("@hourly")
+	require.NoError(t, err)
+	require.NoError(t, dispatcher().CatchUp("job", "task", schedule, scheduler.MisfireRunAll))
+
+	items, err := Queue.GetList("task")
+	require.NoError(t, err)
+	assert.Len(t, items, 3)
+
+	runs, err := SchedulerHistory.LastRuns("job", 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.True(t, runs[0].CatchUp)
+	assert.Equal(t, scheduler.OutcomePending, runs[0].Outcome)
+}
+
+func TestRunCompletedByJobListener(t *testing.T) {
+	initTest(t)
+	require.NoError(t, dispatcher().Dispatch("job", "task", time.Now(), false))
+
+	items, err := Queue.GetList("task")
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+
+	var called bool
+	processQueue(context.Background(), items, testJob{name: "task", callback: func(data [][]byte) {
+		called = true
+		assert.Empty(t, data, "the items of the scheduler don't reach the callback")
+	}})
+	assert.True(t, called)
+
+	runs, err := SchedulerHistory.LastRuns("job", 1)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, scheduler.OutcomeSucceeded, runs[0].Outcome)
+	assert.False(t, runs[0].StartedAt.IsZero())
+}
+
+type testJob struct {
+	name     string
+	callback func(data [][]byte)
+}
+
+func (j testJob) GetName() string { return j.name }
+
+func (j testJob) Callback(i ...interface{}) { j.callback(i[1].([][]byte)) }
@@ -0,0 +1,5 @@
+module gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/etcd/testserver
+
+go 1.15
+
+require go.etcd.io/etcd/server/v3 v3.5.0
@@ -0,0 +1,105 @@
+package etcd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+// List returns names of the items in the work queue in the order of creation
+func (workQueueStoreImpl) List(queueName string) ([]string, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	prefix := getWorkQueuePath(queueName) + etcdSeparator
+	resp, err := Client.Get(ctx, prefix, clientv3.WithPrefix(), clientv3.WithKeysOnly(),
+		clientv3.WithSort(clientv3.SortByCreateRevision, clientv3.SortAscend))
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]string, 0, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		items = append(items, strings.TrimPrefix(string(kv.Key), prefix))
+	}
+	return items, nil
+}
+
+// Get returns data of the item, the revision of its last modification is the version
+func (workQueueStoreImpl) Get(queueName, itemName string) ([]byte, int64, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Get(ctx, getWorkQueuePath(queueName)+etcdSeparator+itemName)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return nil, 0, queue.ErrNotFound
+	}
+	return resp.Kvs[0].Value, resp.Kvs[0].ModRevision, nil
+}
+
+// Create creates a new item in the work queue
+func (workQueueStoreImpl) Create(queueName string, data []byte) (string, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	path := getWorkQueuePath(queueName) + etcdSeparator
+	for {
+		item := fmt.Sprintf("%s%020d", queuePrefix, time.Now().UnixNano())
+		resp, err := Client.Txn(ctx).
+			If(clientv3.Compare(clientv3.CreateRevision(path+item), "=", 0)).
+			Then(clientv3.OpPut(path+item, string(data))).
+			Commit()
+		if err != nil {
+			return "", err
+		}
+		if resp.Succeeded {
+			return item, nil
+		}
+		// the name is taken by an item created at the same nanosecond, try the next one
+	}
+}
+
+// Update replaces data of the item if it wasn't modified since the version
+func (workQueueStoreImpl) Update(queueName, itemName string, data []byte, version int64) error {
+	item := getWorkQueuePath(queueName) + etcdSeparator + itemName
+	return commitIfVersion(item, version, clientv3.OpPut(item, string(data)))
+}
+
+// Delete removes the item if it wasn't modified since the version
+func (workQueueStoreImpl) Delete(queueName, itemName string, version int64) error {
+	item := getWorkQueuePath(queueName) + etcdSeparator + itemName
+	return commitIfVersion(item, version, clientv3.OpDelete(item))
+}
+
+// commitIfVersion applies the operation when the key has the version, the missing key is reported as queue.ErrNotFound
+func commitIfVersion(key string, version int64, op clientv3.Op) error {
+	ctx, cancel := requestContext()
+	defer cancel()
+
+	resp, err := Client.Txn(ctx).
+		If(clientv3.Compare(clientv3.ModRevision(key), "=", version)).
+		Then(op).
+		Else(clientv3.OpGet(key, clientv3.WithCountOnly())).
+		Commit()
+	if err != nil {
+		return err
+	}
+	if resp.Succeeded {
+		return nil
+	}
+	if resp.Responses[0].GetResponseRange().Count == 0 {
+		return queue.ErrNotFound
+	}
+	return queue.ErrConflict
+}
+
+func getWorkQueuePath(queueName string) string {
+	return getPath(workQueueNode, queueName)
+}
@@ -0,0 +1,59 @@
+package etcd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+func TestWorkQueueStore(t *testing.T) {
+	initTest(t)
+
+	first, err := WorkQueueStore.Create("jobs", []byte("first"))
+	require.NoError(t, err)
+	second, err := WorkQueueStore.Create("jobs", []byte("second"))
+	require.NoError(t, err)
+
+	items, err := WorkQueueStore.List("jobs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{first, second}, items)
+
+	data, version, err := WorkQueueStore.Get("jobs", first)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first"), data)
+
+	require.NoError(t, WorkQueueStore.Update("jobs", first, []byte("updated"), version))
+	assert.Equal(t, queue.ErrConflict, WorkQueueStore.Update("jobs", first, []byte("stale"), version))
+	assert.Equal(t, queue.ErrConflict, WorkQueueStore.Delete("jobs", first, version))
+
+	_, version, err = WorkQueueStore.Get("jobs", first)
+	require.NoError(t, err)
+	require.NoError(t, WorkQueueStore.Delete("jobs", first, version))
+	assert.Equal(t, queue.ErrNotFound, WorkQueueStore.Delete("jobs", first, version))
+
+	_, _, err = WorkQueueStore.Get("jobs", first)
+	assert.Equal(t, queue.ErrNotFound, err)
+}
+
+func TestWorkQueue(t *testing.T) {
+	initTest(t)
+
+	q := queue.NewWorkQueue(WorkQueueStore, "jobs", queue.WorkQueueConfig{VisibilityTimeout: time.Minute, MaxDeliveries: 1})
+	_, err := q.Publish([]byte("job"), queue.PublishOptions{})
+	require.NoError(t, err)
+
+	msg, err := q.Claim()
+	require.NoError(t, err)
+	require.NoError(t, q.Nack(msg, 0))
+
+	_, err = q.Claim()
+	assert.Equal(t, queue.ErrEmpty, err)
+	msg, err = q.DeadLetters().Claim()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("job"), msg.Data)
+	require.NoError(t, q.DeadLetters().Ack(msg))
+}
@@ -18,6 +18,7 @@ func main() {
 	l.When("Unlock").Return(nil)
 	zkMockObj.When("NewLock", mock.Any, mock.Any).Return(l)
 	zkMockObj.When("Children", mock.Any).Return([]string{}, &zk.Stat{}, nil)
+	zkMockObj.When("CreateRecursive", mock.Any, mock.Any, mock.Any, mock.Any).Return("/zookeeper_root/broadcast/events/event-0000000000", nil)
 	zkMockObj.When("isCBEnabled").Return(false)
 
 	// Create new zookeeper lock
@@ -58,6 +59,8 @@ func main() {
 		handleError(err)
 	}
 
+	// Decode the payload into a Go type, the payload of an event sent by another instance is decoded from JSON
+	broadCast.AddHandler("example_typed_handler", distributed.TypedHandler(func(e *distributed.Event, payload string) {}, nil))
 }
 
 func handleError(_ error) {
@@ -1,5 +1,7 @@
 package leaderelection
 
+import "context"
+
 // Interface : Leader Election Service
 type Interface interface {
 	// Old implementation of leaderelection
@@ -10,3 +12,29 @@ type Interface interface {
 	StartElection(callback func()) error
 	ResignCandidate()
 }
+
+// Elector : Leader Election Service with observable leadership
+type Elector interface {
+	Interface
+
+	// Run takes part in the election of the registered candidate until ctx is done or the candidate resigns,
+	// reporting every gain and loss of the leadership to the callbacks. A candidate which lost the leadership,
+	// e.g. on a session expiry, rejoins the election as a follower. The candidate resigns when Run returns
+	Run(ctx context.Context, callbacks Callbacks) error
+	// CurrentLeader returns the client name of the current leader of the election resource, empty when there is no leader
+	CurrentLeader() (string, error)
+	// StepDown hands the leadership over to the next candidate, which is already waiting for it, so there is
+	// no new election in between. The candidate stays in the election as the last follower.
+	// It does nothing when the candidate is not the leader
+	StepDown() error
+}
+
+// Callbacks : handlers of the leadership changes of a candidate, both are optional
+type Callbacks struct {
+	// OnGained is called in a new goroutine when the candidate becomes the leader,
+	// ctx is cancelled as soon as the leadership is lost
+	OnGained func(ctx context.Context)
+	// OnLost is called in the goroutine of OnGained when the candidate loses the leadership, after the ctx of OnGained
+	// is cancelled and OnGained has returned, so it never precedes its OnGained
+	OnLost func()
+}
@@ -0,0 +1,86 @@
+package leaderelection
+
+import (
+	"context"
+	"sync"
+)
+
+// Leadership reports the leadership changes of a candidate to its callbacks, it is shared by the Elector implementations.
+// The callbacks of a leadership are called in order by one goroutine: OnGained, then OnLost once the leadership is lost
+// and OnGained has returned. The next leadership starts once they are done, so the callbacks are never called concurrently
+type Leadership struct {
+	ctx       context.Context
+	callbacks Callbacks
+
+	mutex  sync.Mutex
+	cancel context.CancelFunc
+	// lost is closed when the current leadership is lost, done when the callbacks of the last leadership have returned
+	lost  chan struct{}
+	done  chan struct{}
+	ended bool
+}
+
+// NewLeadership returns the Leadership of a candidate running until ctx is done
+func NewLeadership(ctx context.Context, callbacks Callbacks) *Leadership {
+	return &Leadership{ctx: ctx, callbacks: callbacks}
+}
+
+// Gained calls OnGained with a new leadership context, it does nothing when the leadership is already held
+func (l *Leadership) Gained() {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.cancel != nil || l.ended {
+		return
+	}
+
+	var ctx context.Context
+	ctx, l.cancel = context.WithCancel(l.ctx)
+	previous := l.done
+	l.lost, l.done = make(chan struct{}), make(chan struct{})
+	go l.run(ctx, previous, l.lost, l.done)
+}
+
+// run calls the callbacks of a leadership after the ones of the previous leadership
+func (l *Leadership) run(ctx context.Context, previous, lost, done chan struct{}) {
+	defer close(done)
+	if previous != nil {
+		<-previous
+	}
+	if l.callbacks.OnGained != nil {
+		l.callbacks.OnGained(ctx)
+	}
+	<-lost
+	if l.callbacks.OnLost != nil {
+		l.callbacks.OnLost()
+	}
+}
+
+// Lost cancels the leadership context and lets OnLost be called, it does nothing when the leadership is not held
+func (l *Leadership) Lost() {
+	l.release(false)
+}
+
+// End calls Lost and ignores all the later gains, it is called when the candidate leaves the election
+func (l *Leadership) End() {
+	l.release(true)
+}
+
+func (l *Leadership) release(end bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.ended = l.ended || end
+	if l.cancel == nil {
+		return
+	}
+	l.cancel()
+	l.cancel = nil
+	close(l.lost)
+}
+
+// IsLeader checks if the leadership is held
+func (l *Leadership) IsLeader() bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return l.cancel != nil
+}
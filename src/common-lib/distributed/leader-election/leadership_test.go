@@ -0,0 +1,110 @@
+package leaderelection
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeadership(t *testing.T) {
+	var (
+		gained = make(chan context.Context, 2)
+		lost   = make(chan struct{}, 2)
+	)
+	l := NewLeadership(context.Background(), Callbacks{
+		OnGained: func(ctx context.Context) { gained <- ctx },
+		OnLost:   func() { lost <- struct{}{} },
+	})
+
+	l.Lost()
+	assert.Empty(t, lost, "OnLost must not be called without leadership")
+	assert.False(t, l.IsLeader())
+
+	l.Gained()
+	l.Gained()
+	assert.True(t, l.IsLeader())
+
+	var ctx context.Context
+	select {
+	case ctx = <-gained:
+	case <-time.After(time.Second):
+		t.Fatal("OnGained wasn't called")
+	}
+	assert.NoError(t, ctx.Err())
+
+	l.Lost()
+	assert.False(t, l.IsLeader())
+	assert.Equal(t, context.Canceled, ctx.Err(), "leadership context must be cancelled on loss")
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("OnLost wasn't called")
+	}
+	assert.Empty(t, gained, "OnGained must be called once per leadership")
+}
+
+func TestLeadershipOrder(t *testing.T) {
+	var (
+		mutex sync.Mutex
+		calls []string
+		done  = make(chan struct{}, 2)
+	)
+	record := func(call string) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		calls = append(calls, call)
+	}
+	l := NewLeadership(context.Background(), Callbacks{
+		OnGained: func(ctx context.Context) {
+			// OnGained starts late, the leadership is already lost
+			time.Sleep(10 * time.Millisecond)
+			record("gained")
+		},
+		OnLost: func() {
+			record("lost")
+			done <- struct{}{}
+		},
+	})
+
+	l.Gained()
+	l.Lost()
+	l.Gained()
+	l.Lost()
+	for i := 0; i < 2; i++ {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("OnLost wasn't called")
+		}
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	assert.Equal(t, []string{"gained", "lost", "gained", "lost"}, calls, "OnLost must never precede its OnGained")
+}
+
+func TestLeadershipWithoutCallbacks(t *testing.T) {
+	l := NewLeadership(context.Background(), Callbacks{})
+	l.Gained()
+	assert.True(t, l.IsLeader())
+	l.Lost()
+	assert.False(t, l.IsLeader())
+}
+
+func TestLeadershipEnd(t *testing.T) {
+	lost := make(chan struct{}, 1)
+	l := NewLeadership(context.Background(), Callbacks{OnLost: func() { lost <- struct{}{} }})
+	l.Gained()
+	l.End()
+	select {
+	case <-lost:
+	case <-time.After(time.Second):
+		t.Fatal("OnLost wasn't called")
+	}
+
+	l.Gained()
+	assert.False(t, l.IsLeader(), "leadership can't be gained after End")
+}
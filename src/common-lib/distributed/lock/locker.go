@@ -1,5 +1,7 @@
 package lock
 
+import "context"
+
 const (
 	// Ignore - error can be ignored and proceed further
 	Ignore ErrorAction = iota + 1
@@ -18,6 +20,17 @@ type (
 		Unlock() error
 	}
 
+	// ContextLocker presents distributed locking bounded by a context, with try-lock and fencing tokens.
+	// The fencing token returned on acquisition is greater than the tokens of all previous acquisitions
+	// of the same lock and can be passed to downstream writes to reject stale holders
+	ContextLocker interface {
+		Locker
+		// LockContext blocks until the lock is acquired or ctx is done
+		LockContext(ctx context.Context) (token int64, err error)
+		// TryLock acquires the lock only if it is free at the time of invocation, it never waits
+		TryLock() (token int64, acquired bool, err error)
+	}
+
 	// ErrorAction - action to be taken on getting a lock/unlock error
 	ErrorAction int
 
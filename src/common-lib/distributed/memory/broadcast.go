@@ -0,0 +1,195 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed"
+)
+
+const defaultBroadcastRetention = 24 * time.Hour
+
+type (
+	broadcastImpl struct {
+		cluster    *Cluster
+		instanceID string
+		mutex      sync.RWMutex
+		handlers   map[string]distributed.BroadcastHandler
+	}
+
+	// subscriber is notified about the events created while the instance is listening
+	subscriber struct {
+		notify chan struct{}
+	}
+
+	// loggedEvent is an event kept in the broadcast log of the cluster
+	loggedEvent struct {
+		sequence  int64
+		createdAt time.Time
+		data      []byte
+	}
+)
+
+// NewBroadcast returns the broadcast of the instance, instanceID should be unique for each instance.
+// Events are kept in the log of the Cluster for the BroadcastRetention and delivered to all the instances in the same order.
+// As with Zookeeper, the event is sent as JSON, so handlers get the payload decoded from JSON
+func (c *Cluster) NewBroadcast(instanceID string) distributed.ReplayableBroadcast {
+	return &broadcastImpl{
+		cluster:    c,
+		instanceID: instanceID,
+		handlers:   make(map[string]distributed.BroadcastHandler),
+	}
+}
+
+func (b *broadcastImpl) AddHandler(name string, handler distributed.BroadcastHandler) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.handlers[name] = handler
+}
+
+// Listen listens the events created from now on
+func (b *broadcastImpl) Listen(ctx context.Context, wg *sync.WaitGroup) {
+	sequence, _ := b.LastSequence()
+	b.ListenFrom(ctx, wg, sequence)
+}
+
+// ListenFrom listens the events with a sequence greater than the given one, starting with the ones kept in the log
+func (b *broadcastImpl) ListenFrom(ctx context.Context, wg *sync.WaitGroup, sequence int64) {
+	sub := b.cluster.subscribe(b.instanceID)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer b.cluster.unsubscribe(b.instanceID, sub)
+
+		last := sequence
+		for {
+			for _, e := range b.cluster.eventsAfter(last) {
+				// sequence 0 asks for the events kept in the log, so the removed ones are not reported
+				if last > 0 && e.sequence > last+1 {
+					b.missed(last+1, e.sequence-1)
+				}
+				b.process(e.data)
+				last = e.sequence
+			}
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-sub.notify:
+			}
+		}
+	}()
+}
+
+// LastSequence returns the sequence of the latest event
+func (b *broadcastImpl) LastSequence() (int64, error) {
+	b.cluster.mutex.Lock()
+	defer b.cluster.mutex.Unlock()
+	return b.cluster.sequence, nil
+}
+
+func (b *broadcastImpl) process(data []byte) {
+	e := new(distributed.Event)
+	if err := json.Unmarshal(data, e); err != nil {
+		return
+	}
+	b.handle(e)
+}
+
+func (b *broadcastImpl) missed(from, to int64) {
+	b.handle(&distributed.Event{
+		Type:      distributed.EventsMissed,
+		Payload:   distributed.MissedEvents{From: from, To: to},
+		Sequence:  to,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (b *broadcastImpl) handle(e *distributed.Event) {
+	b.mutex.RLock()
+	handler, ok := b.handlers[e.Type]
+	b.mutex.RUnlock()
+	if ok {
+		handler(e)
+	}
+}
+
+func (b *broadcastImpl) CreateEvent(e distributed.Event) error {
+	// appending under the cluster lock keeps the order of sequences and the log the same
+	b.cluster.mutex.Lock()
+	defer b.cluster.mutex.Unlock()
+
+	e.Sequence = b.cluster.sequence + 1
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
+	}
+	content, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	b.cluster.sequence = e.Sequence
+	b.cluster.events = append(b.cluster.events, loggedEvent{sequence: e.Sequence, createdAt: e.CreatedAt, data: content})
+	b.cluster.trimEvents()
+	for _, sub := range b.cluster.subscribers {
+		sub.wake()
+	}
+	return nil
+}
+
+// trimEvents removes the events older than BroadcastRetention, the caller holds the cluster lock
+func (c *Cluster) trimEvents() {
+	retention := c.BroadcastRetention
+	if retention <= 0 {
+		retention = defaultBroadcastRetention
+	}
+
+	oldest := time.Now().Add(-retention)
+	n := 0
+	for n < len(c.events) && c.events[n].createdAt.Before(oldest) {
+		n++
+	}
+	c.events = c.events[n:]
+}
+
+// eventsAfter returns the events of the log with a sequence greater than the given one
+func (c *Cluster) eventsAfter(sequence int64) []loggedEvent {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.trimEvents()
+	for i, e := range c.events {
+		if e.sequence > sequence {
+			return append([]loggedEvent(nil), c.events[i:]...)
+		}
+	}
+	return nil
+}
+
+func (c *Cluster) subscribe(instanceID string) *subscriber {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	sub := &subscriber{notify: make(chan struct{}, 1)}
+	c.subscribers[instanceID] = sub
+	return sub
+}
+
+func (c *Cluster) unsubscribe(instanceID string, sub *subscriber) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.subscribers[instanceID] == sub {
+		delete(c.subscribers, instanceID)
+	}
+}
+
+func (s *subscriber) wake() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
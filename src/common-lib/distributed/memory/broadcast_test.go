@@ -0,0 +1,180 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed"
+)
+
+// recorder collects the payloads of the handled events
+type recorder struct {
+	mutex    sync.Mutex
+	payloads []interface{}
+}
+
+func (r *recorder) handle(e *distributed.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.payloads = append(r.payloads, e.Payload)
+}
+
+func (r *recorder) get() []interface{} {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]interface{}{}, r.payloads...)
+}
+
+func TestBroadcastDeliveryOrder(t *testing.T) {
+	cluster := NewCluster()
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	recorders := []*recorder{{}, {}}
+	for i, id := range []string{"first", "second"} {
+		b := cluster.NewBroadcast(id)
+		b.AddHandler("event", recorders[i].handle)
+		b.Listen(ctx, wg)
+	}
+
+	sender := cluster.NewBroadcast("sender")
+	var expected []interface{}
+	for i := 0; i < 50; i++ {
+		require.NoError(t, sender.CreateEvent(distributed.Event{Type: "event", Payload: float64(i)}))
+		expected = append(expected, float64(i))
+	}
+	require.NoError(t, sender.CreateEvent(distributed.Event{Type: "unknown", Payload: "ignored"}))
+
+	for _, r := range recorders {
+		assert.Eventually(t, func() bool { return len(r.get()) == len(expected) }, time.Second, time.Millisecond)
+		assert.Equal(t, expected, r.get())
+	}
+
+	cancel()
+	wg.Wait()
+}
+
+func TestBroadcastAfterListenerStopped(t *testing.T) {
+	cluster := NewCluster()
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	r := &recorder{}
+	b := cluster.NewBroadcast("listener")
+	b.AddHandler("event", r.handle)
+	b.Listen(ctx, wg)
+	cancel()
+	wg.Wait()
+
+	require.NoError(t, b.CreateEvent(distributed.Event{Type: "event", Payload: "lost"}))
+	time.Sleep(10 * time.Millisecond)
+	assert.Empty(t, r.get())
+}
+
+func TestBroadcastPayloadIsJSON(t *testing.T) {
+	b := NewCluster().NewBroadcast("listener")
+	assert.Error(t, b.CreateEvent(distributed.Event{Type: "event", Payload: make(chan int)}))
+}
+
+func TestBroadcastReplay(t *testing.T) {
+	cluster := NewCluster()
+	sender := cluster.NewBroadcast("sender")
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, sender.CreateEvent(distributed.Event{Type: "event", Payload: float64(i)}))
+	}
+	last, err := sender.LastSequence()
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), last)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	all, late := &recorder{}, &recorder{}
+	b := cluster.NewBroadcast("all")
+	b.AddHandler("event", all.handle)
+	b.ListenFrom(ctx, wg, 0)
+	b = cluster.NewBroadcast("late")
+	b.AddHandler("event", late.handle)
+	b.ListenFrom(ctx, wg, 3)
+
+	require.NoError(t, sender.CreateEvent(distributed.Event{Type: "event", Payload: float64(6)}))
+
+	assert.Eventually(t, func() bool { return len(all.get()) == 6 }, time.Second, time.Millisecond)
+	assert.Equal(t, []interface{}{float64(1), float64(2), float64(3), float64(4), float64(5), float64(6)}, all.get())
+	assert.Eventually(t, func() bool { return len(late.get()) == 3 }, time.Second, time.Millisecond)
+	assert.Equal(t, []interface{}{float64(4), float64(5), float64(6)}, late.get())
+
+	cancel()
+	wg.Wait()
+}
+
+func TestBroadcastEventsMissed(t *testing.T) {
+	cluster := NewCluster()
+	cluster.BroadcastRetention = time.Hour
+	sender := cluster.NewBroadcast("sender")
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, sender.CreateEvent(distributed.Event{
+			Type:      "event",
+			Payload:   float64(i),
+			CreatedAt: time.Now().Add(-2 * time.Hour),
+		}))
+	}
+	require.NoError(t, sender.CreateEvent(distributed.Event{Type: "event", Payload: float64(4)}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	r := &recorder{}
+	var missed distributed.MissedEvents
+	b := cluster.NewBroadcast("listener")
+	b.AddHandler("event", r.handle)
+	b.AddHandler(distributed.EventsMissed, func(e *distributed.Event) {
+		missed = e.Payload.(distributed.MissedEvents)
+		r.handle(e)
+	})
+	b.ListenFrom(ctx, wg, 1)
+
+	assert.Eventually(t, func() bool { return len(r.get()) == 2 }, time.Second, time.Millisecond)
+	assert.Equal(t, distributed.MissedEvents{From: 2, To: 3}, missed)
+	assert.Equal(t, float64(4), r.get()[1])
+
+	cancel()
+	wg.Wait()
+}
+
+func TestBroadcastSequence(t *testing.T) {
+	cluster := NewCluster()
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	var (
+		mutex     sync.Mutex
+		sequences []int64
+	)
+	b := cluster.NewBroadcast("listener")
+	b.AddHandler("event", func(e *distributed.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		sequences = append(sequences, e.Sequence)
+	})
+	b.Listen(ctx, wg)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, b.CreateEvent(distributed.Event{Type: "event"}))
+	}
+
+	assert.Eventually(t, func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return len(sequences) == 3
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, []int64{1, 2, 3}, sequences)
+
+	cancel()
+	wg.Wait()
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+)
+
+var (
+	// ErrQueueExists is returned when creating a queue which already exists
+	ErrQueueExists = errors.New("memory: queue already exists")
+	// ErrNoQueue is returned when the queue does not exist
+	ErrNoQueue = errors.New("memory: queue does not exist")
+	// ErrNoItem is returned when the queue item does not exist
+	ErrNoItem = errors.New("memory: queue item does not exist")
+	// ErrDeadlock is returned by Lock when the lock is already held by the same Locker
+	ErrDeadlock = errors.New("memory: lock is already held by this locker")
+	// ErrNotLocked is returned by Unlock when the Locker does not hold the lock
+	ErrNotLocked = errors.New("memory: not locked")
+	// ErrNotRegistered is returned by StartElection when RegisterCandidate wasn't called
+	ErrNotRegistered = errors.New("memory: candidate is not registered")
+	// ErrResigned is returned by StartElection when the candidate resigned before becoming a leader
+	ErrResigned = errors.New("memory: candidate resigned")
+)
+
+// Cluster is an in-process stand-in of the Zookeeper ensemble.
+// The primitives created from the same Cluster coordinate with each other the way the Zookeeper ones do
+// across the nodes, so a Cluster can back integration tests or a single node deployment
+type Cluster struct {
+	// BroadcastRetention is the time the broadcast events are kept in the log for the replay
+	// Default is 24 hours
+	BroadcastRetention time.Duration
+
+	mutex       sync.Mutex
+	locks       map[string]*lockState
+	queues      map[string]*queueState
+	workQueues  map[string]*workQueueState
+	subscribers map[string]*subscriber
+	events      []loggedEvent
+	sequence    int64
+	elections   map[string]*election
+	history     map[string][]scheduler.Run
+	runSequence int64
+	nextPeerID  int
+}
+
+// NewCluster returns an empty Cluster
+func NewCluster() *Cluster {
+	return &Cluster{
+		BroadcastRetention: defaultBroadcastRetention,
+
+		locks:       make(map[string]*lockState),
+		queues:      make(map[string]*queueState),
+		workQueues:  make(map[string]*workQueueState),
+		subscribers: make(map[string]*subscriber),
+		elections:   make(map[string]*election),
+		history:     make(map[string][]scheduler.Run),
+	}
+}
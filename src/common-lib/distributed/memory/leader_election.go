@@ -0,0 +1,269 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	leaderelection "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/leader-election"
+)
+
+// peersElection is the election resource used by BecomeALeader
+const peersElection = ""
+
+type (
+	// election keeps the candidates in the order of registration, the first one is the leader
+	election struct {
+		candidates []*candidate
+		// changed is closed and replaced every time the candidates change
+		changed chan struct{}
+	}
+
+	candidate struct {
+		name   string
+		peerID int
+	}
+
+	electorImpl struct {
+		cluster   *Cluster
+		mutex     sync.Mutex
+		peer      *candidate
+		resource  string
+		candidate *candidate
+		// leadership is set while Run is running
+		leadership *leaderelection.Leadership
+	}
+)
+
+// NewLeaderElector returns a leader elector of an instance, there is at most one leader per election resource in the Cluster.
+// The leadership is kept until ResignCandidate or StepDown is called
+func (c *Cluster) NewLeaderElector() leaderelection.Elector {
+	return &electorImpl{cluster: c}
+}
+
+// BecomeALeader registers the instance as a peer on the first call, the peer registered first is the leader
+func (e *electorImpl) BecomeALeader() (int, bool, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.cluster.mutex.Lock()
+	defer e.cluster.mutex.Unlock()
+
+	if e.peer == nil {
+		e.cluster.nextPeerID++
+		e.peer = &candidate{peerID: e.cluster.nextPeerID}
+		e.cluster.join(peersElection, e.peer)
+	}
+	return e.peer.peerID, e.cluster.elections[peersElection].candidates[0] == e.peer, nil
+}
+
+// RegisterCandidate registers the instance as a candidate for the election resource
+func (e *electorImpl) RegisterCandidate(electionResource string, clientName string) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.cluster.mutex.Lock()
+	defer e.cluster.mutex.Unlock()
+
+	if e.candidate != nil {
+		e.cluster.leave(e.resource, e.candidate)
+	}
+	e.resource = electionResource
+	e.candidate = &candidate{name: clientName}
+	e.cluster.join(e.resource, e.candidate)
+	return nil
+}
+
+// StartElection blocks until the candidate becomes the leader, runs the callback and resigns
+func (e *electorImpl) StartElection(callback func()) error {
+	e.mutex.Lock()
+	resource, cand := e.resource, e.candidate
+	e.mutex.Unlock()
+
+	if cand == nil {
+		return ErrNotRegistered
+	}
+
+	for {
+		e.cluster.mutex.Lock()
+		el := e.cluster.elections[resource]
+		if el == nil || indexOf(el.candidates, cand) < 0 {
+			e.cluster.mutex.Unlock()
+			return ErrResigned
+		}
+		if el.candidates[0] == cand {
+			e.cluster.mutex.Unlock()
+			break
+		}
+		changed := el.changed
+		e.cluster.mutex.Unlock()
+		<-changed
+	}
+
+	callback()
+	e.ResignCandidate()
+	return nil
+}
+
+// Run reports the leadership changes of the registered candidate until ctx is done or the candidate resigns
+func (e *electorImpl) Run(ctx context.Context, callbacks leaderelection.Callbacks) error {
+	e.mutex.Lock()
+	resource, cand := e.resource, e.candidate
+	e.mutex.Unlock()
+
+	if cand == nil {
+		return ErrNotRegistered
+	}
+
+	leadership := leaderelection.NewLeadership(ctx, callbacks)
+	e.mutex.Lock()
+	e.leadership = leadership
+	e.mutex.Unlock()
+	defer func() {
+		leadership.End()
+		e.mutex.Lock()
+		e.leadership = nil
+		e.mutex.Unlock()
+	}()
+
+	for {
+		e.cluster.mutex.Lock()
+		el := e.cluster.elections[resource]
+		if el == nil || indexOf(el.candidates, cand) < 0 {
+			e.cluster.mutex.Unlock()
+			return nil
+		}
+		leader := el.candidates[0] == cand
+		changed := el.changed
+		e.cluster.mutex.Unlock()
+
+		if leader {
+			leadership.Gained()
+		} else {
+			leadership.Lost()
+		}
+
+		select {
+		case <-ctx.Done():
+			e.ResignCandidate()
+			return nil
+		case <-changed:
+		}
+	}
+}
+
+// CurrentLeader returns the client name of the first candidate of the election resource
+func (e *electorImpl) CurrentLeader() (string, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.candidate == nil {
+		return "", ErrNotRegistered
+	}
+
+	e.cluster.mutex.Lock()
+	defer e.cluster.mutex.Unlock()
+
+	el := e.cluster.elections[e.resource]
+	if el == nil || len(el.candidates) == 0 {
+		return "", nil
+	}
+	return el.candidates[0].name, nil
+}
+
+// StepDown ends the leadership and moves the candidate to the end of the election, the next candidate becomes the leader at once
+func (e *electorImpl) StepDown() error {
+	e.mutex.Lock()
+	resource, cand, leadership := e.resource, e.candidate, e.leadership
+	e.mutex.Unlock()
+
+	if cand == nil {
+		return ErrNotRegistered
+	}
+	if !e.isFirst(resource, cand) {
+		return nil
+	}
+
+	// the work of the leader is cancelled before the next candidate takes over
+	if leadership != nil {
+		leadership.Lost()
+	}
+
+	e.cluster.mutex.Lock()
+	defer e.cluster.mutex.Unlock()
+	if el := e.cluster.elections[resource]; el != nil && indexOf(el.candidates, cand) == 0 {
+		e.cluster.leave(resource, cand)
+		e.cluster.join(resource, cand)
+	}
+	return nil
+}
+
+func (e *electorImpl) isFirst(resource string, cand *candidate) bool {
+	e.cluster.mutex.Lock()
+	defer e.cluster.mutex.Unlock()
+
+	el := e.cluster.elections[resource]
+	return el != nil && indexOf(el.candidates, cand) == 0
+}
+
+// ResignCandidate withdraws the candidate and the peer of the instance, the leadership passes to the next one in order
+func (e *electorImpl) ResignCandidate() {
+	e.mutex.Lock()
+	leadership := e.leadership
+	e.mutex.Unlock()
+
+	// the work of the leader is cancelled before the next candidate takes over
+	if leadership != nil {
+		leadership.End()
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.cluster.mutex.Lock()
+	defer e.cluster.mutex.Unlock()
+
+	if e.candidate != nil {
+		e.cluster.leave(e.resource, e.candidate)
+		e.candidate = nil
+	}
+	if e.peer != nil {
+		e.cluster.leave(peersElection, e.peer)
+		e.peer = nil
+	}
+}
+
+// join and leave are called with the cluster mutex locked
+func (c *Cluster) join(resource string, cand *candidate) {
+	el, ok := c.elections[resource]
+	if !ok {
+		el = &election{changed: make(chan struct{})}
+		c.elections[resource] = el
+	}
+	el.candidates = append(el.candidates, cand)
+	el.notify()
+}
+
+func (c *Cluster) leave(resource string, cand *candidate) {
+	el, ok := c.elections[resource]
+	if !ok {
+		return
+	}
+	if i := indexOf(el.candidates, cand); i >= 0 {
+		el.candidates = append(el.candidates[:i], el.candidates[i+1:]...)
+		el.notify()
+	}
+}
+
+func (el *election) notify() {
+	close(el.changed)
+	el.changed = make(chan struct{})
+}
+
+func indexOf(candidates []*candidate, cand *candidate) int {
+	for i, c := range candidates {
+		if c == cand {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,210 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	leaderelection "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/leader-election"
+)
+
+func TestBecomeALeader(t *testing.T) {
+	cluster := NewCluster()
+	first := cluster.NewLeaderElector()
+	second := cluster.NewLeaderElector()
+
+	firstID, leader, err := first.BecomeALeader()
+	require.NoError(t, err)
+	assert.True(t, leader)
+
+	secondID, leader, err := second.BecomeALeader()
+	require.NoError(t, err)
+	assert.False(t, leader)
+	assert.NotEqual(t, firstID, secondID)
+
+	id, leader, err := first.BecomeALeader()
+	require.NoError(t, err)
+	assert.True(t, leader)
+	assert.Equal(t, firstID, id, "peer ID must be kept between calls")
+
+	first.ResignCandidate()
+	_, leader, err = second.BecomeALeader()
+	require.NoError(t, err)
+	assert.True(t, leader)
+}
+
+func TestStartElectionOneLeaderAtATime(t *testing.T) {
+	cluster := NewCluster()
+	var (
+		mutex           sync.Mutex
+		leaders, rounds int
+		wg              sync.WaitGroup
+	)
+
+	for i := 0; i < 5; i++ {
+		elector := cluster.NewLeaderElector()
+		require.NoError(t, elector.RegisterCandidate("resource", "candidate"))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, elector.StartElection(func() {
+				mutex.Lock()
+				leaders++
+				rounds++
+				current := leaders
+				mutex.Unlock()
+
+				assert.Equal(t, 1, current, "more than one leader at a time")
+				time.Sleep(time.Millisecond)
+
+				mutex.Lock()
+				leaders--
+				mutex.Unlock()
+			}))
+		}()
+	}
+
+	wg.Wait()
+	assert.Equal(t, 5, rounds)
+}
+
+func TestStartElectionResigned(t *testing.T) {
+	cluster := NewCluster()
+	leader := cluster.NewLeaderElector()
+	follower := cluster.NewLeaderElector()
+
+	assert.Equal(t, ErrNotRegistered, follower.StartElection(func() {}))
+
+	require.NoError(t, leader.RegisterCandidate("resource", "leader"))
+	require.NoError(t, follower.RegisterCandidate("resource", "follower"))
+
+	result := make(chan error)
+	go func() {
+		result <- follower.StartElection(func() {
+			t.Error("resigned candidate became a leader")
+		})
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	follower.ResignCandidate()
+	select {
+	case err := <-result:
+		assert.Equal(t, ErrResigned, err)
+	case <-time.After(time.Second):
+		t.Fatal("election wasn't stopped by resignation")
+	}
+}
+
+// observer records the leadership changes of a candidate
+type observer struct {
+	gained chan context.Context
+	lost   chan struct{}
+}
+
+func newObserver() *observer {
+	return &observer{gained: make(chan context.Context, 10), lost: make(chan struct{}, 10)}
+}
+
+func (o *observer) callbacks() leaderelection.Callbacks {
+	return leaderelection.Callbacks{
+		OnGained: func(ctx context.Context) { o.gained <- ctx },
+		OnLost:   func() { o.lost <- struct{}{} },
+	}
+}
+
+func (o *observer) waitGained(t *testing.T) context.Context {
+	select {
+	case ctx := <-o.gained:
+		return ctx
+	case <-time.After(time.Second):
+		t.Fatal("leadership wasn't gained")
+		return nil
+	}
+}
+
+func (o *observer) waitLost(t *testing.T) {
+	select {
+	case <-o.lost:
+	case <-time.After(time.Second):
+		t.Fatal("leadership wasn't lost")
+	}
+}
+
+func TestRunStepDown(t *testing.T) {
+	cluster := NewCluster()
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	electors := []leaderelection.Elector{cluster.NewLeaderElector(), cluster.NewLeaderElector()}
+	observers := []*observer{newObserver(), newObserver()}
+	for i, name := range []string{"first", "second"} {
+		require.NoError(t, electors[i].RegisterCandidate("resource", name))
+	}
+	for i := range electors {
+		elector, o := electors[i], observers[i]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, elector.Run(ctx, o.callbacks()))
+		}()
+	}
+
+	firstCtx := observers[0].waitGained(t)
+	leader, err := electors[1].CurrentLeader()
+	require.NoError(t, err)
+	assert.Equal(t, "first", leader)
+
+	// a follower can't step down
+	require.NoError(t, electors[1].StepDown())
+	leader, err = electors[0].CurrentLeader()
+	require.NoError(t, err)
+	assert.Equal(t, "first", leader)
+
+	require.NoError(t, electors[0].StepDown())
+	observers[0].waitLost(t)
+	assert.Equal(t, context.Canceled, firstCtx.Err(), "leadership context must be cancelled on step down")
+	secondCtx := observers[1].waitGained(t)
+
+	leader, err = electors[0].CurrentLeader()
+	require.NoError(t, err)
+	assert.Equal(t, "second", leader)
+
+	cancel()
+	wg.Wait()
+	observers[1].waitLost(t)
+	assert.Error(t, secondCtx.Err())
+
+	leader, err = NewCluster().NewLeaderElector().CurrentLeader()
+	assert.Equal(t, ErrNotRegistered, err)
+	assert.Empty(t, leader)
+}
+
+func TestRunResign(t *testing.T) {
+	cluster := NewCluster()
+	elector := cluster.NewLeaderElector()
+	assert.Equal(t, ErrNotRegistered, elector.Run(context.Background(), leaderelection.Callbacks{}))
+
+	require.NoError(t, elector.RegisterCandidate("resource", "candidate"))
+	o := newObserver()
+	done := make(chan error)
+	go func() {
+		done <- elector.Run(context.Background(), o.callbacks())
+	}()
+
+	ctx := o.waitGained(t)
+	elector.ResignCandidate()
+	o.waitLost(t)
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Run didn't return after resignation")
+	}
+}
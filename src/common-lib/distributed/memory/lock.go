@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/lock"
+)
+
+type (
+	// lockState is shared by all the lockers with the same name
+	lockState struct {
+		held  chan struct{}
+		fence int64
+	}
+
+	lockImpl struct {
+		cluster *Cluster
+		state   *lockState
+		mutex   sync.Mutex
+		locked  bool
+	}
+)
+
+// NewLock returns an exclusive lock shared by all the lockers with the same name in the Cluster
+func (c *Cluster) NewLock(name string) lock.ContextLocker {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	state, ok := c.locks[name]
+	if !ok {
+		state = &lockState{held: make(chan struct{}, 1)}
+		c.locks[name] = state
+	}
+	return &lockImpl{cluster: c, state: state}
+}
+
+// Lock - blocks until the lock is acquired
+func (l *lockImpl) Lock() error {
+	_, err := l.LockContext(context.Background())
+	return err
+}
+
+// LockContext - blocks until the lock is acquired or ctx is done, the fencing token grows on every acquisition
+func (l *lockImpl) LockContext(ctx context.Context) (int64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.locked {
+		return 0, lock.Error{Code: ErrDeadlock, Action: lock.TryUnlock}
+	}
+
+	select {
+	case l.state.held <- struct{}{}:
+	case <-ctx.Done():
+		return 0, lock.Error{Code: ctx.Err(), Action: lock.TryLock}
+	}
+	return l.acquired(), nil
+}
+
+// TryLock - acquires the lock only if it is not held by another locker
+func (l *lockImpl) TryLock() (int64, bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.locked {
+		return 0, false, lock.Error{Code: ErrDeadlock, Action: lock.TryUnlock}
+	}
+
+	select {
+	case l.state.held <- struct{}{}:
+		return l.acquired(), true, nil
+	default:
+		return 0, false, nil
+	}
+}
+
+// Unlock - releases the lock held by this locker
+func (l *lockImpl) Unlock() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if !l.locked {
+		return lock.Error{Code: ErrNotLocked, Action: lock.Ignore}
+	}
+	l.locked = false
+	<-l.state.held
+	return nil
+}
+
+func (l *lockImpl) acquired() int64 {
+	l.locked = true
+	l.cluster.mutex.Lock()
+	defer l.cluster.mutex.Unlock()
+	l.state.fence++
+	return l.state.fence
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/lock"
+)
+
+func assertAction(t *testing.T, err error, code error, action lock.ErrorAction) {
+	le, ok := err.(lock.Error)
+	require.True(t, ok, "expected lock.Error, got %v", err)
+	assert.Equal(t, code, le.Code)
+	assert.Equal(t, action, le.Action)
+}
+
+func TestLockIsExclusive(t *testing.T) {
+	cluster := NewCluster()
+	first := cluster.NewLock("job")
+	second := cluster.NewLock("job")
+
+	require.NoError(t, first.Lock())
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- second.Lock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second locker acquired the lock held by the first one")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Unlock())
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("second locker didn't acquire the released lock")
+	}
+	require.NoError(t, second.Unlock())
+}
+
+func TestLockNames(t *testing.T) {
+	cluster := NewCluster()
+	require.NoError(t, cluster.NewLock("first").Lock())
+
+	_, acquired, err := cluster.NewLock("second").TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired, "locks with different names must be independent")
+
+	_, acquired, err = NewCluster().NewLock("first").TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired, "locks of different clusters must be independent")
+}
+
+func TestLockContext(t *testing.T) {
+	cluster := NewCluster()
+	first := cluster.NewLock("job")
+	second := cluster.NewLock("job")
+
+	token, err := first.LockContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = second.LockContext(ctx)
+	assertAction(t, err, context.DeadlineExceeded, lock.TryLock)
+
+	require.NoError(t, first.Unlock())
+	token, err = second.LockContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), token, "fencing token must grow on every acquisition")
+	require.NoError(t, second.Unlock())
+}
+
+func TestTryLock(t *testing.T) {
+	cluster := NewCluster()
+	first := cluster.NewLock("job")
+	second := cluster.NewLock("job")
+
+	token, acquired, err := first.TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, int64(1), token)
+
+	_, acquired, err = second.TryLock()
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	_, _, err = first.TryLock()
+	assertAction(t, err, ErrDeadlock, lock.TryUnlock)
+	assertAction(t, first.Lock(), ErrDeadlock, lock.TryUnlock)
+
+	require.NoError(t, first.Unlock())
+	token, acquired, err = second.TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, int64(2), token)
+	require.NoError(t, second.Unlock())
+}
+
+func TestUnlockNotLocked(t *testing.T) {
+	locker := NewCluster().NewLock("job")
+	assertAction(t, locker.Unlock(), ErrNotLocked, lock.Ignore)
+}
@@ -0,0 +1,123 @@
+package memory
+
+import (
+	"fmt"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+const (
+	itemPrefix    = "queue-"
+	pathSeparator = "/"
+)
+
+type (
+	queueState struct {
+		seq   int
+		names []string
+		items map[string][]byte
+	}
+
+	queueImpl struct {
+		cluster *Cluster
+	}
+)
+
+// Queue returns the distributed queues of the Cluster, items are listed in the order of creation
+func (c *Cluster) Queue() queue.Interface {
+	return queueImpl{cluster: c}
+}
+
+// Create creates a new empty queue
+func (q queueImpl) Create(queueName string) (string, error) {
+	q.cluster.mutex.Lock()
+	defer q.cluster.mutex.Unlock()
+
+	if _, ok := q.cluster.queues[queueName]; ok {
+		return "", ErrQueueExists
+	}
+	q.cluster.queues[queueName] = newQueueState()
+	return queueName, nil
+}
+
+// Exists checks if the queue with provided name already exists
+func (q queueImpl) Exists(queueName string) (bool, error) {
+	q.cluster.mutex.Lock()
+	defer q.cluster.mutex.Unlock()
+
+	_, ok := q.cluster.queues[queueName]
+	return ok, nil
+}
+
+// GetList returns names of the items in the queue, the oldest item first
+func (q queueImpl) GetList(queueName string) ([]string, error) {
+	q.cluster.mutex.Lock()
+	defer q.cluster.mutex.Unlock()
+
+	state, ok := q.cluster.queues[queueName]
+	if !ok {
+		return nil, ErrNoQueue
+	}
+	return append([]string{}, state.names...), nil
+}
+
+// CreateItem adds a new item to the queue, the queue is created if it does not exist
+func (q queueImpl) CreateItem(data []byte, queueName string) (string, error) {
+	q.cluster.mutex.Lock()
+	defer q.cluster.mutex.Unlock()
+
+	state, ok := q.cluster.queues[queueName]
+	if !ok {
+		state = newQueueState()
+		q.cluster.queues[queueName] = state
+	}
+
+	name := fmt.Sprintf("%s%010d", itemPrefix, state.seq)
+	state.seq++
+	state.names = append(state.names, name)
+	state.items[name] = append([]byte(nil), data...)
+	return queueName + pathSeparator + name, nil
+}
+
+// GetItemData gets data associated with the item in the queue
+func (q queueImpl) GetItemData(queueName, itemName string) ([]byte, error) {
+	q.cluster.mutex.Lock()
+	defer q.cluster.mutex.Unlock()
+
+	state, ok := q.cluster.queues[queueName]
+	if !ok {
+		return nil, ErrNoItem
+	}
+	data, ok := state.items[itemName]
+	if !ok {
+		return nil, ErrNoItem
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// RemoveItem removes the named item from the queue
+func (q queueImpl) RemoveItem(queueName, itemName string) error {
+	q.cluster.mutex.Lock()
+	defer q.cluster.mutex.Unlock()
+
+	state, ok := q.cluster.queues[queueName]
+	if !ok {
+		return ErrNoItem
+	}
+	if _, ok = state.items[itemName]; !ok {
+		return ErrNoItem
+	}
+
+	delete(state.items, itemName)
+	for i, name := range state.names {
+		if name == itemName {
+			state.names = append(state.names[:i], state.names[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func newQueueState() *queueState {
+	return &queueState{items: make(map[string][]byte)}
+}
@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	q := NewCluster().Queue()
+
+	exists, err := q.Exists("jobs")
+	require.NoError(t, err)
+	assert.False(t, exists)
+	_, err = q.GetList("jobs")
+	assert.Equal(t, ErrNoQueue, err)
+
+	_, err = q.Create("jobs")
+	require.NoError(t, err)
+	_, err = q.Create("jobs")
+	assert.Equal(t, ErrQueueExists, err)
+
+	exists, err = q.Exists("jobs")
+	require.NoError(t, err)
+	assert.True(t, exists)
+
+	items, err := q.GetList("jobs")
+	require.NoError(t, err)
+	assert.Empty(t, items)
+}
+
+func TestQueueItemsAreFIFO(t *testing.T) {
+	q := NewCluster().Queue()
+
+	for _, data := range []string{"first", "second", "third"} {
+		_, err := q.CreateItem([]byte(data), "jobs")
+		require.NoError(t, err)
+	}
+
+	items, err := q.GetList("jobs")
+	require.NoError(t, err)
+	require.Len(t, items, 3)
+
+	var data []string
+	for _, item := range items {
+		d, err := q.GetItemData("jobs", item)
+		require.NoError(t, err)
+		data = append(data, string(d))
+	}
+	assert.Equal(t, []string{"first", "second", "third"}, data)
+
+	require.NoError(t, q.RemoveItem("jobs", items[1]))
+	assert.Equal(t, ErrNoItem, q.RemoveItem("jobs", items[1]))
+	_, err = q.GetItemData("jobs", items[1])
+	assert.Equal(t, ErrNoItem, err)
+
+	_, err = q.CreateItem([]byte("fourth"), "jobs")
+	require.NoError(t, err)
+	left, err := q.GetList("jobs")
+	require.NoError(t, err)
+	require.Len(t, left, 3)
+	assert.Equal(t, []string{items[0], items[2]}, left[:2])
+}
+
+func TestQueueItemDataIsCopied(t *testing.T) {
+	q := NewCluster().Queue()
+	data := []byte("data")
+
+	_, err := q.CreateItem(data, "jobs")
+	require.NoError(t, err)
+	data[0] = 'D'
+
+	items, err := q.GetList("jobs")
+	require.NoError(t, err)
+	stored, err := q.GetItemData("jobs", items[0])
+	require.NoError(t, err)
+	assert.Equal(t, "data", string(stored))
+}
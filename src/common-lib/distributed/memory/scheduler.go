@@ -0,0 +1,240 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+)
+
+// HistorySize is the number of runs kept in the history of each job
+var HistorySize = 100
+
+type (
+	schedulerImpl struct {
+		cluster *Cluster
+	}
+
+	historyImpl struct {
+		cluster *Cluster
+	}
+
+	// scheduledJob is a job of a leading scheduler
+	scheduledJob struct {
+		cluster  *Cluster
+		name     string
+		task     string
+		node     string
+		schedule scheduler.Schedule
+		policy   scheduler.MisfirePolicy
+	}
+)
+
+// Scheduler returns the distributed scheduler of the Cluster. Like the Zookeeper one, the jobs of
+// DistributedScheduler are run by the leading scheduler only and are dispatched through the queue named by the job task
+// to the DistributedJobListener with the same name. Unlike the Zookeeper one, invalid schedules are reported
+// by DistributedScheduler instead of being skipped
+func (c *Cluster) Scheduler() scheduler.Interface {
+	return schedulerImpl{cluster: c}
+}
+
+// History returns the execution history of the jobs run by the schedulers of the Cluster
+func (c *Cluster) History() scheduler.History {
+	return historyImpl{cluster: c}
+}
+
+// DistributedScheduler runs the jobs while the scheduler is the leader, leadership is checked every interval
+// and resigned when ctx is done
+func (s schedulerImpl) DistributedScheduler(ctx context.Context, wg *sync.WaitGroup, jobs []scheduler.ScheduledJob, interval time.Duration) error {
+	scheduled := make([]scheduledJob, 0, len(jobs))
+	for _, job := range jobs {
+		schedule, err := scheduler.Parse(job.GetSchedule())
+		if err != nil {
+			return fmt.Errorf("memory: job %s: %v", job.GetName(), err)
+		}
+		scheduled = append(scheduled, scheduledJob{
+			cluster:  s.cluster,
+			name:     job.GetName(),
+			task:     job.GetTask(),
+			schedule: schedule,
+			policy:   scheduler.GetMisfirePolicy(job),
+		})
+	}
+
+	elector := s.cluster.NewLeaderElector()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer elector.ResignCandidate()
+
+		var cron *scheduler.Cron
+		defer func() {
+			if cron != nil {
+				cron.Stop()
+			}
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+				peerID, leader, _ := elector.BecomeALeader()
+				if leader && cron == nil {
+					cron = startCron(scheduled, fmt.Sprintf("peer-%d", peerID))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func startCron(jobs []scheduledJob, node string) *scheduler.Cron {
+	cron := scheduler.NewCron()
+	for _, job := range jobs {
+		job.node = node
+		cron.Add(job.name, job.schedule, job.run)
+		job.catchUp()
+	}
+	cron.Start()
+	return cron
+}
+
+func (j scheduledJob) run() {
+	_ = j.dispatcher().Dispatch(j.name, j.task, time.Now(), false)
+}
+
+// catchUp makes the runs of the job missed since its last run according to the job misfire policy
+func (j scheduledJob) catchUp() {
+	_ = j.dispatcher().CatchUp(j.name, j.task, j.schedule, j.policy)
+}
+
+// dispatcher sends the runs through the cluster queue and keeps them in the cluster history
+func (j scheduledJob) dispatcher() scheduler.Dispatcher {
+	return scheduler.Dispatcher{Queue: j.cluster.Queue(), Runs: historyImpl{cluster: j.cluster}, Node: j.node}
+}
+
+// DistributedJobListener runs the callback of each job with the data of the queued items, every interval
+// the job queue is processed by the single listener holding the job lock
+func (s schedulerImpl) DistributedJobListener(ctx context.Context, wg *sync.WaitGroup, jobs []scheduler.DistributedJob, interval time.Duration) error {
+	for _, job := range jobs {
+		s.listen(ctx, wg, job, interval)
+	}
+	return nil
+}
+
+func (s schedulerImpl) listen(ctx context.Context, wg *sync.WaitGroup, job scheduler.DistributedJob, interval time.Duration) {
+	locker := s.cluster.NewLock(job.GetName())
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(interval):
+				if _, err := locker.LockContext(ctx); err != nil {
+					continue
+				}
+				s.process(ctx, job)
+				_ = locker.Unlock()
+			}
+		}
+	}()
+}
+
+func (s schedulerImpl) process(ctx context.Context, job scheduler.DistributedJob) {
+	queue := s.cluster.Queue()
+	items, err := queue.GetList(job.GetName())
+	if err != nil || len(items) == 0 {
+		return
+	}
+
+	var itemsData [][]byte
+	for _, item := range items {
+		data, err := queue.GetItemData(job.GetName(), item)
+		if err != nil {
+			continue
+		}
+		if len(data) != 0 {
+			itemsData = append(itemsData, data)
+		}
+		_ = queue.RemoveItem(job.GetName(), item)
+	}
+	_ = scheduler.RunCallback(ctx, historyImpl{cluster: s.cluster}, job, itemsData)
+}
+
+// LastRuns returns up to n latest runs of the job, the latest run first
+func (h historyImpl) LastRuns(jobName string, n int) ([]scheduler.Run, error) {
+	return h.cluster.lastRuns(jobName, n), nil
+}
+
+// LastRun returns the last dispatched run of the job, the run is empty when the job has never run
+func (h historyImpl) LastRun(jobName string) (scheduler.Run, error) {
+	if runs := h.cluster.lastRuns(jobName, 1); len(runs) > 0 {
+		return runs[0], nil
+	}
+	return scheduler.Run{}, nil
+}
+
+// AddRun adds the run to the job history, the ID of the run is returned
+func (h historyImpl) AddRun(run scheduler.Run) (string, error) {
+	return h.cluster.recordRun(run), nil
+}
+
+// UpdateRun applies the update to the run of the job history, a run already removed from the history is ignored
+func (h historyImpl) UpdateRun(jobName, id string, update func(*scheduler.Run)) error {
+	h.cluster.updateRun(scheduler.RunRef{Job: jobName, Run: id}, update)
+	return nil
+}
+
+func (c *Cluster) lastRuns(jobName string, n int) []scheduler.Run {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	history := c.history[jobName]
+	if n > len(history) {
+		n = len(history)
+	}
+	if n <= 0 {
+		return nil
+	}
+
+	runs := make([]scheduler.Run, 0, n)
+	for i := len(history) - 1; i >= len(history)-n; i-- {
+		runs = append(runs, history[i])
+	}
+	return runs
+}
+
+// recordRun adds the run to the job history keeping HistorySize latest runs, the ID of the run is returned
+func (c *Cluster) recordRun(run scheduler.Run) string {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.runSequence++
+	run.ID = fmt.Sprintf("run-%010d", c.runSequence)
+	history := append(c.history[run.Job], run)
+	if len(history) > HistorySize {
+		history = history[len(history)-HistorySize:]
+	}
+	c.history[run.Job] = history
+	return run.ID
+}
+
+// updateRun applies the update to the run of the job history, a run already removed from the history is ignored
+func (c *Cluster) updateRun(ref scheduler.RunRef, update func(*scheduler.Run)) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	history := c.history[ref.Job]
+	for i := range history {
+		if history[i].ID == ref.Run {
+			update(&history[i])
+			return
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+)
+
+type (
+	testScheduledJob struct {
+		name, task, schedule string
+		policy               scheduler.MisfirePolicy
+	}
+
+	testDistributedJob struct {
+		name  string
+		mutex sync.Mutex
+		calls int
+	}
+
+	// blockingDistributedJob runs until release is closed
+	blockingDistributedJob struct {
+		name    string
+		started chan struct{}
+		release chan struct{}
+	}
+)
+
+func (j testScheduledJob) GetName() string                           { return j.name }
+func (j testScheduledJob) GetTask() string                           { return j.task }
+func (j testScheduledJob) GetSchedule() string                       { return j.schedule }
+func (j testScheduledJob) GetMisfirePolicy() scheduler.MisfirePolicy { return j.policy }
+
+func (j *testDistributedJob) GetName() string { return j.name }
+
+func (j *testDistributedJob) Callback(i ...interface{}) {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	j.calls++
+}
+
+func (j *testDistributedJob) getCalls() int {
+	j.mutex.Lock()
+	defer j.mutex.Unlock()
+	return j.calls
+}
+
+func (j *blockingDistributedJob) GetName() string { return j.name }
+
+func (j *blockingDistributedJob) Callback(i ...interface{}) {
+	select {
+	case j.started <- struct{}{}:
+	default:
+	}
+	<-j.release
+}
+
+func TestScheduler(t *testing.T) {
+	cluster := NewCluster()
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+
+	jobs := []scheduler.ScheduledJob{testScheduledJob{name: "job", task: "task", schedule: "@every 1s"}}
+	for i := 0; i < 3; i++ {
+		require.NoError(t, cluster.Scheduler().DistributedScheduler(ctx, wg, jobs, time.Millisecond))
+	}
+	listener := &testDistributedJob{name: "task"}
+	require.NoError(t, cluster.Scheduler().DistributedJobListener(ctx, wg, []scheduler.DistributedJob{listener}, time.Millisecond))
+
+	assert.Eventually(t, func() bool { return listener.getCalls() > 0 }, 3*time.Second, 10*time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	runs, err := cluster.History().LastRuns("job", 10)
+	require.NoError(t, err)
+	require.NotEmpty(t, runs)
+	assert.Equal(t, "job", runs[0].Job)
+	// the latest run can still be pending when the listener stopped
+	completed := runs[len(runs)-1]
+	assert.Equal(t, scheduler.OutcomeSucceeded, completed.Outcome)
+	assert.False(t, completed.StartedAt.Before(completed.DispatchedAt))
+	for _, run := range runs {
+		assert.Equal(t, runs[0].Node, run.Node, "jobs must be run by the leader only")
+	}
+}
+
+func TestSchedulerRunOutcome(t *testing.T) {
+	cluster := NewCluster()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	wg := &sync.WaitGroup{}
+
+	jobs := []scheduler.ScheduledJob{testScheduledJob{name: "job", task: "task", schedule: "@every 1s"}}
+	require.NoError(t, cluster.Scheduler().DistributedScheduler(ctx, wg, jobs, time.Millisecond))
+
+	assert.Eventually(t, func() bool {
+		runs, _ := cluster.History().LastRuns("job", 1)
+		return len(runs) == 1 && runs[0].Outcome == scheduler.OutcomePending
+	}, 3*time.Second, time.Millisecond)
+
+	release := make(chan struct{})
+	listener := &blockingDistributedJob{name: "task", started: make(chan struct{}, 1), release: release}
+	require.NoError(t, cluster.Scheduler().DistributedJobListener(ctx, wg, []scheduler.DistributedJob{listener}, time.Millisecond))
+	<-listener.started
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+
+	assert.Eventually(t, func() bool {
+		runs, _ := cluster.History().LastRuns("job", 10)
+		return len(runs) > 0 && runs[len(runs)-1].Outcome == scheduler.OutcomeSucceeded
+	}, time.Second, time.Millisecond)
+	runs, err := cluster.History().LastRuns("job", 10)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, runs[len(runs)-1].Duration, 20*time.Millisecond, "the duration is the time taken by the job")
+	cancel()
+	wg.Wait()
+}
+
+func TestSchedulerInvalidSchedule(t *testing.T) {
+	jobs := []scheduler.ScheduledJob{testScheduledJob{name: "job", task: "task", schedule: "invalid"}}
+	err := NewCluster().Scheduler().DistributedScheduler(context.Background(), &sync.WaitGroup{}, jobs, time.Millisecond)
+	assert.Error(t, err)
+}
+
+func TestSchedulerCatchUp(t *testing.T) {
+	cluster := NewCluster()
+	lastRun := time.Now().Add(-time.Hour).Truncate(time.Minute)
+	cluster.recordRun(scheduler.Run{Job: "job", ScheduledAt: lastRun})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	jobs := []scheduler.ScheduledJob{
+		testScheduledJob{name: "job", task: "task", schedule: "@hourly", policy: scheduler.MisfireRunOnce},
+	}
+	require.NoError(t, cluster.Scheduler().DistributedScheduler(ctx, wg, jobs, time.Millisecond))
+
+	assert.Eventually(t, func() bool {
+		runs, _ := cluster.History().LastRuns("job", 1)
+		return len(runs) == 1 && runs[0].CatchUp
+	}, time.Second, time.Millisecond)
+	cancel()
+	wg.Wait()
+
+	items, err := cluster.Queue().GetList("task")
+	require.NoError(t, err)
+	assert.Len(t, items, 1)
+}
+
+func TestHistorySize(t *testing.T) {
+	cluster := NewCluster()
+	for i := 0; i < HistorySize+10; i++ {
+		cluster.recordRun(scheduler.Run{Job: "job", Node: string(rune('a' + i%26))})
+	}
+
+	runs, err := cluster.History().LastRuns("job", HistorySize*2)
+	require.NoError(t, err)
+	assert.Len(t, runs, HistorySize)
+
+	runs, err = cluster.History().LastRuns("job", 1)
+	require.NoError(t, err)
+	assert.Equal(t, string(rune('a'+(HistorySize+9)%26)), runs[0].Node)
+}
@@ -0,0 +1,124 @@
+package memory
+
+import (
+	"fmt"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+type (
+	workQueueState struct {
+		seq   int
+		names []string
+		items map[string]*workQueueItem
+	}
+
+	workQueueItem struct {
+		data    []byte
+		version int64
+	}
+
+	workQueueStore struct {
+		cluster *Cluster
+	}
+)
+
+// WorkQueueStore returns the storage of the work queues of the Cluster, to be used with queue.NewWorkQueue
+func (c *Cluster) WorkQueueStore() queue.Store {
+	return workQueueStore{cluster: c}
+}
+
+// List returns names of the items in the queue, the oldest item first
+func (s workQueueStore) List(queueName string) ([]string, error) {
+	s.cluster.mutex.Lock()
+	defer s.cluster.mutex.Unlock()
+
+	state, ok := s.cluster.workQueues[queueName]
+	if !ok {
+		return nil, queue.ErrNotFound
+	}
+	return append([]string{}, state.names...), nil
+}
+
+// Get returns data and version of the item
+func (s workQueueStore) Get(queueName, itemName string) ([]byte, int64, error) {
+	s.cluster.mutex.Lock()
+	defer s.cluster.mutex.Unlock()
+
+	item, err := s.item(queueName, itemName)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append([]byte(nil), item.data...), item.version, nil
+}
+
+// Create adds a new item to the queue, the queue is created if it does not exist
+func (s workQueueStore) Create(queueName string, data []byte) (string, error) {
+	s.cluster.mutex.Lock()
+	defer s.cluster.mutex.Unlock()
+
+	state, ok := s.cluster.workQueues[queueName]
+	if !ok {
+		state = &workQueueState{items: make(map[string]*workQueueItem)}
+		s.cluster.workQueues[queueName] = state
+	}
+
+	name := fmt.Sprintf("%s%010d", itemPrefix, state.seq)
+	state.seq++
+	state.names = append(state.names, name)
+	state.items[name] = &workQueueItem{data: append([]byte(nil), data...)}
+	return name, nil
+}
+
+// Update replaces data of the item if its version matches
+func (s workQueueStore) Update(queueName, itemName string, data []byte, version int64) error {
+	s.cluster.mutex.Lock()
+	defer s.cluster.mutex.Unlock()
+
+	item, err := s.item(queueName, itemName)
+	if err != nil {
+		return err
+	}
+	if item.version != version {
+		return queue.ErrConflict
+	}
+	item.data = append([]byte(nil), data...)
+	item.version++
+	return nil
+}
+
+// Delete removes the item if its version matches
+func (s workQueueStore) Delete(queueName, itemName string, version int64) error {
+	s.cluster.mutex.Lock()
+	defer s.cluster.mutex.Unlock()
+
+	item, err := s.item(queueName, itemName)
+	if err != nil {
+		return err
+	}
+	if item.version != version {
+		return queue.ErrConflict
+	}
+
+	state := s.cluster.workQueues[queueName]
+	delete(state.items, itemName)
+	for i, name := range state.names {
+		if name == itemName {
+			state.names = append(state.names[:i], state.names[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+func (s workQueueStore) item(queueName, itemName string) (*workQueueItem, error) {
+	state, ok := s.cluster.workQueues[queueName]
+	if !ok {
+		return nil, queue.ErrNotFound
+	}
+	item, ok := state.items[itemName]
+	if !ok {
+		return nil, queue.ErrNotFound
+	}
+	return item, nil
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+func TestWorkQueueStore(t *testing.T) {
+	s := NewCluster().WorkQueueStore()
+
+	_, err := s.List("jobs")
+	assert.Equal(t, queue.ErrNotFound, err)
+
+	first, err := s.Create("jobs", []byte("first"))
+	require.NoError(t, err)
+	second, err := s.Create("jobs", []byte("second"))
+	require.NoError(t, err)
+
+	items, err := s.List("jobs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{first, second}, items)
+
+	data, version, err := s.Get("jobs", first)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("first"), data)
+
+	require.NoError(t, s.Update("jobs", first, []byte("updated"), version))
+	assert.Equal(t, queue.ErrConflict, s.Update("jobs", first, []byte("stale"), version))
+	assert.Equal(t, queue.ErrConflict, s.Delete("jobs", first, version))
+
+	data, version, err = s.Get("jobs", first)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("updated"), data)
+	require.NoError(t, s.Delete("jobs", first, version))
+
+	_, _, err = s.Get("jobs", first)
+	assert.Equal(t, queue.ErrNotFound, err)
+	items, err = s.List("jobs")
+	require.NoError(t, err)
+	assert.Equal(t, []string{second}, items)
+}
+
+func TestWorkQueue(t *testing.T) {
+	q := queue.NewWorkQueue(NewCluster().WorkQueueStore(), "jobs", queue.WorkQueueConfig{})
+
+	_, err := q.Publish([]byte("low"), queue.PublishOptions{})
+	require.NoError(t, err)
+	_, err = q.Publish([]byte("high"), queue.PublishOptions{Priority: 1})
+	require.NoError(t, err)
+
+	msg, err := q.Claim()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("high"), msg.Data)
+	require.NoError(t, q.Ack(msg))
+
+	msg, err = q.Claim()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("low"), msg.Data)
+	require.NoError(t, q.Ack(msg))
+
+	_, err = q.Claim()
+	assert.Equal(t, queue.ErrEmpty, err)
+}
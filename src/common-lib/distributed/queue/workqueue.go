@@ -0,0 +1,345 @@
+package queue
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	defaultVisibilityTimeout = 30 * time.Second
+	defaultMaxDeliveries     = 5
+	defaultDeadLetterSuffix  = ".dlq"
+)
+
+var (
+	// ErrEmpty is returned by Claim when no message is available
+	ErrEmpty = errors.New("queue: no message available")
+	// ErrClaimExpired is returned by Ack and Nack when the visibility timeout of the claim has expired,
+	// the message was or will be delivered again
+	ErrClaimExpired = errors.New("queue: claim has expired")
+	// ErrNotFound is returned by Store when the item does not exist
+	ErrNotFound = errors.New("queue: item not found")
+	// ErrConflict is returned by Store when the item was changed by someone else
+	ErrConflict = errors.New("queue: item version conflict")
+)
+
+type (
+	// Store - versioned storage of the work queue items, implemented by the distributed backends.
+	// Every change is conditional on the item version, which makes a claim atomic across the consumers
+	Store interface {
+		// List returns names of the items in the queue in the order of creation
+		List(queueName string) ([]string, error)
+		// Get returns data and version of the item, ErrNotFound when the item does not exist
+		Get(queueName, itemName string) ([]byte, int64, error)
+		// Create adds a new item to the queue, the queue is created if it does not exist
+		Create(queueName string, data []byte) (string, error)
+		// Update replaces data of the item, ErrConflict when the version does not match
+		Update(queueName, itemName string, data []byte, version int64) error
+		// Delete removes the item, ErrConflict when the version does not match
+		Delete(queueName, itemName string, version int64) error
+	}
+
+	// WorkQueueConfig - configuration of a WorkQueue
+	WorkQueueConfig struct {
+		// VisibilityTimeout is the time a claimed message is hidden from other consumers,
+		// a message which is not acknowledged in time is delivered again
+		// Default is 30 seconds
+		VisibilityTimeout time.Duration
+		// MaxDeliveries is the number of deliveries after which an unacknowledged message is moved to the dead-letter queue
+		// Default is 5
+		MaxDeliveries int
+		// DeadLetterSuffix is appended to the queue name to get the name of the dead-letter queue
+		// Default is ".dlq"
+		DeadLetterSuffix string
+	}
+
+	// PublishOptions - delivery options of a message
+	PublishOptions struct {
+		// Priority - messages with higher priority are delivered first, messages with equal priority in the order of publishing
+		Priority int
+		// Delay - the message is not delivered before the delay passes
+		Delay time.Duration
+	}
+
+	// Message - message claimed by a consumer
+	Message struct {
+		ID          string
+		Data        []byte
+		Priority    int
+		PublishedAt time.Time
+		// Deliveries is the number of deliveries of the message including this one
+		Deliveries int
+		receipt    string
+	}
+
+	// WorkQueue - queue with at-least-once delivery. A claimed message is hidden from other consumers until
+	// it is acknowledged, returned by Nack or its visibility timeout expires
+	WorkQueue struct {
+		store  Store
+		name   string
+		config WorkQueueConfig
+		now    func() time.Time
+	}
+
+	// record is the stored state of a message
+	record struct {
+		Data        []byte    `json:"data"`
+		Priority    int       `json:"priority"`
+		PublishedAt time.Time `json:"publishedAt"`
+		VisibleAt   time.Time `json:"visibleAt"`
+		Deliveries  int       `json:"deliveries"`
+		Receipt     string    `json:"receipt,omitempty"`
+	}
+
+	// candidate is a message available for delivery
+	candidate struct {
+		name    string
+		version int64
+		record  record
+	}
+)
+
+// NewWorkQueue returns the work queue with the given name kept in the store
+func NewWorkQueue(store Store, queueName string, config WorkQueueConfig) *WorkQueue {
+	if config.VisibilityTimeout <= 0 {
+		config.VisibilityTimeout = defaultVisibilityTimeout
+	}
+	if config.MaxDeliveries <= 0 {
+		config.MaxDeliveries = defaultMaxDeliveries
+	}
+	if config.DeadLetterSuffix == "" {
+		config.DeadLetterSuffix = defaultDeadLetterSuffix
+	}
+	return &WorkQueue{store: store, name: queueName, config: config, now: time.Now}
+}
+
+// Name returns name of the queue
+func (q *WorkQueue) Name() string {
+	return q.name
+}
+
+// DeadLetters returns the dead-letter queue, its messages can be claimed for inspection or published again
+func (q *WorkQueue) DeadLetters() *WorkQueue {
+	return &WorkQueue{store: q.store, name: q.name + q.config.DeadLetterSuffix, config: q.config, now: q.now}
+}
+
+// Publish adds a message to the queue and returns its ID
+func (q *WorkQueue) Publish(data []byte, options PublishOptions) (string, error) {
+	now := q.now()
+	return q.create(q.name, record{
+		Data:        data,
+		Priority:    options.Priority,
+		PublishedAt: now,
+		VisibleAt:   now.Add(options.Delay),
+	})
+}
+
+// Claim delivers the available message with the highest priority, ErrEmpty is returned when there is none.
+// The message has to be acknowledged within the visibility timeout
+func (q *WorkQueue) Claim() (*Message, error) {
+	for {
+		c, err := q.next()
+		if err != nil {
+			return nil, err
+		}
+
+		if c.record.Deliveries >= q.config.MaxDeliveries {
+			err = q.deadLetter(c)
+		} else {
+			var msg *Message
+			if msg, err = q.claim(c); err == nil {
+				return msg, nil
+			}
+		}
+		// looking for another message if this one was dead-lettered, claimed or removed by another consumer in the meantime
+		if err != nil && err != ErrConflict && err != ErrNotFound {
+			return nil, err
+		}
+	}
+}
+
+// next returns the visible message with the highest priority
+func (q *WorkQueue) next() (*candidate, error) {
+	items, err := q.store.List(q.name)
+	if err == ErrNotFound {
+		return nil, ErrEmpty
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		best *candidate
+		now  = q.now()
+	)
+	for _, item := range items {
+		data, version, err := q.store.Get(q.name, item)
+		if err == ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var r record
+		if err = json.Unmarshal(data, &r); err != nil {
+			return nil, err
+		}
+		// items are listed in the order of creation, so the first one wins among equal priorities
+		if r.VisibleAt.After(now) || (best != nil && r.Priority <= best.record.Priority) {
+			continue
+		}
+		best = &candidate{name: item, version: version, record: r}
+	}
+
+	if best == nil {
+		return nil, ErrEmpty
+	}
+	return best, nil
+}
+
+func (q *WorkQueue) claim(c *candidate) (*Message, error) {
+	receipt, err := newReceipt()
+	if err != nil {
+		return nil, err
+	}
+
+	r := c.record
+	r.Deliveries++
+	r.Receipt = receipt
+	r.VisibleAt = q.now().Add(q.config.VisibilityTimeout)
+	if err = q.update(c.name, r, c.version); err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		ID:          c.name,
+		Data:        r.Data,
+		Priority:    r.Priority,
+		PublishedAt: r.PublishedAt,
+		Deliveries:  r.Deliveries,
+		receipt:     receipt,
+	}, nil
+}
+
+// Ack removes the processed message from the queue
+func (q *WorkQueue) Ack(msg *Message) error {
+	c, err := q.claimed(msg)
+	if err != nil {
+		return err
+	}
+	return q.expireOnConflict(q.store.Delete(q.name, c.name, c.version))
+}
+
+// Nack returns the message to the queue to be delivered again after the delay,
+// the message is moved to the dead-letter queue when it has reached MaxDeliveries
+func (q *WorkQueue) Nack(msg *Message, delay time.Duration) error {
+	c, err := q.claimed(msg)
+	if err != nil {
+		return err
+	}
+
+	if c.record.Deliveries >= q.config.MaxDeliveries {
+		return q.expireOnConflict(q.deadLetter(c))
+	}
+
+	r := c.record
+	r.Receipt = ""
+	r.VisibleAt = q.now().Add(delay)
+	return q.expireOnConflict(q.update(c.name, r, c.version))
+}
+
+// Extend prolongs the claim of the message by the visibility timeout, for the processing taking longer than expected
+func (q *WorkQueue) Extend(msg *Message) error {
+	c, err := q.claimed(msg)
+	if err != nil {
+		return err
+	}
+
+	r := c.record
+	r.VisibleAt = q.now().Add(q.config.VisibilityTimeout)
+	return q.expireOnConflict(q.update(c.name, r, c.version))
+}
+
+// claimed returns the message if it is still claimed with the receipt of msg
+func (q *WorkQueue) claimed(msg *Message) (*candidate, error) {
+	data, version, err := q.store.Get(q.name, msg.ID)
+	if err == ErrNotFound {
+		return nil, ErrClaimExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var r record
+	if err = json.Unmarshal(data, &r); err != nil {
+		return nil, err
+	}
+	if r.Receipt != msg.receipt || !r.VisibleAt.After(q.now()) {
+		return nil, ErrClaimExpired
+	}
+	return &candidate{name: msg.ID, version: version, record: r}, nil
+}
+
+// deadLetter moves the message to the dead-letter queue, it is available there at once with the deliveries reset.
+// The copy is created first, so the message isn't lost when the consumer stops in between, and the message is only
+// removed while unchanged: on a conflict another consumer has handled it in the meantime and the copy is dropped
+func (q *WorkQueue) deadLetter(c *candidate) error {
+	r := c.record
+	r.Receipt = ""
+	r.Deliveries = 0
+	r.VisibleAt = q.now()
+	deadLetters := q.name + q.config.DeadLetterSuffix
+	item, err := q.create(deadLetters, r)
+	if err != nil {
+		return err
+	}
+
+	err = q.store.Delete(q.name, c.name, c.version)
+	if err == ErrConflict || err == ErrNotFound {
+		q.drop(deadLetters, item)
+	}
+	return err
+}
+
+// drop removes the item unless it was changed in the meantime
+func (q *WorkQueue) drop(queueName, item string) {
+	if _, version, err := q.store.Get(queueName, item); err == nil {
+		_ = q.store.Delete(queueName, item, version)
+	}
+}
+
+func (q *WorkQueue) create(queueName string, r record) (string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", err
+	}
+	return q.store.Create(queueName, data)
+}
+
+func (q *WorkQueue) update(item string, r record, version int64) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	return q.store.Update(q.name, item, data, version)
+}
+
+// expireOnConflict reports a change made by another consumer as the expired claim
+func (q *WorkQueue) expireOnConflict(err error) error {
+	if err == ErrConflict || err == ErrNotFound {
+		return ErrClaimExpired
+	}
+	return err
+}
+
+func newReceipt() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
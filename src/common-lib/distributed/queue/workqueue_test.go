@@ -0,0 +1,349 @@
+package queue
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	storeItem struct {
+		data    []byte
+		version int64
+	}
+
+	mapStore struct {
+		mutex sync.Mutex
+		seq   int
+		names map[string][]string
+		items map[string]*storeItem
+	}
+)
+
+func newMapStore() *mapStore {
+	return &mapStore{names: make(map[string][]string), items: make(map[string]*storeItem)}
+}
+
+func (s *mapStore) List(queueName string) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	names, ok := s.names[queueName]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]string{}, names...), nil
+}
+
+func (s *mapStore) Get(queueName, itemName string) ([]byte, int64, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.items[queueName+"/"+itemName]
+	if !ok {
+		return nil, 0, ErrNotFound
+	}
+	return item.data, item.version, nil
+}
+
+func (s *mapStore) Create(queueName string, data []byte) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	name := fmt.Sprintf("item-%05d", s.seq)
+	s.seq++
+	s.names[queueName] = append(s.names[queueName], name)
+	s.items[queueName+"/"+name] = &storeItem{data: data}
+	return name, nil
+}
+
+func (s *mapStore) Update(queueName, itemName string, data []byte, version int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.items[queueName+"/"+itemName]
+	if !ok {
+		return ErrNotFound
+	}
+	if item.version != version {
+		return ErrConflict
+	}
+	item.data = data
+	item.version++
+	return nil
+}
+
+func (s *mapStore) Delete(queueName, itemName string, version int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	item, ok := s.items[queueName+"/"+itemName]
+	if !ok {
+		return ErrNotFound
+	}
+	if item.version != version {
+		return ErrConflict
+	}
+	delete(s.items, queueName+"/"+itemName)
+	names := s.names[queueName]
+	for i, name := range names {
+		if name == itemName {
+			s.names[queueName] = append(names[:i], names[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type clock struct {
+	now time.Time
+}
+
+func (c *clock) Now() time.Time {
+	return c.now
+}
+
+func newTestQueue(config WorkQueueConfig) (*WorkQueue, *clock) {
+	c := &clock{now: time.Date(2021, 6, 1, 10, 0, 0, 0, time.UTC)}
+	q := NewWorkQueue(newMapStore(), "jobs", config)
+	q.now = c.Now
+	return q, c
+}
+
+func TestWorkQueueDefaults(t *testing.T) {
+	q := NewWorkQueue(newMapStore(), "jobs", WorkQueueConfig{})
+	assert.Equal(t, defaultVisibilityTimeout, q.config.VisibilityTimeout)
+	assert.Equal(t, defaultMaxDeliveries, q.config.MaxDeliveries)
+	assert.Equal(t, "jobs.dlq", q.DeadLetters().Name())
+}
+
+func TestWorkQueueClaimAck(t *testing.T) {
+	q, _ := newTestQueue(WorkQueueConfig{})
+
+	_, err := q.Claim()
+	assert.Equal(t, ErrEmpty, err)
+
+	id, err := q.Publish([]byte("job"), PublishOptions{})
+	require.NoError(t, err)
+
+	msg, err := q.Claim()
+	require.NoError(t, err)
+	assert.Equal(t, id, msg.ID)
+	assert.Equal(t, []byte("job"), msg.Data)
+	assert.Equal(t, 1, msg.Deliveries)
+
+	// claimed message is hidden from other consumers
+	_, err = q.Claim()
+	assert.Equal(t, ErrEmpty, err)
+
+	require.NoError(t, q.Ack(msg))
+	assert.Equal(t, ErrClaimExpired, q.Ack(msg))
+	_, err = q.Claim()
+	assert.Equal(t, ErrEmpty, err)
+}
+
+func TestWorkQueuePriorities(t *testing.T) {
+	q, _ := newTestQueue(WorkQueueConfig{})
+
+	for _, p := range []struct {
+		data     string
+		priority int
+	}{{"low", 0}, {"high", 10}, {"medium", 5}, {"high-second", 10}} {
+		_, err := q.Publish([]byte(p.data), PublishOptions{Priority: p.priority})
+		require.NoError(t, err)
+	}
+
+	var got []string
+	for {
+		msg, err := q.Claim()
+		if err == ErrEmpty {
+			break
+		}
+		require.NoError(t, err)
+		got = append(got, string(msg.Data))
+	}
+	assert.Equal(t, []string{"high", "high-second", "medium", "low"}, got)
+}
+
+func TestWorkQueueDelay(t *testing.T) {
+	q, c := newTestQueue(WorkQueueConfig{})
+
+	_, err := q.Publish([]byte("later"), PublishOptions{Delay: time.Minute})
+	require.NoError(t, err)
+
+	_, err = q.Claim()
+	assert.Equal(t, ErrEmpty, err)
+
+	c.now = c.now.Add(time.Minute)
+	msg, err := q.Claim()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("later"), msg.Data)
+}
+
+func TestWorkQueueVisibilityTimeout(t *testing.T) {
+	q, c := newTestQueue(WorkQueueConfig{VisibilityTimeout: 10 * time.Second})
+
+	_, err := q.Publish([]byte("job"), PublishOptions{})
+	require.NoError(t, err)
+
+	first, err := q.Claim()
+	require.NoError(t, err)
+
+	c.now = c.now.Add(5 * time.Second)
+	require.NoError(t, q.Extend(first))
+
+	c.now = c.now.Add(9 * time.Second)
+	_, err = q.Claim()
+	assert.Equal(t, ErrEmpty, err)
+
+	c.now = c.now.Add(time.Second)
+	second, err := q.Claim()
+	require.NoError(t, err)
+	assert.Equal(t, first.ID, second.ID)
+	assert.Equal(t, 2, second.Deliveries)
+
+	// the first consumer has lost the message
+	assert.Equal(t, ErrClaimExpired, q.Ack(first))
+	assert.Equal(t, ErrClaimExpired, q.Nack(first, 0))
+	require.NoError(t, q.Ack(second))
+}
+
+func TestWorkQueueNack(t *testing.T) {
+	q, c := newTestQueue(WorkQueueConfig{})
+
+	_, err := q.Publish([]byte("job"), PublishOptions{})
+	require.NoError(t, err)
+
+	msg, err := q.Claim()
+	require.NoError(t, err)
+	require.NoError(t, q.Nack(msg, time.Second))
+
+	_, err = q.Claim()
+	assert.Equal(t, ErrEmpty, err)
+
+	c.now = c.now.Add(time.Second)
+	msg, err = q.Claim()
+	require.NoError(t, err)
+	assert.Equal(t, 2, msg.Deliveries)
+}
+
+func TestWorkQueueDeadLetters(t *testing.T) {
+	q, c := newTestQueue(WorkQueueConfig{VisibilityTimeout: time.Second, MaxDeliveries: 2})
+
+	_, err := q.Publish([]byte("poison"), PublishOptions{Priority: 3})
+	require.NoError(t, err)
+
+	// the first delivery is returned, the second one expires
+	msg, err := q.Claim()
+	require.NoError(t, err)
+	require.NoError(t, q.Nack(msg, 0))
+	_, err = q.Claim()
+	require.NoError(t, err)
+
+	c.now = c.now.Add(time.Second)
+	_, err = q.Claim()
+	assert.Equal(t, ErrEmpty, err)
+
+	dead, err := q.DeadLetters().Claim()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("poison"), dead.Data)
+	assert.Equal(t, 3, dead.Priority)
+	assert.Equal(t, 1, dead.Deliveries)
+}
+
+// conflictStore changes the item before its first conditional delete, like a concurrent consumer does
+type conflictStore struct {
+	*mapStore
+	changed bool
+}
+
+func (s *conflictStore) Delete(queueName, itemName string, version int64) error {
+	if !s.changed {
+		s.changed = true
+		data, _, err := s.Get(queueName, itemName)
+		if err != nil {
+			return err
+		}
+		if err = s.Update(queueName, itemName, data, version); err != nil {
+			return err
+		}
+	}
+	return s.mapStore.Delete(queueName, itemName, version)
+}
+
+func TestWorkQueueDeadLetterConflict(t *testing.T) {
+	store := &conflictStore{mapStore: newMapStore()}
+	q := NewWorkQueue(store, "jobs", WorkQueueConfig{MaxDeliveries: 1})
+
+	_, err := q.Publish([]byte("poison"), PublishOptions{})
+	require.NoError(t, err)
+	msg, err := q.Claim()
+	require.NoError(t, err)
+
+	assert.Equal(t, ErrClaimExpired, q.Nack(msg, 0))
+	items, err := store.List("jobs")
+	require.NoError(t, err)
+	assert.Len(t, items, 1, "the message changed by another consumer is kept")
+	_, err = q.DeadLetters().Claim()
+	assert.Equal(t, ErrEmpty, err, "the copy of the message handled by another consumer is dropped")
+}
+
+func TestWorkQueueNackAfterMaxDeliveries(t *testing.T) {
+	q, _ := newTestQueue(WorkQueueConfig{MaxDeliveries: 1})
+
+	_, err := q.Publish([]byte("poison"), PublishOptions{})
+	require.NoError(t, err)
+
+	msg, err := q.Claim()
+	require.NoError(t, err)
+	require.NoError(t, q.Nack(msg, 0))
+
+	_, err = q.Claim()
+	assert.Equal(t, ErrEmpty, err)
+	_, err = q.DeadLetters().Claim()
+	assert.NoError(t, err)
+}
+
+func TestWorkQueueConcurrentClaims(t *testing.T) {
+	q := NewWorkQueue(newMapStore(), "jobs", WorkQueueConfig{})
+
+	const messages = 50
+	for i := 0; i < messages; i++ {
+		_, err := q.Publish([]byte(fmt.Sprint(i)), PublishOptions{})
+		require.NoError(t, err)
+	}
+
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		claimed = make(map[string]int)
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				msg, err := q.Claim()
+				if err != nil {
+					return
+				}
+				mutex.Lock()
+				claimed[msg.ID]++
+				mutex.Unlock()
+				if err = q.Ack(msg); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, claimed, messages)
+	for id, n := range claimed {
+		assert.Equal(t, 1, n, id)
+	}
+}
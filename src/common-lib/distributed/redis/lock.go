@@ -0,0 +1,245 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/lock"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/redis"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/runtime/logger"
+)
+
+const (
+	defaultKeyPrefix     = "locks"
+	defaultTTL           = 30 * time.Second
+	defaultRetryInterval = 100 * time.Millisecond
+	keySeparator         = ":"
+	fenceSuffix          = ":fence"
+	defaultTransaction   = "RedisLock"
+
+	// acquireScript sets the lock key to the owner token when it is free and increments the fencing counter
+	acquireScript = `if redis.call("set", KEYS[1], ARGV[1], "NX", "PX", ARGV[2]) then return redis.call("incr", KEYS[2]) else return 0 end`
+	// unlockScript deletes the lock key only when it is still held by the given owner token
+	unlockScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+	// renewScript extends the lease of the lock key only when it is still held by the given owner token
+	renewScript = `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("pexpire", KEYS[1], ARGV[2]) else return 0 end`
+)
+
+var (
+	// Logger : Logger instance used for logging
+	// Defaults to Discard
+	Logger = logger.DiscardLogger
+
+	// ErrDeadlock is returned by Lock when the lock is already held by the same Locker
+	ErrDeadlock = errors.New("redis lock: lock is already held by this locker")
+	// ErrNotLocked is returned by Unlock when the Locker does not hold the lock
+	ErrNotLocked = errors.New("redis lock: not locked")
+	// ErrLockLost is returned by Unlock when the lease expired or the lock was taken over by another owner
+	ErrLockLost = errors.New("redis lock: lease is lost")
+)
+
+type (
+	// Config - configuration of the redis based distributed lock
+	Config struct {
+		// KeyPrefix is prepended to the lock name to build the redis key
+		// Default is "locks"
+		KeyPrefix string
+		// TTL is the lease duration of the lock. The lease is renewed while the lock is held,
+		// so TTL only limits how long a crashed holder can block other owners
+		// Default is 30 seconds
+		TTL time.Duration
+		// RenewInterval is the interval between lease renewals
+		// Default is TTL/3
+		RenewInterval time.Duration
+		// RetryInterval is the interval between acquisition attempts while the lock is held by another owner
+		// Default is 100 milliseconds
+		RetryInterval time.Duration
+	}
+
+	lockImpl struct {
+		client   redis.Client
+		key      string
+		fenceKey string
+		config   Config
+
+		mutex sync.Mutex
+		lease *lease
+	}
+
+	// lease is a single acquisition of the lock identified by the owner token
+	lease struct {
+		owner    string
+		stop     chan struct{}
+		stopOnce sync.Once
+		done     chan struct{}
+	}
+)
+
+// NewLock creates a redis backed lock.ContextLocker with the given name
+func NewLock(client redis.Client, name string, config Config) lock.ContextLocker {
+	config = withDefaults(config)
+	// the hash tag keeps the lock key and its fencing counter in the same cluster slot
+	key := config.KeyPrefix + keySeparator + "{" + name + "}"
+	return &lockImpl{
+		client:   client,
+		key:      key,
+		fenceKey: key + fenceSuffix,
+		config:   config,
+	}
+}
+
+func withDefaults(config Config) Config {
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = defaultKeyPrefix
+	}
+	if config.TTL <= 0 {
+		config.TTL = defaultTTL
+	}
+	if config.RenewInterval <= 0 || config.RenewInterval >= config.TTL {
+		config.RenewInterval = config.TTL / 3
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = defaultRetryInterval
+	}
+	return config
+}
+
+// Lock - blocks until the lock is acquired, the lease is renewed in background until Unlock is called
+func (l *lockImpl) Lock() error {
+	_, err := l.LockContext(context.Background())
+	return err
+}
+
+// LockContext - blocks until the lock is acquired or ctx is done, the lease is renewed in background until Unlock is called.
+// The returned fencing token is a counter incremented on every acquisition of the lock
+func (l *lockImpl) LockContext(ctx context.Context) (int64, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.lease != nil {
+		return 0, lock.Error{Code: ErrDeadlock, Action: lock.TryUnlock}
+	}
+
+	owner := uuid.New().String()
+	for {
+		token, err := l.acquire(owner)
+		if err != nil {
+			return 0, lock.Error{Code: err, Action: lock.TryLock}
+		}
+		if token > 0 {
+			l.hold(owner)
+			return token, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, lock.Error{Code: ctx.Err(), Action: lock.TryLock}
+		case <-time.After(l.config.RetryInterval):
+		}
+	}
+}
+
+// TryLock - acquires the lock only if it is not held by another owner, it never waits
+func (l *lockImpl) TryLock() (int64, bool, error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.lease != nil {
+		return 0, false, lock.Error{Code: ErrDeadlock, Action: lock.TryUnlock}
+	}
+
+	owner := uuid.New().String()
+	token, err := l.acquire(owner)
+	if err != nil {
+		return 0, false, lock.Error{Code: err, Action: lock.TryLock}
+	}
+	if token == 0 {
+		return 0, false, nil
+	}
+
+	l.hold(owner)
+	return token, true, nil
+}
+
+// acquire sets the lock key and increments the fencing counter in one step,
+// it returns the fencing token or zero when the lock is held by another owner
+func (l *lockImpl) acquire(owner string) (int64, error) {
+	result, err := l.client.Eval(acquireScript, []string{l.key, l.fenceKey}, owner, l.config.TTL.Milliseconds())
+	if err != nil {
+		return 0, err
+	}
+	token, _ := result.(int64)
+	return token, nil
+}
+
+func (l *lockImpl) hold(owner string) {
+	l.lease = &lease{owner: owner, stop: make(chan struct{}), done: make(chan struct{})}
+	go l.renew(l.lease)
+}
+
+// Unlock - releases the lock if it is still held by this Locker
+func (l *lockImpl) Unlock() error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if l.lease == nil {
+		return lock.Error{Code: ErrNotLocked, Action: lock.Ignore}
+	}
+
+	l.lease.stopRenewal()
+	released, err := l.eval(unlockScript, l.lease.owner)
+	if err != nil {
+		// the lease is kept so that Unlock can be retried, the key expires after TTL anyway
+		return lock.Error{Code: err, Action: lock.TryUnlock}
+	}
+
+	l.lease = nil
+	if !released {
+		return lock.Error{Code: ErrLockLost, Action: lock.CreateNewLock}
+	}
+	return nil
+}
+
+func (l *lockImpl) renew(ls *lease) {
+	defer close(ls.done)
+	ticker := time.NewTicker(l.config.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ls.stop:
+			return
+		case <-ticker.C:
+			renewed, err := l.eval(renewScript, ls.owner, l.config.TTL.Milliseconds())
+			if err != nil {
+				Logger().Warn(defaultTransaction, "Couldn't renew lease of the lock %s: %v", l.key, err)
+				continue
+			}
+			if !renewed {
+				Logger().Warn(defaultTransaction, "Lease of the lock %s is lost", l.key)
+				return
+			}
+		}
+	}
+}
+
+// eval runs an owner checking script and reports whether the script has changed the lock key
+func (l *lockImpl) eval(script string, args ...interface{}) (bool, error) {
+	result, err := l.client.Eval(script, []string{l.key}, args...)
+	if err != nil {
+		return false, err
+	}
+	changed, ok := result.(int64)
+	return ok && changed == 1, nil
+}
+
+func (ls *lease) stopRenewal() {
+	ls.stopOnce.Do(func() {
+		close(ls.stop)
+	})
+	<-ls.done
+}
@@ -0,0 +1,190 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/lock"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/redis"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/redis/redismock"
+)
+
+func newTestClient(t *testing.T) (redis.Client, *miniredis.Miniredis) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	t.Cleanup(mr.Close)
+
+	client := redis.GetService("test", &redis.Config{ServerAddress: []string{mr.Addr()}})
+	require.NoError(t, client.Init())
+	return client, mr
+}
+
+func assertAction(t *testing.T, err error, code error, action lock.ErrorAction) {
+	le, ok := err.(lock.Error)
+	require.True(t, ok, "expected lock.Error, got %v", err)
+	assert.Equal(t, code, le.Code)
+	assert.Equal(t, action, le.Action)
+}
+
+func TestLockUnlock(t *testing.T) {
+	client, mr := newTestClient(t)
+	locker := NewLock(client, "job", Config{})
+
+	require.NoError(t, locker.Lock())
+	assert.True(t, mr.Exists("locks:{job}"))
+
+	require.NoError(t, locker.Unlock())
+	assert.False(t, mr.Exists("locks:{job}"))
+}
+
+func TestLockIsExclusive(t *testing.T) {
+	client, _ := newTestClient(t)
+	config := Config{RetryInterval: time.Millisecond}
+	first := NewLock(client, "job", config)
+	second := NewLock(client, "job", config)
+
+	require.NoError(t, first.Lock())
+
+	acquired := make(chan error)
+	go func() {
+		acquired <- second.Lock()
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("second locker acquired the lock held by the first one")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	require.NoError(t, first.Unlock())
+	select {
+	case err := <-acquired:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("second locker didn't acquire the released lock")
+	}
+	require.NoError(t, second.Unlock())
+}
+
+func TestLockContext(t *testing.T) {
+	client, _ := newTestClient(t)
+	config := Config{RetryInterval: time.Millisecond}
+	first := NewLock(client, "job", config)
+	second := NewLock(client, "job", config)
+
+	token, err := first.LockContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), token)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err = second.LockContext(ctx)
+	assertAction(t, err, context.DeadlineExceeded, lock.TryLock)
+
+	require.NoError(t, first.Unlock())
+	token, err = second.LockContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), token, "fencing token must grow on every acquisition")
+	require.NoError(t, second.Unlock())
+}
+
+func TestTryLock(t *testing.T) {
+	client, _ := newTestClient(t)
+	first := NewLock(client, "job", Config{})
+	second := NewLock(client, "job", Config{})
+
+	token, acquired, err := first.TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, int64(1), token)
+
+	_, acquired, err = second.TryLock()
+	require.NoError(t, err)
+	assert.False(t, acquired)
+
+	_, _, err = first.TryLock()
+	assertAction(t, err, ErrDeadlock, lock.TryUnlock)
+
+	require.NoError(t, first.Unlock())
+	token, acquired, err = second.TryLock()
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, int64(2), token)
+	require.NoError(t, second.Unlock())
+}
+
+func TestLockTwice(t *testing.T) {
+	client, _ := newTestClient(t)
+	locker := NewLock(client, "job", Config{})
+
+	require.NoError(t, locker.Lock())
+	assertAction(t, locker.Lock(), ErrDeadlock, lock.TryUnlock)
+	require.NoError(t, locker.Unlock())
+}
+
+func TestUnlockNotLocked(t *testing.T) {
+	client, _ := newTestClient(t)
+	locker := NewLock(client, "job", Config{})
+
+	assertAction(t, locker.Unlock(), ErrNotLocked, lock.Ignore)
+}
+
+func TestUnlockByOwnerOnly(t *testing.T) {
+	client, mr := newTestClient(t)
+	locker := NewLock(client, "job", Config{TTL: time.Minute})
+
+	require.NoError(t, locker.Lock())
+	// lease expired and the lock was taken by someone else
+	require.NoError(t, mr.Set("locks:{job}", "another-owner"))
+
+	assertAction(t, locker.Unlock(), ErrLockLost, lock.CreateNewLock)
+	value, err := mr.Get("locks:{job}")
+	require.NoError(t, err)
+	assert.Equal(t, "another-owner", value)
+}
+
+func TestLeaseRenewal(t *testing.T) {
+	client, mr := newTestClient(t)
+	locker := NewLock(client, "job", Config{TTL: 3 * time.Second, RenewInterval: 10 * time.Millisecond})
+
+	require.NoError(t, locker.Lock())
+	mr.FastForward(2 * time.Second)
+	time.Sleep(50 * time.Millisecond)
+	mr.FastForward(2 * time.Second)
+
+	assert.True(t, mr.Exists("locks:{job}"), "lease wasn't renewed")
+	require.NoError(t, locker.Unlock())
+}
+
+func TestLockErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := redismock.NewMockClient(ctrl)
+	injected := errors.New("injected")
+	locker := NewLock(client, "job", Config{TTL: time.Minute})
+
+	keys := []string{"locks:{job}", "locks:{job}:fence"}
+
+	t.Run("lock_error", func(t *testing.T) {
+		client.EXPECT().Eval(acquireScript, keys, gomock.Any(), int64(60000)).Return(nil, injected)
+		assertAction(t, locker.Lock(), injected, lock.TryLock)
+	})
+
+	t.Run("unlock_error", func(t *testing.T) {
+		client.EXPECT().Eval(acquireScript, keys, gomock.Any(), int64(60000)).Return(int64(1), nil)
+		require.NoError(t, locker.Lock())
+
+		client.EXPECT().Eval(unlockScript, []string{"locks:{job}"}, gomock.Any()).Return(nil, injected)
+		assertAction(t, locker.Unlock(), injected, lock.TryUnlock)
+
+		client.EXPECT().Eval(unlockScript, []string{"locks:{job}"}, gomock.Any()).Return(int64(1), nil)
+		require.NoError(t, locker.Unlock())
+	})
+}
@@ -0,0 +1,169 @@
+package scheduler
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// Entry is a function registered in Cron together with its schedule
+	Entry struct {
+		Name     string
+		Schedule Schedule
+		// Next is the next activation time, zero when Cron is not started or the schedule can't be satisfied
+		Next time.Time
+		// Prev is the last activation time, zero when the entry has never run
+		Prev time.Time
+		Run  func()
+	}
+
+	// Cron runs functions at the times described by their schedules.
+	// It sleeps until the next due entry instead of polling
+	Cron struct {
+		mutex   sync.Mutex
+		entries []*Entry
+		running bool
+		stop    chan struct{}
+		wake    chan struct{}
+		done    chan struct{}
+		now     func() time.Time
+	}
+)
+
+// NewCron returns a new Cron, entries can be added before or after Start
+func NewCron() *Cron {
+	return &Cron{
+		wake: make(chan struct{}, 1),
+		now:  time.Now,
+	}
+}
+
+// Add registers the function to be run on the schedule
+func (c *Cron) Add(name string, schedule Schedule, run func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entry := &Entry{Name: name, Schedule: schedule, Run: run}
+	if c.running {
+		entry.Next = schedule.Next(c.now())
+	}
+	c.entries = append(c.entries, entry)
+	c.notify()
+}
+
+// AddFunc parses the specification with Parse and registers the function to be run on the schedule
+func (c *Cron) AddFunc(name string, spec string, run func()) error {
+	schedule, err := Parse(spec)
+	if err != nil {
+		return err
+	}
+	c.Add(name, schedule, run)
+	return nil
+}
+
+// Entries returns a snapshot of the registered entries
+func (c *Cron) Entries() []Entry {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	entries := make([]Entry, 0, len(c.entries))
+	for _, e := range c.entries {
+		entries = append(entries, *e)
+	}
+	return entries
+}
+
+// Start starts running the entries in a background goroutine, it does nothing if Cron is already running
+func (c *Cron) Start() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.running {
+		return
+	}
+	c.running = true
+	c.stop = make(chan struct{})
+	c.done = make(chan struct{})
+
+	now := c.now()
+	for _, e := range c.entries {
+		e.Next = e.Schedule.Next(now)
+	}
+	go c.run(c.stop, c.done)
+}
+
+// Stop stops running the entries, the functions already started are not interrupted
+func (c *Cron) Stop() {
+	c.mutex.Lock()
+	if !c.running {
+		c.mutex.Unlock()
+		return
+	}
+	c.running = false
+	close(c.stop)
+	done := c.done
+	c.mutex.Unlock()
+
+	<-done
+}
+
+func (c *Cron) run(stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+
+	for {
+		timer := time.NewTimer(c.untilNext())
+		select {
+		case <-stop:
+			timer.Stop()
+			return
+		case <-c.wake:
+			timer.Stop()
+		case <-timer.C:
+			c.runDue()
+		}
+	}
+}
+
+// untilNext returns the duration to sleep until the earliest due entry
+func (c *Cron) untilNext() time.Duration {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	sort.SliceStable(c.entries, func(i, j int) bool {
+		// entries which are never due go last
+		if c.entries[i].Next.IsZero() {
+			return false
+		}
+		return c.entries[j].Next.IsZero() || c.entries[i].Next.Before(c.entries[j].Next)
+	})
+
+	if len(c.entries) == 0 || c.entries[0].Next.IsZero() {
+		// nothing to run, sleep until an entry is added or Cron is stopped
+		return 24 * time.Hour
+	}
+	return c.entries[0].Next.Sub(c.now())
+}
+
+func (c *Cron) runDue() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	now := c.now()
+	for _, e := range c.entries {
+		if e.Next.IsZero() || e.Next.After(now) {
+			continue
+		}
+		go e.Run()
+		e.Prev = e.Next
+		e.Next = e.Schedule.Next(now)
+	}
+}
+
+// notify wakes up the running loop to recalculate the sleep duration
+func (c *Cron) notify() {
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,78 @@
+package scheduler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fixedSchedule struct {
+	delay time.Duration
+}
+
+func (s fixedSchedule) Next(after time.Time) time.Time {
+	return after.Add(s.delay)
+}
+
+type neverSchedule struct{}
+
+func (neverSchedule) Next(time.Time) time.Time {
+	return time.Time{}
+}
+
+func TestCronRunsDueEntries(t *testing.T) {
+	var fast, never int32
+	cron := NewCron()
+	cron.Add("fast", fixedSchedule{delay: 10 * time.Millisecond}, func() { atomic.AddInt32(&fast, 1) })
+	cron.Add("never", neverSchedule{}, func() { atomic.AddInt32(&never, 1) })
+
+	cron.Start()
+	time.Sleep(100 * time.Millisecond)
+	cron.Stop()
+
+	assert.True(t, atomic.LoadInt32(&fast) >= 3, "expected several runs, got %d", fast)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&never))
+}
+
+func TestCronAddWhileRunning(t *testing.T) {
+	ran := make(chan string, 1)
+	cron := NewCron()
+	cron.Add("never", neverSchedule{}, func() {})
+	cron.Start()
+	defer cron.Stop()
+
+	cron.Add("added", fixedSchedule{delay: 10 * time.Millisecond}, func() {
+		select {
+		case ran <- "added":
+		default:
+		}
+	})
+
+	select {
+	case name := <-ran:
+		assert.Equal(t, "added", name)
+	case <-time.After(time.Second):
+		t.Fatal("entry added to a running cron wasn't run")
+	}
+}
+
+func TestCronEntries(t *testing.T) {
+	cron := NewCron()
+	require.Error(t, cron.AddFunc("invalid", "invalid-schedule", func() {}))
+	require.NoError(t, cron.AddFunc("hourly", "@hourly", func() {}))
+
+	entries := cron.Entries()
+	require.Len(t, entries, 1)
+	assert.Equal(t, "hourly", entries[0].Name)
+	assert.True(t, entries[0].Next.IsZero())
+
+	cron.Start()
+	cron.Start()
+	entries = cron.Entries()
+	assert.False(t, entries[0].Next.IsZero())
+	cron.Stop()
+	cron.Stop()
+}
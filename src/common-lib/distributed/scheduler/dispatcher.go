@@ -0,0 +1,117 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+type (
+	// RunStore - run history kept by a backend, the Dispatcher records the runs in it
+	RunStore interface {
+		History
+		// LastRun returns the last dispatched run of the job, the run is empty when the job has never run
+		LastRun(jobName string) (Run, error)
+		// AddRun keeps the run as the last dispatched run of the job and adds it to the job history,
+		// the ID of the run in the history is returned once it was added
+		AddRun(run Run) (string, error)
+		// UpdateRun applies the update to the run of the job history, a run already removed from the history is ignored
+		UpdateRun(jobName, id string, update func(*Run)) error
+	}
+
+	// Dispatcher sends the runs of the scheduled jobs to the job listeners through the queue named by the job task
+	// and keeps them in the run history of the backend
+	Dispatcher struct {
+		Queue queue.Interface
+		Runs  RunStore
+		// Node is the name of the leader node recorded in the runs
+		Node string
+	}
+)
+
+// Dispatch records a pending run of the job and sends it to the job listeners, the listener running the job
+// completes the run with RunCallback. The job is dispatched even when the run couldn't be recorded
+func (d Dispatcher) Dispatch(jobName, task string, scheduledAt time.Time, catchUp bool) error {
+	id, recordErr := d.Runs.AddRun(Run{
+		Job:          jobName,
+		ScheduledAt:  scheduledAt,
+		DispatchedAt: time.Now(),
+		Node:         d.Node,
+		Outcome:      OutcomePending,
+		CatchUp:      catchUp,
+	})
+
+	var data []byte
+	if id != "" {
+		data = RunRef{Job: jobName, Run: id}.Marshal()
+	}
+	if _, err := d.Queue.CreateItem(data, task); err != nil {
+		if id != "" {
+			if updateErr := d.Runs.UpdateRun(jobName, id, DispatchFailed(err)); updateErr != nil {
+				return fmt.Errorf("couldn't run the job %s: %v, and couldn't record its failure: %v", jobName, err, updateErr)
+			}
+		}
+		return fmt.Errorf("couldn't run the job %s: %v", jobName, err)
+	}
+
+	if recordErr != nil {
+		return fmt.Errorf("couldn't record a run of the job %s: %v", jobName, recordErr)
+	}
+	return nil
+}
+
+// CatchUp dispatches the runs of the job missed since its last run according to the misfire policy,
+// the first error is returned once all the missed runs were dispatched
+func (d Dispatcher) CatchUp(jobName, task string, schedule Schedule, policy MisfirePolicy) error {
+	if policy == MisfireSkip {
+		return nil
+	}
+
+	last, err := d.Runs.LastRun(jobName)
+	if err != nil {
+		return fmt.Errorf("couldn't get the last run of the job %s: %v", jobName, err)
+	}
+
+	for _, missed := range MissedRuns(schedule, last.ScheduledAt, time.Now(), policy) {
+		if dispatchErr := d.Dispatch(jobName, task, missed, true); dispatchErr != nil && err == nil {
+			err = dispatchErr
+		}
+	}
+	return err
+}
+
+// RunCallback runs the callback of the job with the data of the queue items, the callback gets the data of the items
+// which don't dispatch a run, so it gets an empty slice when it is triggered by the scheduled runs only.
+// The dispatched runs are completed in the run history once the callback returned,
+// a panic of the callback fails them and is propagated
+func RunCallback(ctx context.Context, runs RunStore, job DistributedJob, itemsData [][]byte) (err error) {
+	var (
+		refs []RunRef
+		data [][]byte
+	)
+	for _, item := range itemsData {
+		if ref, ok := ParseRunRef(item); ok {
+			refs = append(refs, ref)
+		} else {
+			data = append(data, item)
+		}
+	}
+
+	startedAt := time.Now()
+	defer func() {
+		failure := recover()
+		complete := Completed(startedAt, failure)
+		for _, ref := range refs {
+			if updateErr := runs.UpdateRun(ref.Job, ref.Run, complete); updateErr != nil && err == nil {
+				err = fmt.Errorf("couldn't complete the run %s of the job %s: %v", ref.Run, ref.Job, updateErr)
+			}
+		}
+		if failure != nil {
+			panic(failure)
+		}
+	}()
+	job.Callback(ctx, data)
+	return nil
+}
@@ -0,0 +1,127 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	testRunStore struct {
+		runs []Run
+	}
+
+	testQueue struct {
+		items [][]byte
+		err   error
+	}
+
+	testDistributedJob struct {
+		data  [][]byte
+		panic bool
+	}
+)
+
+func (s *testRunStore) LastRuns(jobName string, n int) ([]Run, error) { return nil, nil }
+
+func (s *testRunStore) LastRun(jobName string) (Run, error) {
+	if len(s.runs) == 0 {
+		return Run{}, nil
+	}
+	return s.runs[len(s.runs)-1], nil
+}
+
+func (s *testRunStore) AddRun(run Run) (string, error) {
+	run.ID = fmt.Sprintf("run-%d", len(s.runs))
+	s.runs = append(s.runs, run)
+	return run.ID, nil
+}
+
+func (s *testRunStore) UpdateRun(jobName, id string, update func(*Run)) error {
+	for i := range s.runs {
+		if s.runs[i].Job == jobName && s.runs[i].ID == id {
+			update(&s.runs[i])
+		}
+	}
+	return nil
+}
+
+func (q *testQueue) Create(queueName string) (string, error)            { return queueName, nil }
+func (q *testQueue) Exists(queueName string) (bool, error)              { return true, nil }
+func (q *testQueue) GetList(queueName string) ([]string, error)         { return nil, nil }
+func (q *testQueue) RemoveItem(queueName string, itemName string) error { return nil }
+
+func (q *testQueue) GetItemData(queueName, itemName string) ([]byte, error) { return nil, nil }
+
+func (q *testQueue) CreateItem(data []byte, queueName string) (string, error) {
+	if q.err != nil {
+		return "", q.err
+	}
+	q.items = append(q.items, data)
+	return "item", nil
+}
+
+func (j *testDistributedJob) GetName() string { return "task" }
+
+func (j *testDistributedJob) Callback(i ...interface{}) {
+	j.data = i[1].([][]byte)
+	if j.panic {
+		panic("injected")
+	}
+}
+
+func TestDispatch(t *testing.T) {
+	runs, q := &testRunStore{}, &testQueue{}
+	d := Dispatcher{Queue: q, Runs: runs, Node: "node"}
+
+	require.NoError(t, d.Dispatch("job", "task", time.Now(), false))
+	require.Len(t, runs.runs, 1)
+	assert.Equal(t, OutcomePending, runs.runs[0].Outcome)
+	assert.Equal(t, "node", runs.runs[0].Node)
+	require.Len(t, q.items, 1)
+	ref, ok := ParseRunRef(q.items[0])
+	require.True(t, ok)
+	assert.Equal(t, RunRef{Job: "job", Run: "run-0"}, ref)
+
+	q.err = errors.New("injected")
+	assert.Error(t, d.Dispatch("job", "task", time.Now(), false))
+	assert.Equal(t, OutcomeFailed, runs.runs[1].Outcome)
+	assert.Equal(t, "injected", runs.runs[1].Error)
+}
+
+func TestCatchUp(t *testing.T) {
+	schedule, err := Parse("@every 1m")
+	require.NoError(t, err)
+
+	runs, q := &testRunStore{}, &testQueue{}
+	d := Dispatcher{Queue: q, Runs: runs}
+	_, _ = runs.AddRun(Run{Job: "job", ScheduledAt: time.Now().Add(-3*time.Minute - 30*time.Second)})
+
+	require.NoError(t, d.CatchUp("job", "task", schedule, MisfireSkip))
+	assert.Empty(t, q.items)
+
+	require.NoError(t, d.CatchUp("job", "task", schedule, MisfireRunAll))
+	assert.Len(t, q.items, 3)
+	assert.True(t, runs.runs[len(runs.runs)-1].CatchUp)
+}
+
+func TestRunCallback(t *testing.T) {
+	runs := &testRunStore{}
+	id, _ := runs.AddRun(Run{Job: "job", Outcome: OutcomePending})
+	items := [][]byte{RunRef{Job: "job", Run: id}.Marshal(), []byte("data")}
+
+	job := &testDistributedJob{}
+	require.NoError(t, RunCallback(context.Background(), runs, job, items))
+	assert.Equal(t, [][]byte{[]byte("data")}, job.data, "the callback gets the data of the other items only")
+	assert.Equal(t, OutcomeSucceeded, runs.runs[0].Outcome)
+
+	runs.runs[0].Outcome = OutcomePending
+	job.panic = true
+	assert.Panics(t, func() { _ = RunCallback(context.Background(), runs, job, items) })
+	assert.Equal(t, OutcomeFailed, runs.runs[0].Outcome)
+}
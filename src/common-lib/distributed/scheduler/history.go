@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const (
+	// MisfireSkip - runs missed while no scheduler was running are skipped
+	MisfireSkip MisfirePolicy = iota
+	// MisfireRunOnce - a single run is made for all the missed runs
+	MisfireRunOnce
+	// MisfireRunAll - every missed run is made, up to MaxMissedRuns
+	MisfireRunAll
+)
+
+const (
+	// OutcomePending - the job was dispatched to the job listeners, which haven't run it yet
+	OutcomePending Outcome = "pending"
+	// OutcomeSucceeded - the job was run successfully
+	OutcomeSucceeded Outcome = "succeeded"
+	// OutcomeFailed - the job couldn't be dispatched or its callback panicked, see Run.Error
+	OutcomeFailed Outcome = "failed"
+)
+
+// runRefPrefix starts the data of the queue items dispatching a run
+const runRefPrefix = `{"scheduledRun":`
+
+// MaxMissedRuns limits the number of runs made for MisfireRunAll, only the latest missed runs are made
+var MaxMissedRuns = 100
+
+type (
+	// MisfirePolicy - what the scheduler does with the runs missed while no scheduler was running
+	MisfirePolicy int
+
+	// MisfireAwareJob is a ScheduledJob with its own misfire policy, other jobs use MisfireSkip
+	MisfireAwareJob interface {
+		ScheduledJob
+		GetMisfirePolicy() MisfirePolicy
+	}
+
+	// Outcome - result of a job run
+	Outcome string
+
+	// Run - execution record of a scheduled job
+	Run struct {
+		// ID identifies the run in the history of the job
+		ID  string `json:"id,omitempty"`
+		Job string `json:"job"`
+		// ScheduledAt is the activation time of the schedule the run was made for
+		ScheduledAt time.Time `json:"scheduledAt"`
+		// DispatchedAt is the time the run was sent to the job listeners
+		DispatchedAt time.Time `json:"dispatchedAt"`
+		// StartedAt is the time a job listener started the job callback, it is zero while the run is pending
+		StartedAt time.Time `json:"startedAt"`
+		// Duration is the time taken by the job callback
+		Duration time.Duration `json:"duration"`
+		// Node is the name of the leader node which made the run
+		Node    string  `json:"node"`
+		Outcome Outcome `json:"outcome"`
+		Error   string  `json:"error,omitempty"`
+		// CatchUp is set for the runs made for missed activation times
+		CatchUp bool `json:"catchUp,omitempty"`
+	}
+
+	// RunRef is the data of the queue item dispatching a run, it lets the job listener complete the run
+	RunRef struct {
+		Job string `json:"job"`
+		Run string `json:"run"`
+	}
+
+	// History - execution history of the scheduled jobs
+	History interface {
+		// LastRuns returns up to n latest runs of the job, the latest run first
+		LastRuns(jobName string, n int) ([]Run, error)
+	}
+)
+
+// GetMisfirePolicy returns the misfire policy of the job, MisfireSkip unless the job is a MisfireAwareJob
+func GetMisfirePolicy(job ScheduledJob) MisfirePolicy {
+	if j, ok := job.(MisfireAwareJob); ok {
+		return j.GetMisfirePolicy()
+	}
+	return MisfireSkip
+}
+
+// MissedRuns returns the activation times of the schedule after the last run and not later than now,
+// which have to be made according to the misfire policy. Nothing is returned when the job has never run
+func MissedRuns(schedule Schedule, lastRun, now time.Time, policy MisfirePolicy) []time.Time {
+	if policy == MisfireSkip || lastRun.IsZero() {
+		return nil
+	}
+
+	var missed []time.Time
+	for next := schedule.Next(lastRun); !next.IsZero() && !next.After(now); next = schedule.Next(next) {
+		missed = append(missed, next)
+		if policy == MisfireRunOnce {
+			// only the latest missed run is kept
+			missed = missed[len(missed)-1:]
+		} else if len(missed) > MaxMissedRuns {
+			missed = missed[1:]
+		}
+	}
+	return missed
+}
+
+// Marshal returns the data of the queue item dispatching the run
+func (r RunRef) Marshal() []byte {
+	data, _ := json.Marshal(struct {
+		ScheduledRun RunRef `json:"scheduledRun"`
+	}{ScheduledRun: r})
+	return data
+}
+
+// ParseRunRef returns the run dispatched by the queue item with the data, ok is false for the items
+// which don't dispatch a run
+func ParseRunRef(data []byte) (ref RunRef, ok bool) {
+	if !bytes.HasPrefix(data, []byte(runRefPrefix)) {
+		return ref, false
+	}
+
+	var item struct {
+		ScheduledRun RunRef `json:"scheduledRun"`
+	}
+	if err := json.Unmarshal(data, &item); err != nil || item.ScheduledRun.Run == "" {
+		return ref, false
+	}
+	return item.ScheduledRun, true
+}
+
+// Completed returns the update of a run whose job callback started at startedAt and returned,
+// failure is the value recovered from the callback when it panicked
+func Completed(startedAt time.Time, failure interface{}) func(*Run) {
+	duration := time.Since(startedAt)
+	return func(run *Run) {
+		run.StartedAt = startedAt
+		run.Duration = duration
+		run.Outcome = OutcomeSucceeded
+		if failure != nil {
+			run.Outcome = OutcomeFailed
+			run.Error = fmt.Sprint(failure)
+		}
+	}
+}
+
+// DispatchFailed returns the update of a run which couldn't be sent to the job listeners
+func DispatchFailed(err error) func(*Run) {
+	return func(run *Run) {
+		run.Outcome = OutcomeFailed
+		run.Error = err.Error()
+	}
+}
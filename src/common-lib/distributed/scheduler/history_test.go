@@ -0,0 +1,134 @@
+package scheduler
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type misfireJob struct {
+	policy MisfirePolicy
+}
+
+func (misfireJob) GetName() string                   { return "job" }
+func (misfireJob) GetTask() string                   { return "task" }
+func (misfireJob) GetSchedule() string               { return "@hourly" }
+func (j misfireJob) GetMisfirePolicy() MisfirePolicy { return j.policy }
+
+type plainJob struct{}
+
+func (plainJob) GetName() string     { return "job" }
+func (plainJob) GetTask() string     { return "task" }
+func (plainJob) GetSchedule() string { return "@hourly" }
+
+func TestGetMisfirePolicy(t *testing.T) {
+	assert.Equal(t, MisfireRunAll, GetMisfirePolicy(misfireJob{policy: MisfireRunAll}))
+	assert.Equal(t, MisfireSkip, GetMisfirePolicy(plainJob{}))
+}
+
+func TestMissedRuns(t *testing.T) {
+	schedule, err := Parse("TZ=UTC @hourly")
+	require.NoError(t, err)
+	lastRun := time.Date(2021, 6, 15, 10, 0, 0, 0, time.UTC)
+	now := time.Date(2021, 6, 15, 13, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		lastRun  time.Time
+		now      time.Time
+		policy   MisfirePolicy
+		expected []time.Time
+	}{
+		{
+			name:    "skip",
+			lastRun: lastRun,
+			now:     now,
+			policy:  MisfireSkip,
+		},
+		{
+			name:     "run_once",
+			lastRun:  lastRun,
+			now:      now,
+			policy:   MisfireRunOnce,
+			expected: []time.Time{time.Date(2021, 6, 15, 13, 0, 0, 0, time.UTC)},
+		},
+		{
+			name:    "run_all",
+			lastRun: lastRun,
+			now:     now,
+			policy:  MisfireRunAll,
+			expected: []time.Time{
+				time.Date(2021, 6, 15, 11, 0, 0, 0, time.UTC),
+				time.Date(2021, 6, 15, 12, 0, 0, 0, time.UTC),
+				time.Date(2021, 6, 15, 13, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			name:    "nothing_missed",
+			lastRun: time.Date(2021, 6, 15, 13, 0, 0, 0, time.UTC),
+			now:     now,
+			policy:  MisfireRunAll,
+		},
+		{
+			name:   "never_run",
+			now:    now,
+			policy: MisfireRunAll,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, MissedRuns(schedule, test.lastRun, test.now, test.policy))
+		})
+	}
+}
+
+func TestMissedRunsLimit(t *testing.T) {
+	defer func(max int) { MaxMissedRuns = max }(MaxMissedRuns)
+	MaxMissedRuns = 2
+
+	schedule, err := Parse("TZ=UTC @hourly")
+	require.NoError(t, err)
+	missed := MissedRuns(schedule, time.Date(2021, 6, 15, 0, 0, 0, 0, time.UTC), time.Date(2021, 6, 16, 0, 0, 0, 0, time.UTC), MisfireRunAll)
+
+	assert.Equal(t, []time.Time{
+		time.Date(2021, 6, 15, 23, 0, 0, 0, time.UTC),
+		time.Date(2021, 6, 16, 0, 0, 0, 0, time.UTC),
+	}, missed)
+}
+
+func TestParseRunRef(t *testing.T) {
+	ref := RunRef{Job: "job", Run: "run-0000000001"}
+	parsed, ok := ParseRunRef(ref.Marshal())
+	assert.True(t, ok)
+	assert.Equal(t, ref, parsed)
+
+	for _, data := range []string{"", "data", `{"job":"job","run":"run-0000000001"}`, `{"scheduledRun":{"job":"job"}}`} {
+		_, ok = ParseRunRef([]byte(data))
+		assert.False(t, ok, data)
+	}
+}
+
+func TestRunUpdates(t *testing.T) {
+	startedAt := time.Now().Add(-time.Second)
+
+	run := Run{Outcome: OutcomePending}
+	Completed(startedAt, nil)(&run)
+	assert.Equal(t, OutcomeSucceeded, run.Outcome)
+	assert.Equal(t, startedAt, run.StartedAt)
+	assert.GreaterOrEqual(t, run.Duration, time.Second)
+
+	run = Run{Outcome: OutcomePending}
+	Completed(startedAt, "boom")(&run)
+	assert.Equal(t, OutcomeFailed, run.Outcome)
+	assert.Equal(t, "boom", run.Error)
+
+	run = Run{Outcome: OutcomePending}
+	DispatchFailed(errors.New("injected"))(&run)
+	assert.Equal(t, OutcomeFailed, run.Outcome)
+	assert.Equal(t, "injected", run.Error)
+	assert.True(t, run.StartedAt.IsZero())
+}
@@ -17,12 +17,16 @@ type (
 	ScheduledJob interface {
 		GetName() string
 		GetTask() string
+		// GetSchedule returns the schedule specification in the format accepted by Parse
 		GetSchedule() string
 	}
 
 	// DistributedJob interface
 	DistributedJob interface {
 		GetName() string
+		// Callback gets the context and the [][]byte data of the queue items created for the job.
+		// A scheduled run has no data, so the runs dispatched by the scheduler only trigger the callback
+		// and are not counted in the data, see RunCallback
 		Callback(i ...interface{})
 	}
 )
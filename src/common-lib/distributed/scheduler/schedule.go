@@ -0,0 +1,363 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	everyDescriptor = "@every "
+	tzPrefix        = "TZ="
+	cronTZPrefix    = "CRON_TZ="
+
+	// starBit marks a day field set by "*" or "?", used to combine the day of month and day of week fields
+	starBit = 1 << 63
+	// yearsToSearch limits the search of the next activation time for schedules that are never satisfied, e.g. "0 0 0 30 2 *"
+	yearsToSearch = 5
+)
+
+type (
+	// Schedule describes the activation times of a job
+	Schedule interface {
+		// Next returns the first activation time later than after.
+		// The zero time is returned when the schedule can't be satisfied
+		Next(after time.Time) time.Time
+	}
+
+	// cronSchedule is a parsed cron expression, every field is a bit set of allowed values
+	cronSchedule struct {
+		second, minute, hour, dom, month, dow uint64
+		location                              *time.Location
+	}
+
+	// everySchedule activates with a fixed delay between runs
+	everySchedule struct {
+		delay time.Duration
+	}
+
+	bounds struct {
+		name     string
+		min, max uint
+		names    map[string]uint
+	}
+)
+
+var (
+	seconds = bounds{name: "second", min: 0, max: 59}
+	minutes = bounds{name: "minute", min: 0, max: 59}
+	hours   = bounds{name: "hour", min: 0, max: 23}
+	dom     = bounds{name: "day of month", min: 1, max: 31}
+	months  = bounds{name: "month", min: 1, max: 12, names: map[string]uint{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	// both 0 and 7 stand for Sunday
+	dow = bounds{name: "day of week", min: 0, max: 7, names: map[string]uint{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+
+	descriptors = map[string]string{
+		"@yearly":   "0 0 0 1 1 *",
+		"@annually": "0 0 0 1 1 *",
+		"@monthly":  "0 0 0 1 * *",
+		"@weekly":   "0 0 0 * * 0",
+		"@daily":    "0 0 0 * * *",
+		"@midnight": "0 0 0 * * *",
+		"@hourly":   "0 0 * * * *",
+	}
+)
+
+// Parse parses a schedule specification with the grammar of github.com/robfig/cron v1, the supported formats are
+//   - 6 field cron expression with leading seconds: "second minute hour day-of-month month day-of-week"
+//   - 5 field cron expression with leading seconds, the day of week is "*": "second minute hour day-of-month month"
+//   - descriptors: @yearly (@annually), @monthly, @weekly, @daily (@midnight), @hourly
+//   - fixed delay: "@every <duration>", e.g. "@every 1h30m"
+//
+// Fields accept "*", "?", values, names of months and week days, ranges "a-b", steps "*/n", "a-b/n", "a/n" and lists "a,b".
+// The specification is evaluated in the local time zone unless it is prefixed with an IANA time zone,
+// e.g. "CRON_TZ=Europe/Berlin 0 0 9 * * MON-FRI" or "TZ=UTC @daily".
+// Standard 5 field expressions starting with the minute are parsed by ParseStandard,
+// or by Parse once they are written with a leading "0" second
+func Parse(spec string) (Schedule, error) {
+	return parse(spec, false)
+}
+
+// ParseStandard parses a schedule specification as Parse does, except that cron expressions are
+// standard 5 field expressions without seconds: "minute hour day-of-month month day-of-week", e.g. "*/15 * * * *"
+func ParseStandard(spec string) (Schedule, error) {
+	return parse(spec, true)
+}
+
+func parse(spec string, standard bool) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return nil, fmt.Errorf("scheduler: empty schedule")
+	}
+
+	location := time.Local
+	if strings.HasPrefix(spec, tzPrefix) || strings.HasPrefix(spec, cronTZPrefix) {
+		i := strings.IndexAny(spec, " \t")
+		if i < 0 {
+			return nil, fmt.Errorf("scheduler: missing schedule after time zone in %q", spec)
+		}
+		name := spec[strings.Index(spec, "=")+1 : i]
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid time zone %q: %v", name, err)
+		}
+		location = loc
+		spec = strings.TrimSpace(spec[i:])
+	}
+
+	if strings.HasPrefix(spec, everyDescriptor) {
+		delay, err := time.ParseDuration(strings.TrimSpace(spec[len(everyDescriptor):]))
+		if err != nil {
+			return nil, fmt.Errorf("scheduler: invalid duration in %q: %v", spec, err)
+		}
+		return every(delay), nil
+	}
+
+	if strings.HasPrefix(spec, "@") {
+		expr, ok := descriptors[strings.ToLower(spec)]
+		if !ok {
+			return nil, fmt.Errorf("scheduler: unknown descriptor %q", spec)
+		}
+		return parseFields(strings.Fields(expr), location)
+	}
+
+	fields := strings.Fields(spec)
+	switch {
+	case standard && len(fields) == 5:
+		fields = append([]string{"0"}, fields...)
+	case standard:
+		return nil, fmt.Errorf("scheduler: expected 5 fields, found %d in %q", len(fields), spec)
+	case len(fields) == 5:
+		fields = append(fields, "*")
+	case len(fields) == 6:
+	default:
+		return nil, fmt.Errorf("scheduler: expected 5 or 6 fields, found %d in %q", len(fields), spec)
+	}
+	return parseFields(fields, location)
+}
+
+func parseFields(fields []string, location *time.Location) (*cronSchedule, error) {
+	var (
+		s   = &cronSchedule{location: location}
+		err error
+	)
+	for i, f := range []struct {
+		bits   *uint64
+		bounds bounds
+	}{
+		{&s.second, seconds},
+		{&s.minute, minutes},
+		{&s.hour, hours},
+		{&s.dom, dom},
+		{&s.month, months},
+		{&s.dow, dow},
+	} {
+		*f.bits, err = parseField(fields[i], f.bounds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// fold Sunday written as 7 into 0
+	if s.dow&(1<<7) > 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+	return s, nil
+}
+
+// parseField returns the bit set of values allowed by a comma separated list of ranges
+func parseField(field string, b bounds) (uint64, error) {
+	var bits uint64
+	for _, expr := range strings.Split(field, ",") {
+		r, err := parseRange(expr, b)
+		if err != nil {
+			return 0, err
+		}
+		bits |= r
+	}
+	return bits, nil
+}
+
+func parseRange(expr string, b bounds) (uint64, error) {
+	var (
+		start, end, step uint = 0, 0, 1
+		extra            uint64
+		err              error
+	)
+	rangeAndStep := strings.Split(expr, "/")
+	lowAndHigh := strings.Split(rangeAndStep[0], "-")
+
+	if lowAndHigh[0] == "*" || lowAndHigh[0] == "?" {
+		if len(lowAndHigh) > 1 {
+			return 0, fmt.Errorf("scheduler: invalid %s range %q", b.name, expr)
+		}
+		start, end = b.min, b.max
+		extra = starBit
+	} else {
+		if start, err = parseValue(lowAndHigh[0], b); err != nil {
+			return 0, err
+		}
+		switch len(lowAndHigh) {
+		case 1:
+			end = start
+		case 2:
+			if end, err = parseValue(lowAndHigh[1], b); err != nil {
+				return 0, err
+			}
+		default:
+			return 0, fmt.Errorf("scheduler: invalid %s range %q", b.name, expr)
+		}
+	}
+
+	switch len(rangeAndStep) {
+	case 1:
+	case 2:
+		if step, err = parseNumber(rangeAndStep[1]); err != nil || step == 0 {
+			return 0, fmt.Errorf("scheduler: invalid %s step %q", b.name, expr)
+		}
+		// "a/n" stands for "a-max/n"
+		if len(lowAndHigh) == 1 && extra == 0 {
+			end = b.max
+		}
+		if step > 1 {
+			extra = 0
+		}
+	default:
+		return 0, fmt.Errorf("scheduler: invalid %s step %q", b.name, expr)
+	}
+
+	if start < b.min || end > b.max || start > end {
+		return 0, fmt.Errorf("scheduler: %s %q is out of range %d-%d", b.name, expr, b.min, b.max)
+	}
+
+	var bits uint64
+	for i := start; i <= end; i += step {
+		bits |= 1 << i
+	}
+	return bits | extra, nil
+}
+
+func parseValue(value string, b bounds) (uint, error) {
+	if n, ok := b.names[strings.ToLower(value)]; ok {
+		return n, nil
+	}
+	n, err := parseNumber(value)
+	if err != nil {
+		return 0, fmt.Errorf("scheduler: invalid %s value %q", b.name, value)
+	}
+	return n, nil
+}
+
+func parseNumber(value string) (uint, error) {
+	n, err := strconv.ParseUint(value, 10, 8)
+	return uint(n), err
+}
+
+// Next returns the first time later than after matching all the fields of the expression
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	// start with the next whole second, the schedule is checked in its own time zone
+	t := after.In(s.location)
+	t = t.Add(time.Second - time.Duration(t.Nanosecond()))
+	yearLimit := t.Year() + yearsToSearch
+
+	// truncated is set once a field didn't match and the less significant fields were reset to their minimum
+	truncated := false
+
+search:
+	for t.Year() <= yearLimit {
+		for 1<<uint(t.Month())&s.month == 0 {
+			if !truncated {
+				truncated = true
+				t = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, s.location)
+			}
+			t = t.AddDate(0, 1, 0)
+			if t.Month() == time.January {
+				continue search
+			}
+		}
+
+		for !s.dayMatches(t) {
+			if !truncated {
+				truncated = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, s.location)
+			}
+			t = t.AddDate(0, 0, 1)
+			// a daylight saving transition may move midnight, go back to it
+			if t.Hour() != 0 {
+				if t.Hour() > 12 {
+					t = t.Add(time.Duration(24-t.Hour()) * time.Hour)
+				} else {
+					t = t.Add(-time.Duration(t.Hour()) * time.Hour)
+				}
+			}
+			if t.Day() == 1 {
+				continue search
+			}
+		}
+
+		for 1<<uint(t.Hour())&s.hour == 0 {
+			if !truncated {
+				truncated = true
+				t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, s.location)
+			}
+			t = t.Add(time.Hour)
+			if t.Hour() == 0 {
+				continue search
+			}
+		}
+
+		for 1<<uint(t.Minute())&s.minute == 0 {
+			if !truncated {
+				truncated = true
+				t = t.Truncate(time.Minute)
+			}
+			t = t.Add(time.Minute)
+			if t.Minute() == 0 {
+				continue search
+			}
+		}
+
+		for 1<<uint(t.Second())&s.second == 0 {
+			if !truncated {
+				truncated = true
+				t = t.Truncate(time.Second)
+			}
+			t = t.Add(time.Second)
+			if t.Second() == 0 {
+				continue search
+			}
+		}
+
+		return t.In(after.Location())
+	}
+	return time.Time{}
+}
+
+// dayMatches follows the cron rule: when both day fields are restricted a day matching any of them is accepted
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	domMatch := 1<<uint(t.Day())&s.dom > 0
+	dowMatch := 1<<uint(t.Weekday())&s.dow > 0
+	if s.dom&starBit > 0 || s.dow&starBit > 0 {
+		return domMatch && dowMatch
+	}
+	return domMatch || dowMatch
+}
+
+// every returns a schedule activating once per delay, the delay is rounded down to whole seconds with a minimum of one second
+func every(delay time.Duration) everySchedule {
+	if delay < time.Second {
+		delay = time.Second
+	}
+	return everySchedule{delay: delay - delay%time.Second}
+}
+
+// Next returns the whole second one delay after the given time
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(s.delay - time.Duration(after.Nanosecond()))
+}
@@ -0,0 +1,158 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"invalid-schedule",
+		"* * * *",
+		"* * * * * * *",
+		"60 * * * * *",
+		"* * 24 * * *",
+		"* * * 0 * *",
+		"* * * * 13 *",
+		"* * * * * 8",
+		"5-1 * * * * *",
+		"*/0 * * * * *",
+		"*-5 * * * * *",
+		"1/2/3 * * * * *",
+		"* * * * FOO *",
+		"@fortnightly",
+		"@every 1x",
+		"CRON_TZ=Mars/Olympus * * * * * *",
+		"TZ=UTC",
+	}
+
+	for _, spec := range tests {
+		t.Run(spec, func(t *testing.T) {
+			_, err := Parse(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseStandardErrors(t *testing.T) {
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"* * * FOO *",
+		"@fortnightly",
+		"CRON_TZ=Mars/Olympus * * * * *",
+	}
+
+	for _, spec := range tests {
+		t.Run(spec, func(t *testing.T) {
+			_, err := ParseStandard(spec)
+			assert.Error(t, err)
+		})
+	}
+}
+
+type nextTest struct {
+	spec     string
+	after    string
+	expected string
+}
+
+func TestNext(t *testing.T) {
+	testNext(t, Parse, []nextTest{
+		// 6 field expressions
+		{"*/10 * * * * *", "2021-06-15T10:20:30Z", "2021-06-15T10:20:40Z"},
+		{"15 30 * * * *", "2021-06-15T10:20:30Z", "2021-06-15T10:30:15Z"},
+		{"0 30 2 * * *", "2021-06-15T10:20:30Z", "2021-06-16T02:30:00Z"},
+		{"0 */15 * * * *", "2021-06-15T10:20:30Z", "2021-06-15T10:30:00Z"},
+		{"0 0 9-17/4 * * *", "2021-06-15T13:00:00Z", "2021-06-15T17:00:00Z"},
+		{"0 0 0 * * MON-FRI", "2021-06-18T10:00:00Z", "2021-06-21T00:00:00Z"},
+		{"0 0 0 * * 7", "2021-06-15T10:00:00Z", "2021-06-20T00:00:00Z"},
+		{"0 0 0 * JAN,jul ?", "2021-06-15T10:00:00Z", "2021-07-01T00:00:00Z"},
+		// day of month or day of week when both are restricted
+		{"0 0 0 13 * FRI", "2021-06-15T10:00:00Z", "2021-06-18T00:00:00Z"},
+		// 5 field expressions keep the leading seconds of robfig/cron v1, the day of week is "*"
+		{"* * * * *", "2021-06-15T10:20:30Z", "2021-06-15T10:20:31Z"},
+		{"0 30 2 * *", "2021-06-15T10:20:30Z", "2021-06-16T02:30:00Z"},
+		{"*/15 * * * *", "2021-06-15T10:20:30Z", "2021-06-15T10:20:45Z"},
+		{"0 0 0 1,15 *", "2021-06-15T10:20:30Z", "2021-07-01T00:00:00Z"},
+		{"0 0 0 29 2", "2021-06-15T10:00:00Z", "2024-02-29T00:00:00Z"},
+		// descriptors
+		{"@hourly", "2021-06-15T10:20:30Z", "2021-06-15T11:00:00Z"},
+		{"@daily", "2021-06-15T10:20:30Z", "2021-06-16T00:00:00Z"},
+		{"@weekly", "2021-06-15T10:20:30Z", "2021-06-20T00:00:00Z"},
+		{"@monthly", "2021-06-15T10:20:30Z", "2021-07-01T00:00:00Z"},
+		{"@yearly", "2021-06-15T10:20:30Z", "2022-01-01T00:00:00Z"},
+		{"@every 90s", "2021-06-15T10:20:30.5Z", "2021-06-15T10:22:00Z"},
+		// time zones
+		{"TZ=UTC 0 0 12 * * *", "2021-06-15T10:20:30Z", "2021-06-15T12:00:00Z"},
+		{"CRON_TZ=Europe/Berlin 0 0 12 * * *", "2021-06-15T10:20:30Z", "2021-06-16T10:00:00Z"},
+		{"CRON_TZ=America/New_York @daily", "2021-06-15T10:20:30Z", "2021-06-16T04:00:00Z"},
+		// daylight saving: 02:30 doesn't exist on 2021-03-28 in Berlin
+		{"CRON_TZ=Europe/Berlin 0 30 2 * * *", "2021-03-27T12:00:00Z", "2021-03-29T00:30:00Z"},
+	})
+}
+
+func TestNextStandard(t *testing.T) {
+	testNext(t, ParseStandard, []nextTest{
+		{"* * * * *", "2021-06-15T10:20:30Z", "2021-06-15T10:21:00Z"},
+		{"30 2 * * *", "2021-06-15T10:20:30Z", "2021-06-16T02:30:00Z"},
+		{"*/15 * * * *", "2021-06-15T10:20:30Z", "2021-06-15T10:30:00Z"},
+		{"5/20 * * * *", "2021-06-15T10:50:00Z", "2021-06-15T11:05:00Z"},
+		{"0 9-17/4 * * *", "2021-06-15T13:00:00Z", "2021-06-15T17:00:00Z"},
+		{"0 0 1,15 * *", "2021-06-15T10:20:30Z", "2021-07-01T00:00:00Z"},
+		{"0 0 * * MON-FRI", "2021-06-18T10:00:00Z", "2021-06-21T00:00:00Z"},
+		{"0 0 * * 7", "2021-06-15T10:00:00Z", "2021-06-20T00:00:00Z"},
+		{"0 0 * JAN,jul ?", "2021-06-15T10:00:00Z", "2021-07-01T00:00:00Z"},
+		{"0 0 29 2 *", "2021-06-15T10:00:00Z", "2024-02-29T00:00:00Z"},
+		{"0 0 13 * FRI", "2021-06-15T10:00:00Z", "2021-06-18T00:00:00Z"},
+		{"@hourly", "2021-06-15T10:20:30Z", "2021-06-15T11:00:00Z"},
+		{"@every 90s", "2021-06-15T10:20:30.5Z", "2021-06-15T10:22:00Z"},
+		{"TZ=UTC 0 12 * * *", "2021-06-15T10:20:30Z", "2021-06-15T12:00:00Z"},
+		{"CRON_TZ=Europe/Berlin 30 2 * * *", "2021-03-27T12:00:00Z", "2021-03-29T00:30:00Z"},
+	})
+}
+
+func testNext(t *testing.T, parse func(string) (Schedule, error), tests []nextTest) {
+	for _, test := range tests {
+		t.Run(test.spec, func(t *testing.T) {
+			schedule, err := parse(test.spec)
+			require.NoError(t, err)
+
+			next := schedule.Next(parseTime(t, test.after))
+			assert.Equal(t, parseTime(t, test.expected), next.UTC())
+		})
+	}
+}
+
+func TestNextNeverSatisfied(t *testing.T) {
+	schedule, err := Parse("0 0 0 30 2 *")
+	require.NoError(t, err)
+	assert.True(t, schedule.Next(time.Now()).IsZero())
+}
+
+func TestNextKeepsLocation(t *testing.T) {
+	schedule, err := Parse("TZ=UTC @hourly")
+	require.NoError(t, err)
+
+	location := time.FixedZone("test", 3*60*60)
+	next := schedule.Next(time.Date(2021, 6, 15, 10, 20, 30, 0, location))
+	assert.Equal(t, location, next.Location())
+	assert.Equal(t, time.Date(2021, 6, 15, 11, 0, 0, 0, location), next)
+}
+
+func parseTime(t *testing.T, value string) time.Time {
+	parsed, err := time.Parse(time.RFC3339Nano, value)
+	require.NoError(t, err)
+	return parsed.UTC()
+}
@@ -0,0 +1,14 @@
+package semaphore
+
+import "context"
+
+// Semaphore : distributed semaphore, at most the configured number of permits are held at the same time across all the holders.
+// Each Semaphore value holds at most one permit, all the holders of the same semaphore have to use the same number of permits
+type Semaphore interface {
+	// Acquire blocks until a permit is acquired or ctx is done, the waiting holders get the permits in the order of arrival
+	Acquire(ctx context.Context) error
+	// TryAcquire acquires a permit only if one is free at the time of invocation, it never waits
+	TryAcquire() (acquired bool, err error)
+	// Release returns the permit
+	Release() error
+}
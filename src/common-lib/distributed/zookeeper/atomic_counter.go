@@ -0,0 +1,109 @@
+package zookeeper
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/samuel/go-zookeeper/zk"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/counter"
+)
+
+const (
+	countersNode = "counters"
+	// noVersion is the version of the counter node which does not exist yet
+	noVersion = -1
+)
+
+type atomicCounterImpl struct {
+	path string
+}
+
+// NewAtomicCounter returns the counter with the given name. The value is kept as the data of the counter node,
+// which is changed only if its version hasn't changed since the value was read
+func NewAtomicCounter(name string) counter.Counter {
+	return &atomicCounterImpl{path: zookeeperBasePath + zkSeparator + countersNode + zkSeparator + name}
+}
+
+// Get returns the current value
+func (c *atomicCounterImpl) Get(ctx context.Context) (int64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	value, _, err := c.get()
+	return value, err
+}
+
+// Add adds delta to the value, retrying on the concurrent changes until ctx is done
+func (c *atomicCounterImpl) Add(ctx context.Context, delta int64) (int64, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return 0, err
+		}
+
+		value, version, err := c.get()
+		if err != nil {
+			return 0, err
+		}
+		set, err := c.set(value+delta, version)
+		if err != nil {
+			return 0, err
+		}
+		if set {
+			return value + delta, nil
+		}
+	}
+}
+
+// CompareAndSet sets the value if it is equal to expected, retrying on the concurrent changes which keep it equal
+func (c *atomicCounterImpl) CompareAndSet(ctx context.Context, expected, value int64) (bool, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+
+		current, version, err := c.get()
+		if err != nil || current != expected {
+			return false, err
+		}
+		set, err := c.set(value, version)
+		if err != nil || set {
+			return set, err
+		}
+	}
+}
+
+// get returns the value and the version of the counter node, 0 and noVersion when it does not exist
+func (c *atomicCounterImpl) get() (int64, int32, error) {
+	data, stat, err := Client.Get(c.path)
+	if err == zk.ErrNoNode {
+		return 0, noVersion, nil
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+	if len(data) == 0 {
+		return 0, stat.Version, nil
+	}
+	value, err := strconv.ParseInt(string(data), 10, 64)
+	return value, stat.Version, err
+}
+
+// set writes the value if the counter node still has the version, reporting false on a concurrent change
+func (c *atomicCounterImpl) set(value int64, version int32) (bool, error) {
+	data := []byte(strconv.FormatInt(value, 10))
+	var err error
+	if version == noVersion {
+		_, err = Client.CreateRecursive(c.path, data, 0, zk.WorldACL(zk.PermAll))
+	} else {
+		_, err = Client.Set(c.path, data, version)
+	}
+
+	switch err {
+	case nil:
+		return true, nil
+	case zk.ErrNodeExists, zk.ErrBadVersion, zk.ErrNoNode:
+		return false, nil
+	}
+	return false, err
+}
@@ -0,0 +1,72 @@
+package zookeeper
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+func TestAtomicCounter(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	setTestBasePath(t)
+	mockTree(zkMockObj)
+
+	ctx := context.Background()
+	c := NewAtomicCounter("jobs")
+	if value, err := c.Get(ctx); err != nil || value != 0 {
+		t.Fatalf("expected 0, got %d, %v", value, err)
+	}
+	if value, err := c.Add(ctx, 5); err != nil || value != 5 {
+		t.Fatalf("expected 5, got %d, %v", value, err)
+	}
+
+	if set, err := c.CompareAndSet(ctx, 5, 7); err != nil || !set {
+		t.Fatalf("expected the value to be set, got %v, %v", set, err)
+	}
+	if set, err := c.CompareAndSet(ctx, 5, 9); err != nil || set {
+		t.Fatalf("expected the value not to be set, got %v, %v", set, err)
+	}
+	if value, err := NewAtomicCounter("jobs").Get(ctx); err != nil || value != 7 {
+		t.Fatalf("expected 7, got %d, %v", value, err)
+	}
+}
+
+func TestAtomicCounterConcurrentAdd(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	setTestBasePath(t)
+	mockTree(zkMockObj)
+
+	ctx := context.Background()
+	wg := &sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := NewAtomicCounter("jobs").Add(ctx, 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if value, err := NewAtomicCounter("jobs").Get(ctx); err != nil || value != 10 {
+		t.Fatalf("expected 10, got %d, %v", value, err)
+	}
+}
+
+func TestAtomicCounterContext(t *testing.T) {
+	_, originalClient := InitMock()
+	defer Restore(originalClient)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	c := NewAtomicCounter("jobs")
+	if _, err := c.Add(ctx, 1); err != context.Canceled {
+		t.Fatalf("expected %v error, got: %v", context.Canceled, err)
+	}
+	if _, err := c.CompareAndSet(ctx, 0, 1); err != context.Canceled {
+		t.Fatalf("expected %v error, got: %v", context.Canceled, err)
+	}
+}
@@ -0,0 +1,189 @@
+package zookeeper
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/samuel/go-zookeeper/zk"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/barrier"
+)
+
+const (
+	barriersNode      = "barriers"
+	readyNode         = "ready"
+	participantPrefix = "participant-"
+)
+
+var (
+	// ErrBarrierEntered is returned by Enter when the DoubleBarrier has already entered
+	ErrBarrierEntered = errors.New("zookeeper: barrier is already entered")
+	// ErrBarrierNotEntered is returned by Leave when the DoubleBarrier has not entered
+	ErrBarrierNotEntered = errors.New("zookeeper: barrier is not entered")
+)
+
+type doubleBarrierImpl struct {
+	path string
+	size int
+	// node is the participant node owned by this barrier between Enter and Leave
+	node string
+}
+
+// NewDoubleBarrier returns the double barrier with the given name for size participants.
+// The participants are ephemeral sequential nodes, the ready node is created by the participant which completes the size.
+// The barrier can be used again once all the participants have left
+func NewDoubleBarrier(name string, size int) barrier.DoubleBarrier {
+	if size < 1 {
+		size = 1
+	}
+	return &doubleBarrierImpl{
+		path: zookeeperBasePath + zkSeparator + barriersNode + zkSeparator + name,
+		size: size,
+	}
+}
+
+// Enter joins the barrier and waits for the ready node
+func (b *doubleBarrierImpl) Enter(ctx context.Context) error {
+	if b.node != "" {
+		return ErrBarrierEntered
+	}
+
+	ready := b.path + zkSeparator + readyNode
+	// the watch is set before joining, so the ready node created in between is not missed
+	exists, _, events, err := Client.ExistsW(ready)
+	if err != nil {
+		return err
+	}
+
+	node, err := Client.CreateRecursive(b.path+zkSeparator+participantPrefix, []byte{}, int32(zk.FlagEphemeral|zk.FlagSequence), zk.WorldACL(zk.PermAll))
+	if err != nil {
+		return err
+	}
+	b.node = node
+
+	if err = b.waitForReady(ctx, exists, events); err != nil {
+		if delErr := Client.Delete(node, -1); delErr != nil && delErr != zk.ErrNoNode {
+			Logger().Debug(defaultTransaction, "Couldn't delete barrier node %s: %s", node, delErr)
+		}
+		b.node = ""
+		return err
+	}
+	return nil
+}
+
+func (b *doubleBarrierImpl) waitForReady(ctx context.Context, exists bool, events <-chan zk.Event) error {
+	if exists {
+		return nil
+	}
+
+	participants, err := b.participants()
+	if err != nil {
+		return err
+	}
+	if len(participants) >= b.size {
+		_, err = Client.Create(b.path+zkSeparator+readyNode, []byte{}, 0, zk.WorldACL(zk.PermAll))
+		if err == zk.ErrNodeExists {
+			return nil
+		}
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-events:
+		}
+
+		// the watch may fire on a session event as well
+		if exists, _, events, err = Client.ExistsW(b.path + zkSeparator + readyNode); err != nil || exists {
+			return err
+		}
+	}
+}
+
+// Leave removes the participant and waits for the other participants to leave.
+// The lowest participant leaves last, it waits for the highest one, while the others wait for the lowest one
+func (b *doubleBarrierImpl) Leave(ctx context.Context) error {
+	if b.node == "" {
+		return ErrBarrierNotEntered
+	}
+	name := strings.TrimPrefix(b.node, b.path+zkSeparator)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		participants, err := b.participants()
+		if err != nil {
+			return err
+		}
+
+		own := containsNode(participants, b.node)
+		switch {
+		case len(participants) == 0:
+			b.node = ""
+			return nil
+
+		case own && len(participants) == 1:
+			if err = Client.Delete(b.node, -1); err != nil && err != zk.ErrNoNode {
+				return err
+			}
+			b.node = ""
+			// the last participant out resets the barrier for the next use
+			if err = Client.Delete(b.path+zkSeparator+readyNode, -1); err != nil && err != zk.ErrNoNode {
+				Logger().Debug(defaultTransaction, "Couldn't delete barrier ready node %s: %s", b.path, err)
+			}
+			return nil
+
+		case own && participants[0] == name:
+			if err = b.waitForDelete(ctx, participants[len(participants)-1]); err != nil {
+				return err
+			}
+
+		default:
+			if own {
+				if err = Client.Delete(b.node, -1); err != nil && err != zk.ErrNoNode {
+					return err
+				}
+			}
+			if err = b.waitForDelete(ctx, participants[0]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (b *doubleBarrierImpl) waitForDelete(ctx context.Context, participant string) error {
+	exists, _, events, err := Client.ExistsW(b.path + zkSeparator + participant)
+	if err != nil || !exists {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-events:
+		return nil
+	}
+}
+
+// participants returns the participant nodes in the order of joining
+func (b *doubleBarrierImpl) participants() ([]string, error) {
+	children, _, err := Client.Children(b.path)
+	if err != nil {
+		return nil, err
+	}
+
+	participants := make([]string, 0, len(children))
+	for _, child := range children {
+		if strings.HasPrefix(child, participantPrefix) {
+			participants = append(participants, child)
+		}
+	}
+	sort.Strings(participants)
+	return participants, nil
+}
@@ -0,0 +1,82 @@
+package zookeeper
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDoubleBarrier(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	setTestBasePath(t)
+	tree := mockTree(zkMockObj)
+
+	const size = 3
+	entered, left := make(chan error, size), make(chan error, size)
+	leave := make(chan struct{})
+	start := func() {
+		b := NewDoubleBarrier("batch", size)
+		entered <- b.Enter(context.Background())
+		<-leave
+		left <- b.Leave(context.Background())
+	}
+
+	go start()
+	go start()
+	select {
+	case err := <-entered:
+		t.Fatalf("expected to wait for all the participants, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	go start()
+	for i := 0; i < size; i++ {
+		select {
+		case err := <-entered:
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("barrier wasn't entered")
+		}
+	}
+
+	close(leave)
+	for i := 0; i < size; i++ {
+		select {
+		case err := <-left:
+			if err != nil {
+				t.Fatalf("expected no error but got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("barrier wasn't left")
+		}
+	}
+
+	if tree.exists("/test/" + barriersNode + "/batch/" + readyNode) {
+		t.Fatal("expected the ready node to be removed by the last participant")
+	}
+}
+
+func TestDoubleBarrierEnterContext(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	setTestBasePath(t)
+	tree := mockTree(zkMockObj)
+
+	b := NewDoubleBarrier("batch", 2)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := b.Enter(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v error, got: %v", context.DeadlineExceeded, err)
+	}
+
+	children, _ := tree.children("/test/"+barriersNode+"/batch", false)
+	if len(children) != 0 {
+		t.Fatalf("expected the participant node to be removed, got %v", children)
+	}
+	if err := b.Leave(context.Background()); err != ErrBarrierNotEntered {
+		t.Fatalf("expected %v error, got: %v", ErrBarrierNotEntered, err)
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
@@ -14,12 +17,18 @@ import (
 
 const (
 	pathForListeners = "broadcast/listeners"
+	pathForEvents    = "broadcast/events"
+	pathForSequence  = "broadcast/sequence"
+	eventPrefix      = "event-"
 	defaultTimeout   = time.Second
 )
 
 var (
 	// Broadcast instance
-	Broadcast distributed.Broadcast
+	Broadcast distributed.ReplayableBroadcast
+	// BroadcastRetention is the time the broadcast events are kept in the log for the replay,
+	// the latest event is always kept
+	BroadcastRetention = 24 * time.Hour
 	// ErrZookeeperNotInit zookeeper is not initialized error
 	ErrZookeeperNotInit = errors.New("zookeeper is not initialized use zookeeper.Init")
 
@@ -33,8 +42,9 @@ type broadcastImpl struct {
 }
 
 // InitBroadcast singleton, thread-safe, returns pointer to *Broadcast
-// instanceID should be unique for each instance of micro-service
-func InitBroadcast(instanceID string, timeout time.Duration) (distributed.Broadcast, error) {
+// instanceID should be unique for each instance of micro-service.
+// Events are kept in a log of nodes polled by the listeners every timeout
+func InitBroadcast(instanceID string, timeout time.Duration) (distributed.ReplayableBroadcast, error) {
 	if Client == nil {
 		return nil, ErrZookeeperNotInit
 	}
@@ -75,6 +85,27 @@ func (n *broadcastImpl) listenersPath() string {
 	return zookeeperBasePath + zkSeparator + pathForListeners
 }
 
+func (n *broadcastImpl) eventsPath() string {
+	return zookeeperBasePath + zkSeparator + pathForEvents
+}
+
+func (n *broadcastImpl) sequencePath() string {
+	return zookeeperBasePath + zkSeparator + pathForSequence
+}
+
+// Listen listens the events created from now on
+func (n *broadcastImpl) Listen(ctx context.Context, wg *sync.WaitGroup) {
+	sequence, err := n.LastSequence()
+	if err != nil {
+		Logger().Info(defaultTransaction, "lastSequence error: %s", err)
+		// the latest sequence is taken by the first successful poll
+		sequence = -1
+	}
+	n.ListenFrom(ctx, wg, sequence)
+}
+
+// subscribe registers the listener for the publishers of the previous versions, which send the events
+// to the queue of every registered listener instead of the log
 func (n *broadcastImpl) subscribe() error {
 	exists, _, err := Client.Exists(n.absolutePath())
 	if err != nil {
@@ -89,10 +120,13 @@ func (n *broadcastImpl) subscribe() error {
 	return err
 }
 
-func (n *broadcastImpl) Listen(ctx context.Context, wg *sync.WaitGroup) {
+// ListenFrom listens the events with a sequence greater than the given one, starting with the ones kept in the log.
+// The events of the publishers of the previous versions are received through the queue of the listener
+func (n *broadcastImpl) ListenFrom(ctx context.Context, wg *sync.WaitGroup, sequence int64) {
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
+		last := sequence
 		for {
 			select {
 			case <-ctx.Done():
@@ -102,18 +136,131 @@ func (n *broadcastImpl) Listen(ctx context.Context, wg *sync.WaitGroup) {
 				if err := n.subscribe(); err != nil {
 					Logger().Info(defaultTransaction, "subscribe error: %s", err)
 				}
-				items, err := Queue.GetList(n.instanceID)
-				if err != nil && err != zk.ErrNoNode {
-					Logger().Info(defaultTransaction, "getList error: %s", err)
-					continue
-				}
-				n.process(items)
+				last = n.process(last)
+				n.processQueue()
 			}
 		}
 	}()
 }
 
-func (n *broadcastImpl) process(items []string) {
+// LastSequence returns the sequence of the latest event
+func (n *broadcastImpl) LastSequence() (int64, error) {
+	sequence, _, err := n.sequence()
+	return sequence, err
+}
+
+// sequence returns the sequence of the latest event and the version of the sequence node.
+// The node is created with the latest sequence of the log when missing, e.g. for the events of the previous versions
+func (n *broadcastImpl) sequence() (int64, int32, error) {
+	for {
+		data, stat, err := Client.Get(n.sequencePath())
+		if err == nil {
+			sequence, err := strconv.ParseInt(string(data), 10, 64)
+			return sequence, stat.Version, err
+		}
+		if err != zk.ErrNoNode {
+			return 0, 0, err
+		}
+
+		sequences, err := n.sequences()
+		if err != nil {
+			return 0, 0, err
+		}
+		var last int64
+		if len(sequences) > 0 {
+			last = sequences[len(sequences)-1]
+		}
+		_, err = Client.CreateRecursive(n.sequencePath(), []byte(strconv.FormatInt(last, 10)), 0, zk.WorldACL(zk.PermAll))
+		if err != nil && err != zk.ErrNodeExists {
+			return 0, 0, err
+		}
+	}
+}
+
+// sequences returns the sequences of the events kept in the log in ascending order
+func (n *broadcastImpl) sequences() ([]int64, error) {
+	children, _, err := Client.Children(n.eventsPath())
+	if err == zk.ErrNoNode {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sequences := make([]int64, 0, len(children))
+	for _, child := range children {
+		if sequence, err := parseEventSequence(child); err == nil {
+			sequences = append(sequences, sequence)
+		}
+	}
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+	return sequences, nil
+}
+
+// process delivers the events following the last one and returns the sequence of the last delivered event.
+// Only the events up to the sequence node are delivered, the events being created after it are delivered with the next poll
+func (n *broadcastImpl) process(last int64) int64 {
+	published, _, err := n.sequence()
+	if err != nil {
+		Logger().Info(defaultTransaction, "lastSequence error: %s", err)
+		return last
+	}
+	if last < 0 {
+		return published
+	}
+	if published <= last {
+		return last
+	}
+
+	sequences, err := n.sequences()
+	if err != nil {
+		Logger().Info(defaultTransaction, "getList error: %s", err)
+		return last
+	}
+
+	for _, sequence := range sequences {
+		if sequence <= last || sequence > published {
+			continue
+		}
+
+		data, _, err := Client.Get(n.eventPath(sequence))
+		if err == zk.ErrNoNode {
+			// removed by the retention in the meantime, reported as missed with the next event
+			continue
+		}
+		if err != nil {
+			Logger().Error(defaultTransaction, "Broadcast.GetEventFailed", "%v", err)
+			return last
+		}
+
+		// sequence 0 asks for the events kept in the log, so the removed ones are not reported
+		if last > 0 && sequence > last+1 {
+			n.missed(last+1, sequence-1)
+		}
+		last = sequence
+
+		e := new(distributed.Event)
+		if err := json.Unmarshal(data, e); err != nil {
+			Logger().Error(defaultTransaction, "Json.UnmarshalFailed", "%v", err)
+			continue
+		}
+		e.Sequence = sequence
+		n.handle(e)
+	}
+	return last
+}
+
+// processQueue delivers the events sent to the queue of the listener by the publishers of the previous versions,
+// the events with a sequence are sent by the current publishers and delivered from the log
+func (n *broadcastImpl) processQueue() {
+	items, err := Queue.GetList(n.instanceID)
+	if err != nil {
+		if err != zk.ErrNoNode {
+			Logger().Info(defaultTransaction, "getList error: %s", err)
+		}
+		return
+	}
+
 	for _, item := range items {
 		data, err := Queue.GetItemData(n.instanceID, item)
 		if err != nil {
@@ -130,25 +277,43 @@ func (n *broadcastImpl) process(items []string) {
 			Logger().Error(defaultTransaction, "Json.UnmarshalFailed", "%v", err)
 			continue
 		}
-
-		if handler, ok := n.handlers[e.Type]; ok {
-			handler(e)
+		if e.Sequence == 0 {
+			n.handle(e)
 		}
 	}
 }
 
+func (n *broadcastImpl) missed(from, to int64) {
+	Logger().Warn(defaultTransaction, "Broadcast events %d-%d were removed before being delivered", from, to)
+	n.handle(&distributed.Event{
+		Type:      distributed.EventsMissed,
+		Payload:   distributed.MissedEvents{From: from, To: to},
+		Sequence:  to,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (n *broadcastImpl) handle(e *distributed.Event) {
+	if handler, ok := n.handlers[e.Type]; ok {
+		handler(e)
+	}
+}
+
 func (n *broadcastImpl) CreateEvent(e distributed.Event) error {
-	// getting all subscribers
+	// getting all subscribers, the listeners of the previous versions are registered there
 	subscribers, _, err := Client.Children(n.listenersPath())
-	if err != nil {
+	if err != nil && err != zk.ErrNoNode {
 		return err
 	}
 
-	content, err := json.Marshal(e)
-	if err != nil {
-		return err
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now()
 	}
+	var content []byte
 
+	if content, err = n.appendEvent(e); err != nil {
+		return err
+	}
 	// sending the message to subscribers
 	for _, subscriber := range subscribers {
 		_, err := Queue.CreateItem(content, subscriber)
@@ -157,5 +322,76 @@ func (n *broadcastImpl) CreateEvent(e distributed.Event) error {
 		}
 	}
 
+	if err := n.trimEvents(); err != nil {
+		Logger().Error(defaultTransaction, "Broadcast.TrimEventsFailed", "%v", err)
+	}
+	return nil
+}
+
+// appendEvent adds the event to the log with the sequence following the sequence node and returns the stored event.
+// The sequence node is advanced with a versioned Set once the event node exists, so the sequences have no gaps,
+// unlike the sequential nodes whose suffix is also increased by the removed nodes, and the listeners never read
+// a sequence before its event
+func (n *broadcastImpl) appendEvent(e distributed.Event) ([]byte, error) {
+	for {
+		sequence, version, err := n.sequence()
+		if err != nil {
+			return nil, err
+		}
+
+		e.Sequence = sequence + 1
+		content, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = Client.CreateRecursive(n.eventPath(e.Sequence), content, 0, zk.WorldACL(zk.PermAll))
+		if err != nil && err != zk.ErrNodeExists {
+			return nil, err
+		}
+		// the node of an event whose publisher stopped before advancing the sequence is taken over by the next publisher
+		if _, serr := Client.Set(n.sequencePath(), []byte(strconv.FormatInt(e.Sequence, 10)), version); serr != nil && serr != zk.ErrBadVersion {
+			return nil, serr
+		}
+		if err == nil {
+			return content, nil
+		}
+		// the sequence is taken by an event created concurrently, try the next one
+	}
+}
+
+// trimEvents removes the events older than BroadcastRetention except the latest one
+func (n *broadcastImpl) trimEvents() error {
+	sequences, err := n.sequences()
+	if err != nil || len(sequences) < 2 {
+		return err
+	}
+
+	oldest := time.Now().Add(-BroadcastRetention)
+	for _, sequence := range sequences[:len(sequences)-1] {
+		_, stat, err := Client.Get(n.eventPath(sequence))
+		if err == zk.ErrNoNode {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !time.Unix(0, stat.Ctime*int64(time.Millisecond)).Before(oldest) {
+			return nil
+		}
+		if err = Client.Delete(n.eventPath(sequence), stat.Version); err != nil && err != zk.ErrNoNode {
+			return err
+		}
+	}
 	return nil
 }
+
+// eventPath returns the path of the event node, the node sequence starts with 0 and the event sequence with 1
+func (n *broadcastImpl) eventPath(sequence int64) string {
+	return n.eventsPath() + zkSeparator + fmt.Sprintf("%s%010d", eventPrefix, sequence-1)
+}
+
+func parseEventSequence(path string) (int64, error) {
+	seq, err := parseLockSeq(path)
+	return int64(seq) + 1, err
+}
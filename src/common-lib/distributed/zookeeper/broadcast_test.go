@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
@@ -73,6 +74,7 @@ func TestBroadcast_CreateEvent(t *testing.T) {
 		zkMockObj, originalClient := InitMock()
 		defer Restore(originalClient)
 		zkMockObj.When("Children", mock.Any).Return([]string{}, &zk.Stat{}, nil)
+		mockAppend(zkMockObj, nil)
 		if err := Broadcast.CreateEvent(distributed.Event{Type: handlerName, Payload: make(chan int)}); err == nil {
 			t.Error("error can not be <nil>")
 		}
@@ -82,6 +84,7 @@ func TestBroadcast_CreateEvent(t *testing.T) {
 		zkMockObj, originalClient := InitMock()
 		defer Restore(originalClient)
 		zkMockObj.When("Children", mock.Any).Return([]string{subscriber}, &zk.Stat{}, nil)
+		mockAppend(zkMockObj, nil)
 		zkMockObj.When("CreateRecursive", mock.Any, mock.Any, mock.Any, mock.Any).Return("", nil)
 		if err := Broadcast.CreateEvent(distributed.Event{Type: handlerName}); err != nil {
 			t.Error(err)
@@ -92,63 +95,192 @@ func TestBroadcast_CreateEvent(t *testing.T) {
 		zkMockObj, originalClient := InitMock()
 		defer Restore(originalClient)
 		zkMockObj.When("Children", mock.Any).Return([]string{subscriber}, &zk.Stat{}, nil)
+		mockAppend(zkMockObj, nil)
 		zkMockObj.When("CreateRecursive", mock.Any, mock.Any, mock.Any, mock.Any).Return("", ErrMsg)
 		if err := Broadcast.CreateEvent(distributed.Event{Type: handlerName}); err != ErrMsg {
 			t.Errorf("got error %s, expected %s", err, ErrMsg)
 		}
 	})
-}
 
-func TestBroadcast_subscribe(t *testing.T) {
-	impl, ok := Broadcast.(*broadcastImpl)
-	if !ok {
-		t.Error("wrong type assertion")
-	}
-
-	t.Run("Subscribe", func(t *testing.T) {
+	t.Run("NoListeners", func(t *testing.T) {
 		zkMockObj, originalClient := InitMock()
 		defer Restore(originalClient)
-		zkMockObj.When("Exists", mock.Any).Return(false, &zk.Stat{}, nil)
-		zkMockObj.When("CreateRecursive", mock.Any, mock.Any, mock.Any, mock.Any).Return("", nil)
-		if err := impl.subscribe(); err != nil {
+		zkMockObj.When("Children", mock.Any).Return([]string(nil), (*zk.Stat)(nil), zk.ErrNoNode)
+		mockAppend(zkMockObj, nil)
+		if err := Broadcast.CreateEvent(distributed.Event{Type: handlerName}); err != nil {
 			t.Error(err)
 		}
 	})
 
-	t.Run("SubscribeError", func(t *testing.T) {
+	t.Run("LogError", func(t *testing.T) {
 		zkMockObj, originalClient := InitMock()
 		defer Restore(originalClient)
-		zkMockObj.When("Exists", mock.Any).Return(false, &zk.Stat{}, ErrMsg)
-		if err := impl.subscribe(); err != ErrMsg {
+		zkMockObj.When("Children", mock.Any).Return([]string{subscriber}, &zk.Stat{}, nil)
+		mockAppend(zkMockObj, ErrMsg)
+		if err := Broadcast.CreateEvent(distributed.Event{Type: handlerName}); err != ErrMsg {
 			t.Errorf("got error %s, expected %s", err, ErrMsg)
 		}
 	})
+}
 
-	t.Run("SubscribeExists", func(t *testing.T) {
-		zkMockObj, originalClient := InitMock()
-		defer Restore(originalClient)
-		zkMockObj.When("Exists", mock.Any).Return(true, &zk.Stat{}, nil)
-		if err := impl.subscribe(); err != nil {
-			t.Error(err)
-		}
-	})
+func TestBroadcast_trimEvents(t *testing.T) {
+	impl, ok := Broadcast.(*broadcastImpl)
+	if !ok {
+		t.Error("wrong type assertion")
+	}
 
-	t.Run("SubscribeCreateRecursiveError", func(t *testing.T) {
-		zkMockObj, originalClient := InitMock()
-		defer Restore(originalClient)
-		zkMockObj.When("Exists", mock.Any).Return(false, &zk.Stat{}, nil)
-		zkMockObj.When("CreateRecursive", mock.Any, mock.Any, mock.Any, mock.Any).Return("", ErrMsg)
-		if err := impl.subscribe(); err != ErrMsg {
-			t.Errorf("got error %s, expected %s", err, ErrMsg)
-		}
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+
+	expired := time.Now().Add(-2*BroadcastRetention).UnixNano() / int64(time.Millisecond)
+	fresh := time.Now().UnixNano() / int64(time.Millisecond)
+	zkMockObj.When("Children", impl.eventsPath()).Return([]string{"event-0000000002", "event-0000000000", "event-0000000001"}, &zk.Stat{}, nil)
+	zkMockObj.When("Get", impl.eventPath(1)).Return([]byte{}, &zk.Stat{Ctime: expired, Version: 1}, nil)
+	zkMockObj.When("Get", impl.eventPath(2)).Return([]byte{}, &zk.Stat{Ctime: fresh}, nil)
+	zkMockObj.When("Delete", impl.eventPath(1), int32(1)).Return(nil).Times(1)
+
+	if err := impl.trimEvents(); err != nil {
+		t.Error(err)
+	}
+	if _, err := zkMockObj.Verify(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestBroadcast_LastSequence(t *testing.T) {
+	impl := Broadcast.(*broadcastImpl)
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+
+	zkMockObj.When("Get", impl.sequencePath()).Return([]byte("10"), &zk.Stat{}, nil).Times(1)
+	sequence, err := Broadcast.LastSequence()
+	if err != nil || sequence != 10 {
+		t.Errorf("got sequence %d and error %v, expected 10", sequence, err)
+	}
+
+	// the sequence node is created from the log of the previous versions
+	zkMockObj.When("Get", impl.sequencePath()).Return([]byte(nil), (*zk.Stat)(nil), zk.ErrNoNode).Times(1)
+	zkMockObj.When("Children", impl.eventsPath()).Return([]string{"event-0000000004", "event-0000000008"}, &zk.Stat{}, nil).Times(1)
+	zkMockObj.When("CreateRecursive", impl.sequencePath(), []byte("9"), int32(0), mock.Any).Return(impl.sequencePath(), nil).Times(1)
+	zkMockObj.When("Get", impl.sequencePath()).Return([]byte("9"), &zk.Stat{}, nil).Times(1)
+	sequence, err = Broadcast.LastSequence()
+	if err != nil || sequence != 9 {
+		t.Errorf("got sequence %d and error %v, expected 9", sequence, err)
+	}
+	if ok, err := zkMockObj.Verify(); !ok {
+		t.Error(err)
+	}
+}
+
+// mockAppend mocks appending the event 5 to the log
+func mockAppend(zkMockObj *ClientMock, err error) {
+	impl := Broadcast.(*broadcastImpl)
+	zkMockObj.When("Get", impl.sequencePath()).Return([]byte("4"), &zk.Stat{Version: 2}, nil)
+	zkMockObj.When("CreateRecursive", impl.eventPath(5), mock.Any, int32(0), mock.Any).Return(impl.eventPath(5), err)
+	zkMockObj.When("Set", impl.sequencePath(), []byte("5"), int32(2)).Return(&zk.Stat{Version: 3}, nil)
+}
+
+func TestBroadcast_CreateEventAfterTrim(t *testing.T) {
+	impl := Broadcast.(*broadcastImpl)
+	var (
+		mutex     sync.Mutex
+		sequences []int64
+		missed    []distributed.MissedEvents
+	)
+	Broadcast.AddHandler(handlerName, func(e *distributed.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		sequences = append(sequences, e.Sequence)
+	})
+	Broadcast.AddHandler(distributed.EventsMissed, func(e *distributed.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		missed = append(missed, e.Payload.(distributed.MissedEvents))
 	})
+
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	tree := mockTree(zkMockObj)
+
+	require.NoError(t, Broadcast.CreateEvent(distributed.Event{Type: handlerName, Payload: payload}))
+	require.NoError(t, Broadcast.CreateEvent(distributed.Event{Type: handlerName, Payload: payload}))
+	// the first event is removed by the retention, which also increases the suffix of the next sequential node
+	require.False(t, tree.exists(impl.eventPath(1)))
+	last := impl.process(0)
+	require.Equal(t, int64(2), last)
+
+	require.NoError(t, Broadcast.CreateEvent(distributed.Event{Type: handlerName, Payload: payload}))
+	require.Equal(t, int64(3), impl.process(last))
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	require.Equal(t, []int64{2, 3}, sequences)
+	require.Empty(t, missed, "a listener caught up with the log should not miss any event")
 }
 
-func TestBroadcast_Listen(t *testing.T) {
+func TestBroadcast_ListenFrom(t *testing.T) {
+	var (
+		mutex     sync.Mutex
+		sequences []int64
+		missed    []distributed.MissedEvents
+	)
 	Broadcast.AddHandler(handlerName, func(e *distributed.Event) {
 		if e.Payload.(string) != payload {
 			t.Errorf("got %s, expected %s", e.Payload.(string), payload)
 		}
+		mutex.Lock()
+		defer mutex.Unlock()
+		sequences = append(sequences, e.Sequence)
+	})
+	Broadcast.AddHandler(distributed.EventsMissed, func(e *distributed.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		missed = append(missed, e.Payload.(distributed.MissedEvents))
+	})
+
+	impl, ok := Broadcast.(*broadcastImpl)
+	if !ok {
+		t.Error("wrong type assertion")
+	}
+	impl.timeout = time.Millisecond
+
+	content, err := json.Marshal(distributed.Event{Type: handlerName, Payload: payload})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	// events 1 and 2 were removed by the retention, event 4 is removed before being read
+	zkMockObj.When("Get", impl.sequencePath()).Return([]byte("5"), &zk.Stat{}, nil)
+	zkMockObj.When("Children", impl.eventsPath()).Return([]string{"event-0000000002", "event-0000000003", "event-0000000004"}, &zk.Stat{}, nil)
+	zkMockObj.When("Get", impl.eventPath(4)).Return([]byte(nil), (*zk.Stat)(nil), zk.ErrNoNode)
+	zkMockObj.When("Get", mock.Any).Return(content, &zk.Stat{}, nil)
+	zkMockObj.When("Exists", impl.absolutePath()).Return(true, &zk.Stat{}, nil)
+	zkMockObj.When("Children", getQueueZkPath(impl.instanceID)).Return([]string(nil), (*zk.Stat)(nil), zk.ErrNoNode)
+
+	wg := &sync.WaitGroup{}
+	Broadcast.ListenFrom(ctx, wg, 0)
+	time.Sleep(time.Millisecond * 10)
+	cancel()
+	wg.Wait()
+
+	if !reflect.DeepEqual(sequences, []int64{3, 5}) {
+		t.Errorf("got sequences %v, expected [3 5]", sequences)
+	}
+	if !reflect.DeepEqual(missed, []distributed.MissedEvents{{From: 4, To: 4}}) {
+		t.Errorf("got missed events %v, expected [{4 4}]", missed)
+	}
+}
+
+func TestBroadcast_Listen(t *testing.T) {
+	var (
+		mutex     sync.Mutex
+		sequences []int64
+	)
+	Broadcast.AddHandler(handlerName, func(e *distributed.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		sequences = append(sequences, e.Sequence)
 	})
 
 	impl, ok := Broadcast.(*broadcastImpl)
@@ -163,15 +295,74 @@ func TestBroadcast_Listen(t *testing.T) {
 
 	zkMockObj, originalClient := InitMock()
 	defer Restore(originalClient)
-	zkMockObj.When("Exists", mock.Any).Return(false, &zk.Stat{}, nil).Times(2)
-	zkMockObj.When("CreateRecursive", mock.Any, mock.Any, mock.Any, mock.Any).Return("", nil)
-	zkMockObj.When("Children", mock.Any).Return([]string{subscriber, subscriber}, &zk.Stat{}, nil).Times(2)
-	zkMockObj.When("Get", mock.Any).Return(content, &zk.Stat{}, nil).Times(1)
-	zkMockObj.When("Delete", mock.Any, mock.Any).Return(ErrMsg)
+	// the events existing before Listen are not delivered
+	zkMockObj.When("Get", impl.sequencePath()).Return([]byte("1"), &zk.Stat{}, nil).Times(1)
+	zkMockObj.When("Get", impl.sequencePath()).Return([]byte("2"), &zk.Stat{}, nil)
+	zkMockObj.When("Children", impl.eventsPath()).Return([]string{"event-0000000000", "event-0000000001"}, &zk.Stat{}, nil)
+	zkMockObj.When("Get", mock.Any).Return(content, &zk.Stat{}, nil)
+	zkMockObj.When("Exists", impl.absolutePath()).Return(true, &zk.Stat{}, nil)
+	zkMockObj.When("Children", getQueueZkPath(impl.instanceID)).Return([]string(nil), (*zk.Stat)(nil), zk.ErrNoNode)
 
 	wg := &sync.WaitGroup{}
 	Broadcast.Listen(ctx, wg)
 	time.Sleep(time.Millisecond * 10)
 	cancel()
 	wg.Wait()
+
+	if !reflect.DeepEqual(sequences, []int64{2}) {
+		t.Errorf("got sequences %v, expected [2]", sequences)
+	}
+}
+
+func TestBroadcast_ListenPreviousVersions(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		events []*distributed.Event
+	)
+	Broadcast.AddHandler(handlerName, func(e *distributed.Event) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		events = append(events, e)
+	})
+
+	impl, ok := Broadcast.(*broadcastImpl)
+	if !ok {
+		t.Error("wrong type assertion")
+	}
+	impl.timeout = time.Millisecond
+
+	legacy, err := json.Marshal(distributed.Event{Type: handlerName, Payload: payload})
+	require.NoError(t, err)
+	current, err := json.Marshal(distributed.Event{Type: handlerName, Payload: payload, Sequence: 3})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	queuePath := getQueueZkPath(impl.instanceID)
+	// the listener is registered for the publishers of the previous versions
+	zkMockObj.When("Exists", impl.absolutePath()).Return(false, (*zk.Stat)(nil), nil).Times(1)
+	zkMockObj.When("CreateRecursive", impl.absolutePath(), mock.Any, int32(zk.FlagEphemeral), mock.Any).Return(impl.absolutePath(), nil).Times(1)
+	zkMockObj.When("Exists", impl.absolutePath()).Return(true, &zk.Stat{}, nil)
+	zkMockObj.When("Get", impl.sequencePath()).Return([]byte("0"), &zk.Stat{}, nil)
+	zkMockObj.When("Children", impl.eventsPath()).Return([]string(nil), (*zk.Stat)(nil), zk.ErrNoNode)
+	// the event of a current publisher is delivered from the log, so it is only removed from the queue
+	zkMockObj.When("Children", queuePath).Return([]string{"item-1", "item-2"}, &zk.Stat{}, nil).Times(1)
+	zkMockObj.When("Children", queuePath).Return([]string(nil), (*zk.Stat)(nil), zk.ErrNoNode)
+	zkMockObj.When("Get", queuePath+"/item-1").Return(legacy, &zk.Stat{}, nil).Times(1)
+	zkMockObj.When("Get", queuePath+"/item-2").Return(current, &zk.Stat{}, nil).Times(1)
+	zkMockObj.When("Delete", mock.Any, int32(0)).Return(nil).Times(2)
+
+	wg := &sync.WaitGroup{}
+	Broadcast.ListenFrom(ctx, wg, 0)
+	time.Sleep(time.Millisecond * 10)
+	cancel()
+	wg.Wait()
+
+	if len(events) != 1 || events[0].Sequence != 0 {
+		t.Errorf("expected the event of the previous version only, got %v", events)
+	}
+	if ok, err := zkMockObj.Verify(); !ok {
+		t.Error(err)
+	}
 }
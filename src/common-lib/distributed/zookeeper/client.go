@@ -18,10 +18,14 @@ type (
 		State() string
 		// Exists checks is item exist
 		Exists(path string) (bool, *zk.Stat, error)
+		// ExistsW checks is item exist and sets a watch on it
+		ExistsW(path string) (bool, *zk.Stat, <-chan zk.Event, error)
 		// Get gets data by path
 		Get(path string) ([]byte, *zk.Stat, error)
 		// Children gets list of item children
 		Children(path string) ([]string, *zk.Stat, error)
+		// ChildrenW gets list of item children and sets a watch on them
+		ChildrenW(path string) ([]string, *zk.Stat, <-chan zk.Event, error)
 		// Set sets item data to path
 		Set(path string, data []byte, version int32) (*zk.Stat, error)
 		// Delete deletes item from zookeeper by its path
@@ -72,6 +76,27 @@ func (client *zkClient) Exists(path string) (bool, *zk.Stat, error) {
 	return exists, stat, err
 }
 
+func (client *zkClient) ExistsW(path string) (bool, *zk.Stat, <-chan zk.Event, error) {
+	var (
+		err    error
+		exists bool
+		stat   *zk.Stat
+		events <-chan zk.Event
+	)
+	cbErr := circuit.Do(CBCommandName, client.cbEnabled, func() error {
+		exists, stat, events, err = client.conn.ExistsW(path)
+		if validCBError(err) {
+			return err
+		}
+		return nil
+	}, nil)
+
+	if cbErr != nil {
+		err = cbErr
+	}
+	return exists, stat, events, err
+}
+
 func (client *zkClient) Get(path string) ([]byte, *zk.Stat, error) {
 	var (
 		data []byte
@@ -112,6 +137,27 @@ func (client *zkClient) Children(path string) ([]string, *zk.Stat, error) {
 	return data, stat, err
 }
 
+func (client *zkClient) ChildrenW(path string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	var (
+		data   []string
+		stat   *zk.Stat
+		events <-chan zk.Event
+		err    error
+	)
+	cbErr := circuit.Do(CBCommandName, client.cbEnabled, func() error {
+		data, stat, events, err = client.conn.ChildrenW(path)
+		if validCBError(err) {
+			return err
+		}
+		return nil
+	}, nil)
+
+	if cbErr != nil {
+		err = cbErr
+	}
+	return data, stat, events, err
+}
+
 func (client *zkClient) Set(path string, data []byte, version int32) (*zk.Stat, error) {
 	var (
 		stat *zk.Stat
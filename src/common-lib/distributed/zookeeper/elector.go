@@ -0,0 +1,328 @@
+package zookeeper
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/samuel/go-zookeeper/zk"
+
+	leaderelection "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/leader-election"
+)
+
+const (
+	electionsNode   = "elections"
+	candidatePrefix = "candidate-"
+)
+
+var (
+	// ErrNotRegistered is returned when the candidate is not registered by RegisterCandidate
+	ErrNotRegistered = errors.New("zookeeper: candidate is not registered")
+
+	// electionRetryInterval is the delay before checking the election again after a failed request
+	electionRetryInterval = time.Second
+	// connectionCheckInterval is the interval of checking the connection of the client while waiting for the next change
+	connectionCheckInterval = 100 * time.Millisecond
+)
+
+type electorImpl struct {
+	mutex sync.Mutex
+	path  string
+	name  string
+	// node is the candidate node of this elector, it is empty until the candidate joins the election
+	node   string
+	cancel context.CancelFunc
+
+	// leadership and stepDown are set while Run is running, runDone is closed when it returns
+	leadership *leaderelection.Leadership
+	stepDown   chan chan error
+	runDone    chan struct{}
+}
+
+// NewElector returns a leaderelection.Elector using the client initialized by Init.
+// Candidates are ephemeral sequential nodes under <basePath>/elections/<electionResource> holding the client name,
+// the candidate with the lowest sequence is the leader and every follower watches its predecessor only
+func NewElector() leaderelection.Elector {
+	return &electorImpl{}
+}
+
+// BecomeALeader : Old implementation, see LeaderElector
+func (e *electorImpl) BecomeALeader() (int, bool, error) {
+	return LeaderElector.BecomeALeader()
+}
+
+// RegisterCandidate : Register the Candidate for election
+func (e *electorImpl) RegisterCandidate(electionResource string, clientName string) error {
+	if Client == nil {
+		return ErrZookeeperNotInit
+	}
+
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	e.path = getElectionZkPath(electionResource)
+	e.name = clientName
+	return nil
+}
+
+// StartElection : Start the election and call the callback function if node win the election
+func (e *electorImpl) StartElection(callback func()) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	return e.Run(ctx, leaderelection.Callbacks{
+		OnGained: func(context.Context) {
+			callback()
+			cancel()
+		},
+	})
+}
+
+// Run takes part in the election until ctx is done or the candidate resigns. The leadership is lost when the candidate
+// node is gone with the session or Zookeeper can't be reached, the candidate rejoins the election after that.
+// The watches only fire once the client is connected again, so the leadership is also lost as soon as the connection is,
+// another candidate may be elected once the session expires
+func (e *electorImpl) Run(ctx context.Context, callbacks leaderelection.Callbacks) error {
+	e.mutex.Lock()
+	if e.path == "" {
+		e.mutex.Unlock()
+		return ErrNotRegistered
+	}
+	runCtx, cancel := context.WithCancel(ctx)
+	leadership := leaderelection.NewLeadership(ctx, callbacks)
+	stepDown := make(chan chan error)
+	runDone := make(chan struct{})
+	e.cancel = cancel
+	e.leadership = leadership
+	e.stepDown = stepDown
+	e.runDone = runDone
+	e.mutex.Unlock()
+
+	defer func() {
+		e.ResignCandidate()
+		e.mutex.Lock()
+		e.leadership = nil
+		e.stepDown = nil
+		e.mutex.Unlock()
+		close(runDone)
+	}()
+
+	connection := time.NewTicker(connectionCheckInterval)
+	defer connection.Stop()
+	disconnected := false
+	for {
+		var retry <-chan time.Time
+		events, err := e.watchTurn(leadership)
+		if err != nil {
+			Logger().Warn(defaultTransaction, "Candidate %s couldn't check the election, err: %v", e.name, err)
+			// the leadership can't be confirmed without Zookeeper
+			leadership.Lost()
+			retry = time.After(electionRetryInterval)
+		}
+
+	wait:
+		for {
+			select {
+			case <-runCtx.Done():
+				return nil
+			case <-events:
+				break wait
+			case <-retry:
+				break wait
+			case reply := <-stepDown:
+				// the work of the leader is cancelled before the next candidate takes over
+				leadership.Lost()
+				reply <- e.leave()
+				break wait
+			case <-connection.C:
+				if !isConnected() {
+					if !disconnected {
+						Logger().Warn(defaultTransaction, "Candidate %s lost the connection, state: %s", e.name, Client.State())
+					}
+					disconnected = true
+					leadership.Lost()
+				} else if disconnected {
+					// the election is checked again with the restored session or with a new node if it has expired
+					disconnected = false
+					break wait
+				}
+			}
+		}
+	}
+}
+
+// watchTurn joins the election if needed, updates the leadership and watches the node deciding its next change:
+// the own node of the leader and the predecessor of a follower
+func (e *electorImpl) watchTurn(leadership *leaderelection.Leadership) (<-chan zk.Event, error) {
+	for {
+		node, err := e.join()
+		if err != nil {
+			return nil, err
+		}
+
+		children, _, err := Client.Children(e.path)
+		if err != nil {
+			return nil, err
+		}
+
+		seq, err := parseLockSeq(node)
+		if err != nil {
+			return nil, err
+		}
+
+		if !containsNode(children, node) {
+			// the node is gone together with the session
+			Logger().Warn(defaultTransaction, "Candidate %s lost the node %s", e.name, node)
+			leadership.Lost()
+			e.forget(node)
+			continue
+		}
+
+		watched := node
+		if predecessor := findPredecessor(children, seq); predecessor == "" {
+			leadership.Gained()
+		} else {
+			leadership.Lost()
+			watched = e.path + zkSeparator + predecessor
+		}
+
+		exists, _, events, err := Client.ExistsW(watched)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return events, nil
+		}
+	}
+}
+
+// join creates the candidate node unless it is already created
+func (e *electorImpl) join() (string, error) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.node != "" {
+		return e.node, nil
+	}
+
+	node, err := Client.CreateRecursive(e.path+zkSeparator+candidatePrefix, []byte(e.name), int32(zk.FlagEphemeral|zk.FlagSequence), zk.WorldACL(zk.PermAll))
+	if err != nil {
+		return "", err
+	}
+	e.node = node
+	return node, nil
+}
+
+// leave deletes the candidate node, the follower watching it checks its turn at once
+func (e *electorImpl) leave() error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.node == "" {
+		return nil
+	}
+	if err := Client.Delete(e.node, -1); err != nil && err != zk.ErrNoNode {
+		return err
+	}
+	e.node = ""
+	return nil
+}
+
+func (e *electorImpl) forget(node string) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+	if e.node == node {
+		e.node = ""
+	}
+}
+
+// CurrentLeader returns the client name held by the candidate node with the lowest sequence
+func (e *electorImpl) CurrentLeader() (string, error) {
+	e.mutex.Lock()
+	path := e.path
+	e.mutex.Unlock()
+
+	if path == "" {
+		return "", ErrNotRegistered
+	}
+
+	for {
+		children, _, err := Client.Children(path)
+		if err == zk.ErrNoNode || len(children) == 0 {
+			return "", nil
+		}
+		if err != nil {
+			return "", err
+		}
+
+		sort.Slice(children, func(i, j int) bool {
+			iSeq, _ := parseLockSeq(children[i])
+			jSeq, _ := parseLockSeq(children[j])
+			return iSeq < jSeq
+		})
+		data, _, err := Client.Get(path + zkSeparator + children[0])
+		if err == zk.ErrNoNode {
+			// the leader has just left, check the next one
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	}
+}
+
+// StepDown deletes the node of the leading candidate, the next candidate is notified by its watch and takes over.
+// The candidate rejoins the election with a new node as the last follower
+func (e *electorImpl) StepDown() error {
+	e.mutex.Lock()
+	leadership, stepDown, runDone := e.leadership, e.stepDown, e.runDone
+	e.mutex.Unlock()
+
+	if leadership == nil || !leadership.IsLeader() {
+		return nil
+	}
+
+	reply := make(chan error, 1)
+	select {
+	case stepDown <- reply:
+		return <-reply
+	case <-runDone:
+		return nil
+	}
+}
+
+// ResignCandidate : Resign Candidate from election
+func (e *electorImpl) ResignCandidate() {
+	e.mutex.Lock()
+	leadership, cancel := e.leadership, e.cancel
+	e.cancel = nil
+	e.mutex.Unlock()
+
+	// the work of the leader is cancelled before the next candidate takes over
+	if leadership != nil {
+		leadership.End()
+	}
+	if cancel != nil {
+		cancel()
+	}
+	if err := e.leave(); err != nil {
+		Logger().Debug(defaultTransaction, "Couldn't delete candidate node of %s: %v", e.name, err)
+	}
+}
+
+// containsNode checks if the node path is one of the children names
+func containsNode(children []string, node string) bool {
+	for _, child := range children {
+		if strings.HasSuffix(node, zkSeparator+child) {
+			return true
+		}
+	}
+	return false
+}
+
+func getElectionZkPath(electionResource string) string {
+	return zookeeperBasePath + zkSeparator + electionsNode + zkSeparator + electionResource
+}
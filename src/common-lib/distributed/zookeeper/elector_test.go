@@ -0,0 +1,337 @@
+package zookeeper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/maraino/go-mock"
+	"github.com/samuel/go-zookeeper/zk"
+
+	leaderelection "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/leader-election"
+)
+
+// electionTree simulates the candidate nodes of an election behind the mocked client
+type electionTree struct {
+	mutex   sync.Mutex
+	path    string
+	seq     int
+	nodes   map[string][]byte
+	watches map[string][]chan zk.Event
+	state   string
+}
+
+func mockElection(zkMockObj *ClientMock, path string) *electionTree {
+	tree := &electionTree{path: path, nodes: make(map[string][]byte), watches: make(map[string][]chan zk.Event), state: stateHasSession}
+	flag := int32(zk.FlagEphemeral | zk.FlagSequence)
+
+	zkMockObj.When("State").Call(func() string {
+		tree.mutex.Lock()
+		defer tree.mutex.Unlock()
+		return tree.state
+	})
+
+	zkMockObj.When("CreateRecursive", path+"/"+candidatePrefix, mock.Any, flag, mock.Any).Call(func(prefix string, data []byte, _ int32, _ []zk.ACL) (string, error) {
+		tree.mutex.Lock()
+		defer tree.mutex.Unlock()
+		tree.seq++
+		node := fmt.Sprintf("%s%010d", prefix, tree.seq)
+		tree.nodes[node] = data
+		return node, nil
+	})
+	zkMockObj.When("Children", path).Call(func(string) ([]string, *zk.Stat, error) {
+		tree.mutex.Lock()
+		defer tree.mutex.Unlock()
+		children := make([]string, 0, len(tree.nodes))
+		for node := range tree.nodes {
+			children = append(children, strings.TrimPrefix(node, path+"/"))
+		}
+		return children, &zk.Stat{}, nil
+	})
+	zkMockObj.When("Get", mock.Any).Call(func(node string) ([]byte, *zk.Stat, error) {
+		tree.mutex.Lock()
+		defer tree.mutex.Unlock()
+		data, ok := tree.nodes[node]
+		if !ok {
+			return []byte(nil), (*zk.Stat)(nil), zk.ErrNoNode
+		}
+		return data, &zk.Stat{}, nil
+	})
+	zkMockObj.When("ExistsW", mock.Any).Call(func(node string) (bool, *zk.Stat, <-chan zk.Event, error) {
+		tree.mutex.Lock()
+		defer tree.mutex.Unlock()
+		events := make(chan zk.Event, 1)
+		_, ok := tree.nodes[node]
+		if ok {
+			tree.watches[node] = append(tree.watches[node], events)
+		}
+		return ok, &zk.Stat{}, (<-chan zk.Event)(events), nil
+	})
+	zkMockObj.When("Delete", mock.Any, int32(-1)).Call(func(node string, _ int32) error {
+		tree.remove(node, zk.Event{Type: zk.EventNodeDeleted, Path: node})
+		return nil
+	})
+	return tree
+}
+
+// remove deletes the node and fires its watches
+func (tree *electionTree) remove(node string, event zk.Event) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	delete(tree.nodes, node)
+	for _, events := range tree.watches[node] {
+		events <- event
+	}
+	delete(tree.watches, node)
+}
+
+func (tree *electionTree) setState(state string) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	tree.state = state
+}
+
+func (tree *electionTree) nodeOf(name string) string {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	for node, data := range tree.nodes {
+		if string(data) == name {
+			return node
+		}
+	}
+	return ""
+}
+
+type electorObserver struct {
+	gained chan context.Context
+	lost   chan struct{}
+}
+
+func newElectorObserver() *electorObserver {
+	return &electorObserver{gained: make(chan context.Context, 10), lost: make(chan struct{}, 10)}
+}
+
+func (o *electorObserver) callbacks() leaderelection.Callbacks {
+	return leaderelection.Callbacks{
+		OnGained: func(ctx context.Context) { o.gained <- ctx },
+		OnLost:   func() { o.lost <- struct{}{} },
+	}
+}
+
+func (o *electorObserver) waitGained(t *testing.T) context.Context {
+	select {
+	case ctx := <-o.gained:
+		return ctx
+	case <-time.After(time.Second):
+		t.Fatal("leadership wasn't gained")
+		return nil
+	}
+}
+
+func (o *electorObserver) waitLost(t *testing.T) {
+	select {
+	case <-o.lost:
+	case <-time.After(time.Second):
+		t.Fatal("leadership wasn't lost")
+	}
+}
+
+func setTestBasePath(t *testing.T) {
+	basePath := zookeeperBasePath
+	zookeeperBasePath = "/test"
+	t.Cleanup(func() { zookeeperBasePath = basePath })
+}
+
+func TestElectorNotRegistered(t *testing.T) {
+	_, originalClient := InitMock()
+	defer Restore(originalClient)
+
+	elector := NewElector()
+	if err := elector.Run(context.Background(), leaderelection.Callbacks{}); err != ErrNotRegistered {
+		t.Fatalf("expected ErrNotRegistered, got: %v", err)
+	}
+	if _, err := elector.CurrentLeader(); err != ErrNotRegistered {
+		t.Fatalf("expected ErrNotRegistered, got: %v", err)
+	}
+	if err := elector.StepDown(); err != nil {
+		t.Fatalf("expected no error for a follower, got: %v", err)
+	}
+
+	Restore(nil)
+	if err := elector.RegisterCandidate("resource", "name"); err != ErrZookeeperNotInit {
+		t.Fatalf("expected ErrZookeeperNotInit, got: %v", err)
+	}
+}
+
+func TestElectorStepDown(t *testing.T) {
+	setTestBasePath(t)
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	tree := mockElection(zkMockObj, "/test/elections/resource")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wg := &sync.WaitGroup{}
+	first, second := NewElector(), NewElector()
+	firstObserver, secondObserver := newElectorObserver(), newElectorObserver()
+	if err := first.RegisterCandidate("resource", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if err := second.RegisterCandidate("resource", "second"); err != nil {
+		t.Fatal(err)
+	}
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := first.Run(ctx, firstObserver.callbacks()); err != nil {
+			t.Error(err)
+		}
+	}()
+	firstCtx := firstObserver.waitGained(t)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if err := second.Run(ctx, secondObserver.callbacks()); err != nil {
+			t.Error(err)
+		}
+	}()
+	for tree.nodeOf("second") == "" {
+		time.Sleep(time.Millisecond)
+	}
+
+	if leader, err := second.CurrentLeader(); err != nil || leader != "first" {
+		t.Fatalf("expected first to be the leader, got: %v, %v", leader, err)
+	}
+	// a follower can't step down
+	if err := second.StepDown(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.StepDown(); err != nil {
+		t.Fatal(err)
+	}
+	firstObserver.waitLost(t)
+	if firstCtx.Err() != context.Canceled {
+		t.Fatalf("expected the leadership context to be cancelled, got: %v", firstCtx.Err())
+	}
+	secondObserver.waitGained(t)
+
+	if leader, err := first.CurrentLeader(); err != nil || leader != "second" {
+		t.Fatalf("expected second to be the leader, got: %v, %v", leader, err)
+	}
+
+	cancel()
+	wg.Wait()
+	if tree.nodeOf("first") != "" || tree.nodeOf("second") != "" {
+		t.Fatal("expected the candidates to leave the election")
+	}
+}
+
+func TestElectorSessionExpired(t *testing.T) {
+	setTestBasePath(t)
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	tree := mockElection(zkMockObj, "/test/elections/resource")
+
+	elector := NewElector()
+	if err := elector.RegisterCandidate("resource", "candidate"); err != nil {
+		t.Fatal(err)
+	}
+
+	observer := newElectorObserver()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- elector.Run(ctx, observer.callbacks())
+	}()
+	leaderCtx := observer.waitGained(t)
+
+	node := tree.nodeOf("candidate")
+	tree.remove(node, zk.Event{Type: zk.EventNotWatching, State: zk.StateDisconnected, Err: zk.ErrSessionExpired})
+
+	observer.waitLost(t)
+	if leaderCtx.Err() != context.Canceled {
+		t.Fatalf("expected the leadership context to be cancelled, got: %v", leaderCtx.Err())
+	}
+	// rejoined with a new node
+	observer.waitGained(t)
+	if newNode := tree.nodeOf("candidate"); newNode == "" || newNode == node {
+		t.Fatalf("expected a new candidate node, got: %s", newNode)
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestElectorConnectionLost(t *testing.T) {
+	setTestBasePath(t)
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	tree := mockElection(zkMockObj, "/test/elections/resource")
+
+	interval := connectionCheckInterval
+	connectionCheckInterval = time.Millisecond
+	defer func() { connectionCheckInterval = interval }()
+
+	elector := NewElector()
+	if err := elector.RegisterCandidate("resource", "candidate"); err != nil {
+		t.Fatal(err)
+	}
+
+	observer := newElectorObserver()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- elector.Run(ctx, observer.callbacks())
+	}()
+	leaderCtx := observer.waitGained(t)
+	node := tree.nodeOf("candidate")
+
+	// the own node watch doesn't fire while the client is cut off from the ensemble
+	tree.setState("StateDisconnected")
+	observer.waitLost(t)
+	if leaderCtx.Err() != context.Canceled {
+		t.Fatalf("expected the leadership context to be cancelled, got: %v", leaderCtx.Err())
+	}
+
+	// the session is restored with the node
+	tree.setState(stateHasSession)
+	observer.waitGained(t)
+	if tree.nodeOf("candidate") != node {
+		t.Fatal("expected the candidate to keep its node")
+	}
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStartElection(t *testing.T) {
+	setTestBasePath(t)
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	tree := mockElection(zkMockObj, "/test/elections/resource")
+
+	elector := NewElector()
+	if err := elector.RegisterCandidate("resource", "candidate"); err != nil {
+		t.Fatal(err)
+	}
+
+	called := false
+	if err := elector.StartElection(func() { called = true }); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("expected the callback to be called")
+	}
+	if tree.nodeOf("candidate") != "" {
+		t.Fatal("expected the candidate to resign after the callback")
+	}
+}
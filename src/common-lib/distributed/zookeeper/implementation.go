@@ -2,6 +2,7 @@ package zookeeper
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/samuel/go-zookeeper/zk"
@@ -19,6 +20,7 @@ const (
 	queuePrefix        = "queue-"
 	locksNode          = "locks"
 	queueNode          = "queue"
+	workQueueNode      = "workqueue"
 	leaderElectionNode = "leader-election"
 )
 
@@ -33,8 +35,12 @@ var (
 	LeaderElector leaderelection.Interface = leaderElectorImpl{}
 	// Queue implementation
 	Queue queue.Interface = queueImpl{}
+	// WorkQueueStore implementation, to be used with queue.NewWorkQueue
+	WorkQueueStore queue.Store = workQueueStoreImpl{}
 	// Scheduler implementation
 	Scheduler scheduler.Interface = schedulerImpl{}
+	// SchedulerHistory implementation
+	SchedulerHistory scheduler.History = schedulerImpl{}
 	// Client implementation
 	Client ZKClient
 	// Connect implement connection to Zookeeper
@@ -48,13 +54,23 @@ var (
 )
 
 type (
-	leaderElectorImpl struct{}
-	queueImpl         struct{}
-	schedulerImpl     struct{}
-	lockWrapper       struct {
+	leaderElectorImpl  struct{}
+	queueImpl          struct{}
+	workQueueStoreImpl struct{}
+	schedulerImpl      struct{}
+	lockWrapper        struct {
 		zkLock    lock.Locker
 		cbEnabled bool
 		name      string
+		// path is the parent node of the lock
+		path string
+
+		// mx guards the ownership state: node is the lock node owned after LockContext or TryLock succeeded,
+		// zkLocked is set while Lock holds the lock and acquiring while one of them is in progress
+		mx        sync.Mutex
+		node      string
+		zkLocked  bool
+		acquiring bool
 	}
 )
 
@@ -85,10 +101,10 @@ func initImpl(zookeeperHosts []string, basePath string) error {
 }
 
 // NewLock is a wrapper for creating new lock
-func NewLock(name string) lock.Locker {
+func NewLock(name string) lock.ContextLocker {
 	path := getLockPath(name)
 	acl := zk.WorldACL(zk.PermAll)
-	return &lockWrapper{zkLock: Client.NewLock(path, acl), cbEnabled: Client.isCBEnabled(), name: name}
+	return &lockWrapper{zkLock: Client.NewLock(path, acl), cbEnabled: Client.isCBEnabled(), name: name, path: path}
 }
 
 // DeleteLock deletes lock parent node
@@ -130,11 +146,16 @@ func (cs *ConnectionStatus) Status(conn rest.OutboundConnectionStatus) *rest.Out
 	conn.ConnectionURLs = cs.Hosts
 	conn.ConnectionStatus = rest.ConnectionStatusUnavailable
 
-	state := Client.State()
-	// Statuses are not exported from "github.com/samuel/go-zookeeper/zk" for direct comparison
-	if state == stateConnected || state == stateHasSession {
+	if isConnected() {
 		conn.ConnectionStatus = rest.ConnectionStatusActive
 	}
 
 	return &conn
 }
+
+// isConnected checks the state of the client connection
+func isConnected() bool {
+	state := Client.State()
+	// Statuses are not exported from "github.com/samuel/go-zookeeper/zk" for direct comparison
+	return state == stateConnected || state == stateHasSession
+}
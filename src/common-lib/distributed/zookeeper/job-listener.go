@@ -36,8 +36,8 @@ func setDistributedJob(ctx context.Context, wg *sync.WaitGroup, job scheduler.Di
 				return
 
 			case <-time.After(interval):
-				//get lock
-				if err := locker.Lock(); err != nil {
+				//get lock, waiting for it no longer than the listener is running
+				if _, err := locker.LockContext(ctx); err != nil {
 					switch getLockErrAction("lock", job, err) {
 					case lock.TryLock:
 						continue
@@ -105,5 +105,7 @@ func processQueue(ctx context.Context, items []string, job scheduler.Distributed
 		}
 	}
 
-	job.Callback(ctx, itemsData)
+	if err := scheduler.RunCallback(ctx, schedulerImpl{}, job, itemsData); err != nil {
+		Logger().Error(defaultTransaction, "Scheduler.RecordRunFailed", "Distributed Job [%s]. %v", job.GetName(), err)
+	}
 }
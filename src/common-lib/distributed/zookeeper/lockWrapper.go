@@ -1,6 +1,10 @@
 package zookeeper
 
 import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/samuel/go-zookeeper/zk"
@@ -16,9 +20,24 @@ var zkLockErrorToAction = map[error]lock.ErrorAction{
 	zk.ErrNoNode:         lock.CreateNewLock,
 }
 
+const lockNodePrefix = "lock-"
+
+// errLockHeld - the lock is held by another owner and the caller doesn't want to wait
+var errLockHeld = errors.New("lock is held by another owner")
+
 // Lock - Wrapper method to encapsulate error cases for zookeeper lock
-func (lw lockWrapper) Lock() error {
+func (lw *lockWrapper) Lock() error {
+	if err := lw.reserve(); err != nil {
+		return err
+	}
+
 	var err error
+	defer func() {
+		lw.mx.Lock()
+		lw.acquiring = false
+		lw.zkLocked = err == nil
+		lw.mx.Unlock()
+	}()
 	cbErr := circuit.Do(CBCommandName, lw.cbEnabled, func() error {
 		err = lw.zkLock.Lock()
 		if validCBError(err) {
@@ -50,7 +69,14 @@ func (lw lockWrapper) Lock() error {
 var DeleteLockWG sync.WaitGroup
 
 // Unlock - Wrapper method to encapsulate error cases for zookeeper unlock
-func (lw lockWrapper) Unlock() error {
+func (lw *lockWrapper) Unlock() error {
+	lw.mx.Lock()
+	node := lw.node
+	lw.mx.Unlock()
+	if node != "" {
+		return lw.unlockNode(node)
+	}
+
 	var err error
 	cbErr := circuit.Do(CBCommandName, lw.cbEnabled, func() error {
 		err = lw.zkLock.Unlock()
@@ -60,18 +86,7 @@ func (lw lockWrapper) Unlock() error {
 
 		// If there is not a valid CB error we still want to try to delete the lock as either the lock was released but with an error or was not released and then try the delete
 		// will fail anyway because there will be children to the LockerWraper parent node which is the lock itself
-		if lw.name != "" {
-			// Ignore any errors happen from deleting the parent node to the lock as we don't want to override the actual unlock error
-			// checks can be made and if the parent node still exists by the client and DeleteLock can be called again
-			DeleteLockWG.Add(1)
-			go func() {
-				defer DeleteLockWG.Done()
-				delLockErr := DeleteLock(lw.name)
-				if delLockErr != nil {
-					Logger().Debug(defaultTransaction, "Couldn't delete lock parent node: %s", delLockErr)
-				}
-			}()
-		}
+		lw.deleteParent()
 		return nil
 	}, nil)
 
@@ -82,6 +97,9 @@ func (lw lockWrapper) Unlock() error {
 	le := lock.Error{Code: err}
 	switch err {
 	case nil:
+		lw.mx.Lock()
+		lw.zkLocked = false
+		lw.mx.Unlock()
 		return nil
 	default:
 		//we don't know what hit us
@@ -90,3 +108,181 @@ func (lw lockWrapper) Unlock() error {
 
 	return le
 }
+
+func (lw *lockWrapper) deleteParent() {
+	if lw.name == "" {
+		return
+	}
+	// Ignore any errors happen from deleting the parent node to the lock as we don't want to override the actual unlock error
+	// checks can be made and if the parent node still exists by the client and DeleteLock can be called again
+	DeleteLockWG.Add(1)
+	go func() {
+		defer DeleteLockWG.Done()
+		delLockErr := DeleteLock(lw.name)
+		if delLockErr != nil {
+			Logger().Debug(defaultTransaction, "Couldn't delete lock parent node: %s", delLockErr)
+		}
+	}()
+}
+
+// LockContext - acquires the lock waiting for the preceding owners until ctx is done.
+// The returned fencing token is the zxid of the lock node creation, which only grows across the ensemble
+func (lw *lockWrapper) LockContext(ctx context.Context) (int64, error) {
+	return lw.acquire(ctx, true)
+}
+
+// TryLock - acquires the lock only if nobody holds it or waits for it
+func (lw *lockWrapper) TryLock() (int64, bool, error) {
+	token, err := lw.acquire(context.Background(), false)
+	if err == errLockHeld {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+	return token, true, nil
+}
+
+// acquire follows the zookeeper lock recipe with the same node naming as zk.Lock,
+// so owners using Lock and LockContext on the same name exclude each other
+func (lw *lockWrapper) acquire(ctx context.Context, wait bool) (int64, error) {
+	if err := lw.reserve(); err != nil {
+		return 0, err
+	}
+
+	var node string
+	defer func() {
+		lw.mx.Lock()
+		lw.acquiring = false
+		lw.node = node
+		lw.mx.Unlock()
+	}()
+
+	created, err := Client.CreateRecursive(lw.path+zkSeparator+lockNodePrefix, []byte{}, int32(zk.FlagEphemeral|zk.FlagSequence), zk.WorldACL(zk.PermAll))
+	if err != nil {
+		return 0, toLockError(err)
+	}
+
+	token, err := lw.waitForTurn(ctx, created, wait)
+	if err != nil {
+		if delErr := Client.Delete(created, -1); delErr != nil && delErr != zk.ErrNoNode {
+			Logger().Debug(defaultTransaction, "Couldn't delete lock node %s: %s", created, delErr)
+		}
+		if err == errLockHeld {
+			return 0, err
+		}
+		return 0, toLockError(err)
+	}
+
+	node = created
+	return token, nil
+}
+
+// reserve marks the lock as being acquired, failing with zk.ErrDeadlock when the wrapper already holds
+// or acquires it: waiting behind its own node would never return
+func (lw *lockWrapper) reserve() error {
+	lw.mx.Lock()
+	defer lw.mx.Unlock()
+	if lw.node != "" || lw.zkLocked || lw.acquiring {
+		return lock.Error{Code: zk.ErrDeadlock, Action: zkLockErrorToAction[zk.ErrDeadlock]}
+	}
+	lw.acquiring = true
+	return nil
+}
+
+func (lw *lockWrapper) waitForTurn(ctx context.Context, node string, wait bool) (int64, error) {
+	exists, stat, err := Client.Exists(node)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, zk.ErrNoNode
+	}
+
+	seq, err := parseLockSeq(node)
+	if err != nil {
+		return 0, err
+	}
+
+	for {
+		children, _, err := Client.Children(lw.path)
+		if err != nil {
+			return 0, err
+		}
+
+		predecessor := findPredecessor(children, seq)
+		if predecessor == "" {
+			return stat.Czxid, nil
+		}
+		if !wait {
+			return 0, errLockHeld
+		}
+
+		exists, _, events, err := Client.ExistsW(lw.path + zkSeparator + predecessor)
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		case <-events:
+		}
+	}
+}
+
+func (lw *lockWrapper) unlockNode(node string) error {
+	err := Client.Delete(node, -1)
+	if err != nil && err != zk.ErrNoNode {
+		le := toLockError(err)
+		if le.Action == lock.TryLock {
+			le.Action = lock.TryUnlock
+		}
+		return le
+	}
+
+	lw.mx.Lock()
+	lw.node = ""
+	lw.mx.Unlock()
+	lw.deleteParent()
+	if err != nil {
+		// the node is gone together with the session, the lock was lost before unlocking
+		return lock.Error{Code: err, Action: zkLockErrorToAction[err]}
+	}
+	return nil
+}
+
+// toLockError maps an error of the lock recipe to the lock.Error with the expected action
+func toLockError(err error) lock.Error {
+	le := lock.Error{Code: err, Action: zkLockErrorToAction[err]}
+	//if we don't have an action mapped for the error, default to TryLock action
+	if le.Action == 0 {
+		le.Action = lock.TryLock
+	}
+	return le
+}
+
+// findPredecessor returns the lock node with the greatest sequence lower than seq
+func findPredecessor(children []string, seq int) string {
+	predecessor := ""
+	predecessorSeq := -1
+	for _, child := range children {
+		childSeq, err := parseLockSeq(child)
+		if err != nil {
+			continue
+		}
+		if childSeq < seq && childSeq > predecessorSeq {
+			predecessor = child
+			predecessorSeq = childSeq
+		}
+	}
+	return predecessor
+}
+
+func parseLockSeq(path string) (int, error) {
+	parts := strings.Split(path, "-")
+	return strconv.Atoi(parts[len(parts)-1])
+}
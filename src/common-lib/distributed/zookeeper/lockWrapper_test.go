@@ -1,9 +1,14 @@
 package zookeeper
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	"github.com/maraino/go-mock"
 	"github.com/samuel/go-zookeeper/zk"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/lock"
 )
 
 func TestLock(t *testing.T) {
@@ -131,3 +136,188 @@ func TestUnlockWithLWName(t *testing.T) {
 		})
 	}
 }
+
+func TestLockContext(t *testing.T) {
+	const (
+		path = "/test/locks/lw-name"
+		node = path + "/lock-0000000002"
+	)
+	flag := int32(zk.FlagEphemeral | zk.FlagSequence)
+
+	t.Run("acquired", func(t *testing.T) {
+		zkMockObj, originalClient := InitMock()
+		defer Restore(originalClient)
+		zkMockObj.When("CreateRecursive", path+"/lock-", mock.Any, flag, mock.Any).Return(node, nil)
+		zkMockObj.When("Exists", node).Return(true, &zk.Stat{Czxid: 42}, nil)
+		zkMockObj.When("Children", path).Return([]string{"lock-0000000002"}, &zk.Stat{}, nil)
+		zkMockObj.When("Delete", node, int32(-1)).Return(nil).Times(1)
+
+		lw := &lockWrapper{path: path}
+		token, err := lw.LockContext(context.Background())
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if token != 42 {
+			t.Fatalf("expected token: 42, got: %d", token)
+		}
+
+		if _, err = lw.LockContext(context.Background()); err.(lock.Error).Action != lock.TryUnlock {
+			t.Fatalf("expected TryUnlock action for locking twice, got: %v", err)
+		}
+
+		if err = lw.Unlock(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if ok, err := zkMockObj.Verify(); !ok {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("cancelled", func(t *testing.T) {
+		zkMockObj, originalClient := InitMock()
+		defer Restore(originalClient)
+		zkMockObj.When("CreateRecursive", path+"/lock-", mock.Any, flag, mock.Any).Return(node, nil)
+		zkMockObj.When("Exists", node).Return(true, &zk.Stat{Czxid: 42}, nil)
+		zkMockObj.When("Children", path).Return([]string{"lock-0000000001", "lock-0000000002"}, &zk.Stat{}, nil)
+		zkMockObj.When("ExistsW", path+"/lock-0000000001").Return(true, &zk.Stat{}, (<-chan zk.Event)(make(chan zk.Event)), nil)
+		zkMockObj.When("Delete", node, int32(-1)).Return(nil).Times(1)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		lw := &lockWrapper{path: path}
+		_, err := lw.LockContext(ctx)
+		le, ok := err.(lock.Error)
+		if !ok || le.Code != context.DeadlineExceeded || le.Action != lock.TryLock {
+			t.Fatalf("expected deadline exceeded with TryLock action, got: %v", err)
+		}
+		if ok, err := zkMockObj.Verify(); !ok {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("session_expired", func(t *testing.T) {
+		zkMockObj, originalClient := InitMock()
+		defer Restore(originalClient)
+		zkMockObj.When("CreateRecursive", path+"/lock-", mock.Any, flag, mock.Any).Return("", zk.ErrSessionExpired)
+
+		lw := &lockWrapper{path: path}
+		_, err := lw.LockContext(context.Background())
+		if err.(lock.Error).Action != lock.CreateNewLock {
+			t.Fatalf("expected CreateNewLock action, got: %v", err)
+		}
+	})
+}
+
+func TestTryLock(t *testing.T) {
+	const (
+		path = "/test/locks/lw-name"
+		node = path + "/lock-0000000002"
+	)
+	flag := int32(zk.FlagEphemeral | zk.FlagSequence)
+
+	tests := []struct {
+		name     string
+		children []string
+		acquired bool
+	}{
+		{
+			name:     "free",
+			children: []string{"lock-0000000002"},
+			acquired: true,
+		},
+		{
+			name:     "held",
+			children: []string{"_c_guid-lock-0000000001", "lock-0000000002"},
+			acquired: false,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			zkMockObj, originalClient := InitMock()
+			defer Restore(originalClient)
+			zkMockObj.When("CreateRecursive", path+"/lock-", mock.Any, flag, mock.Any).Return(node, nil)
+			zkMockObj.When("Exists", node).Return(true, &zk.Stat{Czxid: 7}, nil)
+			zkMockObj.When("Children", path).Return(test.children, &zk.Stat{}, nil)
+			zkMockObj.When("Delete", node, int32(-1)).Return(nil).Times(1)
+
+			lw := &lockWrapper{path: path}
+			token, acquired, err := lw.TryLock()
+			if err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+			if acquired != test.acquired {
+				t.Fatalf("expected acquired: %t, got: %t", test.acquired, acquired)
+			}
+			if acquired {
+				if token != 7 {
+					t.Fatalf("expected token: 7, got: %d", token)
+				}
+				if err = lw.Unlock(); err != nil {
+					t.Fatalf("expected no error, got: %v", err)
+				}
+			}
+			if ok, err := zkMockObj.Verify(); !ok {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestLockDeadlock(t *testing.T) {
+	const (
+		path = "/test/locks/lw-name"
+		node = path + "/lock-0000000002"
+	)
+	flag := int32(zk.FlagEphemeral | zk.FlagSequence)
+
+	t.Run("LockContext_after_Lock", func(t *testing.T) {
+		zkMockObj, originalClient := InitMock()
+		defer Restore(originalClient)
+		zkLock := &LockMock{}
+		zkLock.When("Lock").Return(nil)
+		zkLock.When("Unlock").Return(nil)
+
+		lw := &lockWrapper{zkLock: zkLock, path: path}
+		if err := lw.Lock(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		_, err := lw.LockContext(context.Background())
+		if le, ok := err.(lock.Error); !ok || le.Code != zk.ErrDeadlock || le.Action != lock.TryUnlock {
+			t.Fatalf("expected ErrDeadlock with TryUnlock action, got: %v", err)
+		}
+		if _, acquired, err := lw.TryLock(); acquired || err == nil {
+			t.Fatalf("expected ErrDeadlock, got: %t, %v", acquired, err)
+		}
+		if err = lw.Unlock(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if ok, err := zkMockObj.Verify(); !ok {
+			t.Fatal(err)
+		}
+	})
+
+	t.Run("Lock_after_LockContext", func(t *testing.T) {
+		zkMockObj, originalClient := InitMock()
+		defer Restore(originalClient)
+		zkMockObj.When("CreateRecursive", path+"/lock-", mock.Any, flag, mock.Any).Return(node, nil)
+		zkMockObj.When("Exists", node).Return(true, &zk.Stat{Czxid: 42}, nil)
+		zkMockObj.When("Children", path).Return([]string{"lock-0000000002"}, &zk.Stat{}, nil)
+		zkMockObj.When("Delete", node, int32(-1)).Return(nil).Times(1)
+
+		lw := &lockWrapper{zkLock: &LockMock{}, path: path}
+		if _, err := lw.LockContext(context.Background()); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		err := lw.Lock()
+		if le, ok := err.(lock.Error); !ok || le.Code != zk.ErrDeadlock {
+			t.Fatalf("expected ErrDeadlock, got: %v", err)
+		}
+		if err = lw.Unlock(); err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if ok, err := zkMockObj.Verify(); !ok {
+			t.Fatal(err)
+		}
+	})
+}
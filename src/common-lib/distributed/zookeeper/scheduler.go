@@ -5,23 +5,23 @@ import (
 	"sync"
 	"time"
 
-	"github.com/robfig/cron"
-
 	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
 )
 
 var (
-	schedulerCron *cron.Cron
+	schedulerCron *scheduler.Cron
 	schedulerInit = false
 	scheduledJobs []scheduler.ScheduledJob
 )
 
 // Job is a struct defining the actual scheduled job
-// Implementing `Job` interface from cron package and `ScheduledJob` from scheduler package
+// Implementing `ScheduledJob` from scheduler package, Run is called by the scheduler.Cron
 type Job struct {
 	Name     string
 	Task     string
 	Schedule string
+	// MisfirePolicy - what to do with the runs missed while no scheduler was running
+	MisfirePolicy scheduler.MisfirePolicy
 }
 
 // GetName returns job name
@@ -39,15 +39,24 @@ func (j Job) GetSchedule() string {
 	return j.Schedule
 }
 
-// Run initial entry point of a Job
+// GetMisfirePolicy returns job misfire policy
+func (j Job) GetMisfirePolicy() scheduler.MisfirePolicy {
+	return j.MisfirePolicy
+}
+
+// Run initial entry point of a Job, sends the job for execution to the job listeners
 func (j Job) Run() {
 	Logger().Info(defaultTransaction, "Scheduling job `%s` for execution", j.GetName())
-	_, err := Queue.CreateItem(nil, j.Task)
-	if err != nil {
-		Logger().Error(defaultTransaction, "Queue.CreateItemFailed", "Scheduler. Couldn't run a distributed job %v, err: %v", j.GetName(), err)
+	if err := dispatcher().Dispatch(j.GetName(), j.Task, time.Now(), false); err != nil {
+		Logger().Error(defaultTransaction, "Scheduler.DispatchFailed", "Scheduler. %v", err)
 	}
 }
 
+// dispatcher sends the runs through the Queue and keeps them in the history of the scheduler
+func dispatcher() scheduler.Dispatcher {
+	return scheduler.Dispatcher{Queue: Queue, Runs: schedulerImpl{}, Node: SchedulerNodeName}
+}
+
 // DistributedScheduler initializes distributed scheduler
 func (schedulerImpl) DistributedScheduler(ctx context.Context, wg *sync.WaitGroup, jobs []scheduler.ScheduledJob, interval time.Duration) error {
 	scheduledJobs = jobs
@@ -80,24 +89,29 @@ func (schedulerImpl) DistributedScheduler(ctx context.Context, wg *sync.WaitGrou
 func startScheduler() {
 	Logger().Info(defaultTransaction, "I'm a new leader. Initializing scheduler...")
 	schedulerInit = true
-	schedulerCron = cron.New()
+	schedulerCron = scheduler.NewCron()
 
 	for _, sj := range scheduledJobs {
 		job := Job{
-			Name:     sj.GetName(),
-			Task:     sj.GetTask(),
-			Schedule: sj.GetSchedule(),
+			Name:          sj.GetName(),
+			Task:          sj.GetTask(),
+			Schedule:      sj.GetSchedule(),
+			MisfirePolicy: scheduler.GetMisfirePolicy(sj),
 		}
 		err := createJobQueue(job.Task)
 		if err != nil {
-			Logger().Error(defaultTransaction, "Queue.VerifyQueue", "Couldn't create job queue for job %v in zookeeper, err: ", job.GetName(), err)
+			Logger().Error(defaultTransaction, "Queue.VerifyQueue", "Couldn't create job queue for job %v in zookeeper, err: %v", job.GetName(), err)
 			continue
 		}
-		err = schedulerCron.AddJob(job.GetSchedule(), job)
+		schedule, err := scheduler.Parse(job.GetSchedule())
 		if err != nil {
-			Logger().Error(defaultTransaction, "schedulerCron.AddJobFailed", "Couldn't add job %v with schedule %v, err: ", job.GetName(), job.GetSchedule(), err)
+			Logger().Error(defaultTransaction, "scheduler.ParseFailed", "Couldn't add job %v with schedule %v, err: %v", job.GetName(), job.GetSchedule(), err)
 			continue
 		}
+		schedulerCron.Add(job.GetName(), schedule, job.Run)
+		if err = dispatcher().CatchUp(job.GetName(), job.Task, schedule, job.MisfirePolicy); err != nil {
+			Logger().Error(defaultTransaction, "Scheduler.CatchUpFailed", "Scheduler. %v", err)
+		}
 	}
 	schedulerCron.Start()
 }
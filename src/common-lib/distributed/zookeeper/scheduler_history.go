@@ -0,0 +1,156 @@
+package zookeeper
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+
+	"github.com/samuel/go-zookeeper/zk"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+)
+
+const (
+	schedulerNode = "scheduler"
+	runPrefix     = "run-"
+)
+
+var (
+	// SchedulerNodeName is the node name recorded in the runs made by this scheduler
+	// Defaults to the host name
+	SchedulerNodeName = hostName()
+	// SchedulerHistorySize is the number of runs kept in the history of each job
+	SchedulerHistorySize = 100
+)
+
+// LastRuns returns up to n latest runs of the job, the latest run first
+func (schedulerImpl) LastRuns(jobName string, n int) ([]scheduler.Run, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+
+	path := getJobZkPath(jobName)
+	children, _, err := Client.Children(path)
+	if err == zk.ErrNoNode {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// run nodes are sequential, so the names are ordered by creation
+	sort.Sort(sort.Reverse(sort.StringSlice(children)))
+	if len(children) > n {
+		children = children[:n]
+	}
+
+	runs := make([]scheduler.Run, 0, len(children))
+	for _, child := range children {
+		data, _, err := Client.Get(path + zkSeparator + child)
+		if err == zk.ErrNoNode {
+			// removed by the history trimming in the meantime
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var run scheduler.Run
+		if err = json.Unmarshal(data, &run); err != nil {
+			return nil, err
+		}
+		run.ID = child
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// LastRun returns the last dispatched run of the job kept as data of the job node, the run is empty when the job has never run
+func (schedulerImpl) LastRun(jobName string) (scheduler.Run, error) {
+	var run scheduler.Run
+	data, _, err := Client.Get(getJobZkPath(jobName))
+	if err == zk.ErrNoNode {
+		return run, nil
+	}
+	if err != nil || len(data) == 0 {
+		return run, err
+	}
+	return run, json.Unmarshal(data, &run)
+}
+
+// AddRun keeps the run as the last dispatched run of the job node and adds it to the job history,
+// the ID of the run in the history is returned once it was added
+func (schedulerImpl) AddRun(run scheduler.Run) (string, error) {
+	data, err := json.Marshal(run)
+	if err != nil {
+		return "", err
+	}
+
+	path := getJobZkPath(run.Job)
+	acl := zk.WorldACL(zk.PermAll)
+	if _, err = Client.Set(path, data, -1); err == zk.ErrNoNode {
+		_, err = Client.CreateRecursive(path, data, 0, acl)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	node, err := Client.Create(path+zkSeparator+runPrefix, data, int32(zk.FlagSequence), acl)
+	if err != nil {
+		return "", err
+	}
+	return node[len(path)+len(zkSeparator):], trimHistory(path)
+}
+
+// UpdateRun applies the update to the run of the job history, a run already removed by the history trimming is ignored
+func (schedulerImpl) UpdateRun(jobName, id string, update func(*scheduler.Run)) error {
+	path := getJobZkPath(jobName) + zkSeparator + id
+	data, stat, err := Client.Get(path)
+	if err == zk.ErrNoNode {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var run scheduler.Run
+	if err = json.Unmarshal(data, &run); err != nil {
+		return err
+	}
+	update(&run)
+	if data, err = json.Marshal(run); err != nil {
+		return err
+	}
+	if _, err = Client.Set(path, data, stat.Version); err == zk.ErrNoNode {
+		return nil
+	}
+	return err
+}
+
+// trimHistory removes the oldest runs exceeding SchedulerHistorySize
+func trimHistory(path string) error {
+	children, _, err := Client.Children(path)
+	if err != nil || len(children) <= SchedulerHistorySize {
+		return err
+	}
+
+	sort.Strings(children)
+	for _, child := range children[:len(children)-SchedulerHistorySize] {
+		if err = Client.Delete(path+zkSeparator+child, -1); err != nil && err != zk.ErrNoNode {
+			return err
+		}
+	}
+	return nil
+}
+
+func getJobZkPath(jobName string) string {
+	return zookeeperBasePath + zkSeparator + schedulerNode + zkSeparator + jobName
+}
+
+func hostName() string {
+	name, err := os.Hostname()
+	if err != nil {
+		return ""
+	}
+	return name
+}
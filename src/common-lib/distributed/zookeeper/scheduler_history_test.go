@@ -0,0 +1,209 @@
+package zookeeper
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/maraino/go-mock"
+	"github.com/samuel/go-zookeeper/zk"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/scheduler"
+)
+
+func TestLastRuns(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	path := getJobZkPath("job")
+
+	t.Run("no_history", func(t *testing.T) {
+		zkMockObj.When("Children", path).Return([]string(nil), (*zk.Stat)(nil), zk.ErrNoNode).Times(1)
+		runs, err := SchedulerHistory.LastRuns("job", 10)
+		if err != nil || len(runs) != 0 {
+			t.Fatalf("expected no runs and no error, got: %v, %v", runs, err)
+		}
+	})
+
+	t.Run("latest_first", func(t *testing.T) {
+		zkMockObj.When("Children", path).Return([]string{"run-0000000001", "run-0000000003", "run-0000000002"}, &zk.Stat{}, nil).Times(1)
+		for i := 2; i <= 3; i++ {
+			data, _ := json.Marshal(scheduler.Run{Job: "job", Node: fmt.Sprintf("node-%d", i)})
+			zkMockObj.When("Get", fmt.Sprintf("%s/run-000000000%d", path, i)).Return(data, &zk.Stat{}, nil).Times(1)
+		}
+
+		runs, err := SchedulerHistory.LastRuns("job", 2)
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		if len(runs) != 2 || runs[0].Node != "node-3" || runs[1].Node != "node-2" || runs[0].ID != "run-0000000003" {
+			t.Fatalf("expected runs of node-3 and node-2, got: %+v", runs)
+		}
+	})
+
+	t.Run("get_error", func(t *testing.T) {
+		zkMockObj.When("Children", path).Return([]string{"run-0000000001"}, &zk.Stat{}, nil).Times(1)
+		zkMockObj.When("Get", path+"/run-0000000001").Return([]byte(nil), (*zk.Stat)(nil), zk.ErrConnectionClosed).Times(1)
+
+		if _, err := SchedulerHistory.LastRuns("job", 2); err != zk.ErrConnectionClosed {
+			t.Fatalf("expected error: %v, got: %v", zk.ErrConnectionClosed, err)
+		}
+	})
+}
+
+func TestRecordRun(t *testing.T) {
+	defer func(size int) { SchedulerHistorySize = size }(SchedulerHistorySize)
+	SchedulerHistorySize = 2
+
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	path := getJobZkPath("job")
+
+	zkMockObj.When("Set", path, mock.Any, int32(-1)).Return((*zk.Stat)(nil), zk.ErrNoNode).Times(1)
+	zkMockObj.When("CreateRecursive", path, mock.Any, int32(0), mock.Any).Return(path, nil).Times(1)
+	zkMockObj.When("Create", path+"/run-", mock.Any, int32(zk.FlagSequence), mock.Any).Return(path+"/run-0000000003", nil).Times(1)
+	zkMockObj.When("Children", path).Return([]string{"run-0000000003", "run-0000000001", "run-0000000002"}, &zk.Stat{}, nil).Times(1)
+	zkMockObj.When("Delete", path+"/run-0000000001", int32(-1)).Return(nil).Times(1)
+
+	id, err := schedulerImpl{}.AddRun(scheduler.Run{Job: "job", Outcome: scheduler.OutcomePending})
+	if err != nil || id != "run-0000000003" {
+		t.Fatalf("expected run-0000000003 and no error, got: %v, %v", id, err)
+	}
+	if ok, err := zkMockObj.Verify(); !ok {
+		t.Fatal(err)
+	}
+}
+
+func TestCatchUp(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockQueue := NewMockInterface(ctrl)
+	defer func(queue queue.Interface) { Queue = queue }(Queue)
+	Queue = mockQueue
+
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	path := getJobZkPath("job")
+
+	schedule, err := scheduler.Parse("@every 1m")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	last, _ := json.Marshal(scheduler.Run{Job: "job", ScheduledAt: time.Now().Add(-3*time.Minute - 30*time.Second)})
+
+	tests := []struct {
+		policy   scheduler.MisfirePolicy
+		expected int
+	}{
+		{policy: scheduler.MisfireSkip, expected: 0},
+		{policy: scheduler.MisfireRunOnce, expected: 1},
+		{policy: scheduler.MisfireRunAll, expected: 3},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("policy_%d", test.policy), func(t *testing.T) {
+			zkMockObj.Reset()
+			zkMockObj.When("Get", path).Return(last, &zk.Stat{}, nil)
+			zkMockObj.When("Set", path, mock.Any, int32(-1)).Return(&zk.Stat{}, nil)
+			zkMockObj.When("Create", path+"/run-", mock.Any, int32(zk.FlagSequence), mock.Any).Return(path+"/run-0000000001", nil)
+			zkMockObj.When("Children", path).Return([]string{"run-0000000001"}, &zk.Stat{}, nil)
+			if test.expected > 0 {
+				data := scheduler.RunRef{Job: "job", Run: "run-0000000001"}.Marshal()
+				mockQueue.EXPECT().CreateItem(data, "task").Return("", nil).Times(test.expected)
+			}
+
+			if err := dispatcher().CatchUp("job", "task", schedule, test.policy); err != nil {
+				t.Fatalf("expected no error, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestDispatchFailure(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockQueue := NewMockInterface(ctrl)
+	defer func(queue queue.Interface) { Queue = queue }(Queue)
+	Queue = mockQueue
+
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	path := getJobZkPath("job")
+
+	var recorded scheduler.Run
+	mockQueue.EXPECT().CreateItem(gomock.Any(), "task").Return("", errors.New("injected"))
+	zkMockObj.When("Set", path, mock.Any, int32(-1)).Call(func(_ string, data []byte, _ int32) (*zk.Stat, error) {
+		return &zk.Stat{}, json.Unmarshal(data, &recorded)
+	})
+	zkMockObj.When("Create", path+"/run-", mock.Any, int32(zk.FlagSequence), mock.Any).Return(path+"/run-0000000001", nil)
+	zkMockObj.When("Children", path).Return([]string{"run-0000000001"}, &zk.Stat{}, nil)
+	zkMockObj.When("Get", path+"/run-0000000001").Call(func(string) ([]byte, *zk.Stat, error) {
+		data, err := json.Marshal(recorded)
+		return data, &zk.Stat{Version: 1}, err
+	})
+	zkMockObj.When("Set", path+"/run-0000000001", mock.Any, int32(1)).Call(func(_ string, data []byte, _ int32) (*zk.Stat, error) {
+		return &zk.Stat{}, json.Unmarshal(data, &recorded)
+	}).Times(1)
+
+	Job{Name: "job", Task: "task"}.Run()
+
+	if recorded.Outcome != scheduler.OutcomeFailed || recorded.Error != "injected" || recorded.Node != SchedulerNodeName {
+		t.Fatalf("expected failed run recorded, got: %+v", recorded)
+	}
+	if ok, err := zkMockObj.Verify(); !ok {
+		t.Fatal(err)
+	}
+}
+
+func TestProcessQueueCompletesRuns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockQueue := NewMockInterface(ctrl)
+	defer func(queue queue.Interface) { Queue = queue }(Queue)
+	Queue = mockQueue
+
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	runPath := getJobZkPath("job") + "/run-0000000001"
+
+	pending, _ := json.Marshal(scheduler.Run{Job: "job", DispatchedAt: time.Now(), Outcome: scheduler.OutcomePending})
+	mockQueue.EXPECT().GetItemData("task", "item-1").Return(scheduler.RunRef{Job: "job", Run: "run-0000000001"}.Marshal(), nil)
+	mockQueue.EXPECT().GetItemData("task", "item-2").Return([]byte("data"), nil)
+	mockQueue.EXPECT().RemoveItem("task", gomock.Any()).Return(nil).Times(2)
+
+	var recorded scheduler.Run
+	zkMockObj.When("Get", runPath).Return(pending, &zk.Stat{Version: 3}, nil).Times(1)
+	zkMockObj.When("Set", runPath, mock.Any, int32(3)).Call(func(_ string, data []byte, _ int32) (*zk.Stat, error) {
+		return &zk.Stat{}, json.Unmarshal(data, &recorded)
+	}).Times(1)
+
+	job := &callbackJob{name: "task", delay: 10 * time.Millisecond}
+	processQueue(context.Background(), []string{"item-1", "item-2"}, job)
+
+	if len(job.data) != 1 || string(job.data[0]) != "data" {
+		t.Fatalf("expected the callback to get the data of the other items only, got: %q", job.data)
+	}
+	if recorded.Outcome != scheduler.OutcomeSucceeded || recorded.StartedAt.IsZero() || recorded.Duration < job.delay {
+		t.Fatalf("expected the run completed with the duration of the callback, got: %+v", recorded)
+	}
+	if ok, err := zkMockObj.Verify(); !ok {
+		t.Fatal(err)
+	}
+}
+
+type callbackJob struct {
+	name  string
+	delay time.Duration
+	data  [][]byte
+}
+
+func (j *callbackJob) GetName() string { return j.name }
+
+func (j *callbackJob) Callback(i ...interface{}) {
+	j.data = i[1].([][]byte)
+	time.Sleep(j.delay)
+}
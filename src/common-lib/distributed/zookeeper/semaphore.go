@@ -0,0 +1,148 @@
+package zookeeper
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/samuel/go-zookeeper/zk"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/semaphore"
+)
+
+const (
+	semaphoresNode = "semaphores"
+	leasePrefix    = "lease-"
+)
+
+var (
+	// ErrPermitAcquired is returned by Acquire when the Semaphore already holds a permit
+	ErrPermitAcquired = errors.New("zookeeper: semaphore permit is already acquired")
+	// ErrPermitNotAcquired is returned by Release when the Semaphore does not hold a permit
+	ErrPermitNotAcquired = errors.New("zookeeper: semaphore permit is not acquired")
+	// ErrPermitLost is returned by Release when the permit was released by the session expiry before
+	ErrPermitLost = errors.New("zookeeper: semaphore permit was lost with the session")
+
+	// errPermitsTaken - all the permits are held by others and the caller doesn't want to wait
+	errPermitsTaken = errors.New("semaphore permits are taken")
+)
+
+type semaphoreImpl struct {
+	path    string
+	permits int
+	// node is the lease node owned by this semaphore after Acquire or TryAcquire succeeded
+	node string
+}
+
+// NewSemaphore returns the semaphore with the given name and number of permits.
+// The holders are ephemeral sequential lease nodes, the ones among the first permits nodes hold a permit,
+// so a permit is released together with the session of a stopped holder
+func NewSemaphore(name string, permits int) semaphore.Semaphore {
+	if permits < 1 {
+		permits = 1
+	}
+	return &semaphoreImpl{
+		path:    zookeeperBasePath + zkSeparator + semaphoresNode + zkSeparator + name,
+		permits: permits,
+	}
+}
+
+// Acquire blocks until a permit is acquired or ctx is done
+func (s *semaphoreImpl) Acquire(ctx context.Context) error {
+	return s.acquire(ctx, true)
+}
+
+// TryAcquire acquires a permit only if nobody waits for one and one is free
+func (s *semaphoreImpl) TryAcquire() (bool, error) {
+	err := s.acquire(context.Background(), false)
+	if err == errPermitsTaken {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (s *semaphoreImpl) acquire(ctx context.Context, wait bool) error {
+	if s.node != "" {
+		return ErrPermitAcquired
+	}
+
+	node, err := Client.CreateRecursive(s.path+zkSeparator+leasePrefix, []byte{}, int32(zk.FlagEphemeral|zk.FlagSequence), zk.WorldACL(zk.PermAll))
+	if err != nil {
+		return err
+	}
+
+	if err = s.waitForPermit(ctx, node, wait); err != nil {
+		if delErr := Client.Delete(node, -1); delErr != nil && delErr != zk.ErrNoNode {
+			Logger().Debug(defaultTransaction, "Couldn't delete semaphore node %s: %s", node, delErr)
+		}
+		return err
+	}
+
+	s.node = node
+	return nil
+}
+
+// waitForPermit waits until fewer than permits lease nodes precede the node
+func (s *semaphoreImpl) waitForPermit(ctx context.Context, node string, wait bool) error {
+	seq, err := parseLockSeq(node)
+	if err != nil {
+		return err
+	}
+	name := strings.TrimPrefix(node, s.path+zkSeparator)
+
+	for {
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		children, _, events, err := Client.ChildrenW(s.path)
+		if err != nil {
+			return err
+		}
+
+		preceding, found := 0, false
+		for _, child := range children {
+			if child == name {
+				found = true
+				continue
+			}
+			if childSeq, err := parseLockSeq(child); err == nil && childSeq < seq {
+				preceding++
+			}
+		}
+		if !found {
+			// the node is gone together with the session
+			return zk.ErrNoNode
+		}
+		if preceding < s.permits {
+			return nil
+		}
+		if !wait {
+			return errPermitsTaken
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-events:
+		}
+	}
+}
+
+// Release deletes the lease node, so the next waiting holder gets the permit
+func (s *semaphoreImpl) Release() error {
+	if s.node == "" {
+		return ErrPermitNotAcquired
+	}
+
+	err := Client.Delete(s.node, -1)
+	if err != nil && err != zk.ErrNoNode {
+		return err
+	}
+
+	s.node = ""
+	if err == zk.ErrNoNode {
+		return ErrPermitLost
+	}
+	return nil
+}
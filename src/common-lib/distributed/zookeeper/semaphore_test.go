@@ -0,0 +1,115 @@
+package zookeeper
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/semaphore"
+)
+
+func TestSemaphoreTryAcquire(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	setTestBasePath(t)
+	mockTree(zkMockObj)
+
+	first, second, third := NewSemaphore("partner", 2), NewSemaphore("partner", 2), NewSemaphore("partner", 2)
+	for i, s := range []semaphore.Semaphore{first, second} {
+		if acquired, err := s.TryAcquire(); err != nil || !acquired {
+			t.Fatalf("holder %d: expected a permit, got %v, %v", i, acquired, err)
+		}
+	}
+	if acquired, err := third.TryAcquire(); err != nil || acquired {
+		t.Fatalf("expected no permit, got %v, %v", acquired, err)
+	}
+	if err := first.Acquire(context.Background()); err != ErrPermitAcquired {
+		t.Fatalf("expected %v error, got: %v", ErrPermitAcquired, err)
+	}
+
+	if err := first.Release(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if err := first.Release(); err != ErrPermitNotAcquired {
+		t.Fatalf("expected %v error, got: %v", ErrPermitNotAcquired, err)
+	}
+	if acquired, err := third.TryAcquire(); err != nil || !acquired {
+		t.Fatalf("expected the released permit, got %v, %v", acquired, err)
+	}
+}
+
+func TestSemaphoreAcquireWaits(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	setTestBasePath(t)
+	mockTree(zkMockObj)
+
+	holder, waiter := NewSemaphore("partner", 1), NewSemaphore("partner", 1)
+	if err := holder.Acquire(context.Background()); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	acquired := make(chan error, 1)
+	go func() { acquired <- waiter.Acquire(context.Background()) }()
+
+	select {
+	case err := <-acquired:
+		t.Fatalf("expected to wait for the permit, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if err := holder.Release(); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	select {
+	case err := <-acquired:
+		if err != nil {
+			t.Fatalf("expected no error but got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("permit wasn't acquired after the release")
+	}
+}
+
+func TestSemaphoreAcquireContext(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	setTestBasePath(t)
+	tree := mockTree(zkMockObj)
+
+	holder, waiter := NewSemaphore("partner", 1), NewSemaphore("partner", 1)
+	if err := holder.Acquire(context.Background()); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := waiter.Acquire(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected %v error, got: %v", context.DeadlineExceeded, err)
+	}
+
+	children, _ := tree.children("/test/"+semaphoresNode+"/partner", false)
+	if len(children) != 1 {
+		t.Fatalf("expected the lease node of the waiter to be removed, got %v", children)
+	}
+}
+
+func TestSemaphorePermitLost(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+	setTestBasePath(t)
+	tree := mockTree(zkMockObj)
+
+	s := NewSemaphore("partner", 1)
+	if err := s.Acquire(context.Background()); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+
+	// the session expired
+	if err := tree.delete(s.(*semaphoreImpl).node, -1); err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if err := s.Release(); err != ErrPermitLost {
+		t.Fatalf("expected %v error, got: %v", ErrPermitLost, err)
+	}
+}
@@ -0,0 +1,64 @@
+package zookeeper
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/samuel/go-zookeeper/zk"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+// List returns names of the items in the work queue, item nodes are sequential so the names are ordered by creation
+func (workQueueStoreImpl) List(queueName string) ([]string, error) {
+	children, _, err := Client.Children(getWorkQueueZkPath(queueName))
+	if err != nil {
+		return nil, toWorkQueueError(err)
+	}
+	sort.Strings(children)
+	return children, nil
+}
+
+// Get returns data and version of the item node
+func (workQueueStoreImpl) Get(queueName, itemName string) ([]byte, int64, error) {
+	data, stat, err := Client.Get(getWorkQueueZkPath(queueName) + zkSeparator + itemName)
+	if err != nil {
+		return nil, 0, toWorkQueueError(err)
+	}
+	return data, int64(stat.Version), nil
+}
+
+// Create creates a new sequential item node, the queue node is created if it does not exist
+func (workQueueStoreImpl) Create(queueName string, data []byte) (string, error) {
+	path := getWorkQueueZkPath(queueName)
+	item, err := Client.CreateRecursive(path+zkSeparator+queuePrefix, data, int32(zk.FlagSequence), zk.WorldACL(zk.PermAll))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(item, path+zkSeparator), nil
+}
+
+// Update sets data of the item node if its version matches
+func (workQueueStoreImpl) Update(queueName, itemName string, data []byte, version int64) error {
+	_, err := Client.Set(getWorkQueueZkPath(queueName)+zkSeparator+itemName, data, int32(version))
+	return toWorkQueueError(err)
+}
+
+// Delete removes the item node if its version matches
+func (workQueueStoreImpl) Delete(queueName, itemName string, version int64) error {
+	return toWorkQueueError(Client.Delete(getWorkQueueZkPath(queueName)+zkSeparator+itemName, int32(version)))
+}
+
+func toWorkQueueError(err error) error {
+	switch err {
+	case zk.ErrNoNode:
+		return queue.ErrNotFound
+	case zk.ErrBadVersion:
+		return queue.ErrConflict
+	}
+	return err
+}
+
+func getWorkQueueZkPath(queueName string) string {
+	return zookeeperBasePath + zkSeparator + workQueueNode + zkSeparator + queueName
+}
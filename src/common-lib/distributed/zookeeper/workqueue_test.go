@@ -0,0 +1,73 @@
+package zookeeper
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/maraino/go-mock"
+	"github.com/samuel/go-zookeeper/zk"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/distributed/queue"
+)
+
+func TestWorkQueueStoreList(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+
+	zkMockObj.When("Children", getWorkQueueZkPath("jobs")).Return([]string{"queue-0000000002", "queue-0000000001"}, &zk.Stat{}, nil)
+	items, err := WorkQueueStore.List("jobs")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if !reflect.DeepEqual(items, []string{"queue-0000000001", "queue-0000000002"}) {
+		t.Fatalf("expected sorted items, got: %v", items)
+	}
+	zkMockObj.Reset()
+
+	zkMockObj.When("Children", getWorkQueueZkPath("jobs")).Return([]string(nil), (*zk.Stat)(nil), zk.ErrNoNode)
+	if _, err = WorkQueueStore.List("jobs"); err != queue.ErrNotFound {
+		t.Fatalf("expected %v error, got: %v", queue.ErrNotFound, err)
+	}
+}
+
+func TestWorkQueueStoreCreate(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+
+	path := getWorkQueueZkPath("jobs")
+	zkMockObj.When("CreateRecursive", path+zkSeparator+queuePrefix, []byte("data"), int32(zk.FlagSequence), mock.Any).
+		Return(path+zkSeparator+"queue-0000000001", nil)
+
+	item, err := WorkQueueStore.Create("jobs", []byte("data"))
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if item != "queue-0000000001" {
+		t.Fatalf("expected item: queue-0000000001, got: %s", item)
+	}
+}
+
+func TestWorkQueueStoreVersions(t *testing.T) {
+	zkMockObj, originalClient := InitMock()
+	defer Restore(originalClient)
+
+	item := getWorkQueueZkPath("jobs") + zkSeparator + "queue-0000000001"
+	zkMockObj.When("Get", item).Return([]byte("data"), &zk.Stat{Version: 3}, nil)
+	data, version, err := WorkQueueStore.Get("jobs", "queue-0000000001")
+	if err != nil {
+		t.Fatalf("expected no error but got %v", err)
+	}
+	if string(data) != "data" || version != 3 {
+		t.Fatalf("expected data and version 3, got: %s %d", data, version)
+	}
+
+	zkMockObj.When("Set", item, []byte("new"), int32(3)).Return((*zk.Stat)(nil), zk.ErrBadVersion)
+	if err = WorkQueueStore.Update("jobs", "queue-0000000001", []byte("new"), 3); err != queue.ErrConflict {
+		t.Fatalf("expected %v error, got: %v", queue.ErrConflict, err)
+	}
+
+	zkMockObj.When("Delete", item, int32(3)).Return(zk.ErrNoNode)
+	if err = WorkQueueStore.Delete("jobs", "queue-0000000001", 3); err != queue.ErrNotFound {
+		t.Fatalf("expected %v error, got: %v", queue.ErrNotFound, err)
+	}
+}
@@ -0,0 +1,159 @@
+package zookeeper
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/maraino/go-mock"
+	"github.com/samuel/go-zookeeper/zk"
+)
+
+type (
+	// zkTree simulates the nodes and watches of an ensemble behind the mocked client
+	zkTree struct {
+		mutex        sync.Mutex
+		seq          map[string]int
+		nodes        map[string]*zkNode
+		watches      map[string][]chan zk.Event
+		childWatches map[string][]chan zk.Event
+	}
+
+	zkNode struct {
+		data    []byte
+		version int32
+	}
+)
+
+func mockTree(zkMockObj *ClientMock) *zkTree {
+	tree := &zkTree{
+		seq:          make(map[string]int),
+		nodes:        make(map[string]*zkNode),
+		watches:      make(map[string][]chan zk.Event),
+		childWatches: make(map[string][]chan zk.Event),
+	}
+
+	create := func(node string, data []byte, flag int32, _ []zk.ACL) (string, error) {
+		return tree.create(node, data, flag)
+	}
+	zkMockObj.When("CreateRecursive", mock.Any, mock.Any, mock.Any, mock.Any).Call(create)
+	zkMockObj.When("Create", mock.Any, mock.Any, mock.Any, mock.Any).Call(create)
+	zkMockObj.When("Children", mock.Any).Call(func(parent string) ([]string, *zk.Stat, error) {
+		children, _ := tree.children(parent, false)
+		return children, &zk.Stat{}, nil
+	})
+	zkMockObj.When("ChildrenW", mock.Any).Call(func(parent string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+		children, events := tree.children(parent, true)
+		return children, &zk.Stat{}, events, nil
+	})
+	zkMockObj.When("ExistsW", mock.Any).Call(func(node string) (bool, *zk.Stat, <-chan zk.Event, error) {
+		tree.mutex.Lock()
+		defer tree.mutex.Unlock()
+		events := make(chan zk.Event, 1)
+		tree.watches[node] = append(tree.watches[node], events)
+		_, ok := tree.nodes[node]
+		return ok, &zk.Stat{}, (<-chan zk.Event)(events), nil
+	})
+	zkMockObj.When("Get", mock.Any).Call(func(node string) ([]byte, *zk.Stat, error) {
+		tree.mutex.Lock()
+		defer tree.mutex.Unlock()
+		n, ok := tree.nodes[node]
+		if !ok {
+			return []byte(nil), (*zk.Stat)(nil), zk.ErrNoNode
+		}
+		return n.data, &zk.Stat{Version: n.version}, nil
+	})
+	zkMockObj.When("Set", mock.Any, mock.Any, mock.Any).Call(func(node string, data []byte, version int32) (*zk.Stat, error) {
+		tree.mutex.Lock()
+		defer tree.mutex.Unlock()
+		n, ok := tree.nodes[node]
+		if !ok {
+			return (*zk.Stat)(nil), zk.ErrNoNode
+		}
+		if version != -1 && version != n.version {
+			return (*zk.Stat)(nil), zk.ErrBadVersion
+		}
+		n.data = data
+		n.version++
+		return &zk.Stat{Version: n.version}, nil
+	})
+	zkMockObj.When("Delete", mock.Any, mock.Any).Call(func(node string, version int32) error {
+		return tree.delete(node, version)
+	})
+	return tree
+}
+
+func (tree *zkTree) create(node string, data []byte, flag int32) (string, error) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	if flag&zk.FlagSequence != 0 {
+		parent := path.Dir(node)
+		node = fmt.Sprintf("%s%010d", node, tree.seq[parent])
+		tree.seq[parent]++
+	}
+	if _, ok := tree.nodes[node]; ok {
+		return "", zk.ErrNodeExists
+	}
+	tree.nodes[node] = &zkNode{data: data}
+	tree.fire(node, zk.EventNodeCreated)
+	return node, nil
+}
+
+func (tree *zkTree) delete(node string, version int32) error {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	n, ok := tree.nodes[node]
+	if !ok {
+		return zk.ErrNoNode
+	}
+	if version != -1 && version != n.version {
+		return zk.ErrBadVersion
+	}
+	delete(tree.nodes, node)
+	// like the cversion of the parent, the sequence of its next child is increased by a delete too
+	tree.seq[path.Dir(node)]++
+	tree.fire(node, zk.EventNodeDeleted)
+	return nil
+}
+
+// fire notifies the watches of the node and of the children of its parent, the caller holds the mutex
+func (tree *zkTree) fire(node string, eventType zk.EventType) {
+	for _, events := range tree.watches[node] {
+		events <- zk.Event{Type: eventType, Path: node}
+	}
+	delete(tree.watches, node)
+
+	parent := path.Dir(node)
+	for _, events := range tree.childWatches[parent] {
+		events <- zk.Event{Type: zk.EventNodeChildrenChanged, Path: parent}
+	}
+	delete(tree.childWatches, parent)
+}
+
+func (tree *zkTree) children(parent string, watch bool) ([]string, <-chan zk.Event) {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+
+	children := []string{}
+	for node := range tree.nodes {
+		if path.Dir(node) == parent {
+			children = append(children, strings.TrimPrefix(node, parent+"/"))
+		}
+	}
+
+	events := make(chan zk.Event, 1)
+	if watch {
+		tree.childWatches[parent] = append(tree.childWatches[parent], events)
+	}
+	return children, events
+}
+
+func (tree *zkTree) exists(node string) bool {
+	tree.mutex.Lock()
+	defer tree.mutex.Unlock()
+	_, ok := tree.nodes[node]
+	return ok
+}
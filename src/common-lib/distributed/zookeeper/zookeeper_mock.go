@@ -38,6 +38,12 @@ func (m *ClientMock) Exists(path string) (bool, *zk.Stat, error) {
 	return ret.Bool(0), ret.Get(1).(*zk.Stat), ret.Error(2)
 }
 
+// ExistsW implements ZKClient
+func (m *ClientMock) ExistsW(path string) (bool, *zk.Stat, <-chan zk.Event, error) {
+	ret := m.Called(path)
+	return ret.Bool(0), ret.Get(1).(*zk.Stat), ret.Get(2).(<-chan zk.Event), ret.Error(3)
+}
+
 // Get implements ZKClient
 func (m *ClientMock) Get(path string) ([]byte, *zk.Stat, error) {
 	ret := m.Called(path)
@@ -50,6 +56,12 @@ func (m *ClientMock) Children(path string) ([]string, *zk.Stat, error) {
 	return ret.Get(0).([]string), ret.Get(1).(*zk.Stat), ret.Error(2)
 }
 
+// ChildrenW implements ZKClient
+func (m *ClientMock) ChildrenW(path string) ([]string, *zk.Stat, <-chan zk.Event, error) {
+	ret := m.Called(path)
+	return ret.Get(0).([]string), ret.Get(1).(*zk.Stat), ret.Get(2).(<-chan zk.Event), ret.Error(3)
+}
+
 // Set implements ZKClient
 func (m *ClientMock) Set(path string, data []byte, version int32) (*zk.Stat, error) {
 	ret := m.Called(path, data, version)
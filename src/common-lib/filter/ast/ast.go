@@ -0,0 +1,61 @@
+// Package ast represents a filter query as a typed syntax tree.
+// The fields of the query are validated against a Schema, so a query
+// with an unknown field, a forbidden operator or a value of a wrong type
+// is rejected with the position of the error before any query language is built.
+package ast
+
+import (
+	"fmt"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+// Node : a node of the filter syntax tree, either Logical or Comparison.
+type Node interface {
+	// Position : 1-based position of the node in the filter query.
+	Position() int
+}
+
+// Logical : joins two nodes with the AND or OR operator.
+type Logical struct {
+	Op    command.Op
+	Left  Node
+	Right Node
+	Pos   int
+}
+
+// Comparison : compares a field with the values.
+// Values hold the parsed values of the field type, there are no values for IS NULL and IS NOT NULL,
+// and there may be several values for IN. Raw holds the values as they are in the query.
+type Comparison struct {
+	Field  Field
+	Op     command.Op
+	Values []interface{}
+	Raw    []string
+	Pos    int
+}
+
+// Error : a filter query error with its position.
+type Error struct {
+	// Position : 1-based position of the error in the filter query.
+	Position int
+	Msg      string
+}
+
+// Position : returns the position of the node.
+func (l *Logical) Position() int {
+	return l.Pos
+}
+
+// Position : returns the position of the node.
+func (c *Comparison) Position() int {
+	return c.Pos
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("filter error at position %d: %s", e.Position, e.Msg)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Position: pos, Msg: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,83 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+const valueDelimiter = ","
+
+// Convert : converts the syntax tree to a filter with the converter.
+// The fields are passed to the converter by their name and the mapper maps them to the columns,
+// as for the commands of the tokenize strategy, the commands also carry the parsed values.
+// A nested logical node is enclosed in parentheses. The filter is checked by the converter if it is a command.Validator.
+func Convert(node Node, cnv command.Converter, mapper func(string) string) (*filter.Filter, error) {
+	var result *filter.Filter
+	add := func(f *filter.Filter) {
+		if result == nil {
+			result = f
+			return
+		}
+		r := result.Add(f)
+		result = &r
+	}
+	if err := convert(node, cnv, mapper, add); err != nil {
+		return nil, err
+	}
+	if v, ok := cnv.(command.Validator); ok {
+		if err := v.Validate(result); err != nil {
+			//nolint:wrapcheck
+			return nil, err
+		}
+	}
+	return result, nil
+}
+
+func convert(node Node, cnv command.Converter, mapper func(string) string, add func(*filter.Filter)) error {
+	switch n := node.(type) {
+	case *Logical:
+		for i, child := range []Node{n.Left, n.Right} {
+			if i > 0 {
+				if err := accept(command.New("", string(n.Op), ""), cnv, mapper, add); err != nil {
+					return err
+				}
+			}
+			if err := convertOperand(child, cnv, mapper, add); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *Comparison:
+		value := strings.Join(n.Raw, valueDelimiter)
+		return accept(command.NewWithValues(n.Field.Name, string(n.Op), value, n.Values...), cnv, mapper, add)
+	}
+	//nolint:goerr113
+	return fmt.Errorf("unexpected node %T", node)
+}
+
+// convertOperand encloses a nested logical node in parentheses.
+func convertOperand(node Node, cnv command.Converter, mapper func(string) string, add func(*filter.Filter)) error {
+	if _, ok := node.(*Logical); !ok {
+		return convert(node, cnv, mapper, add)
+	}
+	if err := accept(command.New("", string(command.LHS), ""), cnv, mapper, add); err != nil {
+		return err
+	}
+	if err := convert(node, cnv, mapper, add); err != nil {
+		return err
+	}
+	return accept(command.New("", string(command.RHS), ""), cnv, mapper, add)
+}
+
+func accept(c command.Command, cnv command.Converter, mapper func(string) string, add func(*filter.Filter)) error {
+	f, err := c.Accept(cnv, mapper)
+	if err != nil {
+		//nolint:wrapcheck
+		return err
+	}
+	add(f)
+	return nil
+}
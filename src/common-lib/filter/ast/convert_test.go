@@ -0,0 +1,109 @@
+package ast
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/converters/sql"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/web/filter/strategies/tokenize"
+)
+
+func TestStrategy_Parse(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		mapper     func(string) string
+		wantQuery  string
+		wantValues []interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "comparison",
+			query:      "createdAt >= 2021-01-02",
+			wantQuery:  "(created_at) >= (%v)",
+			wantValues: []interface{}{"2021-01-02"},
+		},
+		{
+			name:       "nested",
+			query:      "deletedAt IS NULL AND (partnerId IN 1,2 OR name : acme)",
+			wantQuery:  "deleted_at is null and ( partner_id in (%v,%v) or name  like  %v )",
+			wantValues: []interface{}{"1", "2", "%acme%"},
+		},
+		{
+			name:       "mapper",
+			query:      "partnerId = 1",
+			mapper:     func(column string) string { return "p." + column },
+			wantQuery:  "(p.partner_id) = (%v)",
+			wantValues: []interface{}{"1"},
+		},
+		{
+			name:    "invalid",
+			query:   "createdAt : abc",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewStrategy(testSchema(t)).Parse(sql.GetConverter(), tt.query, tt.mapper)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Strategy.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.GetQuery() != tt.wantQuery {
+				t.Errorf("Strategy.Parse() query = %q, want %q", got.GetQuery(), tt.wantQuery)
+			}
+			if !reflect.DeepEqual(got.GetValues(), tt.wantValues) {
+				t.Errorf("Strategy.Parse() values = %v, want %v", got.GetValues(), tt.wantValues)
+			}
+		})
+	}
+}
+
+// TestStrategy_Parse_tokenize runs the queries of the tokenize strategy tests through both strategies.
+// The parentheses may differ, as the syntax tree only keeps the grouping the precedence requires.
+func TestStrategy_Parse_tokenize(t *testing.T) {
+	schema, err := NewSchema(
+		Field{Name: "partnerId", Column: "partner_id", Type: reflect.TypeOf("")},
+		Field{Name: "companyName", Column: "name", Type: reflect.TypeOf("")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper := schema.Mapper()
+	queries := []string{
+		"(partnerId IN 1000,2000,3000,4000 OR partnerId = 5000 AND partnerId : 6000)",
+		`(partnerId = "ABCD" OR partnerId = "XYZ") AND (companyName = "ABCD" OR companyName != "XYZ")`,
+		`(partnerId = "ABCD" OR partnerId = "XYZ") and (companyName = "ABCD" OR companyName != "XYZ")`,
+		`partnerId = "ABCD")`,
+		`(partnerId = "ABCD")(`,
+		`(UNKNOWN = "ABCD")(`,
+		`(partnerId UNKNOWN "ABCD")(`,
+		"companyName : acme inc",
+		"companyName = acme  inc AND partnerId IN 1,2",
+		`companyName = "acme inc" OR companyName = O"Brien`,
+	}
+	normalize := strings.NewReplacer("(", "", ")", "", " ", "")
+	for _, query := range queries {
+		t.Run(query, func(t *testing.T) {
+			want, wantErr := tokenize.GetStrategy().Parse(sql.GetConverter(), query, mapper)
+			got, err := NewStrategy(schema).Parse(sql.GetConverter(), query, nil)
+			if (err != nil) != (wantErr != nil) {
+				t.Errorf("Strategy.Parse() error = %v, tokenize error = %v", err, wantErr)
+				return
+			}
+			if err != nil {
+				return
+			}
+			if normalize.Replace(got.GetQuery()) != normalize.Replace(want.GetQuery()) {
+				t.Errorf("Strategy.Parse() query = %q, tokenize query = %q", got.GetQuery(), want.GetQuery())
+			}
+			if !reflect.DeepEqual(got.GetValues(), want.GetValues()) {
+				t.Errorf("Strategy.Parse() values = %q, tokenize values = %q", got.GetValues(), want.GetValues())
+			}
+		})
+	}
+}
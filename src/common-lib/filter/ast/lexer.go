@@ -0,0 +1,85 @@
+package ast
+
+import (
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+const (
+	quote  = '"'
+	escape = '\\'
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+	// end is the offset of the query after the token.
+	end int
+}
+
+// lex splits the query into words, quoted strings, parentheses and commas.
+// The operators are words, so they should be separated by spaces as in the command package.
+// A quote starts a string only at the beginning of a token, inside a word it is a part of the word.
+func lex(query string) ([]token, error) {
+	var tokens []token
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case c == '(':
+			tokens = append(tokens, token{kind: tokenLParen, value: "(", pos: i + 1, end: i + 1})
+			i++
+		case c == ')':
+			tokens = append(tokens, token{kind: tokenRParen, value: ")", pos: i + 1, end: i + 1})
+			i++
+		case c == ',':
+			tokens = append(tokens, token{kind: tokenComma, value: ",", pos: i + 1, end: i + 1})
+			i++
+		case c == quote:
+			s, n, err := lexString(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenString, value: s, pos: i + 1, end: i + n})
+			i += n
+		default:
+			start := i
+			for i < len(query) && !strings.ContainsRune(" \t\n\r(),", rune(query[i])) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenWord, value: query[start:i], pos: start + 1, end: i})
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(query) + 1, end: len(query)}), nil
+}
+
+// lexString returns the unescaped string starting at the quote and the length of the quoted string.
+func lexString(query string, start int) (string, int, error) {
+	var b strings.Builder
+	for i := start + 1; i < len(query); i++ {
+		switch c := query[i]; c {
+		case escape:
+			if i+1 < len(query) {
+				i++
+				b.WriteByte(query[i])
+			}
+		case quote:
+			return b.String(), i - start + 1, nil
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return "", 0, errorf(start+1, "unterminated string")
+}
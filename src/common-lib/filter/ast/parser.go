@@ -0,0 +1,208 @@
+package ast
+
+import (
+	"strings"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+const (
+	keywordIs   = "IS"
+	keywordNot  = "NOT"
+	keywordNull = "NULL"
+)
+
+// comparisons are the operators followed by a single value.
+var comparisons = map[string]command.Op{
+	string(command.Eq):   command.Eq,
+	string(command.Not):  command.Not,
+	string(command.Gt):   command.Gt,
+	string(command.Ge):   command.Ge,
+	string(command.Lt):   command.Lt,
+	string(command.Le):   command.Le,
+	string(command.Like): command.Like,
+}
+
+type parser struct {
+	schema *Schema
+	query  string
+	tokens []token
+	pos    int
+}
+
+// Parse : parses the filter query into a syntax tree validated against the schema.
+// The query has the syntax of the tokenize strategy, AND binds tighter than OR:
+//
+//	(partnerId IN 1000,2000 OR name : "acme inc") AND createdAt > 2021-01-02T15:04:05Z AND deletedAt IS NULL
+//
+// As in the tokenize strategy, a value runs up to the next AND, OR or parenthesis, so `name : acme inc`
+// is the same as `name : "acme inc"`, the spaces between the words of a value are collapsed and the quotes
+// around a value of several words are removed. A quoted value may also contain AND, OR, parentheses and escaped quotes.
+// Any error is an *Error with the position of the error in the query.
+func (s *Schema) Parse(query string) (Node, error) {
+	tokens, err := lex(query)
+	if err != nil {
+		return nil, err
+	}
+	if tokens[0].kind == tokenEOF {
+		return nil, errorf(1, "filter is empty")
+	}
+	p := &parser{schema: s, query: query, tokens: tokens}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, errorf(t.pos, "unexpected %q", t.value)
+	}
+	return node, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) isKeyword(t token, keyword string) bool {
+	return t.kind == tokenWord && t.value == keyword
+}
+
+func (p *parser) parseOr() (Node, error) {
+	return p.parseLogical(command.Or, p.parseAnd)
+}
+
+func (p *parser) parseAnd() (Node, error) {
+	return p.parseLogical(command.And, p.parsePrimary)
+}
+
+func (p *parser) parseLogical(op command.Op, operand func() (Node, error)) (Node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword(p.peek(), string(op)) {
+		t := p.next()
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{Op: op, Left: left, Right: right, Pos: t.pos}
+	}
+	return left, nil
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokenLParen:
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if r := p.next(); r.kind != tokenRParen {
+			return nil, errorf(r.pos, "expected \")\" to close \"(\" at position %d", t.pos)
+		}
+		return node, nil
+	case tokenWord:
+		return p.parseComparison(t)
+	case tokenEOF:
+		return nil, errorf(t.pos, "expected field, got end of filter")
+	}
+	return nil, errorf(t.pos, "expected field, got %q", t.value)
+}
+
+func (p *parser) parseComparison(name token) (Node, error) {
+	field, ok := p.schema.Field(name.value)
+	if !ok {
+		return nil, errorf(name.pos, "unknown field %s", name.value)
+	}
+	c := &Comparison{Field: field, Pos: name.pos}
+	t := p.next()
+	switch {
+	case p.isKeyword(t, keywordIs):
+		c.Op = command.Null
+		if p.isKeyword(p.peek(), keywordNot) {
+			p.next()
+			c.Op = command.NonNull
+		}
+		if n := p.next(); !p.isKeyword(n, keywordNull) {
+			return nil, errorf(n.pos, "expected NULL")
+		}
+	case p.isKeyword(t, string(command.In)):
+		c.Op = command.In
+	case t.kind == tokenWord && comparisons[t.value] != "":
+		c.Op = comparisons[t.value]
+	case t.kind == tokenEOF:
+		return nil, errorf(t.pos, "expected operator after field %s, got end of filter", field.Name)
+	default:
+		return nil, errorf(t.pos, "unknown operator %q", t.value)
+	}
+	if !field.Allows(c.Op) {
+		return nil, errorf(t.pos, "operator %s is not allowed for field %s", strings.TrimSpace(string(c.Op)), field.Name)
+	}
+	if c.Op == command.Null || c.Op == command.NonNull {
+		return c, nil
+	}
+	for {
+		if err := p.parseValue(c); err != nil {
+			return nil, err
+		}
+		if c.Op != command.In || p.peek().kind != tokenComma {
+			return c, nil
+		}
+		p.next()
+	}
+}
+
+// parseValue parses the words up to the next AND, OR, parenthesis or, for IN, comma as one value.
+func (p *parser) parseValue(c *Comparison) error {
+	var parts []token
+	for p.isValuePart(p.peek(), c.Op) {
+		parts = append(parts, p.next())
+	}
+	if len(parts) == 0 {
+		t := p.next()
+		if t.kind == tokenEOF {
+			return errorf(t.pos, "expected value for field %s, got end of filter", c.Field.Name)
+		}
+		return errorf(t.pos, "expected value for field %s, got %q", c.Field.Name, t.value)
+	}
+	raw := parts[0].value
+	if len(parts) > 1 {
+		raw = trimQuotes(strings.Join(strings.Fields(p.query[parts[0].pos-1:parts[len(parts)-1].end]), " "))
+	}
+	v, err := c.Field.Parse(raw)
+	if err != nil {
+		return errorf(parts[0].pos, "invalid value for field %s: %v", c.Field.Name, err)
+	}
+	c.Values = append(c.Values, v)
+	c.Raw = append(c.Raw, raw)
+	return nil
+}
+
+func (p *parser) isValuePart(t token, op command.Op) bool {
+	switch t.kind {
+	case tokenWord:
+		return !p.isKeyword(t, string(command.And)) && !p.isKeyword(t, string(command.Or))
+	case tokenString:
+		return true
+	case tokenComma:
+		return op != command.In
+	}
+	return false
+}
+
+// trimQuotes removes the quotes around the value like the converters do for the tokenize strategy.
+func trimQuotes(value string) string {
+	if len(value) > 1 && value[0] == quote && value[len(value)-1] == quote {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,153 @@
+package ast
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+func testSchema(t *testing.T) *Schema {
+	var deletedAt *time.Time
+	s, err := NewSchema(
+		Field{Name: "partnerId", Column: "partner_id", Type: reflect.TypeOf(int64(0))},
+		NewField("name", ""),
+		NewField("active", false),
+		Field{Name: "createdAt", Column: "created_at", Type: reflect.TypeOf(time.Time{})},
+		Field{Name: "deletedAt", Column: "deleted_at", Type: reflect.TypeOf(deletedAt), Operators: []command.Op{command.Null, command.NonNull}},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestSchema_Parse(t *testing.T) {
+	s := testSchema(t)
+	partnerID, _ := s.Field("partnerId")
+	name, _ := s.Field("name")
+	active, _ := s.Field("active")
+	deletedAt, _ := s.Field("deletedAt")
+	tests := []struct {
+		name  string
+		query string
+		want  Node
+	}{
+		{
+			name:  "comparison",
+			query: "partnerId = 1000",
+			want:  &Comparison{Field: partnerID, Op: command.Eq, Values: []interface{}{int64(1000)}, Raw: []string{"1000"}, Pos: 1},
+		},
+		{
+			name:  "quoted string",
+			query: `name : "acme \"inc\""`,
+			want:  &Comparison{Field: name, Op: command.Like, Values: []interface{}{`acme "inc"`}, Raw: []string{`acme "inc"`}, Pos: 1},
+		},
+		{
+			name:  "words",
+			query: "name : acme  inc",
+			want:  &Comparison{Field: name, Op: command.Like, Values: []interface{}{"acme inc"}, Raw: []string{"acme inc"}, Pos: 1},
+		},
+		{
+			name:  "quote inside word",
+			query: `name = O"Brien`,
+			want:  &Comparison{Field: name, Op: command.Eq, Values: []interface{}{`O"Brien`}, Raw: []string{`O"Brien`}, Pos: 1},
+		},
+		{
+			name:  "words in",
+			query: "name IN acme inc, umbrella",
+			want:  &Comparison{Field: name, Op: command.In, Values: []interface{}{"acme inc", "umbrella"}, Raw: []string{"acme inc", "umbrella"}, Pos: 1},
+		},
+		{
+			name:  "in",
+			query: "partnerId IN 1000, 2000",
+			want:  &Comparison{Field: partnerID, Op: command.In, Values: []interface{}{int64(1000), int64(2000)}, Raw: []string{"1000", "2000"}, Pos: 1},
+		},
+		{
+			name:  "is not null",
+			query: "deletedAt IS NOT NULL",
+			want:  &Comparison{Field: deletedAt, Op: command.NonNull, Pos: 1},
+		},
+		{
+			name:  "and binds tighter than or",
+			query: "active = true OR name = a AND partnerId != 1",
+			want: &Logical{
+				Op:   command.Or,
+				Left: &Comparison{Field: active, Op: command.Eq, Values: []interface{}{true}, Raw: []string{"true"}, Pos: 1},
+				Right: &Logical{
+					Op:    command.And,
+					Left:  &Comparison{Field: name, Op: command.Eq, Values: []interface{}{"a"}, Raw: []string{"a"}, Pos: 18},
+					Right: &Comparison{Field: partnerID, Op: command.Not, Values: []interface{}{int64(1)}, Raw: []string{"1"}, Pos: 31},
+					Pos:   27,
+				},
+				Pos: 15,
+			},
+		},
+		{
+			name:  "parentheses",
+			query: "(active = true OR name = a) AND partnerId != 1",
+			want: &Logical{
+				Op: command.And,
+				Left: &Logical{
+					Op:    command.Or,
+					Left:  &Comparison{Field: active, Op: command.Eq, Values: []interface{}{true}, Raw: []string{"true"}, Pos: 2},
+					Right: &Comparison{Field: name, Op: command.Eq, Values: []interface{}{"a"}, Raw: []string{"a"}, Pos: 19},
+					Pos:   16,
+				},
+				Right: &Comparison{Field: partnerID, Op: command.Not, Values: []interface{}{int64(1)}, Raw: []string{"1"}, Pos: 33},
+				Pos:   29,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := s.Parse(tt.query)
+			if err != nil {
+				t.Errorf("Schema.Parse() error = %v", err)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Schema.Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchema_Parse_errors(t *testing.T) {
+	s := testSchema(t)
+	tests := []struct {
+		name     string
+		query    string
+		position int
+	}{
+		{name: "like on time", query: "createdAt : abc", position: 11},
+		{name: "invalid time", query: "createdAt > abc", position: 13},
+		{name: "operator not allowed", query: "active > true", position: 8},
+		{name: "null not allowed", query: "name IS NULL", position: 6},
+		{name: "unknown field", query: "partnerId = 1 AND color = red", position: 19},
+		{name: "unknown operator", query: "partnerId == 1", position: 11},
+		{name: "invalid int", query: "partnerId IN 1,x", position: 16},
+		{name: "missing value", query: "partnerId =", position: 12},
+		{name: "missing NULL", query: "deletedAt IS NOT", position: 17},
+		{name: "unclosed parenthesis", query: "(partnerId = 1", position: 15},
+		{name: "extra parenthesis", query: "partnerId = 1)", position: 14},
+		{name: "lowercase keyword", query: "partnerId = 1 and name = a", position: 13},
+		{name: "unterminated string", query: `name = "abc`, position: 8},
+		{name: "empty", query: "  ", position: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.Parse(tt.query)
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Errorf("Schema.Parse() error = %v, want *Error", err)
+				return
+			}
+			if e.Position != tt.position {
+				t.Errorf("Schema.Parse() error = %v, want position %d", err, tt.position)
+			}
+		})
+	}
+}
@@ -0,0 +1,190 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+// TimeLayouts : layouts accepted for the values of the time.Time fields.
+var TimeLayouts = []string{time.RFC3339Nano, time.RFC3339, "2006-01-02"}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+var (
+	orderedOperators = []command.Op{command.Eq, command.Not, command.Gt, command.Ge, command.Lt, command.Le, command.In}
+	stringOperators  = append(append([]command.Op{}, orderedOperators...), command.Like)
+	boolOperators    = []command.Op{command.Eq, command.Not}
+	nullOperators    = []command.Op{command.Null, command.NonNull}
+)
+
+// Field : a filterable field.
+type Field struct {
+	// Name : name of the field in the filter query.
+	Name string
+	// Column : name of the field passed to the converter, defaults to Name.
+	Column string
+	// Type : Go type of the field. A pointer type makes the field nullable.
+	Type reflect.Type
+	// Operators : operators allowed for the field, defaults to all the operators supported by Type.
+	Operators []command.Op
+}
+
+// Schema : the filterable fields of a query.
+type Schema struct {
+	fields map[string]Field
+}
+
+// NewField : returns the field of the sample type allowing the operators,
+// or all the operators supported by the type if there are none.
+func NewField(name string, sample interface{}, operators ...command.Op) Field {
+	return Field{Name: name, Type: reflect.TypeOf(sample), Operators: operators}
+}
+
+// NewSchema : returns the schema of the fields or an error if a field has an unsupported type or operator.
+func NewSchema(fields ...Field) (*Schema, error) {
+	s := &Schema{fields: make(map[string]Field, len(fields))}
+	for _, f := range fields {
+		if f.Name == "" {
+			return nil, fmt.Errorf("field name is empty")
+		}
+		if _, ok := s.fields[f.Name]; ok {
+			return nil, fmt.Errorf("field %s is declared twice", f.Name)
+		}
+		supported := supportedOperators(f.Type)
+		if supported == nil {
+			return nil, fmt.Errorf("field %s has unsupported type %v", f.Name, f.Type)
+		}
+		if len(f.Operators) == 0 {
+			f.Operators = supported
+		}
+		for _, op := range f.Operators {
+			if !hasOperator(supported, op) {
+				return nil, fmt.Errorf("field %s of type %v does not support operator %s", f.Name, f.Type, op)
+			}
+		}
+		if f.Column == "" {
+			f.Column = f.Name
+		}
+		s.fields[f.Name] = f
+	}
+	return s, nil
+}
+
+// Field : returns the field declared with the name.
+func (s *Schema) Field(name string) (Field, bool) {
+	f, ok := s.fields[name]
+	return f, ok
+}
+
+// Mapper : maps a field name to its column, the names not in the schema are kept.
+func (s *Schema) Mapper() func(string) string {
+	return func(name string) string {
+		if f, ok := s.fields[name]; ok {
+			return f.Column
+		}
+		return name
+	}
+}
+
+// Allows : returns true if the operator is allowed for the field.
+func (f Field) Allows(op command.Op) bool {
+	return hasOperator(f.Operators, op)
+}
+
+// Parse : returns the value of the field type, a pointer type field returns the value of its element type.
+func (f Field) Parse(raw string) (interface{}, error) {
+	t := f.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	v, err := parseValue(t, raw)
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func parseValue(t reflect.Type, raw string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if t == timeType {
+		for _, layout := range TimeLayouts {
+			if tm, err := time.Parse(layout, raw); err == nil {
+				v.Set(reflect.ValueOf(tm))
+				return v, nil
+			}
+		}
+		return v, fmt.Errorf("expected time in RFC3339 format, got %q", raw)
+	}
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return v, fmt.Errorf("expected bool, got %q", raw)
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("expected %v, got %q", t, raw)
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("expected %v, got %q", t, raw)
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		fl, err := strconv.ParseFloat(raw, t.Bits())
+		if err != nil {
+			return v, fmt.Errorf("expected %v, got %q", t, raw)
+		}
+		v.SetFloat(fl)
+	default:
+		return v, fmt.Errorf("unsupported type %v", t)
+	}
+	return v, nil
+}
+
+// supportedOperators returns the operators supported by the type or nil if the type is not supported.
+func supportedOperators(t reflect.Type) []command.Op {
+	if t == nil {
+		return nil
+	}
+	if t.Kind() == reflect.Ptr {
+		ops := supportedOperators(t.Elem())
+		if ops == nil {
+			return nil
+		}
+		return append(append([]command.Op{}, ops...), nullOperators...)
+	}
+	if t == timeType {
+		return orderedOperators
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return stringOperators
+	case reflect.Bool:
+		return boolOperators
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return orderedOperators
+	}
+	return nil
+}
+
+func hasOperator(ops []command.Op, op command.Op) bool {
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,131 @@
+package ast
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+func TestNewSchema(t *testing.T) {
+	var nullable *time.Time
+	tests := []struct {
+		name    string
+		fields  []Field
+		want    Field
+		wantErr bool
+	}{
+		{
+			name:   "defaults",
+			fields: []Field{NewField("name", "")},
+			want: Field{
+				Name:      "name",
+				Column:    "name",
+				Type:      reflect.TypeOf(""),
+				Operators: stringOperators,
+			},
+		},
+		{
+			name:   "nullable",
+			fields: []Field{{Name: "deletedAt", Column: "deleted_at", Type: reflect.TypeOf(nullable), Operators: []command.Op{command.Null}}},
+			want: Field{
+				Name:      "deletedAt",
+				Column:    "deleted_at",
+				Type:      reflect.TypeOf(nullable),
+				Operators: []command.Op{command.Null},
+			},
+		},
+		{
+			name:    "empty name",
+			fields:  []Field{NewField("", "")},
+			wantErr: true,
+		},
+		{
+			name:    "declared twice",
+			fields:  []Field{NewField("name", ""), NewField("name", 1)},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported type",
+			fields:  []Field{NewField("tags", []string{})},
+			wantErr: true,
+		},
+		{
+			name:    "unsupported operator",
+			fields:  []Field{NewField("active", true, command.Gt)},
+			wantErr: true,
+		},
+		{
+			name:    "null on non nullable",
+			fields:  []Field{NewField("id", 1, command.Null)},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewSchema(tt.fields...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("NewSchema() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if f, _ := got.Field(tt.want.Name); !reflect.DeepEqual(f, tt.want) {
+				t.Errorf("NewSchema() field = %v, want %v", f, tt.want)
+			}
+		})
+	}
+}
+
+func TestField_Parse(t *testing.T) {
+	type status string
+	var nullable *int
+	tests := []struct {
+		name    string
+		field   Field
+		raw     string
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "string", field: NewField("f", ""), raw: "abc", want: "abc"},
+		{name: "named string", field: NewField("f", status("")), raw: "active", want: status("active")},
+		{name: "bool", field: NewField("f", false), raw: "true", want: true},
+		{name: "int", field: NewField("f", 0), raw: "-42", want: -42},
+		{name: "int8 overflow", field: NewField("f", int8(0)), raw: "300", wantErr: true},
+		{name: "uint", field: NewField("f", uint64(0)), raw: "42", want: uint64(42)},
+		{name: "float", field: NewField("f", 0.0), raw: "1.5", want: 1.5},
+		{name: "pointer", field: NewField("f", nullable), raw: "7", want: 7},
+		{name: "time", field: NewField("f", time.Time{}), raw: "2021-01-02T15:04:05Z", want: time.Date(2021, 1, 2, 15, 4, 5, 0, time.UTC)},
+		{name: "date", field: NewField("f", time.Time{}), raw: "2021-01-02", want: time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{name: "invalid time", field: NewField("f", time.Time{}), raw: "abc", wantErr: true},
+		{name: "invalid bool", field: NewField("f", false), raw: "yes please", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.Parse(tt.raw)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Field.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Field.Parse() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSchema_Mapper(t *testing.T) {
+	s, err := NewSchema(Field{Name: "createdAt", Column: "created_at", Type: reflect.TypeOf(time.Time{})})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mapper := s.Mapper()
+	if got := mapper("createdAt"); got != "created_at" {
+		t.Errorf("Mapper() = %v, want created_at", got)
+	}
+	if got := mapper("unknown"); got != "unknown" {
+		t.Errorf("Mapper() = %v, want unknown", got)
+	}
+}
@@ -0,0 +1,33 @@
+package ast
+
+import (
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+// Strategy : a strategy of the filter middleware that validates the query against a schema.
+type Strategy struct {
+	schema *Schema
+}
+
+// NewStrategy : returns a strategy that parses the queries with the schema.
+func NewStrategy(schema *Schema) *Strategy {
+	return &Strategy{schema: schema}
+}
+
+// Parse : parses the query with the schema and converts it with the converter.
+// The fields are mapped to their schema column and then by the mapper, if any.
+// An invalid query returns an *Error, so it can be answered with 400 Bad Request.
+func (s *Strategy) Parse(cnv command.Converter, query string, mapper func(string) string) (*filter.Filter, error) {
+	node, err := s.schema.Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	columns := s.schema.Mapper()
+	if mapper == nil {
+		return Convert(node, cnv, columns)
+	}
+	return Convert(node, cnv, func(name string) string {
+		return mapper(columns(name))
+	})
+}
@@ -92,6 +92,12 @@ type Converter interface {
 	GetOrderByFilter(field string, mapper func(string) string) (*filter.Filter, error)
 }
 
+// Validator : optional interface of a converter that checks the whole filter,
+// the strategies call it once all the commands of a query are converted.
+type Validator interface {
+	Validate(*filter.Filter) error
+}
+
 // Op : an operator.
 type Op string
 
@@ -106,6 +112,7 @@ type Command struct {
 	property string
 	operator string
 	value    string
+	values   []interface{}
 }
 
 func init() {
@@ -149,11 +156,27 @@ func (k opKey) equals(s string) bool {
 	return s == string(operators[k])
 }
 
+// NewWithValues : returns a new Command with the values parsed to the type of the property,
+// one per comma separated value of IN.
+func NewWithValues(property, operator, value string, values ...interface{}) Command {
+	return Command{
+		property: property,
+		operator: operator,
+		value:    value,
+		values:   values,
+	}
+}
+
 // Value : returns the command value.
 func (c Command) Value() string {
 	return c.value
 }
 
+// Values : returns the typed command values, nil if the command only has the string value.
+func (c Command) Values() []interface{} {
+	return c.values
+}
+
 // Operator : returns the command operator.
 func (c Command) Operator() string {
 	return c.operator
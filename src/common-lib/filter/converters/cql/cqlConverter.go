@@ -0,0 +1,389 @@
+// Package cql comprises the convertor that can be used
+// to convert a Command to a Filter which consists of
+// a CQL query and a value as generated by the converter.
+// Cassandra can only filter on the key and indexed columns, so the converter
+// knows the columns of the table and rejects the operators a column does not support.
+// The commands are converted one by one, so the restrictions of the whole filter
+// are checked against the order of the key columns by Validate, which the strategies
+// call once the whole filter is converted.
+package cql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+// ColumnKind : the kind of a column a filter can be run on.
+type ColumnKind int
+
+const (
+	// RegularColumn : a column that cannot be filtered on.
+	RegularColumn ColumnKind = iota
+	// PartitionKey : a partition key column, supports = and IN.
+	PartitionKey
+	// ClusteringColumn : a clustering column, supports =, IN, range operators and ORDER BY.
+	ClusteringColumn
+	// IndexedColumn : a column with a secondary index, supports =.
+	IndexedColumn
+)
+
+const (
+	errOperatorNotFound    string = "no matching operator found for %v"
+	errFieldNotFound       string = "no matching field found for %v"
+	errOperatorNotAllowed  string = "operator %v is not supported on %v column %v"
+	errOperatorUnsupported string = "operator %v is not supported by CQL"
+	errUnexpectedCommand   string = "unexpected command received: %v"
+	errUnexpectedMapper    string = "unexpected mapper received: %v"
+
+	errPartitionKeyPartial   string = "partition key column %v is not restricted, the whole partition key must be restricted"
+	errClusteringNoPartition string = "clustering column %v cannot be restricted without the whole partition key"
+	errClusteringSkipped     string = "clustering column %v cannot be restricted, preceding clustering column %v is not restricted"
+	errClusteringAfterRange  string = "clustering column %v cannot be restricted, preceding clustering column %v is restricted by a range"
+	errOrderByNoPartition    string = "ORDER BY requires the whole partition key to be restricted"
+)
+
+const (
+	stmtPlaceholder = "%v"
+	cqlPlaceholder  = "?"
+	doubleQuote     = "\""
+	inPattern       = "(%v)"
+	queryTerminator = ";"
+	space           = " "
+	valueDelimiter  = ","
+	ascending       = "ASC"
+	descending      = "DESC"
+	orderByPrefix   = "ORDER"
+)
+
+// operators : map of command operators vs CQL operators.
+var operators = map[string]string{
+	string(command.And):     "AND",
+	string(command.Gt):      ">",
+	string(command.Ge):      ">=",
+	string(command.Lt):      "<",
+	string(command.Le):      "<=",
+	string(command.Eq):      "=",
+	string(command.In):      "IN",
+	string(command.LHS):     "",
+	string(command.RHS):     "",
+	string(command.Limit):   "LIMIT",
+	string(command.OrderBy): "ORDER BY",
+}
+
+// allowed : map of column kinds vs the operators they support.
+var allowed = map[ColumnKind][]command.Op{
+	PartitionKey:     {command.Eq, command.In},
+	ClusteringColumn: {command.Eq, command.In, command.Gt, command.Ge, command.Lt, command.Le},
+	IndexedColumn:    {command.Eq},
+}
+
+// equalities : the CQL operators restricting a column to single values, the other ones are ranges.
+var equalities = map[string]bool{
+	"=":  true,
+	"IN": true,
+}
+
+var kindNames = map[ColumnKind]string{
+	RegularColumn:    "regular",
+	PartitionKey:     "partition key",
+	ClusteringColumn: "clustering",
+	IndexedColumn:    "indexed",
+}
+
+// Table : the columns of a table that can be filtered on.
+type Table struct {
+	PartitionKey []string
+	Clustering   []string
+	Indexed      []string
+}
+
+// CQLconverter : Converts command to corresponding CQL syntax.
+// Parentheses are dropped because CQL has no OR, the filters are always ANDed.
+type CQLconverter struct {
+	table   Table
+	columns map[string]ColumnKind
+}
+
+// NewConverter : returns a CQL converter for the table.
+func NewConverter(t Table) *CQLconverter {
+	c := &CQLconverter{table: t, columns: make(map[string]ColumnKind)}
+	for _, col := range t.Indexed {
+		c.columns[col] = IndexedColumn
+	}
+	for _, col := range t.Clustering {
+		c.columns[col] = ClusteringColumn
+	}
+	for _, col := range t.PartitionKey {
+		c.columns[col] = PartitionKey
+	}
+	return c
+}
+
+// Kind : returns the kind of the column.
+func (s *CQLconverter) Kind(column string) ColumnKind {
+	return s.columns[column]
+}
+
+func (s *CQLconverter) getColumn(property string, op command.Op, mapper func(string) string) (string, error) {
+	column := mapper(property)
+	if column == "" {
+		//nolint:goerr113
+		return "", fmt.Errorf(errFieldNotFound, property)
+	}
+	kind := s.columns[column]
+	for _, o := range allowed[kind] {
+		if o == op {
+			return column, nil
+		}
+	}
+	//nolint:goerr113
+	return "", fmt.Errorf(errOperatorNotAllowed, op, kindNames[kind], column)
+}
+
+// Validate : checks the restrictions of a filter built by the converter against the key order of the table.
+// Either the whole partition key or none of it is restricted, a clustering column is restricted only
+// with the whole partition key and equalities (= or IN) on the preceding clustering columns,
+// so a range is allowed on the last restricted clustering column only. ORDER BY requires the whole partition key.
+func (s *CQLconverter) Validate(f *filter.Filter) error {
+	if f == nil {
+		return nil
+	}
+	equal := make(map[string]bool)
+	ranged := make(map[string]bool)
+	ordered := false
+	words := strings.Fields(f.GetQuery())
+	for i := 0; i+1 < len(words); i++ {
+		if words[i] == orderByPrefix {
+			ordered = true
+			continue
+		}
+		if _, ok := s.columns[words[i]]; !ok {
+			continue
+		}
+		switch op := words[i+1]; {
+		case equalities[op]:
+			equal[words[i]] = true
+		case op == operators[string(command.Gt)], op == operators[string(command.Ge)],
+			op == operators[string(command.Lt)], op == operators[string(command.Le)]:
+			ranged[words[i]] = true
+		}
+	}
+
+	var missing string
+	for _, col := range s.table.PartitionKey {
+		if !equal[col] && missing == "" {
+			missing = col
+		}
+	}
+	partition := len(s.table.PartitionKey) > 0 && missing == ""
+	for _, col := range s.table.PartitionKey {
+		if equal[col] && missing != "" {
+			//nolint:goerr113
+			return fmt.Errorf(errPartitionKeyPartial, missing)
+		}
+	}
+
+	var skipped, rangeOn string
+	for _, col := range s.table.Clustering {
+		if !equal[col] && !ranged[col] {
+			if skipped == "" {
+				skipped = col
+			}
+			continue
+		}
+		switch {
+		case !partition:
+			//nolint:goerr113
+			return fmt.Errorf(errClusteringNoPartition, col)
+		case skipped != "":
+			//nolint:goerr113
+			return fmt.Errorf(errClusteringSkipped, col, skipped)
+		case rangeOn != "":
+			//nolint:goerr113
+			return fmt.Errorf(errClusteringAfterRange, col, rangeOn)
+		}
+		if ranged[col] {
+			rangeOn = col
+		}
+	}
+
+	if ordered && !partition {
+		//nolint:goerr113
+		return fmt.Errorf(errOrderByNoPartition)
+	}
+	return nil
+}
+
+func getOperator(op string) (string, error) {
+	key, ok := operators[op]
+	if !ok {
+		//nolint:goerr113
+		return "", fmt.Errorf(errOperatorUnsupported, op)
+	}
+	return key, nil
+}
+
+func stripDoubleQuotes(val string) string {
+	if len(val) > 1 && strings.HasPrefix(val, doubleQuote) && strings.HasSuffix(val, doubleQuote) {
+		return val[1 : len(val)-1]
+	}
+	return val
+}
+
+// DoForCommandWithoutValue : A visitor Do method for a command that
+// does not have a value. IS NULL, IS NOT NULL and OR are not supported by CQL.
+func (s *CQLconverter) DoForCommandWithoutValue(c command.Command, mapper func(string) string) (*filter.Filter, error) {
+	if c.Value() != "" {
+		return nil, nil
+	}
+	key, err := getOperator(c.Operator())
+	if err != nil {
+		return nil, err
+	}
+	return filter.New(key), nil
+}
+
+// DoForCommandWithoutProperty : A visitor Do method for a command that
+// does not have a property. E.g. LIMIT, ORDER BY
+// Only a clustering column can be ordered by.
+func (s *CQLconverter) DoForCommandWithoutProperty(c command.Command, mapper func(string) string) (*filter.Filter, error) {
+	if c.Property() != "" {
+		//nolint:goerr113
+		return nil, fmt.Errorf(errUnexpectedCommand, c)
+	}
+	key, err := getOperator(c.Operator())
+	if err != nil {
+		return nil, err
+	}
+	if c.Operator() != string(command.OrderBy) {
+		return filter.New(key+space+stmtPlaceholder, c.Value()), nil
+	}
+	if mapper == nil {
+		//nolint:goerr113
+		return nil, fmt.Errorf(errUnexpectedMapper, nil)
+	}
+	fields := strings.Fields(c.Value())
+	if len(fields) == 0 {
+		//nolint:goerr113
+		return nil, fmt.Errorf(errFieldNotFound, c.Value())
+	}
+	column := mapper(fields[0])
+	if column == "" {
+		//nolint:goerr113
+		return nil, fmt.Errorf(errFieldNotFound, fields[0])
+	}
+	if kind := s.columns[column]; kind != ClusteringColumn {
+		//nolint:goerr113
+		return nil, fmt.Errorf(errOperatorNotAllowed, command.OrderBy, kindNames[kind], column)
+	}
+	key = key + space + column
+	if len(fields) > 1 {
+		order := strings.ToUpper(fields[1])
+		if order != descending {
+			order = ascending
+		}
+		key = key + space + order
+	}
+	return filter.New(key), nil
+}
+
+// DoForCommandWithValue : A visitor Do method for a command that
+// has a value.
+// This function validates the property and the operator against the
+// columns of the table and returns an error if Cassandra cannot run the filter.
+// The typed values of the command are bound when there are any, gocql cannot
+// marshal a string into a column of another type.
+func (s *CQLconverter) DoForCommandWithValue(c command.Command, mapper func(string) string) (*filter.Filter, error) {
+	operator, err := getOperator(c.Operator())
+	if err != nil {
+		return nil, err
+	}
+	column, err := s.getColumn(c.Property(), command.Op(c.Operator()), mapper)
+	if err != nil {
+		return nil, err
+	}
+	val := stripDoubleQuotes(c.Value())
+
+	if c.Operator() != string(command.In) {
+		return filter.New(column+space+operator+space+stmtPlaceholder, bindValues(c, []string{val})...), nil
+	}
+	tokens := strings.Split(val, valueDelimiter)
+	placeholders := make([]string, len(tokens))
+	for i := range tokens {
+		placeholders[i] = stmtPlaceholder
+	}
+	key := column + space + operator + space + fmt.Sprintf(inPattern, strings.Join(placeholders, valueDelimiter))
+	return filter.New(key, bindValues(c, tokens)...), nil
+}
+
+// bindValues returns the typed values of the command or the string tokens if the command has no typed value for each of them.
+func bindValues(c command.Command, tokens []string) []interface{} {
+	if values := c.Values(); len(values) == len(tokens) {
+		return values
+	}
+	vals := make([]interface{}, len(tokens))
+	for i, token := range tokens {
+		vals[i] = token
+	}
+	return vals
+}
+
+// AND : Allows you to AND together several filters
+// If you have filters f1, f2, and f3, this function produces CQL like "f1 AND f2 AND f3"
+func (s *CQLconverter) AND(filters ...*filter.Filter) *filter.Filter {
+	if len(filters) == 0 {
+		return filter.New("")
+	}
+	newFilter := *filters[0]
+	for _, f := range filters[1:] {
+		newFilter = newFilter.Add(filter.New(operators[string(command.And)]+space+f.GetQuery(), f.GetValues()...))
+	}
+	return &newFilter
+}
+
+// OR : CQL has no OR, so it always returns nil.
+func (s *CQLconverter) OR(filters ...*filter.Filter) *filter.Filter {
+	return nil
+}
+
+// GetLimitFilter : a useful method to quickly get a LIMIT filter
+func (s *CQLconverter) GetLimitFilter(limit int) (*filter.Filter, error) {
+	cmd := command.New("", string(command.Limit), strconv.Itoa(limit))
+	lf, err := s.DoForCommandWithoutProperty(cmd, nil)
+	if err == nil {
+		lf.ShouldAnd = false
+	}
+	return lf, err
+}
+
+// GetOrderByFilter : a useful method to quickly get an ORDER BY filter
+func (s *CQLconverter) GetOrderByFilter(field string, mapper func(string) string) (*filter.Filter, error) {
+	cmd := command.New("", string(command.OrderBy), field)
+	of, err := s.DoForCommandWithoutProperty(cmd, mapper)
+	if err == nil {
+		of.ShouldAnd = false
+	}
+	return of, err
+}
+
+// AppendFilterToWhereClause : appends the filter to CQL query where clause,
+// the values are bound with the ? placeholder.
+func AppendFilterToWhereClause(query string, f *filter.Filter, args []interface{}) (string, []interface{}) {
+	if f == nil {
+		return query, args
+	}
+	placeholders := make([]interface{}, len(f.GetValues()))
+	for i := range placeholders {
+		placeholders[i] = cqlPlaceholder
+	}
+	clause := strings.Join(strings.Fields(fmt.Sprintf(f.GetQuery(), placeholders...)), space)
+	query = strings.TrimSuffix(strings.TrimSpace(query), queryTerminator)
+	if f.ShouldAnd {
+		query = query + space + operators[string(command.And)]
+	}
+	return query + space + clause + queryTerminator, append(args, f.GetValues()...)
+}
@@ -0,0 +1,309 @@
+package cql
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/ast"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/web/filter/strategies/tokenize"
+)
+
+const (
+	filterPartnerID string = "partnerId"
+	filterCreatedAt string = "createdAt"
+	filterStatus    string = "status"
+	filterName      string = "name"
+	colPartnerID    string = "partner_id"
+	colCreatedAt    string = "created_at"
+	colStatus       string = "status"
+	colName         string = "name"
+)
+
+var testMap = map[string]string{
+	filterPartnerID: colPartnerID,
+	filterCreatedAt: colCreatedAt,
+	filterStatus:    colStatus,
+	filterName:      colName,
+}
+
+func mapper(key string) string {
+	return testMap[key]
+}
+
+func testConverter() *CQLconverter {
+	return NewConverter(Table{
+		PartitionKey: []string{colPartnerID},
+		Clustering:   []string{colCreatedAt},
+		Indexed:      []string{colStatus},
+	})
+}
+
+func TestCQLconverter_DoForCommandWithoutValue(t *testing.T) {
+	tests := []struct {
+		name    string
+		c       command.Command
+		want    *filter.Filter
+		wantErr bool
+	}{
+		{name: "And", c: command.New("", string(command.And), ""), want: filter.New("AND")},
+		{name: "Parenthesis", c: command.New("", string(command.LHS), ""), want: filter.New("")},
+		{name: "Or", c: command.New("", string(command.Or), ""), wantErr: true},
+		{name: "Null", c: command.New(filterStatus, string(command.Null), ""), wantErr: true},
+		{name: "With value", c: command.New(filterStatus, string(command.Eq), "New"), want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testConverter().DoForCommandWithoutValue(tt.c, mapper)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CQLconverter.DoForCommandWithoutValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CQLconverter.DoForCommandWithoutValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCQLconverter_DoForCommandWithValue(t *testing.T) {
+	date := time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		c       command.Command
+		want    *filter.Filter
+		wantErr bool
+	}{
+		{
+			name: "Partition key equal",
+			c:    command.New(filterPartnerID, string(command.Eq), "1000"),
+			want: filter.New("partner_id = %v", "1000"),
+		},
+		{
+			name: "Partition key in",
+			c:    command.New(filterPartnerID, string(command.In), "1000,2000"),
+			want: filter.New("partner_id IN (%v,%v)", "1000", "2000"),
+		},
+		{
+			name: "Clustering range",
+			c:    command.New(filterCreatedAt, string(command.Ge), "\"2021-01-02\""),
+			want: filter.New("created_at >= %v", "2021-01-02"),
+		},
+		{
+			name: "Indexed equal",
+			c:    command.New(filterStatus, string(command.Eq), "New"),
+			want: filter.New("status = %v", "New"),
+		},
+		{
+			name: "Typed partition key in",
+			c:    command.NewWithValues(filterPartnerID, string(command.In), "1000,2000", int64(1000), int64(2000)),
+			want: filter.New("partner_id IN (%v,%v)", int64(1000), int64(2000)),
+		},
+		{
+			name: "Typed clustering range",
+			c:    command.NewWithValues(filterCreatedAt, string(command.Ge), "\"2021-01-02\"", date),
+			want: filter.New("created_at >= %v", date),
+		},
+		{
+			name:    "Partition key range",
+			c:       command.New(filterPartnerID, string(command.Gt), "1000"),
+			wantErr: true,
+		},
+		{
+			name:    "Indexed in",
+			c:       command.New(filterStatus, string(command.In), "New,Closed"),
+			wantErr: true,
+		},
+		{
+			name:    "Regular column",
+			c:       command.New(filterName, string(command.Eq), "acme"),
+			wantErr: true,
+		},
+		{
+			name:    "Like",
+			c:       command.New(filterStatus, string(command.Like), "New"),
+			wantErr: true,
+		},
+		{
+			name:    "Not equal",
+			c:       command.New(filterCreatedAt, string(command.Not), "2021-01-02"),
+			wantErr: true,
+		},
+		{
+			name:    "Unknown field",
+			c:       command.New("unknown", string(command.Eq), "1"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testConverter().DoForCommandWithValue(tt.c, mapper)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CQLconverter.DoForCommandWithValue() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CQLconverter.DoForCommandWithValue() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCQLconverter_GetOrderByFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		want    string
+		wantErr bool
+	}{
+		{name: "Clustering", field: filterCreatedAt, want: "ORDER BY created_at"},
+		{name: "Clustering desc", field: filterCreatedAt + " desc", want: "ORDER BY created_at DESC"},
+		{name: "Partition key", field: filterPartnerID, wantErr: true},
+		{name: "Unknown field", field: "unknown", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := testConverter().GetOrderByFilter(tt.field, mapper)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CQLconverter.GetOrderByFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if got.GetQuery() != tt.want || got.ShouldAnd {
+				t.Errorf("CQLconverter.GetOrderByFilter() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCQLconverter_GetLimitFilter(t *testing.T) {
+	got, err := testConverter().GetLimitFilter(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filter.New("LIMIT %v", "10"); got.GetQuery() != want.GetQuery() || !reflect.DeepEqual(got.GetValues(), want.GetValues()) || got.ShouldAnd {
+		t.Errorf("CQLconverter.GetLimitFilter() = %v, want %v", got, want)
+	}
+}
+
+func TestCQLconverter_AND(t *testing.T) {
+	cnv := testConverter()
+	got := cnv.AND(filter.New("partner_id = %v", "1"), filter.New("status = %v", "New"))
+	want := filter.New("partner_id = %v AND status = %v", "1", "New")
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CQLconverter.AND() = %v, want %v", got, want)
+	}
+	if got := cnv.OR(filter.New("partner_id = %v", "1")); got != nil {
+		t.Errorf("CQLconverter.OR() = %v, want nil", got)
+	}
+}
+
+func TestAppendFilterToWhereClause(t *testing.T) {
+	f := filter.New("  partner_id = %v AND created_at > %v  ", "1", "2021-01-02")
+	gotQuery, gotArgs := AppendFilterToWhereClause("SELECT * FROM sites WHERE id = ?;", f, []interface{}{"a"})
+	wantQuery := "SELECT * FROM sites WHERE id = ? AND partner_id = ? AND created_at > ?;"
+	if gotQuery != wantQuery {
+		t.Errorf("AppendFilterToWhereClause() query = %q, want %q", gotQuery, wantQuery)
+	}
+	if want := []interface{}{"a", "1", "2021-01-02"}; !reflect.DeepEqual(gotArgs, want) {
+		t.Errorf("AppendFilterToWhereClause() args = %v, want %v", gotArgs, want)
+	}
+}
+
+func TestCQLconverter_Validate(t *testing.T) {
+	cnv := NewConverter(Table{
+		PartitionKey: []string{"partner_id", "region"},
+		Clustering:   []string{"created_at", "id"},
+		Indexed:      []string{colStatus},
+	})
+	tests := []struct {
+		name    string
+		query   string
+		wantErr bool
+	}{
+		{name: "Key", query: "partner_id = %v AND region IN (%v,%v) AND created_at = %v AND id > %v AND id <= %v"},
+		{name: "Clustering range", query: "partner_id = %v AND region = %v AND created_at >= %v ORDER BY created_at DESC"},
+		{name: "Indexed only", query: "status = %v LIMIT %v"},
+		{name: "Partial partition key", query: "partner_id = %v AND status = %v", wantErr: true},
+		{name: "Clustering without partition key", query: "created_at > %v", wantErr: true},
+		{name: "Skipped clustering column", query: "partner_id = %v AND region = %v AND id = %v", wantErr: true},
+		{name: "Clustering after range", query: "partner_id = %v AND region = %v AND created_at > %v AND id = %v", wantErr: true},
+		{name: "Order without partition key", query: "status = %v ORDER BY created_at", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cnv.Validate(filter.New(tt.query)); (err != nil) != tt.wantErr {
+				t.Errorf("CQLconverter.Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCQLconverter_tokenize(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		wantQuery string
+		wantErr   bool
+	}{
+		{
+			name:      "Success",
+			query:     "partnerId IN 1,2 AND (createdAt > 2021-01-02 AND status = New)",
+			wantQuery: "SELECT * FROM sites WHERE partner_id IN (?,?) AND created_at > ? AND status = ?;",
+		},
+		{
+			name:    "Or",
+			query:   "partnerId = 1 OR status = New",
+			wantErr: true,
+		},
+		{
+			name:    "Clustering without partition key",
+			query:   "createdAt > 2021-01-02 AND status = New",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cnv := testConverter()
+			f, err := tokenize.GetStrategy().Parse(cnv, tt.query, mapper)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Strategy.Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			f.ShouldAnd = false
+			if got, _ := AppendFilterToWhereClause("SELECT * FROM sites WHERE;", f, nil); got != tt.wantQuery {
+				t.Errorf("AppendFilterToWhereClause() = %q, want %q", got, tt.wantQuery)
+			}
+		})
+	}
+}
+
+func TestCQLconverter_ast(t *testing.T) {
+	schema, err := ast.NewSchema(
+		ast.NewField(filterPartnerID, int64(0)),
+		ast.NewField(filterCreatedAt, time.Time{}),
+		ast.NewField(filterStatus, ""),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := ast.NewStrategy(schema).Parse(testConverter(), `partnerId IN 1,2 AND createdAt > "2021-01-02" AND status = New`, mapper)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{int64(1), int64(2), time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), "New"}
+	if !reflect.DeepEqual(f.GetValues(), want) {
+		t.Errorf("Strategy.Parse() values = %#v, want %#v", f.GetValues(), want)
+	}
+
+	if _, err = ast.NewStrategy(schema).Parse(testConverter(), `createdAt > "2021-01-02" AND status = New`, mapper); err == nil {
+		t.Error("Strategy.Parse() expected an error for a clustering column without the partition key")
+	}
+}
@@ -0,0 +1,239 @@
+// Package memory applies a filter syntax tree of the ast package to Go values,
+// so the filter of a query can be run on the cached collections as well as in a database.
+// A filter field is resolved on a struct by the filter tag, the json tag or the name
+// of the struct field (case insensitive), a nested field is separated by a dot.
+package memory
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/ast"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+const (
+	filterTag        = "filter"
+	jsonTag          = "json"
+	fieldDelimiter   = "."
+	tagDelimiter     = ","
+	errFieldMissing  = "no matching field found for %v in %v"
+	errNotSlice      = "expected a slice, got %v"
+	errNotComparable = "field %v of type %v cannot be compared with %v"
+	errUnexpected    = "unexpected node %T"
+	errOperator      = "unexpected operator %v"
+	errOrder         = "%v is not comparable with %v"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Match : returns true if the value matches the filter.
+func Match(node ast.Node, value interface{}) (bool, error) {
+	return match(node, reflect.ValueOf(value))
+}
+
+// Select : returns a new slice of the same type with the items of the slice matching the filter.
+func Select(node ast.Node, slice interface{}) (interface{}, error) {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice {
+		//nolint:goerr113
+		return nil, fmt.Errorf(errNotSlice, v.Type())
+	}
+	result := reflect.MakeSlice(v.Type(), 0, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		ok, err := match(node, v.Index(i))
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			result = reflect.Append(result, v.Index(i))
+		}
+	}
+	return result.Interface(), nil
+}
+
+func match(node ast.Node, v reflect.Value) (bool, error) {
+	switch n := node.(type) {
+	case *ast.Logical:
+		left, err := match(n.Left, v)
+		if err != nil {
+			return false, err
+		}
+		if n.Op == command.And && !left || n.Op == command.Or && left {
+			return left, nil
+		}
+		return match(n.Right, v)
+	case *ast.Comparison:
+		return compare(n, v)
+	}
+	//nolint:goerr113
+	return false, fmt.Errorf(errUnexpected, node)
+}
+
+func compare(c *ast.Comparison, v reflect.Value) (bool, error) {
+	field, err := lookup(v, c.Field.Name)
+	if err != nil {
+		return false, err
+	}
+	isNull := !field.IsValid()
+	switch c.Op {
+	case command.Null:
+		return isNull, nil
+	case command.NonNull:
+		return !isNull, nil
+	}
+	if isNull {
+		return false, nil
+	}
+	for _, want := range c.Values {
+		ok, err := compareValue(c, field, reflect.ValueOf(want))
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func compareValue(c *ast.Comparison, field, want reflect.Value) (bool, error) {
+	if c.Op == command.Like {
+		if field.Kind() != reflect.String || want.Kind() != reflect.String {
+			//nolint:goerr113
+			return false, fmt.Errorf(errNotComparable, c.Field.Name, field.Type(), want.Type())
+		}
+		return strings.Contains(field.String(), want.String()), nil
+	}
+	cmp, err := order(field, want)
+	if err != nil {
+		//nolint:goerr113
+		return false, fmt.Errorf(errNotComparable, c.Field.Name, field.Type(), want.Type())
+	}
+	switch c.Op {
+	case command.Eq, command.In:
+		return cmp == 0, nil
+	case command.Not:
+		return cmp != 0, nil
+	case command.Gt:
+		return cmp > 0, nil
+	case command.Ge:
+		return cmp >= 0, nil
+	case command.Lt:
+		return cmp < 0, nil
+	case command.Le:
+		return cmp <= 0, nil
+	}
+	//nolint:goerr113
+	return false, fmt.Errorf(errOperator, c.Op)
+}
+
+// order returns -1, 0 or 1 as a is less than, equal to or greater than b.
+func order(a, b reflect.Value) (int, error) {
+	switch {
+	case a.Type() == timeType && b.Type() == timeType:
+		at, bt := a.Interface().(time.Time), b.Interface().(time.Time)
+		return boolInt(at.After(bt)) - boolInt(at.Before(bt)), nil
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(a.String(), b.String()), nil
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return boolInt(a.Bool()) - boolInt(b.Bool()), nil
+	case isInt(a) && isInt(b):
+		x, y := a.Int(), b.Int()
+		return boolInt(x > y) - boolInt(x < y), nil
+	case isUint(a) && isUint(b):
+		x, y := a.Uint(), b.Uint()
+		return boolInt(x > y) - boolInt(x < y), nil
+	case isNumber(a) && isNumber(b):
+		x, y := float(a), float(b)
+		return boolInt(x > y) - boolInt(x < y), nil
+	}
+	//nolint:goerr113
+	return 0, fmt.Errorf(errOrder, a.Type(), b.Type())
+}
+
+// lookup returns the value of the field or an invalid value if the field is nil.
+func lookup(v reflect.Value, name string) (reflect.Value, error) {
+	for _, part := range strings.Split(name, fieldDelimiter) {
+		v = indirect(v)
+		if !v.IsValid() {
+			return v, nil
+		}
+		if v.Kind() != reflect.Struct {
+			//nolint:goerr113
+			return reflect.Value{}, fmt.Errorf(errFieldMissing, name, v.Type())
+		}
+		f, ok := structField(v.Type(), part)
+		if !ok {
+			//nolint:goerr113
+			return reflect.Value{}, fmt.Errorf(errFieldMissing, name, v.Type())
+		}
+		v = v.FieldByIndex(f.Index)
+	}
+	return indirect(v), nil
+}
+
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for _, tag := range []string{filterTag, jsonTag} {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if f.PkgPath != "" {
+				continue
+			}
+			if tagName := strings.Split(f.Tag.Get(tag), tagDelimiter)[0]; tagName == name {
+				return f, true
+			}
+		}
+	}
+	f, ok := t.FieldByNameFunc(func(n string) bool {
+		return strings.EqualFold(n, name)
+	})
+	return f, ok && f.PkgPath == ""
+}
+
+// indirect dereferences the pointers and interfaces, it returns an invalid value for nil.
+func indirect(v reflect.Value) reflect.Value {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+func isInt(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return true
+	}
+	return false
+}
+
+func isUint(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return true
+	}
+	return false
+}
+
+func isNumber(v reflect.Value) bool {
+	return isInt(v) || isUint(v) || v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64
+}
+
+func float(v reflect.Value) float64 {
+	switch {
+	case isInt(v):
+		return float64(v.Int())
+	case isUint(v):
+		return float64(v.Uint())
+	}
+	return v.Float()
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/ast"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+type address struct {
+	Country string `json:"country"`
+}
+
+type site struct {
+	ID        int64      `json:"id"`
+	Name      string     `json:"name"`
+	Active    bool       `filter:"active" json:"isActive"`
+	Rating    float64    `json:"rating"`
+	CreatedAt time.Time  `json:"createdAt"`
+	DeletedAt *time.Time `json:"deletedAt"`
+	Address   *address   `json:"address"`
+}
+
+func testSchema(t *testing.T) *ast.Schema {
+	var deletedAt *time.Time
+	s, err := ast.NewSchema(
+		ast.NewField("id", 0),
+		ast.NewField("name", ""),
+		ast.NewField("active", false),
+		ast.NewField("rating", 0),
+		ast.NewField("createdAt", time.Time{}),
+		ast.NewField("deletedAt", deletedAt, command.Null, command.NonNull),
+		ast.NewField("address.country", ""),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func testSites() []site {
+	deleted := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+	return []site{
+		{ID: 1, Name: "acme north", Active: true, Rating: 4, CreatedAt: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC), Address: &address{Country: "India"}},
+		{ID: 2, Name: "acme south", Rating: 2.5, CreatedAt: time.Date(2021, 2, 1, 0, 0, 0, 0, time.UTC), DeletedAt: &deleted},
+		{ID: 3, Name: "globex", Active: true, Rating: 5, CreatedAt: time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC), Address: &address{Country: "France"}},
+	}
+}
+
+func TestSelect(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []int64
+	}{
+		{name: "equal", query: "id = 2", want: []int64{2}},
+		{name: "not equal", query: "id != 2", want: []int64{1, 3}},
+		{name: "in", query: "id IN 1,3,5", want: []int64{1, 3}},
+		{name: "like", query: "name : acme", want: []int64{1, 2}},
+		{name: "bool by filter tag", query: "active = false", want: []int64{2}},
+		{name: "int field with float value", query: "rating >= 4", want: []int64{1, 3}},
+		{name: "time", query: "createdAt < 2021-02-15", want: []int64{1, 2}},
+		{name: "is null", query: "deletedAt IS NULL", want: []int64{1, 3}},
+		{name: "is not null", query: "deletedAt IS NOT NULL", want: []int64{2}},
+		{name: "nested", query: `address.country = "France"`, want: []int64{3}},
+		{name: "nil nested", query: "address.country != France", want: []int64{1}},
+		{name: "and or", query: "(name : acme OR id = 3) AND active = true", want: []int64{1, 3}},
+		{name: "none", query: "id > 3", want: nil},
+	}
+	s := testSchema(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := s.Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Select(node, testSites())
+			if err != nil {
+				t.Errorf("Select() error = %v", err)
+				return
+			}
+			var ids []int64
+			for _, site := range got.([]site) {
+				ids = append(ids, site.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("Select() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	type other struct {
+		Name int
+	}
+	s := testSchema(t)
+	tests := []struct {
+		name    string
+		query   string
+		value   interface{}
+		want    bool
+		wantErr bool
+	}{
+		{name: "pointer", query: "id = 1", value: &testSites()[0], want: true},
+		{name: "missing field", query: "active = true", value: other{}, wantErr: true},
+		{name: "type mismatch", query: "name = abc", value: other{Name: 1}, wantErr: true},
+		{name: "not a struct", query: "id = 1", value: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := s.Parse(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := Match(node, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Match() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSelect_notSlice(t *testing.T) {
+	node, err := testSchema(t).Parse("id = 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Select(node, testSites()[0]); err == nil {
+		t.Errorf("Select() error = nil, want error")
+	}
+}
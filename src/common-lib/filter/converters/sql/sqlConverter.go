@@ -176,21 +176,14 @@ func (s *SQLconverter) DoForCommandWithoutProperty(c command.Command, mapper fun
 				return nil, fmt.Errorf(errUnexpectedMapper, nil)
 			}
 
-			sortField, sortOrder := getSortFieldAndOrderFromCommandValue(c.Value())
-			//count returned by getField is ignored for now
-			//we can consider it if we have a use case where we would want to pass
-			// comma delimited fields to DoForCommandWithoutProperty
-			values, _ := s.getField(sortField, mapper)
-			if values == "" {
-				//nolint:goerr113
-				return nil, fmt.Errorf(errFieldNotFound, sortField)
-			}
-
 			// When sorting in postgres, you must interpolate the sorting column name directly into the SQL.
 			// You simply cannot use a positional argument in place of the column's name.
 			// See: https://www.postgresql.org/message-id/1421875206968-5834948.post@n5.nabble.com
 
-			key = addValuesAndSortOrderToKey(key, values, sortOrder)
+			key, err := s.addOrderByColumns(key, c.Value(), mapper)
+			if err != nil {
+				return nil, err
+			}
 			return filter.New(key), nil
 		} else {
 			key = key + " %v"
@@ -201,6 +194,44 @@ func (s *SQLconverter) DoForCommandWithoutProperty(c command.Command, mapper fun
 	return nil, fmt.Errorf(errUnexpectedCommand, c)
 }
 
+// addOrderByColumns : adds the sort columns of the ORDER BY command value to the key. The value is either
+// comma delimited fields followed by a sort order for all of them, e.g. "name,id desc",
+// or comma delimited fields each followed by its own sort order, e.g. "status asc,updatedAt desc,id asc".
+func (s *SQLconverter) addOrderByColumns(key string, value string, mapper func(string) string) (string, error) {
+	sortFields := []string{value}
+	if hasSortOrderPerField(value) {
+		sortFields = strings.Split(value, multiColumnDelimiter)
+	}
+
+	columns := make([]string, 0, len(sortFields))
+	for _, sortField := range sortFields {
+		field, sortOrder := getSortFieldAndOrderFromCommandValue(strings.TrimSpace(sortField))
+		//count returned by getField is ignored for now
+		//we can consider it if we have a use case where we would want to pass
+		// comma delimited fields to DoForCommandWithoutProperty
+		values, _ := s.getField(field, mapper)
+		if values == "" {
+			//nolint:goerr113
+			return "", fmt.Errorf(errFieldNotFound, field)
+		}
+		columns = append(columns, strings.TrimPrefix(addValuesAndSortOrderToKey("", values, sortOrder), space))
+	}
+
+	return key + space + strings.Join(columns, multiColumnDelimiter+space), nil
+}
+
+// hasSortOrderPerField : tells if a field other than the last one of the ORDER BY command value has a sort order
+func hasSortOrderPerField(value string) bool {
+	sortFields := strings.Split(value, multiColumnDelimiter)
+	for _, sortField := range sortFields[:len(sortFields)-1] {
+		if len(strings.Fields(sortField)) > 1 {
+			return true
+		}
+	}
+
+	return false
+}
+
 // getSortFieldAndOrderFromCommandValue : Separates the sortBy value into a field (id, name, etc.) and order (asc, desc)
 func getSortFieldAndOrderFromCommandValue(s string) (string, string) {
 	sortFieldsAndSortOrder := strings.Split(s, " ")
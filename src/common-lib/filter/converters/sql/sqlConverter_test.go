@@ -430,6 +430,26 @@ func TestSQLconverter_GetOrderByFilter(t *testing.T) {
 			want:    filter.New("order by " + colCompanyName + " asc, " + filterCompanyUniqueField + " asc"),
 			wantErr: false,
 		},
+		{
+			name: "success_with_sortOrder_per_field",
+			s:    &SQLconverter{},
+			args: args{
+				field:  filterCompanyName + " desc, " + filterPartnerID + "," + filterCompanyUniqueField + " asc",
+				mapper: mapper,
+			},
+			want:    filter.New("order by " + colCompanyName + " desc, " + colPartnerID + ", " + filterCompanyUniqueField + " asc"),
+			wantErr: false,
+		},
+		{
+			name: "err_when_providing_invalid_field_with_sortOrder_per_field",
+			s:    &SQLconverter{},
+			args: args{
+				field:  filterCompanyName + " desc,unexpectedField asc",
+				mapper: mapper,
+			},
+			want:    nil,
+			wantErr: true,
+		},
 		{
 			name: "err_when_providing_invalid_field",
 			s:    &SQLconverter{},
@@ -54,6 +54,7 @@ require (
 	github.com/kardianos/service v1.0.1-0.20190326161025-0e5bec1b9eec
 	github.com/kennygrant/sanitize v1.2.4
 	github.com/lib/pq v1.10.0
+	github.com/linkedin/goavro/v2 v2.11.1
 	github.com/maraino/go-mock v0.0.0-20180321183845-4c74c434cd3a
 	github.com/mattn/go-ieproxy v0.0.1
 	github.com/patrickmn/go-cache v2.1.0+incompatible
@@ -61,7 +62,6 @@ require (
 	github.com/pkg/errors v0.9.1
 	github.com/pkg/profile v1.6.0
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
-	github.com/robfig/cron v1.0.1-0.20170526150127-736158dc09e1
 	github.com/samuel/go-zookeeper v0.0.0-20201211165307-7117e9ea2414
 	github.com/satori/go.uuid v1.2.0 // indirect
 	github.com/scylladb/gocqlx v0.0.0-20180515120735-5526e6046474
@@ -71,9 +71,10 @@ require (
 	github.com/stretchr/testify v1.7.0
 	github.com/yuin/gopher-lua v0.0.0-20210529063254-f4c35e4016d9 // indirect
 	gitlab.kksharmadevdev.com/platform/platform-api-model v0.0.0-20220311122951-55cf5d733d37
+	go.etcd.io/etcd/client/v3 v3.5.0
 	go.uber.org/atomic v1.9.0
 	go.uber.org/multierr v1.7.0 // indirect
-	go.uber.org/zap v1.16.0
+	go.uber.org/zap v1.17.0
 	golang.org/x/net v0.0.0-20211209124913-491a49abca63
 	golang.org/x/sys v0.0.0-20210806184541-e5e7981a1069
 	google.golang.org/genproto v0.0.0-20210726143408-b02e89920bf0 // indirect
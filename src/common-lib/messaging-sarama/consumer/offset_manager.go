@@ -76,7 +76,7 @@ func (o *onMessageCompletion) beforeHandler(transaction, topic string, partition
 		o.mutex.Unlock()
 	}
 	value.mutex.Lock()
-	value.setOffset(append(value.offset, &offsetStatus{offset: offset, status: inProgress}))
+	value.offset = append(value.offset, &offsetStatus{offset: offset, status: inProgress})
 	value.mutex.Unlock()
 }
 
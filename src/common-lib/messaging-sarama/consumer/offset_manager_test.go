@@ -2,6 +2,7 @@ package consumer
 
 import (
 	"testing"
+	"time"
 )
 
 func Test_getCommitStrategy(t *testing.T) {
@@ -39,3 +40,28 @@ func Test_getCommitStrategy(t *testing.T) {
 		t.Errorf("getCommitStrategy() = %v, want %v", got, "Panic")
 	})
 }
+
+func Test_onMessageCompletion_handlers(t *testing.T) {
+	var marked []int64
+	strategy := getCommitStrategy(Config{CommitMode: OnMessageCompletion}, func(topic string, partition int32, offset int64) {
+		marked = append(marked, offset)
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		strategy.beforeHandler("transaction", "topic", 0, 0)
+		strategy.beforeHandler("transaction", "topic", 0, 1)
+		strategy.afterHandler("transaction", "topic", 0, 0)
+		strategy.afterHandler("transaction", "topic", 0, 1)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("onMessageCompletion handlers did not return, deadlock")
+	}
+	if len(marked) != 2 || marked[0] != 0 || marked[1] != 1 {
+		t.Errorf("onMessageCompletion marked offsets = %v, want %v", marked, []int64{0, 1})
+	}
+}
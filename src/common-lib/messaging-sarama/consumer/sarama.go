@@ -70,7 +70,7 @@ func New(cfg Config) (Service, error) {
 		config.Group.Mode = cluster.ConsumerModePartitions
 	}
 
-	consumer, err := cluster.NewConsumer(cfg.Address, cfg.Group, cfg.Topics, config)
+	consumer, err := NewClusterConsumer(cfg.Address, cfg.Group, cfg.Topics, config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create consumer : %+v", err)
 	}
@@ -92,9 +92,35 @@ func New(cfg Config) (Service, error) {
 	return sc, nil
 }
 
+// ClusterConsumer describes the sarama-cluster consumer used by the consumer, implemented by *cluster.Consumer
+type ClusterConsumer interface {
+	// Messages returns the read channel for the messages that are returned by the broker
+	Messages() <-chan *sarama.ConsumerMessage
+	// Partitions returns the read channels for individual partitions of this broker, in ConsumerModePartitions
+	Partitions() <-chan cluster.PartitionConsumer
+	// Errors returns a read channel of errors that occur during offset management
+	Errors() <-chan error
+	// Notifications returns a channel of Notifications that occur during consumer rebalancing
+	Notifications() <-chan *cluster.Notification
+	// MarkPartitionOffset marks an offset of the provided topic/partition as processed
+	MarkPartitionOffset(topic string, partition int32, offset int64, metadata string)
+	// Close safely closes the consumer and releases all resources
+	Close() error
+}
+
+// NewClusterConsumer creates the sarama-cluster consumer of the consumer.
+// It can be replaced to run the consumer against another implementation, e.g. the in-memory cluster of messaging/kafkatest.
+var NewClusterConsumer = func(addrs []string, groupID string, topics []string, config *cluster.Config) (ClusterConsumer, error) {
+	consumer, err := cluster.NewConsumer(addrs, groupID, topics, config)
+	if err != nil {
+		return nil, err
+	}
+	return consumer, nil
+}
+
 type saramaConsumer struct {
 	cfg              Config
-	consumer         *cluster.Consumer
+	consumer         ClusterConsumer
 	pool             *workerPool
 	commitStrategy   commitStrategy
 	consumerStrategy consumerStrategy
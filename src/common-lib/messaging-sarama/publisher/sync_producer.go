@@ -10,6 +10,10 @@ import (
 	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/circuit"
 )
 
+// NewSaramaSyncProducer - creates the sarama sync producer of the publisher.
+// It can be replaced to run the publisher against another implementation, e.g. the in-memory cluster of messaging/kafkatest.
+var NewSaramaSyncProducer = sarama.NewSyncProducer
+
 // SyncProducer - publish messages using Kafka Sync producer
 type syncProducer struct {
 	cfg          *Config
@@ -26,7 +30,7 @@ func (s *syncProducer) syncProducer(transaction string) (sarama.SyncProducer, er
 		producerMutex.Lock()
 		defer producerMutex.Unlock()
 		if s.producer == nil {
-			prod, err := sarama.NewSyncProducer(s.cfg.Address, GetConfig(s.producerType, s.cfg))
+			prod, err := NewSaramaSyncProducer(s.cfg.Address, GetConfig(s.producerType, s.cfg))
 			if err != nil {
 				Logger().Error(transaction, "sync.producer.creation.failed", "Error in creating %s Kafka sync producer %v", s.producerType, err)
 				return nil, err
@@ -147,7 +151,7 @@ func (s *syncProducer) publish(ctx context.Context, transaction string, messages
 // reconnect - reconnect to sync producer
 func (s *syncProducer) reconnect(transaction string) error {
 	s.existing = s.producer
-	prod, err := sarama.NewSyncProducer(s.cfg.Address, GetConfig(s.producerType, s.cfg))
+	prod, err := NewSaramaSyncProducer(s.cfg.Address, GetConfig(s.producerType, s.cfg))
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,264 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BatchError is returned by a BatchMessageHandler to report the messages of the batch which failed.
+// Messages which are not in Failed are considered as handled successfully.
+type BatchError struct {
+	// Failed: errors of the failed messages by their index in the batch
+	Failed map[int]error
+}
+
+// NewBatchError creates an empty BatchError
+func NewBatchError() *BatchError {
+	return &BatchError{Failed: make(map[int]error)}
+}
+
+// Add reports the failure of the message at the index in the batch
+func (e *BatchError) Add(index int, err error) {
+	if e.Failed == nil {
+		e.Failed = make(map[int]error)
+	}
+	e.Failed[index] = err
+}
+
+// Error so that BatchError implements error interface.
+func (e *BatchError) Error() string {
+	indexes := make([]int, 0, len(e.Failed))
+	for i := range e.Failed {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	errs := make([]string, 0, len(indexes))
+	for _, i := range indexes {
+		errs = append(errs, fmt.Sprintf("message %d: %v", i, e.Failed[i]))
+	}
+	return fmt.Sprintf("%d messages of the batch failed: %s", len(indexes), strings.Join(errs, "; "))
+}
+
+// batcher collects messages into batches which are handled when they are full or when timeout passes after their first message.
+// Batches are handled one at a time and in the order they were collected.
+type batcher struct {
+	size    int
+	timeout time.Duration
+	handle  func([]*Message)
+
+	mx         sync.Mutex
+	handlingMx sync.Mutex
+	batch      []*Message
+	timer      *time.Timer
+	generation int // incremented on each flush so that the timer of a flushed batch does not flush the next one
+}
+
+func newBatcher(size int, timeout time.Duration, handle func([]*Message)) *batcher {
+	return &batcher{
+		size:    size,
+		timeout: timeout,
+		handle:  handle,
+	}
+}
+
+func (b *batcher) add(message *Message) {
+	b.mx.Lock()
+	b.batch = append(b.batch, message)
+	if len(b.batch) >= b.size {
+		b.flushLocked()
+		return
+	}
+	if len(b.batch) == 1 {
+		generation := b.generation
+		b.timer = time.AfterFunc(b.timeout, func() {
+			b.mx.Lock()
+			if b.generation != generation {
+				b.mx.Unlock()
+				return
+			}
+			b.flushLocked()
+		})
+	}
+	b.mx.Unlock()
+}
+
+// stop handles the pending batch if flush is true, otherwise the pending batch is dropped
+func (b *batcher) stop(flush bool) {
+	b.mx.Lock()
+	if flush && len(b.batch) > 0 {
+		b.flushLocked()
+		return
+	}
+	b.reset()
+	b.mx.Unlock()
+}
+
+// flushLocked handles the pending batch; it should be called with mx locked and unlocks it.
+// handlingMx is locked before unlocking mx so the batches are handled in order.
+func (b *batcher) flushLocked() {
+	batch := b.batch
+	b.reset()
+	b.handlingMx.Lock()
+	defer b.handlingMx.Unlock()
+	b.mx.Unlock()
+	b.handle(batch)
+}
+
+func (b *batcher) reset() {
+	b.batch = nil
+	b.generation++
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+}
+
+// messageProcessor returns the function processing the messages pulled by the consumer strategy
+func (c *KafkaConsumer) messageProcessor() func(*Message) {
+	if c.config != nil && c.config.BatchMessageHandler != nil {
+		return c.addToBatch
+	}
+	return c.process
+}
+
+func (c *KafkaConsumer) addToBatch(message *Message) {
+	transactionID := message.GetTransactionID()
+	c.commitStrategy.onPull(transactionID, message.Topic, message.Partition, message.Offset)
+	c.commitStrategy.beforeHandler(transactionID, message.Topic, message.Partition, message.Offset)
+	c.batcherFor(message).add(message)
+}
+
+// batcherFor returns the batcher of the message partition in PullOrdered mode, otherwise the batcher shared by all partitions
+func (c *KafkaConsumer) batcherFor(message *Message) *batcher {
+	var key partition
+	if c.config.ConsumerMode == PullOrdered {
+		key = partition{topic: message.Topic, partition: message.Partition}
+	}
+
+	c.batchMx.Lock()
+	defer c.batchMx.Unlock()
+	if c.batchers == nil {
+		c.batchers = make(map[partition]*batcher)
+	}
+	b, ok := c.batchers[key]
+	if !ok {
+		b = newBatcher(c.config.BatchSize, c.config.BatchTimeout, c.processBatch)
+		c.batchers[key] = b
+	}
+	return b
+}
+
+// stopBatchers handles (flush) or drops the pending batches, e.g. when partitions are revoked or the consumer is closed
+func (c *KafkaConsumer) stopBatchers(flush bool) {
+	c.batchMx.Lock()
+	batchers := c.batchers
+	c.batchers = nil
+	c.batchMx.Unlock()
+
+	for _, b := range batchers {
+		b.stop(flush)
+	}
+}
+
+// processBatch handles the batch, retries the failed messages and marks the offsets of the batch once it is done
+func (c *KafkaConsumer) processBatch(messages []*Message) {
+	attempts := make([]int64, len(messages))
+	errs := make([]error, len(messages))
+	pending := make([]int, len(messages))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	for try := 0; len(pending) > 0; try++ {
+		if try > 0 {
+			time.Sleep(c.config.RetryDelay)
+		}
+		batch := make([]Message, len(pending))
+		for j, i := range pending {
+			batch[j] = *messages[i]
+			attempts[i]++
+		}
+
+		failed := c.handleBatch(batch)
+		var retry []int
+		for j, i := range pending {
+			errs[i] = failed[j]
+			if errs[i] != nil && attempts[i] <= c.maxRetries(messages[i]) {
+				retry = append(retry, i)
+			}
+		}
+		pending = retry
+	}
+
+	for i, message := range messages {
+		err := errs[i]
+		if err != nil {
+			err = c.routeFailure(message, err, attempts[i])
+		}
+		if !c.keepOffset(err) {
+			c.commitStrategy.afterHandler(message.GetTransactionID(), message.Topic, message.Partition, message.Offset)
+		}
+		if err != nil {
+			c.invokeErrorHandler(err, message)
+		}
+	}
+}
+
+// maxRetries returns the number of retries of a failed message, a message on a retry topic gets a single attempt
+func (c *KafkaConsumer) maxRetries(message *Message) int64 {
+	if c.config.retryTier(message.Topic) >= 0 {
+		return 0
+	}
+	return c.config.RetryCount
+}
+
+// handleBatch invokes the handler and returns the errors of the failed messages by their index in the batch
+func (c *KafkaConsumer) handleBatch(batch []Message) map[int]error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.invokeBatchHandler(ctx, batch)
+		close(errc)
+	}()
+
+	var err error
+	select {
+	case <-ctx.Done():
+		// the handler may not have completed, the batch is failed rather than marked as handled
+		c.debugf("Batch of %d messages from Topic: %s at %d/%d ==> %v\n", len(batch), batch[0].Topic, batch[0].Partition, batch[0].Offset, ctx.Err())
+		err = ctx.Err()
+	case err = <-errc:
+	}
+	if err == nil {
+		return nil
+	}
+
+	var batchErr *BatchError
+	if errors.As(err, &batchErr) {
+		return batchErr.Failed
+	}
+	failed := make(map[int]error, len(batch))
+	for i := range batch {
+		failed[i] = err
+	}
+	return failed
+}
+
+// invokeBatchHandler returns the panic of the handler as the error of the whole batch, it is reported with the failed messages
+func (c *KafkaConsumer) invokeBatchHandler(ctx context.Context, batch []Message) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invokeBatchHandler.Panic: While processing %v, trace : %s", r, string(debug.Stack()))
+		}
+	}()
+	return c.config.BatchMessageHandler(ctx, batch)
+}
@@ -0,0 +1,327 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+type batchRecorder struct {
+	mx      sync.Mutex
+	batches [][]int64
+	done    chan struct{}
+}
+
+func newBatchRecorder() *batchRecorder {
+	return &batchRecorder{done: make(chan struct{}, 100)}
+}
+
+func (r *batchRecorder) handle(messages []*Message) {
+	offsets := make([]int64, 0, len(messages))
+	for _, m := range messages {
+		offsets = append(offsets, m.Offset)
+	}
+	r.mx.Lock()
+	r.batches = append(r.batches, offsets)
+	r.mx.Unlock()
+	r.done <- struct{}{}
+}
+
+func (r *batchRecorder) get() [][]int64 {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return r.batches
+}
+
+func TestBatcher(t *testing.T) {
+	tests := []struct {
+		name    string
+		size    int
+		timeout time.Duration
+		offsets []int64
+		stop    func(b *batcher)
+		want    [][]int64
+	}{
+		{
+			name:    "Flushed when full",
+			size:    2,
+			timeout: time.Hour,
+			offsets: []int64{1, 2, 3, 4},
+			want:    [][]int64{{1, 2}, {3, 4}},
+		},
+		{
+			name:    "Flushed after timeout",
+			size:    10,
+			timeout: 10 * time.Millisecond,
+			offsets: []int64{1, 2, 3},
+			want:    [][]int64{{1, 2, 3}},
+		},
+		{
+			name:    "Pending batch flushed on stop",
+			size:    2,
+			timeout: time.Hour,
+			offsets: []int64{1, 2, 3},
+			stop:    func(b *batcher) { b.stop(true) },
+			want:    [][]int64{{1, 2}, {3}},
+		},
+		{
+			name:    "Pending batch dropped on stop",
+			size:    2,
+			timeout: 10 * time.Millisecond,
+			offsets: []int64{1, 2, 3},
+			stop:    func(b *batcher) { b.stop(false) },
+			want:    [][]int64{{1, 2}},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			r := newBatchRecorder()
+			b := newBatcher(tt.size, tt.timeout, r.handle)
+			for _, offset := range tt.offsets {
+				b.add(&Message{Offset: offset})
+			}
+			if tt.stop != nil {
+				tt.stop(b)
+			}
+			for range tt.want {
+				select {
+				case <-r.done:
+				case <-time.After(time.Second):
+					t.Fatalf("batches not handled, got %v", r.get())
+				}
+			}
+			if tt.timeout < time.Second {
+				// the timer of a handled or dropped batch should not handle it again
+				time.Sleep(3 * tt.timeout)
+			}
+			require.Equal(t, tt.want, r.get())
+		})
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	err := NewBatchError()
+	err.Add(3, errors.New("three"))
+	err.Add(1, errors.New("one"))
+	require.Equal(t, "2 messages of the batch failed: message 1: one; message 3: three", err.Error())
+
+	var wrapped error = err
+	var batchErr *BatchError
+	require.True(t, errors.As(wrapped, &batchErr))
+}
+
+func TestKafkaConsumer_processBatch(t *testing.T) {
+	oops := errors.New("oops")
+	tests := []struct {
+		name        string
+		retryCount  int64
+		timeout     time.Duration
+		handler     func(calls int) error
+		wantBatches [][]int64 // offsets of the batches passed to the handler
+		wantErrors  []int64   // offsets reported to the ErrorHandler
+		wantErr     string    // error reported to the ErrorHandler, oops by default
+	}{
+		{
+			name:        "Success",
+			handler:     func(calls int) error { return nil },
+			wantBatches: [][]int64{{10, 11, 12}},
+		},
+		{
+			name:        "Whole batch failed",
+			retryCount:  1,
+			handler:     func(calls int) error { return oops },
+			wantBatches: [][]int64{{10, 11, 12}, {10, 11, 12}},
+			wantErrors:  []int64{10, 11, 12},
+		},
+		{
+			name:       "Partial failure retried",
+			retryCount: 2,
+			handler: func(calls int) error {
+				err := NewBatchError()
+				switch calls {
+				case 1:
+					err.Add(1, oops)
+					err.Add(2, oops)
+				case 2:
+					err.Add(1, oops)
+				default:
+					return &BatchError{Failed: map[int]error{0: oops}}
+				}
+				return err
+			},
+			wantBatches: [][]int64{{10, 11, 12}, {11, 12}, {12}},
+			wantErrors:  []int64{12},
+		},
+		{
+			name:        "Timed out",
+			timeout:     20 * time.Millisecond,
+			handler:     func(calls int) error { time.Sleep(100 * time.Millisecond); return nil },
+			wantBatches: [][]int64{{10, 11, 12}},
+			wantErrors:  []int64{10, 11, 12},
+			wantErr:     context.DeadlineExceeded.Error(),
+		},
+		{
+			name:        "Panic",
+			handler:     func(calls int) error { panic("oops") },
+			wantBatches: [][]int64{{10, 11, 12}},
+			wantErrors:  []int64{10, 11, 12},
+			wantErr:     "invokeBatchHandler.Panic",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			cs := NewMockcommitStrategy(ctrl)
+			for _, offset := range []int64{10, 11, 12} {
+				cs.EXPECT().afterHandler(gomock.Any(), "topic", int32(0), offset).Times(1)
+			}
+
+			timeout := tt.timeout
+			if timeout == 0 {
+				timeout = 5 * time.Second
+			}
+			var mx sync.Mutex
+			var batches [][]int64
+			var reported []int64
+			c := &KafkaConsumer{
+				config: &Config{
+					RetryCount:           tt.retryCount,
+					Timeout:              timeout,
+					ErrorHandlingTimeout: time.Second,
+					BatchMessageHandler: func(ctx context.Context, batch []Message) error {
+						offsets := make([]int64, 0, len(batch))
+						for _, m := range batch {
+							offsets = append(offsets, m.Offset)
+						}
+						mx.Lock()
+						batches = append(batches, offsets)
+						calls := len(batches)
+						mx.Unlock()
+						return tt.handler(calls)
+					},
+					ErrorHandler: func(ctx context.Context, err error, message *Message) {
+						mx.Lock()
+						defer mx.Unlock()
+						if tt.wantErr == "" && !errors.Is(err, oops) || !strings.Contains(err.Error(), tt.wantErr) {
+							t.Errorf("unexpected error %v", err)
+						}
+						reported = append(reported, message.Offset)
+					},
+				},
+				commitStrategy: cs,
+			}
+			c.processBatch([]*Message{
+				{Topic: "topic", Offset: 10, transactionID: "1"},
+				{Topic: "topic", Offset: 11, transactionID: "1"},
+				{Topic: "topic", Offset: 12, transactionID: "1"},
+			})
+
+			mx.Lock()
+			defer mx.Unlock()
+			require.Equal(t, tt.wantBatches, batches)
+			require.Equal(t, tt.wantErrors, reported)
+		})
+	}
+}
+
+func TestKafkaConsumer_addToBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cs := NewMockcommitStrategy(ctrl)
+	cs.EXPECT().onPull(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+	cs.EXPECT().beforeHandler(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+	cs.EXPECT().afterHandler(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(4)
+
+	var mx sync.Mutex
+	var batches [][]Message
+	c := &KafkaConsumer{
+		config: &Config{
+			ConsumerMode: PullOrdered,
+			Timeout:      5 * time.Second,
+			BatchSize:    2,
+			BatchTimeout: time.Hour,
+			BatchMessageHandler: func(ctx context.Context, batch []Message) error {
+				mx.Lock()
+				defer mx.Unlock()
+				batches = append(batches, batch)
+				return nil
+			},
+		},
+		commitStrategy: cs,
+	}
+	process := c.messageProcessor()
+	process(&Message{Topic: "topic", Partition: 0, Offset: 1, transactionID: "1"})
+	process(&Message{Topic: "topic", Partition: 1, Offset: 1, transactionID: "1"})
+	process(&Message{Topic: "topic", Partition: 0, Offset: 2, transactionID: "1"})
+	process(&Message{Topic: "topic", Partition: 1, Offset: 2, transactionID: "1"})
+
+	mx.Lock()
+	defer mx.Unlock()
+	require.Len(t, batches, 2, "batches are collected per partition in PullOrdered mode")
+	for _, batch := range batches {
+		require.Len(t, batch, 2)
+		require.Equal(t, batch[0].Partition, batch[1].Partition)
+	}
+}
+
+func TestKafkaConsumer_handleRevokedPartitions_batch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	topic := "topic"
+	var calls []string
+	kc := NewMockconsumer(ctrl)
+	kc.EXPECT().StoreOffsets(gomock.Any()).DoAndReturn(func(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+		for _, tp := range offsets {
+			calls = append(calls, fmt.Sprintf("store %d", tp.Offset))
+		}
+		return offsets, nil
+	}).AnyTimes()
+	kc.EXPECT().Unassign().DoAndReturn(func() error {
+		calls = append(calls, "unassign")
+		return nil
+	}).Times(1)
+
+	strategy := NewMockconsumerStrategy(ctrl)
+	strategy.EXPECT().handleRevokedPartitions(gomock.Any()).Times(1)
+
+	c := &KafkaConsumer{
+		config: &Config{
+			CommitMode:   OnMessageCompletion,
+			Timeout:      5 * time.Second,
+			BatchSize:    10,
+			BatchTimeout: time.Hour,
+			BatchMessageHandler: func(ctx context.Context, batch []Message) error {
+				return nil
+			},
+		},
+		consumer:         kc,
+		consumerStrategy: strategy,
+		partMx:           &sync.Mutex{},
+		pausedPartitions: make(map[partition]kafka.TopicPartition),
+		retryWaits:       make(map[partition]retryWait),
+	}
+	c.commitStrategy = getCommitStrategy(c.config, c.MarkOffset)
+	process := c.messageProcessor()
+	process(&Message{Topic: topic, Partition: 0, Offset: 1, transactionID: "1"})
+	process(&Message{Topic: topic, Partition: 0, Offset: 2, transactionID: "1"})
+
+	c.handleRevokedPartitions(kafka.RevokedPartitions{Partitions: []kafka.TopicPartition{{Topic: &topic, Partition: 0}}})
+
+	require.NotEmpty(t, calls)
+	require.Equal(t, "unassign", calls[len(calls)-1], "the offsets of the pending batch should be stored before the partitions are unassigned")
+	require.Contains(t, calls, "store 3")
+}
@@ -3,6 +3,8 @@ package consumer
 import (
 	"context"
 	"time"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
 )
 
 type commitMode int
@@ -45,13 +47,28 @@ type Config struct {
 	ErrorHandlingTimeout time.Duration
 
 	// MessageHandler: This will get used as a callback function for message handling (must be thread safe)
-	// Incompatible with PausableMessageHandler. There must be either MessageHandler or PausableMessageHandler provided to the Config. NOT both!
+	// Incompatible with PausableMessageHandler and BatchMessageHandler. There must be exactly one of them provided to the Config!
 	MessageHandler func(context.Context, Message) error
 
 	// PausableMessageHandler: This will get used as a callback function for message handling (must be thread safe) with access to the Pause/Resume functionality.
-	// Incompatible with MessageHandler. There must be either MessageHandler or PausableMessageHandler provided to the Config. NOT both!
+	// Incompatible with MessageHandler and BatchMessageHandler. There must be exactly one of them provided to the Config!
 	PausableMessageHandler func(context.Context, Message, PauseResumer) error
 
+	// BatchMessageHandler: This will get used as a callback function for handling batches of messages (must be thread safe)
+	// A batch has up to BatchSize messages collected during at most BatchTimeout, per partition in PullOrdered mode and across partitions in PullUnOrdered mode.
+	// Returning an error fails the whole batch, returning a *BatchError fails only the messages it reports. Failed messages are retried according to RetryCount.
+	// The offsets of a batch are marked once the batch is handled.
+	// Incompatible with MessageHandler and PausableMessageHandler. There must be exactly one of them provided to the Config!
+	BatchMessageHandler func(context.Context, []Message) error
+
+	// BatchSize: Max number of messages in a batch handled by BatchMessageHandler
+	// Default: 100
+	BatchSize int
+
+	// BatchTimeout: Max time to wait for a batch to be filled, counted from its first message
+	// Default: 1s
+	BatchTimeout time.Duration
+
 	// ErrorHandler: If available, any errors that occurred while consuming are returned on this handler (must be thread safe)
 	ErrorHandler func(context.Context, error, *Message)
 
@@ -71,6 +88,29 @@ type Config struct {
 	// Default: 10
 	RetryCount int64
 
+	// RetryTopics: Tiered retry topics with their delays, e.g. orders.retry.1m and orders.retry.10m
+	// A message failing RetryCount + 1 times is republished to the first tier, failing on a tier to the next one and failing on the last tier to the DeadLetterTopic.
+	// Retry topics are consumed along with Topics, a message on a retry topic is handled once after the Delay of its tier,
+	// so a failing message does not block its partition in PullOrdered mode. Keep RetryCount low when using them.
+	RetryTopics []RetryTopic
+
+	// DeadLetterTopic: If available, a message which keeps failing is republished to this topic instead of being dropped.
+	// The message keeps its key and headers, the failure metadata is added as headers (HeaderFailureError, HeaderFailureAttempts, ...).
+	// The error is still returned on the ErrorHandler.
+	DeadLetterTopic string
+
+	// FailureProducer: Producer used to republish messages to the RetryTopics and DeadLetterTopic
+	// Default: a sync producer for Address, created when RetryTopics or DeadLetterTopic are configured
+	FailureProducer producer.Producer
+
+	// TransactionMaxMessages: Max number of messages processed in one transaction by the TransactionalKafkaConsumer
+	// Default: 100
+	TransactionMaxMessages int
+
+	// TransactionInterval: Max time a transaction of the TransactionalKafkaConsumer stays open before it is committed
+	// Default: 100ms
+	TransactionInterval time.Duration
+
 	// TransactionID: Default transaction id to be used by the consumer
 	TransactionID string
 
@@ -93,6 +133,11 @@ type Config struct {
 	// Default: 5000
 	CommitIntervalMs int
 
+	// IsolationLevel: ReadCommitted to consume only messages of committed transactions, or ReadUncommitted.
+	// The TransactionalKafkaConsumer always uses ReadCommitted.
+	// Default: "" (kafka default, read_committed)
+	IsolationLevel string
+
 	// EnableKafkaLogs: whether to enable logging of kafka internals.
 	// Default: false
 	EnableKafkaLogs bool
@@ -118,20 +163,24 @@ type Config struct {
 // NewConfig is a function to return default consumer configuration
 func NewConfig() *Config {
 	return &Config{
-		SubscriberPerCore:    20,
-		CommitMode:           OnPull,
-		ConsumerMode:         PullUnOrdered,
-		OffsetsInitial:       OffsetNewest,
-		Timeout:              time.Minute,
-		ErrorHandlingTimeout: time.Minute,
-		RetryCount:           10,
-		RetryDelay:           30 * time.Second,
-		Partitions:           500,
-		MaxQueueSize:         100,
-		EmptyQueueWaitTime:   500 * time.Millisecond,
-		CommitIntervalMs:     5000,
-		EnableKafkaLogs:      false,
-		LogLevel:             3,
-		LoggedComponents:     "all",
+		SubscriberPerCore:      20,
+		CommitMode:             OnPull,
+		ConsumerMode:           PullUnOrdered,
+		OffsetsInitial:         OffsetNewest,
+		Timeout:                time.Minute,
+		ErrorHandlingTimeout:   time.Minute,
+		RetryCount:             10,
+		RetryDelay:             30 * time.Second,
+		BatchSize:              100,
+		BatchTimeout:           time.Second,
+		TransactionMaxMessages: 100,
+		TransactionInterval:    100 * time.Millisecond,
+		Partitions:             500,
+		MaxQueueSize:           100,
+		EmptyQueueWaitTime:     500 * time.Millisecond,
+		CommitIntervalMs:       5000,
+		EnableKafkaLogs:        false,
+		LogLevel:               3,
+		LoggedComponents:       "all",
 	}
 }
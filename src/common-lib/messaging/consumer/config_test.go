@@ -12,21 +12,25 @@ func TestNewConfigDefaults(t *testing.T) {
 		want *Config
 	}{
 		{name: "default", want: &Config{
-			SubscriberPerCore:    20,
-			CommitMode:           OnPull,
-			ConsumerMode:         PullUnOrdered,
-			OffsetsInitial:       OffsetNewest,
-			Timeout:              time.Minute,
-			ErrorHandlingTimeout: time.Minute,
-			RetryCount:           10,
-			RetryDelay:           30 * time.Second,
-			Partitions:           500,
-			MaxQueueSize:         100,
-			EmptyQueueWaitTime:   500 * time.Millisecond,
-			CommitIntervalMs:     5000,
-			EnableKafkaLogs:      false,
-			LogLevel:             3,
-			LoggedComponents:     "all",
+			SubscriberPerCore:      20,
+			CommitMode:             OnPull,
+			ConsumerMode:           PullUnOrdered,
+			OffsetsInitial:         OffsetNewest,
+			Timeout:                time.Minute,
+			ErrorHandlingTimeout:   time.Minute,
+			RetryCount:             10,
+			RetryDelay:             30 * time.Second,
+			BatchSize:              100,
+			BatchTimeout:           time.Second,
+			TransactionMaxMessages: 100,
+			TransactionInterval:    100 * time.Millisecond,
+			Partitions:             500,
+			MaxQueueSize:           100,
+			EmptyQueueWaitTime:     500 * time.Millisecond,
+			CommitIntervalMs:       5000,
+			EnableKafkaLogs:        false,
+			LogLevel:               3,
+			LoggedComponents:       "all",
 		}},
 	}
 	for _, tt := range tests {
@@ -13,6 +13,7 @@ import (
 	"github.com/gammazero/workerpool"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
 )
 
 //go:generate mockgen -package consumer -source=consumer.go -destination=consumer_mock.go .
@@ -93,6 +94,27 @@ type consumer interface {
 	// This is a blocking call.
 	// Returns the committed offsets on success.
 	CommitOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error)
+	// Seek seeks the given topic partitions to the offset
+	Seek(partition kafka.TopicPartition, timeoutMs int) error
+}
+
+// Client describes the kafka consumer used by the consumers of this package, implemented by *kafka.Consumer.
+type Client interface {
+	consumer
+	// GetConsumerGroupMetadata returns the consumer's current group metadata, used by transactions
+	GetConsumerGroupMetadata() (*kafka.ConsumerGroupMetadata, error)
+	// Logs returns the log channel if enabled, or nil otherwise
+	Logs() chan kafka.LogEvent
+}
+
+// NewClient creates the kafka consumer of the consumers.
+// It can be replaced to run the consumers against another implementation, e.g. the in-memory cluster of messaging/kafkatest.
+var NewClient = func(configMap *kafka.ConfigMap) (Client, error) {
+	c, err := kafka.NewConsumer(configMap)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
 }
 
 // Service interface.
@@ -144,6 +166,13 @@ type KafkaConsumer struct {
 
 	partMx           *sync.Mutex // it's rarely used and always used to mutate the state so there is no point in using RWMutex
 	pausedPartitions map[partition]kafka.TopicPartition
+	retryWaits       map[partition]retryWait // partitions of the retry topics paused until their message is due, guarded by partMx
+
+	failureProducer    producer.Producer
+	ownFailureProducer bool // the producer was created by the consumer and is closed along with it
+
+	batchMx  sync.Mutex
+	batchers map[partition]*batcher
 }
 
 type partition struct {
@@ -342,9 +371,13 @@ func (c *KafkaConsumer) Pull() {
 				c.infof("Closing receiving events; wait: %v", wait)
 				close(poller)
 				c.consumerStrategy.close(wait)
+				c.stopBatchers(wait)
 				if err := c.consumer.Close(); err != nil {
 					c.invokeErrorHandler(err, nil)
 				}
+				if c.ownFailureProducer {
+					c.failureProducer.Close()
+				}
 				return
 			default:
 				m := c.consumer.Poll(150) // the higher the value the lower CPU usage when there are no events to consume, but it negatively affects the shutdown time
@@ -358,6 +391,9 @@ func (c *KafkaConsumer) Pull() {
 	for ev := range poller {
 		switch e := ev.(type) {
 		case *kafka.Message:
+			if c.deferRetry(e) {
+				continue
+			}
 			if err := c.consumerStrategy.handleMessage(e); err != nil {
 				c.invokeErrorHandler(err, newMessage(e))
 			}
@@ -404,6 +440,7 @@ func (c *KafkaConsumer) handleAssignedPartitions(p kafka.AssignedPartitions) {
 	if err := c.ResumeAll(); err != nil {
 		c.invokeErrorHandler(err, nil)
 	}
+	c.cancelRetryWaits()
 
 	if err := c.consumer.Assign(p.Partitions); err != nil {
 		c.invokeErrorHandler(err, nil)
@@ -414,17 +451,20 @@ func (c *KafkaConsumer) handleAssignedPartitions(p kafka.AssignedPartitions) {
 		defer c.healthMx.Unlock()
 		c.health.ConnectionState = true
 	}()
-	c.consumerStrategy.handleAssignedPartitions(p, c.process)
+	c.consumerStrategy.handleAssignedPartitions(p, c.messageProcessor())
 }
 
 func (c *KafkaConsumer) handleRevokedPartitions(p kafka.RevokedPartitions) {
 	if err := c.ResumeAll(); err != nil {
 		c.invokeErrorHandler(err, nil)
 	}
+	c.cancelRetryWaits()
+	// the pending messages and batches are handled first, so their offsets are stored while the partitions are still assigned
+	c.consumerStrategy.handleRevokedPartitions(p)
+	c.stopBatchers(true)
 	if err := c.consumer.Unassign(); err != nil {
 		c.invokeErrorHandler(err, nil)
 	}
-	c.consumerStrategy.handleRevokedPartitions(p)
 }
 
 // Close stops pulling messages from kafka
@@ -434,6 +474,7 @@ func (c *KafkaConsumer) Close() error {
 	for part := range c.pausedPartitions {
 		delete(c.pausedPartitions, part)
 	}
+	c.stopRetryWaits()
 	return c.close(false)
 }
 
@@ -444,6 +485,7 @@ func (c *KafkaConsumer) CloseWait() error {
 	for part := range c.pausedPartitions {
 		delete(c.pausedPartitions, part)
 	}
+	c.stopRetryWaits()
 	return c.close(true)
 }
 
@@ -579,16 +621,28 @@ func (c *KafkaConsumer) debugf(format string, v ...interface{}) {
 
 // New creates a new Consumer instance with config settings
 func New(config *Config) (*KafkaConsumer, error) {
-	if len(config.Address) == 0 || config.Group == "" || len(config.Topics) == 0 || (config.MessageHandler == nil && config.PausableMessageHandler == nil) {
+	handlers := 0
+	for _, provided := range []bool{config.MessageHandler != nil, config.PausableMessageHandler != nil, config.BatchMessageHandler != nil} {
+		if provided {
+			handlers++
+		}
+	}
+	if len(config.Address) == 0 || config.Group == "" || len(config.Topics) == 0 || handlers == 0 {
 		return nil, fmt.Errorf("some of the values are blank. MessageHandler, Address : %v, Group : %s and Topics : %v",
 			config.Address, config.Group, config.Topics)
 	}
-	if config.MessageHandler != nil && config.PausableMessageHandler != nil {
-		return nil, fmt.Errorf("both MessageHandler and PausableMessageHandler are provided but only one is expected")
+	if handlers > 1 {
+		return nil, fmt.Errorf("more than one of MessageHandler, PausableMessageHandler and BatchMessageHandler are provided but only one is expected")
+	}
+	if config.BatchMessageHandler != nil && (config.BatchSize <= 0 || config.BatchTimeout <= 0) {
+		return nil, fmt.Errorf("BatchSize : %d and BatchTimeout : %v should be positive for BatchMessageHandler", config.BatchSize, config.BatchTimeout)
 	}
 	if config.ConsumerMode == PullUnOrdered && config.CommitMode == OnMessageCompletion {
 		return nil, errors.New("ConsumerMode 'PullUnOrdered' cannot be used with CommitMode 'OnMessageCompletion'")
 	}
+	if err := config.validateFailureRouting(); err != nil {
+		return nil, err
+	}
 	configMap := kafka.ConfigMap{
 		"bootstrap.servers":               strings.Join(config.Address, ","),
 		"group.id":                        config.Group,
@@ -598,6 +652,9 @@ func New(config *Config) (*KafkaConsumer, error) {
 		"auto.commit.interval.ms":         config.CommitIntervalMs,
 		"enable.auto.offset.store":        false,
 	}
+	if config.IsolationLevel != "" {
+		configMap["isolation.level"] = config.IsolationLevel
+	}
 	if config.EnableKafkaLogs {
 		configMap["go.logs.channel.enable"] = true
 		configMap["log_level"] = config.LogLevel
@@ -606,7 +663,7 @@ func New(config *Config) (*KafkaConsumer, error) {
 		}
 	}
 
-	c, err := kafka.NewConsumer(&configMap)
+	c, err := NewClient(&configMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create consumer: %s", err)
 	}
@@ -636,6 +693,9 @@ func New(config *Config) (*KafkaConsumer, error) {
 
 		partMx:           &sync.Mutex{},
 		pausedPartitions: make(map[partition]kafka.TopicPartition),
+		retryWaits:       make(map[partition]retryWait),
+
+		failureProducer: config.FailureProducer,
 	}
 	func() {
 		kc.healthMx.Lock()
@@ -661,8 +721,20 @@ func New(config *Config) (*KafkaConsumer, error) {
 		}
 	}
 
-	err = kc.consumer.SubscribeTopics(kc.config.Topics, rebalanceCb)
+	if config.routesFailures() && kc.failureProducer == nil {
+		pcfg := producer.NewConfig()
+		pcfg.Address = config.Address
+		if kc.failureProducer, err = producer.NewSyncProducer(pcfg); err != nil {
+			return nil, fmt.Errorf("failed to create failure producer: %s", err)
+		}
+		kc.ownFailureProducer = true
+	}
+
+	err = kc.consumer.SubscribeTopics(kc.config.subscribedTopics(), rebalanceCb)
 	if err != nil {
+		if kc.ownFailureProducer {
+			kc.failureProducer.Close()
+		}
 		return nil, err
 	}
 	return kc, nil
@@ -709,7 +781,12 @@ func (c *KafkaConsumer) processMessage(message *Message, commitStrategy commitSt
 	var retryCount int64
 	var err error
 
-	for retryCount = 0; retryCount <= config.RetryCount; retryCount++ {
+	// a message on a retry topic gets a single attempt, failing it moves the message to the next tier
+	maxRetries := config.RetryCount
+	if config.retryTier(message.Topic) >= 0 {
+		maxRetries = 0
+	}
+	for retryCount = 0; retryCount <= maxRetries; retryCount++ {
 		resultErr := func() error {
 			ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
 			defer cancel()
@@ -735,13 +812,21 @@ func (c *KafkaConsumer) processMessage(message *Message, commitStrategy commitSt
 		}()
 		if resultErr != nil {
 			err = resultErr
-			time.Sleep(config.RetryDelay)
+			if retryCount < maxRetries {
+				time.Sleep(config.RetryDelay)
+			}
 		} else {
 			err = nil
 			break
 		}
 	}
-	commitStrategy.afterHandler(transactionID, message.Topic, message.Partition, message.Offset)
+	if err != nil {
+		// republish before the offset gets marked, with OnMessageCompletion the offset is not marked if republishing fails
+		err = c.routeFailure(message, err, retryCount)
+	}
+	if !c.keepOffset(err) {
+		commitStrategy.afterHandler(transactionID, message.Topic, message.Partition, message.Offset)
+	}
 
 	// queue.processed(message)
 	if err != nil {
@@ -174,6 +174,20 @@ func (mr *MockconsumerMockRecorder) Resume(partitions interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*Mockconsumer)(nil).Resume), partitions)
 }
 
+// Seek mocks base method.
+func (m *Mockconsumer) Seek(partition kafka.TopicPartition, timeoutMs int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seek", partition, timeoutMs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Seek indicates an expected call of Seek.
+func (mr *MockconsumerMockRecorder) Seek(partition, timeoutMs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seek", reflect.TypeOf((*Mockconsumer)(nil).Seek), partition, timeoutMs)
+}
+
 // StoreOffsets mocks base method.
 func (m *Mockconsumer) StoreOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
 	m.ctrl.T.Helper()
@@ -217,6 +231,215 @@ func (mr *MockconsumerMockRecorder) Unassign() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unassign", reflect.TypeOf((*Mockconsumer)(nil).Unassign))
 }
 
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// Assign mocks base method.
+func (m *MockClient) Assign(partitions []kafka.TopicPartition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Assign", partitions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Assign indicates an expected call of Assign.
+func (mr *MockClientMockRecorder) Assign(partitions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Assign", reflect.TypeOf((*MockClient)(nil).Assign), partitions)
+}
+
+// Assignment mocks base method.
+func (m *MockClient) Assignment() ([]kafka.TopicPartition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Assignment")
+	ret0, _ := ret[0].([]kafka.TopicPartition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Assignment indicates an expected call of Assignment.
+func (mr *MockClientMockRecorder) Assignment() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Assignment", reflect.TypeOf((*MockClient)(nil).Assignment))
+}
+
+// Close mocks base method.
+func (m *MockClient) Close() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Close")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClient)(nil).Close))
+}
+
+// CommitOffsets mocks base method.
+func (m *MockClient) CommitOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitOffsets", offsets)
+	ret0, _ := ret[0].([]kafka.TopicPartition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CommitOffsets indicates an expected call of CommitOffsets.
+func (mr *MockClientMockRecorder) CommitOffsets(offsets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitOffsets", reflect.TypeOf((*MockClient)(nil).CommitOffsets), offsets)
+}
+
+// GetConsumerGroupMetadata mocks base method.
+func (m *MockClient) GetConsumerGroupMetadata() (*kafka.ConsumerGroupMetadata, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetConsumerGroupMetadata")
+	ret0, _ := ret[0].(*kafka.ConsumerGroupMetadata)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetConsumerGroupMetadata indicates an expected call of GetConsumerGroupMetadata.
+func (mr *MockClientMockRecorder) GetConsumerGroupMetadata() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConsumerGroupMetadata", reflect.TypeOf((*MockClient)(nil).GetConsumerGroupMetadata))
+}
+
+// Logs mocks base method.
+func (m *MockClient) Logs() chan kafka.LogEvent {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Logs")
+	ret0, _ := ret[0].(chan kafka.LogEvent)
+	return ret0
+}
+
+// Logs indicates an expected call of Logs.
+func (mr *MockClientMockRecorder) Logs() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Logs", reflect.TypeOf((*MockClient)(nil).Logs))
+}
+
+// Pause mocks base method.
+func (m *MockClient) Pause(partitions []kafka.TopicPartition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Pause", partitions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Pause indicates an expected call of Pause.
+func (mr *MockClientMockRecorder) Pause(partitions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Pause", reflect.TypeOf((*MockClient)(nil).Pause), partitions)
+}
+
+// Poll mocks base method.
+func (m *MockClient) Poll(timeoutMs int) kafka.Event {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Poll", timeoutMs)
+	ret0, _ := ret[0].(kafka.Event)
+	return ret0
+}
+
+// Poll indicates an expected call of Poll.
+func (mr *MockClientMockRecorder) Poll(timeoutMs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Poll", reflect.TypeOf((*MockClient)(nil).Poll), timeoutMs)
+}
+
+// Resume mocks base method.
+func (m *MockClient) Resume(partitions []kafka.TopicPartition) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Resume", partitions)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Resume indicates an expected call of Resume.
+func (mr *MockClientMockRecorder) Resume(partitions interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Resume", reflect.TypeOf((*MockClient)(nil).Resume), partitions)
+}
+
+// Seek mocks base method.
+func (m *MockClient) Seek(partition kafka.TopicPartition, timeoutMs int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Seek", partition, timeoutMs)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Seek indicates an expected call of Seek.
+func (mr *MockClientMockRecorder) Seek(partition, timeoutMs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Seek", reflect.TypeOf((*MockClient)(nil).Seek), partition, timeoutMs)
+}
+
+// StoreOffsets mocks base method.
+func (m *MockClient) StoreOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StoreOffsets", offsets)
+	ret0, _ := ret[0].([]kafka.TopicPartition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StoreOffsets indicates an expected call of StoreOffsets.
+func (mr *MockClientMockRecorder) StoreOffsets(offsets interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StoreOffsets", reflect.TypeOf((*MockClient)(nil).StoreOffsets), offsets)
+}
+
+// SubscribeTopics mocks base method.
+func (m *MockClient) SubscribeTopics(topics []string, rebalanceCb kafka.RebalanceCb) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SubscribeTopics", topics, rebalanceCb)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SubscribeTopics indicates an expected call of SubscribeTopics.
+func (mr *MockClientMockRecorder) SubscribeTopics(topics, rebalanceCb interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeTopics", reflect.TypeOf((*MockClient)(nil).SubscribeTopics), topics, rebalanceCb)
+}
+
+// Unassign mocks base method.
+func (m *MockClient) Unassign() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unassign")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unassign indicates an expected call of Unassign.
+func (mr *MockClientMockRecorder) Unassign() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unassign", reflect.TypeOf((*MockClient)(nil).Unassign))
+}
+
 // MockService is a mock of Service interface.
 type MockService struct {
 	ctrl     *gomock.Controller
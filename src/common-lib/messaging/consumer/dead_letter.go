@@ -0,0 +1,270 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
+)
+
+// Headers added to a message republished to a retry topic or to the dead-letter topic
+const (
+	// HeaderFailureError : error returned by the last failed attempt
+	HeaderFailureError = "x-failure-error"
+	// HeaderFailureAttempts : number of failed attempts, including the ones on previous retry topics
+	HeaderFailureAttempts = "x-failure-attempts"
+	// HeaderFailureTopic : topic the message was originally consumed from
+	HeaderFailureTopic = "x-failure-topic"
+	// HeaderFailurePartition : partition the message was originally consumed from
+	HeaderFailurePartition = "x-failure-partition"
+	// HeaderFailureOffset : offset of the message in the topic it was originally consumed from
+	HeaderFailureOffset = "x-failure-offset"
+	// HeaderRetryAt : time in unix milliseconds after which a message on a retry topic is handled
+	HeaderRetryAt = "x-retry-at"
+)
+
+// RetryTopic is a tier of retry topics
+type RetryTopic struct {
+	// Topic: Name of the retry topic, e.g. orders.retry.1m
+	Topic string
+
+	// Delay: Time to wait after a message was republished to the topic before handling it again
+	Delay time.Duration
+}
+
+// retryTier returns the index of the retry topic in the config or -1 for topics that are not retry topics
+func (c *Config) retryTier(topic string) int {
+	for i, rt := range c.RetryTopics {
+		if rt.Topic == topic {
+			return i
+		}
+	}
+	return -1
+}
+
+// routesFailures tells whether failed messages are republished instead of being dropped
+func (c *Config) routesFailures() bool {
+	return c.DeadLetterTopic != "" || len(c.RetryTopics) > 0
+}
+
+// subscribedTopics returns the topics and the retry topics the consumer subscribes to
+func (c *Config) subscribedTopics() []string {
+	topics := append([]string{}, c.Topics...)
+	for _, rt := range c.RetryTopics {
+		if !contains(topics, rt.Topic) {
+			topics = append(topics, rt.Topic)
+		}
+	}
+	return topics
+}
+
+func (c *Config) validateFailureRouting() error {
+	for _, rt := range c.RetryTopics {
+		if rt.Topic == "" || rt.Delay < 0 {
+			return fmt.Errorf("invalid retry topic %+v, topic name is required and delay should not be negative", rt)
+		}
+		if rt.Topic == c.DeadLetterTopic || contains(c.Topics, rt.Topic) {
+			return fmt.Errorf("retry topic %q cannot be used as consumed topic or dead-letter topic", rt.Topic)
+		}
+	}
+	if c.DeadLetterTopic != "" && contains(c.Topics, c.DeadLetterTopic) {
+		return fmt.Errorf("dead-letter topic %q cannot be used as consumed topic", c.DeadLetterTopic)
+	}
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// retryWait is a partition of a retry topic paused until its next message is due
+type retryWait struct {
+	timer *time.Timer
+	// at is the offset of the message the partition was rewound to
+	at kafka.TopicPartition
+}
+
+// deferRetry pauses the partition of a message consumed from a retry topic before it is due and rewinds it to the message,
+// the partition is resumed once the message is due, so it is consumed again then. It returns false when the message is due.
+// The messages of a paused partition fetched before the rewind are skipped, they are consumed again after the message.
+func (c *KafkaConsumer) deferRetry(m *kafka.Message) bool {
+	if m.TopicPartition.Topic == nil || c.config.retryTier(*m.TopicPartition.Topic) < 0 {
+		return false
+	}
+	deferred, err := c.pauseRetry(m)
+	if err != nil {
+		c.invokeErrorHandler(err, newMessage(m))
+	}
+	return deferred
+}
+
+func (c *KafkaConsumer) pauseRetry(m *kafka.Message) (bool, error) {
+	p := partition{topic: *m.TopicPartition.Topic, partition: m.TopicPartition.Partition}
+	c.partMx.Lock()
+	defer c.partMx.Unlock()
+	if w, ok := c.retryWaits[p]; ok {
+		// the partition was resumed by someone else or the message was fetched before the rewind
+		return true, c.rewind(w.at)
+	}
+
+	retryAt, err := strconv.ParseInt(newMessage(m).GetHeader(HeaderRetryAt), 10, 64)
+	if err != nil {
+		return false, nil
+	}
+	wait := time.Until(time.Unix(0, retryAt*int64(time.Millisecond)))
+	if wait <= 0 {
+		return false, nil
+	}
+	if err = c.rewind(m.TopicPartition); err != nil {
+		// handle the message now rather than losing it
+		return false, err
+	}
+	c.debugf("Topic: %s at %d/%d ==> paused for %v before retrying\n", p.topic, p.partition, m.TopicPartition.Offset, wait)
+	if c.retryWaits == nil {
+		c.retryWaits = make(map[partition]retryWait)
+	}
+	c.retryWaits[p] = retryWait{timer: time.AfterFunc(wait, func() { c.resumeRetry(p) }), at: m.TopicPartition}
+	return true, nil
+}
+
+// rewind pauses the partition and seeks it to the offset
+func (c *KafkaConsumer) rewind(at kafka.TopicPartition) error {
+	if err := c.consumer.Pause([]kafka.TopicPartition{at}); err != nil {
+		return fmt.Errorf("failed to pause retry partition %s/%d: %w", *at.Topic, at.Partition, err)
+	}
+	if err := c.consumer.Seek(at, int(c.config.Timeout/time.Millisecond)); err != nil {
+		if rerr := c.consumer.Resume([]kafka.TopicPartition{at}); rerr != nil {
+			c.errorf("failed to resume retry partition %s/%d: %v", *at.Topic, at.Partition, rerr)
+		}
+		return fmt.Errorf("failed to seek retry partition %s/%d to %d: %w", *at.Topic, at.Partition, at.Offset, err)
+	}
+	return nil
+}
+
+// resumeRetry resumes the partition once its message is due, unless it was paused through the PauseResumer
+func (c *KafkaConsumer) resumeRetry(p partition) {
+	err := func() error {
+		c.partMx.Lock()
+		defer c.partMx.Unlock()
+		w, ok := c.retryWaits[p]
+		if !ok {
+			return nil // stopped by a rebalance or the shutdown
+		}
+		delete(c.retryWaits, p)
+		if _, paused := c.pausedPartitions[p]; paused {
+			return nil
+		}
+		return c.consumer.Resume([]kafka.TopicPartition{w.at})
+	}()
+	if err != nil {
+		c.invokeErrorHandler(err, nil)
+	}
+}
+
+// cancelRetryWaits cancels the pending resumes on a rebalance, the partitions are resumed by the new assignment
+func (c *KafkaConsumer) cancelRetryWaits() {
+	c.partMx.Lock()
+	defer c.partMx.Unlock()
+	c.stopRetryWaits()
+}
+
+// stopRetryWaits cancels the pending resumes, it should be called with partMx locked
+func (c *KafkaConsumer) stopRetryWaits() {
+	for p, w := range c.retryWaits {
+		w.timer.Stop()
+		delete(c.retryWaits, p)
+	}
+}
+
+// republishError is returned by routeFailure when the message could not be republished
+type republishError struct {
+	topic   string
+	cause   error
+	handler error
+}
+
+func (e *republishError) Error() string {
+	return fmt.Sprintf("failed to republish message to %q: %v: %v", e.topic, e.cause, e.handler)
+}
+
+// Unwrap returns the error of the message handler
+func (e *republishError) Unwrap() error {
+	return e.handler
+}
+
+// keepOffset tells whether the offset of a failed message should not be marked:
+// with OnMessageCompletion a message which could not be republished is consumed again after a restart or a rebalance
+func (c *KafkaConsumer) keepOffset(err error) bool {
+	var rerr *republishError
+	return c.config.CommitMode == OnMessageCompletion && errors.As(err, &rerr)
+}
+
+// routeFailure republishes a message that failed all its attempts to the next retry topic or to the dead-letter topic.
+// It returns nil when the message was republished to a retry topic, otherwise the error to be reported to the ErrorHandler,
+// a *republishError when republishing failed.
+func (c *KafkaConsumer) routeFailure(message *Message, err error, attempts int64) error {
+	if c.failureProducer == nil {
+		return err
+	}
+
+	topic, delay := c.config.DeadLetterTopic, time.Duration(0)
+	if next := c.config.retryTier(message.Topic) + 1; next < len(c.config.RetryTopics) {
+		topic, delay = c.config.RetryTopics[next].Topic, c.config.RetryTopics[next].Delay
+	}
+	if topic == "" {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	failed := newFailureMessage(message, topic, err, attempts, delay)
+	if perr := c.failureProducer.Produce(ctx, message.GetTransactionID(), failed); perr != nil {
+		return &republishError{topic: topic, cause: perr, handler: err}
+	}
+
+	c.debugf("Topic: %s at %d/%d ==> republished to %s\n", message.Topic, message.Partition, message.Offset, topic)
+	if topic == c.config.DeadLetterTopic {
+		return err
+	}
+	return nil
+}
+
+// newFailureMessage copies the key, value and headers of the message and adds the failure metadata.
+// The source topic, partition and offset are those of the first failure, so they survive going through the retry topics.
+func newFailureMessage(message *Message, topic string, err error, attempts int64, delay time.Duration) *producer.Message {
+	failed := &producer.Message{
+		Topic: topic,
+		Key:   message.Key,
+		Value: message.Message,
+	}
+
+	headers := message.GetHeaders()
+	if _, ok := headers[HeaderFailureTopic]; !ok {
+		headers[HeaderFailureTopic] = message.Topic
+		headers[HeaderFailurePartition] = strconv.FormatInt(int64(message.Partition), 10)
+		headers[HeaderFailureOffset] = strconv.FormatInt(message.Offset, 10)
+	}
+	if previous, perr := strconv.ParseInt(headers[HeaderFailureAttempts], 10, 64); perr == nil {
+		attempts += previous
+	}
+	headers[HeaderFailureAttempts] = strconv.FormatInt(attempts, 10)
+	headers[HeaderFailureError] = err.Error()
+	delete(headers, HeaderRetryAt)
+	if delay > 0 {
+		headers[HeaderRetryAt] = strconv.FormatInt(time.Now().Add(delay).UnixNano()/int64(time.Millisecond), 10)
+	}
+
+	for k, v := range headers {
+		failed.AddHeader(k, v)
+	}
+	return failed
+}
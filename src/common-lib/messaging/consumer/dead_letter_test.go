@@ -0,0 +1,417 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
+	mock_producer "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer/mocks"
+)
+
+func failureRoutingConfig() *Config {
+	return &Config{
+		Topics:          []string{"orders"},
+		Timeout:         5 * time.Second,
+		DeadLetterTopic: "orders.dlq",
+		RetryTopics: []RetryTopic{
+			{Topic: "orders.retry.1m", Delay: time.Minute},
+			{Topic: "orders.retry.10m", Delay: 10 * time.Minute},
+		},
+	}
+}
+
+func TestConfig_validateFailureRouting(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		wantErr bool
+	}{
+		{name: "Valid", modify: func(c *Config) {}},
+		{name: "No routing", modify: func(c *Config) { c.DeadLetterTopic = ""; c.RetryTopics = nil }},
+		{name: "Empty retry topic", modify: func(c *Config) { c.RetryTopics[0].Topic = "" }, wantErr: true},
+		{name: "Negative delay", modify: func(c *Config) { c.RetryTopics[1].Delay = -time.Second }, wantErr: true},
+		{name: "Retry topic consumed", modify: func(c *Config) { c.Topics = append(c.Topics, "orders.retry.1m") }, wantErr: true},
+		{name: "Retry topic is dead-letter topic", modify: func(c *Config) { c.DeadLetterTopic = "orders.retry.10m" }, wantErr: true},
+		{name: "Dead-letter topic consumed", modify: func(c *Config) { c.DeadLetterTopic = "orders" }, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := failureRoutingConfig()
+			tt.modify(c)
+			err := c.validateFailureRouting()
+			require.Equal(t, tt.wantErr, err != nil, "validateFailureRouting() error = %v", err)
+		})
+	}
+}
+
+func TestConfig_subscribedTopics(t *testing.T) {
+	require.Equal(t, []string{"orders", "orders.retry.1m", "orders.retry.10m"}, failureRoutingConfig().subscribedTopics())
+	require.Equal(t, []string{"orders"}, (&Config{Topics: []string{"orders"}}).subscribedTopics())
+}
+
+func TestKafkaConsumer_routeFailure(t *testing.T) {
+	handlerErr := errors.New("oops")
+	tests := []struct {
+		name         string
+		modify       func(c *Config)
+		message      *Message
+		wantTopic    string
+		wantAttempts string
+		wantRetryAt  bool
+		produceErr   error
+		wantErr      bool
+	}{
+		{
+			name:         "Source topic to first tier",
+			message:      &Message{Topic: "orders", Partition: 3, Offset: 42, Key: []byte("key"), headers: map[string]string{"h": "v"}},
+			wantTopic:    "orders.retry.1m",
+			wantAttempts: "2",
+			wantRetryAt:  true,
+		},
+		{
+			name: "First tier to second tier",
+			message: &Message{Topic: "orders.retry.1m", Partition: 0, Offset: 7, Key: []byte("key"), headers: map[string]string{
+				"h": "v", HeaderFailureTopic: "orders", HeaderFailurePartition: "3", HeaderFailureOffset: "42", HeaderFailureAttempts: "2", HeaderRetryAt: "1",
+			}},
+			wantTopic:    "orders.retry.10m",
+			wantAttempts: "4",
+			wantRetryAt:  true,
+		},
+		{
+			name: "Last tier to dead-letter topic",
+			message: &Message{Topic: "orders.retry.10m", Partition: 1, Offset: 9, Key: []byte("key"), headers: map[string]string{
+				"h": "v", HeaderFailureTopic: "orders", HeaderFailurePartition: "3", HeaderFailureOffset: "42", HeaderFailureAttempts: "4", HeaderRetryAt: "1",
+			}},
+			wantTopic:    "orders.dlq",
+			wantAttempts: "6",
+			wantErr:      true,
+		},
+		{
+			name:         "Dead-letter topic only",
+			modify:       func(c *Config) { c.RetryTopics = nil },
+			message:      &Message{Topic: "orders", Partition: 3, Offset: 42, Key: []byte("key"), headers: map[string]string{"h": "v"}},
+			wantTopic:    "orders.dlq",
+			wantAttempts: "2",
+			wantErr:      true,
+		},
+		{
+			name:    "Last tier without dead-letter topic",
+			modify:  func(c *Config) { c.DeadLetterTopic = "" },
+			message: &Message{Topic: "orders.retry.10m", headers: map[string]string{}},
+			wantErr: true,
+		},
+		{
+			name:         "Republishing failed",
+			message:      &Message{Topic: "orders", Partition: 3, Offset: 42, Key: []byte("key"), headers: map[string]string{"h": "v"}},
+			wantTopic:    "orders.retry.1m",
+			wantAttempts: "2",
+			wantRetryAt:  true,
+			produceErr:   errors.New("broker down"),
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			config := failureRoutingConfig()
+			if tt.modify != nil {
+				tt.modify(config)
+			}
+			p := mock_producer.NewMockProducer(ctrl)
+			var produced *producer.Message
+			if tt.wantTopic != "" {
+				p.EXPECT().Produce(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(ctx context.Context, transaction string, messages ...*producer.Message) error {
+						produced = messages[0]
+						return tt.produceErr
+					})
+			}
+			log := NewMockConsumerLogger(ctrl)
+			log.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+			c := &KafkaConsumer{config: config, failureProducer: p, debugLog: log}
+			err := c.routeFailure(tt.message, handlerErr, 2)
+			require.Equal(t, tt.wantErr, err != nil, "routeFailure() error = %v", err)
+			if err != nil {
+				require.True(t, errors.Is(err, handlerErr))
+			}
+			if tt.wantTopic == "" {
+				return
+			}
+
+			require.Equal(t, tt.wantTopic, produced.Topic)
+			require.Equal(t, tt.message.Key, produced.Key)
+			headers := produced.GetHeaders()
+			require.Equal(t, "v", headers["h"])
+			require.Equal(t, "orders", headers[HeaderFailureTopic])
+			require.Equal(t, "3", headers[HeaderFailurePartition])
+			require.Equal(t, "42", headers[HeaderFailureOffset])
+			require.Equal(t, tt.wantAttempts, headers[HeaderFailureAttempts])
+			require.Equal(t, "oops", headers[HeaderFailureError])
+			_, hasRetryAt := headers[HeaderRetryAt]
+			require.Equal(t, tt.wantRetryAt, hasRetryAt)
+		})
+	}
+}
+
+func TestKafkaConsumer_process_retryTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	cs := NewMockcommitStrategy(ctrl)
+	cs.EXPECT().onPull(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	cs.EXPECT().beforeHandler(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+	cs.EXPECT().afterHandler(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+
+	p := mock_producer.NewMockProducer(ctrl)
+	p.EXPECT().Produce(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	log := NewMockConsumerLogger(ctrl)
+	log.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	calls := 0
+	config := failureRoutingConfig()
+	config.RetryCount = 5
+	config.RetryDelay = time.Hour
+	config.MessageHandler = func(ctx context.Context, message Message) error {
+		calls++
+		return errors.New("oops")
+	}
+	config.ErrorHandler = func(ctx context.Context, err error, message *Message) {
+		t.Errorf("unexpected error reported for a message republished to a retry topic: %v", err)
+	}
+	config.ErrorHandlingTimeout = time.Second
+
+	c := &KafkaConsumer{config: config, commitStrategy: cs, failureProducer: p, debugLog: log}
+	c.process(&Message{
+		Topic:         "orders.retry.1m",
+		transactionID: "1",
+		headers:       map[string]string{HeaderRetryAt: retryAtHeader(time.Now())},
+	})
+
+	require.Equal(t, 1, calls, "a message on a retry topic should be handled once")
+}
+
+func TestKafkaConsumer_process_keepOffset(t *testing.T) {
+	tests := []struct {
+		name       string
+		commitMode commitMode
+		produceErr error
+		wantMarked bool
+	}{
+		{name: "Republished", commitMode: OnMessageCompletion, wantMarked: true},
+		{name: "Republishing failed", commitMode: OnMessageCompletion, produceErr: errors.New("broker down")},
+		{name: "Republishing failed on pull", commitMode: OnPull, produceErr: errors.New("broker down"), wantMarked: true},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			marked := 0
+			cs := NewMockcommitStrategy(ctrl)
+			cs.EXPECT().onPull(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+			cs.EXPECT().beforeHandler(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(1)
+			cs.EXPECT().afterHandler(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Do(
+				func(string, string, int32, int64) { marked++ }).AnyTimes()
+
+			p := mock_producer.NewMockProducer(ctrl)
+			p.EXPECT().Produce(gomock.Any(), gomock.Any(), gomock.Any()).Return(tt.produceErr).Times(1)
+
+			log := NewMockConsumerLogger(ctrl)
+			log.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+			reported := make(chan error, 1)
+			config := failureRoutingConfig()
+			config.CommitMode = tt.commitMode
+			config.MessageHandler = func(ctx context.Context, message Message) error {
+				return errors.New("oops")
+			}
+			config.ErrorHandler = func(ctx context.Context, err error, message *Message) {
+				reported <- err
+			}
+			config.ErrorHandlingTimeout = time.Second
+
+			c := &KafkaConsumer{config: config, commitStrategy: cs, failureProducer: p, debugLog: log}
+			c.process(&Message{Topic: "orders", transactionID: "1", headers: map[string]string{}})
+
+			require.Equal(t, tt.wantMarked, marked == 1, "afterHandler calls = %d", marked)
+			if tt.produceErr != nil {
+				err := <-reported
+				var rerr *republishError
+				require.True(t, errors.As(err, &rerr), "the republishing error should be reported: %v", err)
+			}
+		})
+	}
+}
+
+func retryAtHeader(at time.Time) string {
+	return strconv.FormatInt(at.UnixNano()/int64(time.Millisecond), 10)
+}
+
+// retryFeed serves the events to the Poll of the mock, the partition refetches the messages from the offset it was sought to
+type retryFeed struct {
+	mx     sync.Mutex
+	events []kafka.Event
+}
+
+func (f *retryFeed) push(events ...kafka.Event) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.events = append(f.events, events...)
+}
+
+func (f *retryFeed) poll(int) kafka.Event {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	if len(f.events) == 0 {
+		time.Sleep(time.Millisecond)
+		return nil
+	}
+	e := f.events[0]
+	f.events = f.events[1:]
+	return e
+}
+
+func TestKafkaConsumer_Pull_retryTopic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	retryTopic, topic := "orders.retry.1m", "orders"
+	retryAt := time.Now().Add(100 * time.Millisecond)
+	retryMessage := func(offset kafka.Offset) *kafka.Message {
+		return &kafka.Message{
+			TopicPartition: kafka.TopicPartition{Topic: &retryTopic, Partition: 0, Offset: offset},
+			Headers:        []kafka.Header{{Key: HeaderRetryAt, Value: []byte(retryAtHeader(retryAt))}},
+		}
+	}
+	first, second := retryMessage(5), retryMessage(6)
+	source := &kafka.Message{TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: 0, Offset: 1}}
+
+	feed := &retryFeed{}
+	feed.push(first, second, source)
+
+	kc := NewMockconsumer(ctrl)
+	kc.EXPECT().Poll(gomock.Any()).DoAndReturn(feed.poll).AnyTimes()
+	// the second message was fetched before the rewind, so the partition is rewound again
+	kc.EXPECT().Pause([]kafka.TopicPartition{first.TopicPartition}).Return(nil).Times(2)
+	kc.EXPECT().Seek(first.TopicPartition, gomock.Any()).Return(nil).Times(2)
+	kc.EXPECT().Resume([]kafka.TopicPartition{first.TopicPartition}).DoAndReturn(func([]kafka.TopicPartition) error {
+		feed.push(first, second)
+		return nil
+	}).Times(1)
+	kc.EXPECT().Close().Return(nil).Times(1)
+
+	var (
+		mx      sync.Mutex
+		handled []string
+		times   []time.Time
+		done    sync.WaitGroup
+	)
+	done.Add(3)
+	strategy := NewMockconsumerStrategy(ctrl)
+	strategy.EXPECT().handleMessage(gomock.Any()).DoAndReturn(func(m *kafka.Message) error {
+		mx.Lock()
+		defer mx.Unlock()
+		handled = append(handled, fmt.Sprintf("%s/%d", *m.TopicPartition.Topic, m.TopicPartition.Offset))
+		times = append(times, time.Now())
+		done.Done()
+		return nil
+	}).Times(3)
+	closed := make(chan struct{})
+	strategy.EXPECT().close(gomock.Any()).Do(func(bool) { close(closed) }).Times(1)
+
+	log := NewMockConsumerLogger(ctrl)
+	log.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	c := &KafkaConsumer{
+		config:           failureRoutingConfig(),
+		closing:          make(chan bool, 1),
+		consumer:         kc,
+		consumerStrategy: strategy,
+		partMx:           &sync.Mutex{},
+		pausedPartitions: make(map[partition]kafka.TopicPartition),
+		retryWaits:       make(map[partition]retryWait),
+		debugLog:         log,
+		infoLog:          log,
+	}
+	go c.Pull()
+
+	waitOrFail(t, &done, 5*time.Second)
+	require.NoError(t, c.Close())
+	<-closed
+
+	mx.Lock()
+	defer mx.Unlock()
+	require.Equal(t, []string{"orders/1", "orders.retry.1m/5", "orders.retry.1m/6"}, handled,
+		"the source topic should be consumed while the retry partition waits, the retry messages in their order")
+	require.False(t, times[1].Before(retryAt.Truncate(time.Millisecond)), "the message should not be handled before it is due")
+}
+
+func TestKafkaConsumer_Pull_retryTopicClose(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	retryTopic := "orders.retry.1m"
+	message := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &retryTopic, Partition: 0, Offset: 5},
+		Headers:        []kafka.Header{{Key: HeaderRetryAt, Value: []byte(retryAtHeader(time.Now().Add(time.Hour)))}},
+	}
+	feed := &retryFeed{}
+	feed.push(message)
+
+	paused := make(chan struct{})
+	kc := NewMockconsumer(ctrl)
+	kc.EXPECT().Poll(gomock.Any()).DoAndReturn(feed.poll).AnyTimes()
+	kc.EXPECT().Pause(gomock.Any()).Return(nil).Times(1)
+	kc.EXPECT().Seek(message.TopicPartition, gomock.Any()).DoAndReturn(func(kafka.TopicPartition, int) error {
+		close(paused)
+		return nil
+	}).Times(1)
+	kc.EXPECT().Close().Return(nil).Times(1)
+
+	closed := make(chan struct{})
+	strategy := NewMockconsumerStrategy(ctrl)
+	strategy.EXPECT().close(gomock.Any()).Do(func(bool) { close(closed) }).Times(1)
+
+	log := NewMockConsumerLogger(ctrl)
+	log.EXPECT().Printf(gomock.Any(), gomock.Any()).AnyTimes()
+
+	c := &KafkaConsumer{
+		config:           failureRoutingConfig(),
+		closing:          make(chan bool, 1),
+		consumer:         kc,
+		consumerStrategy: strategy,
+		partMx:           &sync.Mutex{},
+		pausedPartitions: make(map[partition]kafka.TopicPartition),
+		retryWaits:       make(map[partition]retryWait),
+		debugLog:         log,
+		infoLog:          log,
+	}
+	go c.Pull()
+
+	select {
+	case <-paused:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the retry partition was not paused")
+	}
+	require.NoError(t, c.Close())
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Fatal("the shutdown should not wait for the retry message")
+	}
+	require.Empty(t, c.retryWaits)
+}
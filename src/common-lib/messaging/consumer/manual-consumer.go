@@ -64,6 +64,9 @@ func NewManualConsumer(config *Config) (*ManualKafkaConsumer, error) {
 		"enable.auto.commit":              false, // for this Consumer, manual commit is the only way to commit offsets!
 		"enable.auto.offset.store":        false,
 	}
+	if config.IsolationLevel != "" {
+		configMap["isolation.level"] = config.IsolationLevel
+	}
 	if config.EnableKafkaLogs {
 		configMap["go.logs.channel.enable"] = true
 		configMap["log_level"] = config.LogLevel
@@ -72,7 +75,7 @@ func NewManualConsumer(config *Config) (*ManualKafkaConsumer, error) {
 		}
 	}
 
-	c, err := kafka.NewConsumer(&configMap)
+	c, err := NewClient(&configMap)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create consumer: %s", err)
 	}
@@ -0,0 +1,473 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
+)
+
+// Isolation levels of the consumer (isolation.level)
+const (
+	// ReadCommitted - only messages of committed transactions are consumed
+	ReadCommitted = "read_committed"
+	// ReadUncommitted - messages are consumed whether their transaction is committed, aborted or in progress
+	ReadUncommitted = "read_uncommitted"
+)
+
+// Backoff between the attempts to send the offsets to a transaction and to commit it, doubled after each retriable error
+const (
+	transactionRetryBackoff    = 100 * time.Millisecond
+	transactionMaxRetryBackoff = 2 * time.Second
+)
+
+// TransformHandler transforms a consumed message into the messages produced in the same transaction as its offset.
+// Returning no messages only commits the offset.
+type TransformHandler func(ctx context.Context, message Message) ([]*producer.Message, error)
+
+// transactionalConsumer describes the kafka consumer used by the TransactionalKafkaConsumer
+// copy of kafka.Consumer with methods used in this package for mocking/testing.
+type transactionalConsumer interface {
+	SubscribeTopics(topics []string, rebalanceCb kafka.RebalanceCb) error
+	Poll(timeoutMs int) (event kafka.Event)
+	Assign(partitions []kafka.TopicPartition) (err error)
+	Unassign() (err error)
+	Seek(partition kafka.TopicPartition, timeoutMs int) error
+	GetConsumerGroupMetadata() (*kafka.ConsumerGroupMetadata, error)
+	Close() error
+}
+
+// TransactionalKafkaConsumer consumes messages, transforms them and produces the results along with the consumed offsets
+// in Kafka transactions, so that a read-process-write pipeline is exactly-once.
+// Messages are processed sequentially, a transaction is committed every TransactionMaxMessages messages or TransactionInterval.
+type TransactionalKafkaConsumer struct {
+	config   *Config
+	handler  TransformHandler
+	consumer transactionalConsumer
+	producer producer.TransactionalProducer
+	closing  chan bool
+
+	healthMx *sync.RWMutex
+	health   *Health
+
+	errorLog ConsumerLogger
+	infoLog  ConsumerLogger
+	debugLog ConsumerLogger
+
+	// state of the current transaction, only used by the Pull go routine
+	inTransaction bool
+	txStarted     time.Time
+	txMessages    int
+	txStart       map[partition]kafka.TopicPartition // first offset of each partition in the transaction, to rewind on abort
+	txOffsets     map[partition]kafka.TopicPartition // offset of each partition committed with the transaction
+	fatal         error
+}
+
+// NewTransactional creates a TransactionalKafkaConsumer, the producer config needs a TransactionalID unique per consumer instance.
+func NewTransactional(config *Config, producerConfig *producer.Config, handler TransformHandler) (*TransactionalKafkaConsumer, error) {
+	if len(config.Address) == 0 || config.Group == "" || len(config.Topics) == 0 || handler == nil {
+		return nil, fmt.Errorf("some of the values are blank. TransformHandler, Address : %v, Group : %s and Topics : %v",
+			config.Address, config.Group, config.Topics)
+	}
+	if config.IsolationLevel != "" && config.IsolationLevel != ReadCommitted {
+		return nil, fmt.Errorf("IsolationLevel %q cannot be used for exactly-once processing, use %q", config.IsolationLevel, ReadCommitted)
+	}
+	if config.TransactionMaxMessages <= 0 || config.TransactionInterval <= 0 {
+		return nil, fmt.Errorf("TransactionMaxMessages : %d and TransactionInterval : %v should be positive",
+			config.TransactionMaxMessages, config.TransactionInterval)
+	}
+	if len(producerConfig.Address) == 0 {
+		producerConfig.Address = config.Address
+	}
+
+	p, err := producer.NewTransactionalProducer(producerConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transactional producer: %s", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+	if err = p.InitTransactions(ctx); err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to init transactions: %s", err)
+	}
+
+	configMap := kafka.ConfigMap{
+		"bootstrap.servers":               strings.Join(config.Address, ","),
+		"group.id":                        config.Group,
+		"go.application.rebalance.enable": true,
+		"auto.offset.reset":               getResetPosition(config),
+		"enable.auto.commit":              false, // offsets are committed by the transactions
+		"isolation.level":                 ReadCommitted,
+	}
+	if config.EnableKafkaLogs {
+		configMap["go.logs.channel.enable"] = true
+		configMap["log_level"] = config.LogLevel
+		if len(config.LoggedComponents) > 0 {
+			configMap["debug"] = config.LoggedComponents
+		}
+	}
+	c, err := NewClient(&configMap)
+	if err != nil {
+		p.Close()
+		return nil, fmt.Errorf("failed to create consumer: %s", err)
+	}
+
+	tc := newTransactional(config, c, p, handler)
+	if config.EnableKafkaLogs {
+		if ch := c.Logs(); ch != nil {
+			go tc.logKafkaLogs(ch) // logs chan is closed by kafka on Close()
+		}
+	}
+
+	rebalanceCb := func(cons *kafka.Consumer, ev kafka.Event) error {
+		tc.handleRebalance(ev)
+		return nil
+	}
+	if err = tc.consumer.SubscribeTopics(config.Topics, rebalanceCb); err != nil {
+		p.Close()
+		return nil, err
+	}
+	return tc, nil
+}
+
+func newTransactional(config *Config, c transactionalConsumer, p producer.TransactionalProducer, handler TransformHandler) *TransactionalKafkaConsumer {
+	tc := &TransactionalKafkaConsumer{
+		config:   config,
+		handler:  handler,
+		consumer: c,
+		producer: p,
+		closing:  make(chan bool, 1),
+
+		healthMx: &sync.RWMutex{},
+		health:   newHealth(),
+
+		errorLog: config.ErrorLog,
+		infoLog:  config.InfoLog,
+		debugLog: config.DebugLog,
+	}
+	tc.health.Group = config.Group
+	tc.health.Topics = config.Topics
+	tc.health.Address = config.Address
+	tc.resetTransaction()
+	return tc
+}
+
+// Pull starts polling kafka messages (blocks)
+// A fatal producer error stops pulling, the consumer should be recreated then.
+func (c *TransactionalKafkaConsumer) Pull() {
+	defer func() {
+		if err := c.consumer.Close(); err != nil {
+			c.invokeErrorHandler(err, nil)
+		}
+		c.producer.Close()
+	}()
+
+	for c.fatal == nil {
+		select {
+		case <-c.closing:
+			c.infof("Closing transactional consumer")
+			c.commitTransaction(false)
+			return
+		default:
+		}
+
+		switch e := c.consumer.Poll(100).(type) {
+		case *kafka.Message:
+			c.process(newMessage(e))
+		case kafka.Error:
+			if e.Code() == kafka.ErrAllBrokersDown {
+				c.setConnectionState(false)
+			}
+			c.invokeErrorHandler(e, nil)
+		}
+
+		if c.inTransaction && (c.txMessages >= c.config.TransactionMaxMessages || time.Since(c.txStarted) >= c.config.TransactionInterval) {
+			c.commitTransaction(true)
+		}
+	}
+	c.errorf("Transactional consumer stopped by a fatal error: %v", c.fatal)
+}
+
+// Close commits the current transaction and stops pulling messages from kafka
+func (c *TransactionalKafkaConsumer) Close() error {
+	c.closing <- true
+	close(c.closing)
+	return nil
+}
+
+// Health returns consumer's health
+func (c *TransactionalKafkaConsumer) Health() (Health, error) {
+	c.healthMx.RLock()
+	defer c.healthMx.RUnlock()
+	return *c.health, nil
+}
+
+func (c *TransactionalKafkaConsumer) setConnectionState(state bool) {
+	c.healthMx.Lock()
+	defer c.healthMx.Unlock()
+	c.health.ConnectionState = state
+}
+
+// handleRebalance commits the current transaction before the partitions are revoked, so their new owner continues after it
+func (c *TransactionalKafkaConsumer) handleRebalance(ev kafka.Event) {
+	switch e := ev.(type) {
+	case kafka.AssignedPartitions:
+		c.invokeNotificationHandler(fmt.Sprintf("%+v", e))
+		if err := c.consumer.Assign(e.Partitions); err != nil {
+			c.invokeErrorHandler(err, nil)
+			return
+		}
+		c.setConnectionState(true)
+	case kafka.RevokedPartitions:
+		c.invokeNotificationHandler(fmt.Sprintf("%+v", e))
+		c.commitTransaction(false)
+		if err := c.consumer.Unassign(); err != nil {
+			c.invokeErrorHandler(err, nil)
+		}
+	}
+}
+
+func (c *TransactionalKafkaConsumer) process(message *Message) {
+	if !c.inTransaction {
+		if err := c.producer.BeginTransaction(); err != nil {
+			c.failTransaction(fmt.Errorf("failed to begin transaction: %w", err), true, message)
+			return
+		}
+		c.inTransaction = true
+		c.txStarted = time.Now()
+	}
+
+	p := partition{topic: message.Topic, partition: message.Partition}
+	if _, ok := c.txStart[p]; !ok {
+		c.txStart[p] = kafka.TopicPartition{Topic: &message.Topic, Partition: message.Partition, Offset: kafka.Offset(message.Offset)}
+	}
+
+	outputs, err := c.transform(message)
+	if err != nil {
+		// same as the other consumers the message is dropped after the retries, its offset is committed without outputs
+		c.invokeErrorHandler(err, message)
+	} else if len(outputs) > 0 {
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		defer cancel()
+		if err = c.producer.Produce(ctx, message.GetTransactionID(), outputs...); err != nil {
+			c.failTransaction(fmt.Errorf("failed to produce transformed messages: %w", err), true, message)
+			return
+		}
+	}
+
+	// consumer starts at N, so we have to commit N + 1
+	c.txOffsets[p] = kafka.TopicPartition{Topic: &message.Topic, Partition: message.Partition, Offset: kafka.Offset(message.Offset + 1)}
+	c.txMessages++
+}
+
+// transform invokes the handler, retrying according to RetryCount and RetryDelay
+func (c *TransactionalKafkaConsumer) transform(message *Message) ([]*producer.Message, error) {
+	var err error
+	for retry := int64(0); retry <= c.config.RetryCount; retry++ {
+		if retry > 0 {
+			time.Sleep(c.config.RetryDelay)
+		}
+		var outputs []*producer.Message
+		if outputs, err = c.invokeTransformHandler(message); err == nil {
+			return outputs, nil
+		}
+	}
+	return nil, err
+}
+
+func (c *TransactionalKafkaConsumer) invokeTransformHandler(message *Message) (outputs []*producer.Message, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("invokeTransformHandler.Panic: While processing %v, trace : %s", r, string(debug.Stack()))
+		}
+	}()
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+	return c.handler(ctx, *message)
+}
+
+// commitTransaction sends the consumed offsets to the transaction and commits it, both are retried with a backoff on retriable errors
+// until Timeout. The transaction is never committed without its offsets.
+// On failure the transaction is aborted and, if rewind is true, the partitions are rewound to consume its messages again.
+func (c *TransactionalKafkaConsumer) commitTransaction(rewind bool) {
+	if !c.inTransaction {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+	defer cancel()
+
+	offsets := make([]kafka.TopicPartition, 0, len(c.txOffsets))
+	for _, tp := range c.txOffsets {
+		offsets = append(offsets, tp)
+	}
+	if len(offsets) > 0 {
+		err := retryTransactional(ctx, func() error {
+			metadata, err := c.consumer.GetConsumerGroupMetadata()
+			if err != nil {
+				return err
+			}
+			return c.producer.SendOffsetsToTransaction(ctx, offsets, metadata)
+		})
+		if err != nil {
+			c.failTransaction(fmt.Errorf("failed to send offsets to transaction: %w", err), rewind, nil)
+			return
+		}
+	}
+	if err := retryTransactional(ctx, func() error { return c.producer.CommitTransaction(ctx) }); err != nil {
+		c.failTransaction(fmt.Errorf("failed to commit transaction: %w", err), rewind, nil)
+		return
+	}
+	c.debugf("Committed transaction of %d messages, offsets: %v", c.txMessages, offsets)
+	c.resetTransaction()
+}
+
+// retryTransactional calls op until it succeeds, fails with an error which is not retriable or ctx is done
+func retryTransactional(ctx context.Context, op func() error) error {
+	backoff := transactionRetryBackoff
+	for {
+		err := op()
+		if err == nil || !isRetriable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > transactionMaxRetryBackoff {
+			backoff = transactionMaxRetryBackoff
+		}
+	}
+}
+
+// failTransaction reports the error and aborts the current transaction.
+// If rewind is true, the partitions are rewound to the first offsets of the transaction to consume its messages again.
+func (c *TransactionalKafkaConsumer) failTransaction(err error, rewind bool, message *Message) {
+	c.invokeErrorHandler(err, message)
+	if isFatal(err) {
+		c.fatal = err
+		return
+	}
+
+	if c.inTransaction {
+		ctx, cancel := context.WithTimeout(context.Background(), c.config.Timeout)
+		defer cancel()
+		if abortErr := c.producer.AbortTransaction(ctx); abortErr != nil {
+			c.invokeErrorHandler(fmt.Errorf("failed to abort transaction: %w", abortErr), nil)
+			if isFatal(abortErr) {
+				c.fatal = abortErr
+				return
+			}
+		}
+	}
+
+	if rewind {
+		for _, tp := range c.txStart {
+			if seekErr := c.consumer.Seek(tp, int(c.config.Timeout/time.Millisecond)); seekErr != nil {
+				c.invokeErrorHandler(fmt.Errorf("failed to rewind %s to %v: %w", *tp.Topic, tp.Offset, seekErr), nil)
+			}
+		}
+		// a message whose transaction could not begin is consumed again as well
+		if message != nil {
+			if _, ok := c.txStart[partition{topic: message.Topic, partition: message.Partition}]; !ok {
+				tp := kafka.TopicPartition{Topic: &message.Topic, Partition: message.Partition, Offset: kafka.Offset(message.Offset)}
+				if seekErr := c.consumer.Seek(tp, int(c.config.Timeout/time.Millisecond)); seekErr != nil {
+					c.invokeErrorHandler(fmt.Errorf("failed to rewind %s to %v: %w", message.Topic, message.Offset, seekErr), nil)
+				}
+			}
+		}
+	}
+	c.resetTransaction()
+}
+
+func (c *TransactionalKafkaConsumer) resetTransaction() {
+	c.inTransaction = false
+	c.txMessages = 0
+	c.txStart = make(map[partition]kafka.TopicPartition)
+	c.txOffsets = make(map[partition]kafka.TopicPartition)
+}
+
+// isRetriable tells whether the operation can be retried, e.g. for a kafka.Error
+func isRetriable(err error) bool {
+	var rerr interface{ IsRetriable() bool }
+	return errors.As(err, &rerr) && rerr.IsRetriable()
+}
+
+func isFatal(err error) bool {
+	var kerr kafka.Error
+	return errors.As(err, &kerr) && kerr.IsFatal()
+}
+
+func (c *TransactionalKafkaConsumer) logKafkaLogs(ch <-chan kafka.LogEvent) {
+	for l := range ch {
+		switch {
+		case l.Level <= 2:
+			c.errorf("[%s] [%s] %s", l.Name, l.Tag, l.Message)
+		case l.Level <= 6:
+			c.infof("[%s] [%s] %s", l.Name, l.Tag, l.Message)
+		default:
+			c.debugf("[%s] [%s] %s", l.Name, l.Tag, l.Message)
+		}
+	}
+}
+
+func (c *TransactionalKafkaConsumer) errorf(format string, v ...interface{}) {
+	if c.errorLog != nil {
+		c.errorLog.Printf(format, v...)
+	}
+}
+func (c *TransactionalKafkaConsumer) infof(format string, v ...interface{}) {
+	if c.infoLog != nil {
+		c.infoLog.Printf(format, v...)
+	}
+}
+func (c *TransactionalKafkaConsumer) debugf(format string, v ...interface{}) {
+	if c.debugLog != nil {
+		c.debugLog.Printf(format, v...)
+	}
+}
+
+func (c *TransactionalKafkaConsumer) invokeNotificationHandler(notification string) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.invokeErrorHandler(
+				fmt.Errorf("invokeNotificationHandler.panic: While processing %v, trace : %s", r, string(debug.Stack())),
+				nil,
+			)
+		}
+	}()
+	if c.config.NotificationHandler != nil {
+		c.config.NotificationHandler(notification)
+	}
+}
+
+func (c *TransactionalKafkaConsumer) invokeErrorHandler(err error, message *Message) {
+	if c.config.ErrorHandler == nil {
+		c.debugf("Transactional consumer failed with : %v", err)
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), c.config.ErrorHandlingTimeout)
+	defer cancel()
+
+	resultCh := make(chan interface{})
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				c.errorf("Panic: While processing %v, trace : %s", r, string(debug.Stack()))
+			}
+		}()
+		c.config.ErrorHandler(ctx, err, message)
+		close(resultCh)
+	}()
+	select {
+	case <-ctx.Done():
+		c.debugf("Error Handling TimedOut for err=%v", err)
+	case <-resultCh:
+	}
+}
@@ -0,0 +1,295 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/stretchr/testify/require"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
+)
+
+type fakeTransactionalConsumer struct {
+	seeks      []kafka.TopicPartition
+	assigned   []kafka.TopicPartition
+	unassigned int
+}
+
+func (f *fakeTransactionalConsumer) SubscribeTopics(topics []string, rebalanceCb kafka.RebalanceCb) error {
+	return nil
+}
+func (f *fakeTransactionalConsumer) Poll(timeoutMs int) kafka.Event { return nil }
+func (f *fakeTransactionalConsumer) Assign(partitions []kafka.TopicPartition) error {
+	f.assigned = partitions
+	return nil
+}
+func (f *fakeTransactionalConsumer) Unassign() error {
+	f.unassigned++
+	return nil
+}
+func (f *fakeTransactionalConsumer) Seek(partition kafka.TopicPartition, timeoutMs int) error {
+	f.seeks = append(f.seeks, partition)
+	return nil
+}
+func (f *fakeTransactionalConsumer) GetConsumerGroupMetadata() (*kafka.ConsumerGroupMetadata, error) {
+	return &kafka.ConsumerGroupMetadata{}, nil
+}
+func (f *fakeTransactionalConsumer) Close() error { return nil }
+
+type fakeTransactionalProducer struct {
+	calls      []string
+	produced   []*producer.Message
+	offsets    []kafka.TopicPartition
+	produceErr error
+	offsetErrs []error // returned by the successive SendOffsetsToTransaction calls
+	commitErrs []error // returned by the successive CommitTransaction calls
+}
+
+type retriableError struct{}
+
+func (retriableError) Error() string     { return "retriable" }
+func (retriableError) IsRetriable() bool { return true }
+
+func nextErr(errs *[]error) error {
+	if len(*errs) == 0 {
+		return nil
+	}
+	err := (*errs)[0]
+	*errs = (*errs)[1:]
+	return err
+}
+
+func (f *fakeTransactionalProducer) InitTransactions(ctx context.Context) error { return nil }
+func (f *fakeTransactionalProducer) BeginTransaction() error {
+	f.calls = append(f.calls, "begin")
+	return nil
+}
+func (f *fakeTransactionalProducer) Produce(ctx context.Context, transaction string, messages ...*producer.Message) error {
+	f.calls = append(f.calls, "produce")
+	if f.produceErr != nil {
+		return f.produceErr
+	}
+	f.produced = append(f.produced, messages...)
+	return nil
+}
+func (f *fakeTransactionalProducer) SendOffsetsToTransaction(ctx context.Context, offsets []kafka.TopicPartition, metadata *kafka.ConsumerGroupMetadata) error {
+	f.calls = append(f.calls, "offsets")
+	if err := nextErr(&f.offsetErrs); err != nil {
+		return err
+	}
+	f.offsets = append(f.offsets, offsets...)
+	return nil
+}
+func (f *fakeTransactionalProducer) CommitTransaction(ctx context.Context) error {
+	f.calls = append(f.calls, "commit")
+	return nextErr(&f.commitErrs)
+}
+func (f *fakeTransactionalProducer) AbortTransaction(ctx context.Context) error {
+	f.calls = append(f.calls, "abort")
+	return nil
+}
+func (f *fakeTransactionalProducer) Close()                            {}
+func (f *fakeTransactionalProducer) Health() (*producer.Health, error) { return nil, nil }
+
+func transactionalMessage(topic string, p int32, offset int64) *Message {
+	return &Message{Topic: topic, Partition: p, Offset: offset, Message: []byte("in"), transactionID: "1"}
+}
+
+func sortedOffsets(tps []kafka.TopicPartition) []int64 {
+	offsets := make([]int64, 0, len(tps))
+	for _, tp := range tps {
+		offsets = append(offsets, int64(tp.Offset))
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+	return offsets
+}
+
+func TestTransactionalKafkaConsumer(t *testing.T) {
+	oops := errors.New("oops")
+	tests := []struct {
+		name         string
+		produceErr   error
+		offsetErrs   []error
+		commitErrs   []error
+		handler      TransformHandler
+		wantCalls    []string
+		wantProduced int
+		wantOffsets  []int64 // offsets sent to the transaction
+		wantSeeks    []int64 // offsets the partitions are rewound to
+		wantErrors   int
+	}{
+		{
+			name:         "Committed with outputs and offsets",
+			wantCalls:    []string{"begin", "produce", "produce", "offsets", "commit"},
+			wantProduced: 2,
+			wantOffsets:  []int64{11, 21},
+		},
+		{
+			name: "Failed message offset committed without outputs",
+			handler: func(ctx context.Context, message Message) ([]*producer.Message, error) {
+				if message.Partition == 1 {
+					return nil, oops
+				}
+				return []*producer.Message{{Topic: "out", Value: message.Message}}, nil
+			},
+			wantCalls:    []string{"begin", "produce", "offsets", "commit"},
+			wantProduced: 1,
+			wantOffsets:  []int64{11, 21},
+			wantErrors:   1,
+		},
+		{
+			name:       "Aborted and rewound when produce fails",
+			produceErr: oops,
+			wantCalls:  []string{"begin", "produce", "abort"},
+			wantSeeks:  []int64{10},
+			wantErrors: 1,
+		},
+		{
+			name:         "Aborted and rewound when commit fails",
+			commitErrs:   []error{oops},
+			wantCalls:    []string{"begin", "produce", "produce", "offsets", "commit", "abort"},
+			wantProduced: 2,
+			wantOffsets:  []int64{11, 21},
+			wantSeeks:    []int64{10, 20},
+			wantErrors:   1,
+		},
+		{
+			name:         "Commit retried after retriable errors",
+			commitErrs:   []error{retriableError{}, retriableError{}},
+			wantCalls:    []string{"begin", "produce", "produce", "offsets", "commit", "commit", "commit"},
+			wantProduced: 2,
+			wantOffsets:  []int64{11, 21},
+		},
+		{
+			name:         "Offsets sent again after a retriable error",
+			offsetErrs:   []error{retriableError{}},
+			wantCalls:    []string{"begin", "produce", "produce", "offsets", "offsets", "commit"},
+			wantProduced: 2,
+			wantOffsets:  []int64{11, 21},
+		},
+		{
+			name:         "Aborted and rewound when sending offsets fails",
+			offsetErrs:   []error{oops},
+			wantCalls:    []string{"begin", "produce", "produce", "offsets", "abort"},
+			wantProduced: 2,
+			wantSeeks:    []int64{10, 20},
+			wantErrors:   1,
+		},
+		{
+			name:         "Aborted and rewound when sending offsets times out",
+			offsetErrs:   []error{retriableError{}, retriableError{}, retriableError{}, retriableError{}, retriableError{}},
+			wantCalls:    []string{"begin", "produce", "produce", "offsets", "offsets", "offsets", "offsets", "abort"},
+			wantProduced: 2,
+			wantSeeks:    []int64{10, 20},
+			wantErrors:   1,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			handler := tt.handler
+			if handler == nil {
+				handler = func(ctx context.Context, message Message) ([]*producer.Message, error) {
+					return []*producer.Message{{Topic: "out", Value: message.Message}}, nil
+				}
+			}
+			errs := 0
+			config := NewConfig()
+			config.RetryCount = 0
+			config.Timeout = time.Second
+			config.ErrorHandlingTimeout = time.Second
+			config.ErrorHandler = func(ctx context.Context, err error, message *Message) { errs++ }
+
+			kc := &fakeTransactionalConsumer{}
+			kp := &fakeTransactionalProducer{produceErr: tt.produceErr, offsetErrs: tt.offsetErrs, commitErrs: tt.commitErrs}
+			c := newTransactional(config, kc, kp, handler)
+
+			c.process(transactionalMessage("in", 0, 10))
+			if tt.produceErr == nil {
+				c.process(transactionalMessage("in", 1, 20))
+			}
+			c.commitTransaction(true)
+
+			require.Equal(t, tt.wantCalls, kp.calls)
+			require.Len(t, kp.produced, tt.wantProduced)
+			require.Equal(t, tt.wantOffsets, nilIfEmpty(sortedOffsets(kp.offsets)))
+			require.Equal(t, tt.wantSeeks, nilIfEmpty(sortedOffsets(kc.seeks)))
+			require.Equal(t, tt.wantErrors, errs)
+			require.False(t, c.inTransaction)
+			require.NoError(t, c.fatal)
+		})
+	}
+}
+
+func TestTransactionalKafkaConsumer_fatalError(t *testing.T) {
+	config := NewConfig()
+	config.Timeout = time.Second
+	kp := &fakeTransactionalProducer{commitErrs: []error{kafka.NewError(kafka.ErrFenced, "fenced", true)}}
+	c := newTransactional(config, &fakeTransactionalConsumer{}, kp, func(ctx context.Context, message Message) ([]*producer.Message, error) {
+		return nil, nil
+	})
+
+	c.process(transactionalMessage("in", 0, 10))
+	c.commitTransaction(true)
+
+	require.Error(t, c.fatal)
+	require.NotContains(t, kp.calls, "abort", "a fenced producer cannot abort")
+}
+
+func TestTransactionalKafkaConsumer_handleRebalance(t *testing.T) {
+	config := NewConfig()
+	config.Timeout = time.Second
+	kc := &fakeTransactionalConsumer{}
+	kp := &fakeTransactionalProducer{}
+	c := newTransactional(config, kc, kp, func(ctx context.Context, message Message) ([]*producer.Message, error) {
+		return nil, nil
+	})
+
+	topic := "in"
+	c.handleRebalance(kafka.AssignedPartitions{Partitions: []kafka.TopicPartition{{Topic: &topic, Partition: 0}}})
+	require.Len(t, kc.assigned, 1)
+	health, _ := c.Health()
+	require.True(t, health.ConnectionState)
+
+	c.process(transactionalMessage("in", 0, 10))
+	c.handleRebalance(kafka.RevokedPartitions{Partitions: []kafka.TopicPartition{{Topic: &topic, Partition: 0}}})
+	require.Equal(t, []string{"begin", "offsets", "commit"}, kp.calls, "the transaction is committed before the partitions are revoked")
+	require.Equal(t, []int64{11}, sortedOffsets(kp.offsets))
+	require.Equal(t, 1, kc.unassigned)
+	require.False(t, c.inTransaction)
+}
+
+func TestNewTransactional_validation(t *testing.T) {
+	handler := func(ctx context.Context, message Message) ([]*producer.Message, error) { return nil, nil }
+	tests := []struct {
+		name    string
+		modify  func(c *Config)
+		handler TransformHandler
+	}{
+		{name: "No handler", modify: func(c *Config) {}},
+		{name: "No topics", modify: func(c *Config) { c.Topics = nil }, handler: handler},
+		{name: "Read uncommitted", modify: func(c *Config) { c.IsolationLevel = ReadUncommitted }, handler: handler},
+		{name: "No transaction interval", modify: func(c *Config) { c.TransactionInterval = 0 }, handler: handler},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := NewConfig()
+			config.Address = []string{"localhost:9092"}
+			config.Group = "group"
+			config.Topics = []string{"in"}
+			tt.modify(config)
+			_, err := NewTransactional(config, &producer.Config{TransactionalID: "tx"}, tt.handler)
+			require.Error(t, err)
+		})
+	}
+}
+
+func nilIfEmpty(offsets []int64) []int64 {
+	if len(offsets) == 0 {
+		return nil
+	}
+	return offsets
+}
@@ -0,0 +1,60 @@
+package kafkatest
+
+import (
+	"github.com/Shopify/sarama"
+	cluster "github.com/bsm/sarama-cluster"
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+
+	saramaconsumer "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging-sarama/consumer"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging-sarama/publisher"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/consumer"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
+)
+
+// attach wraps the client factories of the packages, so that clients configured with the address of a cluster
+// are created on it while the others are still created by the previous factories
+func attach() {
+	newConsumer := consumer.NewClient
+	consumer.NewClient = func(configMap *kafka.ConfigMap) (consumer.Client, error) {
+		if c := lookup(configString(configMap, "bootstrap.servers", "")); c != nil {
+			cc, err := newConfluentConsumer(c, configMap)
+			if err != nil {
+				return nil, err
+			}
+			return cc, nil
+		}
+		return newConsumer(configMap)
+	}
+
+	newProducer := producer.NewClient
+	producer.NewClient = func(configMap *kafka.ConfigMap) (producer.Client, error) {
+		if c := lookup(configString(configMap, "bootstrap.servers", "")); c != nil {
+			return newConfluentProducer(c, configMap), nil
+		}
+		return newProducer(configMap)
+	}
+
+	newSaramaConsumer := saramaconsumer.NewClusterConsumer
+	saramaconsumer.NewClusterConsumer = func(addrs []string, groupID string, topics []string, config *cluster.Config) (saramaconsumer.ClusterConsumer, error) {
+		if c := lookup(addrs...); c != nil {
+			cc, err := newClusterConsumer(c, groupID, topics, config)
+			if err != nil {
+				return nil, err
+			}
+			return cc, nil
+		}
+		return newSaramaConsumer(addrs, groupID, topics, config)
+	}
+
+	newSyncProducer := publisher.NewSaramaSyncProducer
+	publisher.NewSaramaSyncProducer = func(addrs []string, config *sarama.Config) (sarama.SyncProducer, error) {
+		if c := lookup(addrs...); c != nil {
+			p, err := newSaramaSyncProducer(c, config)
+			if err != nil {
+				return nil, err
+			}
+			return p, nil
+		}
+		return newSyncProducer(addrs, config)
+	}
+}
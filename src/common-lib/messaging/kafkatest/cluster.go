@@ -0,0 +1,555 @@
+// Package kafkatest provides an in-memory Kafka cluster to test code using the Kafka clients of this repository without a broker.
+//
+// A Cluster has topics with partitions, consumer groups with rebalancing, committed offsets, headers and transactions.
+// The clients of messaging/consumer, messaging/producer, messaging-sarama/consumer and messaging-sarama/publisher
+// attach to a cluster when they are configured with its Address, the clients of the kafka package through its factories.
+package kafkatest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+var (
+	// ErrTopicExists : Error the topic already exists
+	ErrTopicExists = errors.New("KafkaTest:Topic:Exists")
+
+	// ErrInvalidPartitions : Error the number of partitions of a topic is not positive
+	ErrInvalidPartitions = errors.New("KafkaTest:Partitions:Invalid")
+
+	// ErrUnknownTopicOrPartition : Error the topic or the partition does not exist
+	ErrUnknownTopicOrPartition = errors.New("KafkaTest:TopicOrPartition:Unknown")
+)
+
+var (
+	clustersMx sync.RWMutex
+	clusters   = make(map[string]*Cluster)
+	clusterSeq int
+	attachOnce sync.Once
+)
+
+// Header is a header of a record
+type Header struct {
+	Key   string
+	Value []byte
+}
+
+// Record is a message stored in a partition
+type Record struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+	Headers   []Header
+	Timestamp time.Time
+}
+
+func (r Record) copy() Record {
+	r.Key = copyBytes(r.Key)
+	r.Value = copyBytes(r.Value)
+	if r.Headers != nil {
+		headers := make([]Header, len(r.Headers))
+		for i, h := range r.Headers {
+			headers[i] = Header{Key: h.Key, Value: copyBytes(h.Value)}
+		}
+		r.Headers = headers
+	}
+	return r
+}
+
+func copyBytes(b []byte) []byte {
+	if b == nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+type topicPartition struct {
+	topic     string
+	partition int32
+}
+
+type transactionState int
+
+const (
+	transactionOpen transactionState = iota
+	transactionCommitted
+	transactionAborted
+)
+
+// transaction groups the records and the consumer offsets of a producer transaction
+type transaction struct {
+	state   transactionState
+	offsets map[string]map[topicPartition]int64 // offsets by consumer group
+}
+
+type record struct {
+	Record
+	transaction *transaction
+}
+
+type group struct {
+	id         string
+	generation int
+	members    []*member // in joining order
+	committed  map[topicPartition]int64
+	owners     map[topicPartition]*member
+}
+
+// member is a consumer of a group, its partitions are revoked on each rebalance and then assigned
+// once released by the other members, like with the eager rebalance protocol
+type member struct {
+	group      *group
+	topics     map[string]bool
+	target     []topicPartition // assignment of the group generation
+	generation int              // generation of the owned partitions
+	owned      []topicPartition
+	assigned   bool
+	revoking   bool
+	left       bool
+}
+
+type rebalanceStep int
+
+const (
+	noRebalance rebalanceStep = iota
+	revokePartitions
+	assignPartitions
+)
+
+// Cluster is an in-memory Kafka cluster, safe for concurrent use.
+type Cluster struct {
+	address string
+
+	mx            sync.Mutex
+	topics        map[string][][]record
+	groups        map[string]*group
+	groupMetadata map[*kafka.ConsumerGroupMetadata]string
+	changed       chan struct{} // closed and replaced on each change
+}
+
+// NewCluster creates an empty cluster and attaches the Kafka clients of this repository to it.
+func NewCluster() *Cluster {
+	attachOnce.Do(attach)
+
+	clustersMx.Lock()
+	defer clustersMx.Unlock()
+	clusterSeq++
+	c := &Cluster{
+		address:       fmt.Sprintf("kafkatest://%d", clusterSeq),
+		topics:        make(map[string][][]record),
+		groups:        make(map[string]*group),
+		groupMetadata: make(map[*kafka.ConsumerGroupMetadata]string),
+		changed:       make(chan struct{}),
+	}
+	clusters[c.address] = c
+	return c
+}
+
+// lookup returns the cluster of the first of the comma separated addresses served by a cluster, or nil
+func lookup(addresses ...string) *Cluster {
+	clustersMx.RLock()
+	defer clustersMx.RUnlock()
+	for _, address := range addresses {
+		for _, a := range strings.Split(address, ",") {
+			if c, ok := clusters[strings.TrimSpace(a)]; ok {
+				return c
+			}
+		}
+	}
+	return nil
+}
+
+// Address returns the bootstrap address of the cluster, to be used as the Address of the clients' configuration.
+func (c *Cluster) Address() string {
+	return c.address
+}
+
+// Close detaches the cluster, clients cannot be created for its address anymore.
+func (c *Cluster) Close() {
+	clustersMx.Lock()
+	defer clustersMx.Unlock()
+	delete(clusters, c.address)
+}
+
+// CreateTopic creates a topic with partitions.
+// Topics are created with one partition when they are produced to or consumed before being created.
+func (c *Cluster) CreateTopic(name string, partitions int32) error {
+	if partitions <= 0 {
+		return ErrInvalidPartitions
+	}
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if _, ok := c.topics[name]; ok {
+		return fmt.Errorf("%w: %s", ErrTopicExists, name)
+	}
+	c.createTopic(name, partitions)
+	return nil
+}
+
+// Produce appends a record to a partition of the topic and returns its offset.
+func (c *Cluster) Produce(topic string, partition int32, key, value []byte, headers ...Header) (int64, error) {
+	r, err := c.append(Record{
+		Topic:     topic,
+		Partition: partition,
+		Key:       key,
+		Value:     value,
+		Headers:   headers,
+	}, nil)
+	return r.Offset, err
+}
+
+// Records returns the records of a partition visible to read_committed consumers:
+// records of open and aborted transactions are left out.
+func (c *Cluster) Records(topic string, partition int32) []Record {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	var records []Record
+	for _, r := range c.partition(topicPartition{topic, partition}) {
+		if r.transaction != nil && r.transaction.state != transactionCommitted {
+			continue
+		}
+		records = append(records, r.Record.copy())
+	}
+	return records
+}
+
+// CommittedOffset returns the offset committed by the consumer group for the partition, -1 when there is none.
+// As in Kafka the committed offset is the offset of the next record to consume.
+func (c *Cluster) CommittedOffset(group, topic string, partition int32) int64 {
+	if offset, ok := c.committed(group, topicPartition{topic, partition}); ok {
+		return offset
+	}
+	return -1
+}
+
+// Partitions returns the number of partitions of the topic, creating it if it does not exist.
+func (c *Cluster) Partitions(topic string) int32 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if _, ok := c.topics[topic]; !ok {
+		c.createTopic(topic, 1)
+	}
+	return int32(len(c.topics[topic]))
+}
+
+// Members returns the partitions owned by each member of the consumer group, in joining order.
+func (c *Cluster) Members(group string) [][]kafka.TopicPartition {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	g, ok := c.groups[group]
+	if !ok {
+		return nil
+	}
+	members := make([][]kafka.TopicPartition, 0, len(g.members))
+	for _, m := range g.members {
+		members = append(members, toTopicPartitions(m.owned))
+	}
+	return members
+}
+
+// createTopic creates the topic and rebalances the groups subscribed to it, c.mx is held
+func (c *Cluster) createTopic(name string, partitions int32) {
+	c.topics[name] = make([][]record, partitions)
+	for _, g := range c.groups {
+		for _, m := range g.members {
+			if m.topics[name] {
+				c.rebalance(g)
+				break
+			}
+		}
+	}
+	c.notify()
+}
+
+// partition returns the records of the partition, c.mx is held
+func (c *Cluster) partition(tp topicPartition) []record {
+	partitions := c.topics[tp.topic]
+	if tp.partition < 0 || int(tp.partition) >= len(partitions) {
+		return nil
+	}
+	return partitions[tp.partition]
+}
+
+// notify wakes up the clients waiting for a change, c.mx is held
+func (c *Cluster) notify() {
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// changes returns a channel closed on the next change, to be taken before checking the state to wait for
+func (c *Cluster) changes() <-chan struct{} {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return c.changed
+}
+
+// append appends the record to its partition, as part of the transaction if not nil, and returns it as stored
+func (c *Cluster) append(r Record, t *transaction) (Record, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if _, ok := c.topics[r.Topic]; !ok {
+		c.createTopic(r.Topic, 1)
+	}
+	partitions := c.topics[r.Topic]
+	if r.Partition < 0 || int(r.Partition) >= len(partitions) {
+		return Record{}, fmt.Errorf("%w: %s[%d]", ErrUnknownTopicOrPartition, r.Topic, r.Partition)
+	}
+	r = r.copy()
+	r.Offset = int64(len(partitions[r.Partition]))
+	if r.Timestamp.IsZero() {
+		r.Timestamp = time.Now()
+	}
+	partitions[r.Partition] = append(partitions[r.Partition], record{Record: r, transaction: t})
+	c.notify()
+	return r, nil
+}
+
+// fetch returns the first record from the offset that is visible with the isolation level and the offset following it,
+// or nil and the offset to fetch from next time when there is none yet.
+// Like the last stable offset, an open transaction blocks read_committed consumers.
+func (c *Cluster) fetch(tp topicPartition, offset int64, readCommitted bool) (*Record, int64) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	records := c.partition(tp)
+	for ; offset < int64(len(records)); offset++ {
+		r := records[offset]
+		if readCommitted && r.transaction != nil {
+			if r.transaction.state == transactionOpen {
+				return nil, offset
+			}
+			if r.transaction.state == transactionAborted {
+				continue
+			}
+		}
+		record := r.Record.copy()
+		return &record, offset + 1
+	}
+	return nil, offset
+}
+
+// highWatermark returns the offset of the next record of the partition
+func (c *Cluster) highWatermark(tp topicPartition) int64 {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	return int64(len(c.partition(tp)))
+}
+
+func (c *Cluster) committed(groupID string, tp topicPartition) (int64, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	g, ok := c.groups[groupID]
+	if !ok {
+		return 0, false
+	}
+	offset, ok := g.committed[tp]
+	return offset, ok
+}
+
+func (c *Cluster) commit(groupID string, offsets map[topicPartition]int64) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	g := c.group(groupID)
+	for tp, offset := range offsets {
+		g.committed[tp] = offset
+	}
+	c.notify()
+}
+
+// endTransaction commits or aborts the records and the consumer offsets of the transaction
+func (c *Cluster) endTransaction(t *transaction, commit bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if !commit {
+		t.state = transactionAborted
+		c.notify()
+		return
+	}
+	t.state = transactionCommitted
+	for groupID, offsets := range t.offsets {
+		g := c.group(groupID)
+		for tp, offset := range offsets {
+			g.committed[tp] = offset
+		}
+	}
+	c.notify()
+}
+
+// group returns the consumer group, creating it if needed, c.mx is held
+func (c *Cluster) group(id string) *group {
+	g, ok := c.groups[id]
+	if !ok {
+		g = &group{
+			id:        id,
+			committed: make(map[topicPartition]int64),
+			owners:    make(map[topicPartition]*member),
+		}
+		c.groups[id] = g
+	}
+	return g
+}
+
+// join adds a member subscribed to the topics to the consumer group
+func (c *Cluster) join(groupID string, topics []string) *member {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	g := c.group(groupID)
+	m := &member{group: g}
+	g.members = append(g.members, m)
+	c.subscribe(m, topics)
+	return m
+}
+
+// subscribe replaces the topics of the member, creating the missing ones, and rebalances its group, c.mx is held
+func (c *Cluster) subscribe(m *member, topics []string) {
+	m.topics = make(map[string]bool, len(topics))
+	for _, t := range topics {
+		if _, ok := c.topics[t]; !ok {
+			c.createTopic(t, 1)
+		}
+		m.topics[t] = true
+	}
+	c.rebalance(m.group)
+}
+
+// resubscribe replaces the topics of the member
+func (c *Cluster) resubscribe(m *member, topics []string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.subscribe(m, topics)
+}
+
+// leave removes the member from its group, releasing its partitions
+func (c *Cluster) leave(m *member) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if m.left {
+		return
+	}
+	m.left = true
+	c.releaseOwned(m)
+	g := m.group
+	for i, gm := range g.members {
+		if gm == m {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			break
+		}
+	}
+	c.rebalance(g)
+}
+
+// rebalance starts a new generation of the group: the partitions of each subscribed topic are assigned to
+// the members subscribed to it in round-robin, c.mx is held
+func (c *Cluster) rebalance(g *group) {
+	g.generation++
+	var topics []string
+	for topic := range c.topics {
+		topics = append(topics, topic)
+	}
+	sort.Strings(topics)
+
+	targets := make(map[*member][]topicPartition, len(g.members))
+	for _, topic := range topics {
+		var subscribers []*member
+		for _, m := range g.members {
+			if m.topics[topic] {
+				subscribers = append(subscribers, m)
+			}
+		}
+		if len(subscribers) == 0 {
+			continue
+		}
+		for p := range c.topics[topic] {
+			m := subscribers[p%len(subscribers)]
+			targets[m] = append(targets[m], topicPartition{topic, int32(p)})
+		}
+	}
+	for _, m := range g.members {
+		m.target = targets[m]
+	}
+	c.notify()
+}
+
+// rebalanceStep returns the next rebalance step of the member: revoking its partitions on a new generation,
+// then being assigned the partitions of the generation once the other members released them.
+// The partitions of a revoke step are owned until the member releases them.
+func (c *Cluster) rebalanceStep(m *member) (rebalanceStep, []topicPartition) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	g := m.group
+	if m.left || m.revoking {
+		return noRebalance, nil
+	}
+	if m.assigned {
+		if m.generation == g.generation {
+			return noRebalance, nil
+		}
+		if len(m.owned) > 0 {
+			m.revoking = true
+			return revokePartitions, m.owned
+		}
+		m.assigned = false
+	}
+	for _, tp := range m.target {
+		if owner, ok := g.owners[tp]; ok && owner != m {
+			return noRebalance, nil
+		}
+	}
+	for _, tp := range m.target {
+		g.owners[tp] = m
+	}
+	m.assigned, m.generation, m.owned = true, g.generation, m.target
+	return assignPartitions, m.target
+}
+
+// release releases the partitions revoked by the member
+func (c *Cluster) release(m *member) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if !m.revoking {
+		return
+	}
+	c.releaseOwned(m)
+	c.notify()
+}
+
+// releaseOwned releases the partitions owned by the member, c.mx is held
+func (c *Cluster) releaseOwned(m *member) {
+	for _, tp := range m.owned {
+		if m.group.owners[tp] == m {
+			delete(m.group.owners, tp)
+		}
+	}
+	m.owned, m.assigned, m.revoking = nil, false, false
+}
+
+// registerGroupMetadata remembers the consumer group of metadata returned to a consumer, for the transactions
+func (c *Cluster) registerGroupMetadata(metadata *kafka.ConsumerGroupMetadata, groupID string) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.groupMetadata[metadata] = groupID
+}
+
+func (c *Cluster) groupOf(metadata *kafka.ConsumerGroupMetadata) (string, bool) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	groupID, ok := c.groupMetadata[metadata]
+	return groupID, ok
+}
+
+// toTopicPartitions converts the partitions without offset, as in the rebalance events
+func toTopicPartitions(tps []topicPartition) []kafka.TopicPartition {
+	partitions := make([]kafka.TopicPartition, 0, len(tps))
+	for _, tp := range tps {
+		topic := tp.topic
+		partitions = append(partitions, kafka.TopicPartition{Topic: &topic, Partition: tp.partition, Offset: kafka.OffsetInvalid})
+	}
+	return partitions
+}
@@ -0,0 +1,124 @@
+package kafkatest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCluster(t *testing.T) *Cluster {
+	c := NewCluster()
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestClusterCreateTopic(t *testing.T) {
+	c := newTestCluster(t)
+
+	require.NoError(t, c.CreateTopic("orders", 3))
+	require.Equal(t, int32(3), c.Partitions("orders"))
+
+	err := c.CreateTopic("orders", 1)
+	require.True(t, errors.Is(err, ErrTopicExists), "got %v", err)
+	require.Equal(t, ErrInvalidPartitions, c.CreateTopic("payments", 0))
+
+	require.Equal(t, int32(1), c.Partitions("created-on-first-use"))
+	c.join("group", []string{"created-on-subscribe"})
+	require.Contains(t, c.topics, "created-on-subscribe")
+}
+
+func TestClusterProduce(t *testing.T) {
+	c := newTestCluster(t)
+	require.NoError(t, c.CreateTopic("orders", 2))
+
+	for i, value := range []string{"a", "b"} {
+		offset, err := c.Produce("orders", 1, []byte("key"), []byte(value), Header{Key: "h", Value: []byte(value)})
+		require.NoError(t, err)
+		require.Equal(t, int64(i), offset)
+	}
+	_, err := c.Produce("orders", 2, nil, []byte("c"))
+	require.True(t, errors.Is(err, ErrUnknownTopicOrPartition), "got %v", err)
+
+	records := c.Records("orders", 1)
+	require.Len(t, records, 2)
+	require.Equal(t, "orders", records[1].Topic)
+	require.Equal(t, int64(1), records[1].Offset)
+	require.Equal(t, []byte("key"), records[1].Key)
+	require.Equal(t, []byte("b"), records[1].Value)
+	require.Equal(t, []Header{{Key: "h", Value: []byte("b")}}, records[1].Headers)
+	require.False(t, records[1].Timestamp.IsZero())
+	require.Empty(t, c.Records("orders", 0))
+}
+
+func TestClusterTransactions(t *testing.T) {
+	c := newTestCluster(t)
+	tp := topicPartition{"orders", 0}
+
+	committed := &transaction{offsets: map[string]map[topicPartition]int64{"group": {{"input", 0}: 5}}}
+	aborted := &transaction{offsets: map[string]map[topicPartition]int64{"group": {{"input", 0}: 7}}}
+	_, err := c.append(Record{Topic: "orders", Value: []byte("committed")}, committed)
+	require.NoError(t, err)
+	_, err = c.append(Record{Topic: "orders", Value: []byte("aborted")}, aborted)
+	require.NoError(t, err)
+	_, err = c.Produce("orders", 0, nil, []byte("plain"))
+	require.NoError(t, err)
+
+	r, next := c.fetch(tp, 0, true)
+	require.Nil(t, r, "an open transaction blocks read_committed consumers")
+	require.Equal(t, int64(0), next)
+	r, next = c.fetch(tp, 0, false)
+	require.Equal(t, []byte("committed"), r.Value)
+	require.Equal(t, int64(1), next)
+	records := c.Records("orders", 0)
+	require.Len(t, records, 1)
+	require.Equal(t, []byte("plain"), records[0].Value)
+
+	c.endTransaction(committed, true)
+	c.endTransaction(aborted, false)
+	require.Equal(t, int64(5), c.CommittedOffset("group", "input", 0))
+
+	r, next = c.fetch(tp, 1, true)
+	require.Equal(t, []byte("plain"), r.Value, "aborted records are skipped")
+	require.Equal(t, int64(3), next)
+	records = c.Records("orders", 0)
+	require.Len(t, records, 2)
+	require.Equal(t, []byte("committed"), records[0].Value)
+	require.Equal(t, []byte("plain"), records[1].Value)
+}
+
+func TestClusterRebalance(t *testing.T) {
+	c := newTestCluster(t)
+	require.NoError(t, c.CreateTopic("orders", 3))
+
+	first := c.join("group", []string{"orders"})
+	step, tps := c.rebalanceStep(first)
+	require.Equal(t, assignPartitions, step)
+	require.Len(t, tps, 3)
+
+	second := c.join("group", []string{"orders"})
+	step, _ = c.rebalanceStep(second)
+	require.Equal(t, noRebalance, step, "the partitions are owned by the first member until it releases them")
+
+	step, tps = c.rebalanceStep(first)
+	require.Equal(t, revokePartitions, step)
+	require.Len(t, tps, 3)
+	c.release(first)
+
+	step, tps = c.rebalanceStep(first)
+	require.Equal(t, assignPartitions, step)
+	require.Equal(t, []topicPartition{{"orders", 0}, {"orders", 2}}, tps)
+	step, tps = c.rebalanceStep(second)
+	require.Equal(t, assignPartitions, step)
+	require.Equal(t, []topicPartition{{"orders", 1}}, tps)
+	require.Len(t, c.Members("group"), 2)
+
+	c.leave(first)
+	step, _ = c.rebalanceStep(second)
+	require.Equal(t, revokePartitions, step)
+	c.release(second)
+	step, tps = c.rebalanceStep(second)
+	require.Equal(t, assignPartitions, step)
+	require.Len(t, tps, 3)
+	require.Len(t, c.Members("group"), 1)
+}
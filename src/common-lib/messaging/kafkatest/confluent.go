@@ -0,0 +1,717 @@
+package kafkatest
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+const (
+	defaultChannelSize = 10000
+	flushInterval      = 10 * time.Millisecond
+)
+
+// confluentConsumer implements consumer.Client on a cluster.
+// The rebalance callback is called with a nil *kafka.Consumer.
+type confluentConsumer struct {
+	cluster       *Cluster
+	groupID       string
+	autoCommit    bool
+	autoStore     bool
+	earliest      bool
+	readCommitted bool
+	appRebalance  bool
+	logs          chan kafka.LogEvent
+	done          chan struct{}
+
+	mx          sync.Mutex
+	member      *member
+	rebalanceCb kafka.RebalanceCb
+	rebalanced  bool // Assign or Unassign called by the rebalance callback
+	revoking    bool
+	partitions  []*assignedPartition
+	next        int // partition to fetch from first, for fairness
+	stored      map[topicPartition]int64
+	committed   map[topicPartition]int64
+	metadata    *kafka.ConsumerGroupMetadata
+	closed      bool
+}
+
+type assignedPartition struct {
+	topicPartition
+	position int64
+	paused   bool
+}
+
+func newConfluentConsumer(c *Cluster, configMap *kafka.ConfigMap) (*confluentConsumer, error) {
+	groupID := configString(configMap, "group.id", "")
+	if groupID == "" {
+		return nil, kafka.NewError(kafka.ErrInvalidArg, "Required property group.id not set", false)
+	}
+	reset := configString(configMap, "auto.offset.reset", "largest")
+	cc := &confluentConsumer{
+		cluster:       c,
+		groupID:       groupID,
+		autoCommit:    configBool(configMap, "enable.auto.commit", true),
+		autoStore:     configBool(configMap, "enable.auto.offset.store", true),
+		earliest:      reset == "smallest" || reset == "earliest" || reset == "beginning",
+		readCommitted: configString(configMap, "isolation.level", "read_committed") == "read_committed",
+		appRebalance:  configBool(configMap, "go.application.rebalance.enable", false),
+		done:          make(chan struct{}),
+		stored:        make(map[topicPartition]int64),
+		committed:     make(map[topicPartition]int64),
+	}
+	if configBool(configMap, "go.logs.channel.enable", false) {
+		cc.logs = make(chan kafka.LogEvent, defaultChannelSize)
+	}
+	if interval := configInt(configMap, "auto.commit.interval.ms", 5000); cc.autoCommit && interval > 0 {
+		go cc.autoCommitStored(time.Duration(interval) * time.Millisecond)
+	}
+	return cc, nil
+}
+
+func (cc *confluentConsumer) SubscribeTopics(topics []string, rebalanceCb kafka.RebalanceCb) error {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	cc.rebalanceCb = rebalanceCb
+	if cc.member == nil {
+		cc.member = cc.cluster.join(cc.groupID, topics)
+		return nil
+	}
+	cc.cluster.resubscribe(cc.member, topics)
+	return nil
+}
+
+func (cc *confluentConsumer) Poll(timeoutMs int) kafka.Event {
+	var timeout <-chan time.Time
+	if timeoutMs >= 0 {
+		timer := time.NewTimer(time.Duration(timeoutMs) * time.Millisecond)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	for {
+		changed := cc.cluster.changes()
+		ev, rebalanced := cc.rebalance()
+		if ev != nil {
+			return ev
+		}
+		if rebalanced {
+			continue
+		}
+		if m := cc.fetch(); m != nil {
+			return m
+		}
+		select {
+		case <-changed:
+		case <-timeout:
+			return nil
+		case <-cc.done:
+			return nil
+		}
+	}
+}
+
+// rebalance takes the next rebalance step of the consumer and calls the rebalance callback, assigning or unassigning
+// the partitions if the callback did not. Without callback the event is returned if the application handles the rebalance.
+func (cc *confluentConsumer) rebalance() (kafka.Event, bool) {
+	cc.mx.Lock()
+	m, rebalanceCb := cc.member, cc.rebalanceCb
+	if m == nil || cc.closed || cc.revoking {
+		cc.mx.Unlock()
+		return nil, false
+	}
+	cc.mx.Unlock()
+
+	step, tps := cc.cluster.rebalanceStep(m)
+	var ev kafka.Event
+	switch step {
+	case revokePartitions:
+		cc.mx.Lock()
+		cc.revoking = true
+		cc.mx.Unlock()
+		ev = kafka.RevokedPartitions{Partitions: toTopicPartitions(tps)}
+	case assignPartitions:
+		ev = kafka.AssignedPartitions{Partitions: toTopicPartitions(tps)}
+	default:
+		return nil, false
+	}
+	if rebalanceCb == nil && cc.appRebalance {
+		return ev, true
+	}
+
+	cc.mx.Lock()
+	cc.rebalanced = false
+	cc.mx.Unlock()
+	if rebalanceCb != nil {
+		_ = rebalanceCb(nil, ev) // the error is ignored, as by the kafka client
+	}
+	cc.mx.Lock()
+	rebalanced := cc.rebalanced
+	cc.mx.Unlock()
+	if !rebalanced {
+		if e, ok := ev.(kafka.AssignedPartitions); ok {
+			_ = cc.Assign(e.Partitions)
+		} else {
+			_ = cc.Unassign()
+		}
+	}
+	return nil, true
+}
+
+// fetch returns the next message of the assigned partitions, going through them in turn
+func (cc *confluentConsumer) fetch() *kafka.Message {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	if cc.closed {
+		return nil
+	}
+	n := len(cc.partitions)
+	for i := 0; i < n; i++ {
+		p := cc.partitions[(cc.next+i)%n]
+		if p.paused {
+			continue
+		}
+		r, next := cc.cluster.fetch(p.topicPartition, p.position, cc.readCommitted)
+		p.position = next
+		if r == nil {
+			continue
+		}
+		cc.next = (cc.next + i + 1) % n
+		if cc.autoStore {
+			cc.stored[p.topicPartition] = next
+		}
+		return toKafkaMessage(r)
+	}
+	return nil
+}
+
+func (cc *confluentConsumer) StoreOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	var err error
+	stored := append([]kafka.TopicPartition{}, offsets...)
+	for i, tp := range stored {
+		key := toTopicPartition(tp)
+		if cc.assigned(key) == nil {
+			stored[i].Error = kafka.NewError(kafka.ErrUnknownPartition, fmt.Sprintf("%s is not assigned", tp), false)
+			err = stored[i].Error
+			continue
+		}
+		if tp.Offset >= 0 {
+			cc.stored[key] = int64(tp.Offset)
+		}
+	}
+	return stored, err
+}
+
+func (cc *confluentConsumer) CommitOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	commit := make(map[topicPartition]int64, len(offsets))
+	for _, tp := range offsets {
+		if tp.Offset >= 0 {
+			commit[toTopicPartition(tp)] = int64(tp.Offset)
+		}
+	}
+	cc.cluster.commit(cc.groupID, commit)
+	return append([]kafka.TopicPartition{}, offsets...), nil
+}
+
+func (cc *confluentConsumer) Assign(partitions []kafka.TopicPartition) error {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	cc.unassign()
+	for _, tp := range partitions {
+		key := toTopicPartition(tp)
+		cc.partitions = append(cc.partitions, &assignedPartition{topicPartition: key, position: cc.startOffset(key, tp.Offset)})
+	}
+	return nil
+}
+
+func (cc *confluentConsumer) Unassign() error {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	cc.unassign()
+	return nil
+}
+
+// unassign commits the stored offsets if auto commit is enabled and drops the assignment,
+// releasing the partitions of a revoke step, cc.mx is held
+func (cc *confluentConsumer) unassign() {
+	if cc.autoCommit {
+		cc.commitStored()
+	}
+	if cc.revoking {
+		cc.cluster.release(cc.member)
+		cc.revoking = false
+	}
+	cc.partitions, cc.next = nil, 0
+	cc.stored = make(map[topicPartition]int64)
+	cc.rebalanced = true
+}
+
+// startOffset resolves the offset of an assigned partition: the committed offset of the group for a stored
+// offset, else the auto.offset.reset one, cc.mx is held
+func (cc *confluentConsumer) startOffset(tp topicPartition, offset kafka.Offset) int64 {
+	switch {
+	case offset >= 0:
+		return int64(offset)
+	case offset == kafka.OffsetBeginning:
+		return 0
+	case offset == kafka.OffsetEnd:
+		return cc.cluster.highWatermark(tp)
+	}
+	if committed, ok := cc.cluster.committed(cc.groupID, tp); ok {
+		return committed
+	}
+	if cc.earliest {
+		return 0
+	}
+	return cc.cluster.highWatermark(tp)
+}
+
+func (cc *confluentConsumer) Assignment() ([]kafka.TopicPartition, error) {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	tps := make([]topicPartition, 0, len(cc.partitions))
+	for _, p := range cc.partitions {
+		tps = append(tps, p.topicPartition)
+	}
+	return toTopicPartitions(tps), nil
+}
+
+func (cc *confluentConsumer) Pause(partitions []kafka.TopicPartition) error {
+	return cc.setPaused(partitions, true)
+}
+
+func (cc *confluentConsumer) Resume(partitions []kafka.TopicPartition) error {
+	return cc.setPaused(partitions, false)
+}
+
+func (cc *confluentConsumer) setPaused(partitions []kafka.TopicPartition, paused bool) error {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	for _, tp := range partitions {
+		if p := cc.assigned(toTopicPartition(tp)); p != nil {
+			p.paused = paused
+		}
+	}
+	return nil
+}
+
+func (cc *confluentConsumer) Seek(partition kafka.TopicPartition, timeoutMs int) error {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	key := toTopicPartition(partition)
+	p := cc.assigned(key)
+	if p == nil {
+		return kafka.NewError(kafka.ErrUnknownPartition, fmt.Sprintf("%s is not assigned", partition), false)
+	}
+	p.position = cc.startOffset(key, partition.Offset)
+	return nil
+}
+
+// assigned returns the assigned partition, or nil, cc.mx is held
+func (cc *confluentConsumer) assigned(tp topicPartition) *assignedPartition {
+	for _, p := range cc.partitions {
+		if p.topicPartition == tp {
+			return p
+		}
+	}
+	return nil
+}
+
+func (cc *confluentConsumer) GetConsumerGroupMetadata() (*kafka.ConsumerGroupMetadata, error) {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	if cc.metadata == nil {
+		metadata, err := kafka.NewTestConsumerGroupMetadata(cc.groupID)
+		if err != nil {
+			return nil, err
+		}
+		cc.cluster.registerGroupMetadata(metadata, cc.groupID)
+		cc.metadata = metadata
+	}
+	return cc.metadata, nil
+}
+
+func (cc *confluentConsumer) Logs() chan kafka.LogEvent {
+	return cc.logs
+}
+
+// Close commits the stored offsets if auto commit is enabled and leaves the group, without calling the rebalance callback
+func (cc *confluentConsumer) Close() error {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	if cc.closed {
+		return nil
+	}
+	cc.closed = true
+	close(cc.done)
+	if cc.autoCommit {
+		cc.commitStored()
+	}
+	if cc.member != nil {
+		cc.cluster.leave(cc.member)
+	}
+	cc.partitions = nil
+	if cc.logs != nil {
+		close(cc.logs)
+	}
+	return nil
+}
+
+func (cc *confluentConsumer) autoCommitStored(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-cc.done:
+			return
+		case <-ticker.C:
+			cc.mx.Lock()
+			cc.commitStored()
+			cc.mx.Unlock()
+		}
+	}
+}
+
+// commitStored commits the offsets stored for the assigned partitions since the last commit, cc.mx is held
+func (cc *confluentConsumer) commitStored() {
+	offsets := make(map[topicPartition]int64)
+	for _, p := range cc.partitions {
+		if offset, ok := cc.stored[p.topicPartition]; ok && offset != cc.committed[p.topicPartition] {
+			offsets[p.topicPartition] = offset
+			cc.committed[p.topicPartition] = offset
+		}
+	}
+	if len(offsets) > 0 {
+		cc.cluster.commit(cc.groupID, offsets)
+	}
+}
+
+// confluentProducer implements producer.Client on a cluster.
+// Messages are appended to their partition on Produce, the delivery reports are sent in order by a goroutine.
+type confluentProducer struct {
+	cluster         *Cluster
+	transactionalID string
+	deliveryReports bool
+	produceChannel  chan *kafka.Message
+	events          chan kafka.Event
+	done            chan struct{}
+	closeOnce       sync.Once
+	wg              sync.WaitGroup
+
+	mx          sync.Mutex
+	cond        *sync.Cond
+	queue       []delivery
+	outstanding int
+	next        map[string]int32 // next partition of the messages without key, by topic
+	initialized bool
+	transaction *transaction
+	closed      bool
+}
+
+type delivery struct {
+	report  *kafka.Message
+	channel chan kafka.Event
+}
+
+func newConfluentProducer(c *Cluster, configMap *kafka.ConfigMap) *confluentProducer {
+	p := &confluentProducer{
+		cluster:         c,
+		transactionalID: configString(configMap, "transactional.id", ""),
+		deliveryReports: configBool(configMap, "go.delivery.reports", true),
+		produceChannel:  make(chan *kafka.Message, configInt(configMap, "go.produce.channel.size", defaultChannelSize)),
+		events:          make(chan kafka.Event, configInt(configMap, "go.events.channel.size", defaultChannelSize)),
+		done:            make(chan struct{}),
+		next:            make(map[string]int32),
+	}
+	p.cond = sync.NewCond(&p.mx)
+	p.wg.Add(2)
+	go p.deliver()
+	go p.produceFromChannel()
+	return p
+}
+
+func (p *confluentProducer) Produce(msg *kafka.Message, deliveryChan chan kafka.Event) error {
+	if msg == nil || msg.TopicPartition.Topic == nil {
+		return kafka.NewError(kafka.ErrInvalidArg, "message topic is required", false)
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if p.closed {
+		return kafka.NewError(kafka.ErrState, "producer is closed", false)
+	}
+	if p.transactionalID != "" && p.transaction == nil {
+		return kafka.NewError(kafka.ErrState, "transactional producer is not in a transaction", false)
+	}
+
+	topic := *msg.TopicPartition.Topic
+	partition := msg.TopicPartition.Partition
+	if partition == kafka.PartitionAny {
+		partitions := p.cluster.Partitions(topic)
+		if msg.Key != nil {
+			partition = int32(crc32.ChecksumIEEE(msg.Key) % uint32(partitions))
+		} else {
+			partition = p.next[topic] % partitions
+			p.next[topic] = partition + 1
+		}
+	}
+	r := Record{
+		Topic:     topic,
+		Partition: partition,
+		Key:       msg.Key,
+		Value:     msg.Value,
+		Timestamp: msg.Timestamp,
+	}
+	for _, h := range msg.Headers {
+		r.Headers = append(r.Headers, Header{Key: h.Key, Value: h.Value})
+	}
+	r, err := p.cluster.append(r, p.transaction)
+	if err != nil {
+		return kafka.NewError(kafka.ErrUnknownPartition, err.Error(), false)
+	}
+
+	report := *msg
+	report.TopicPartition = kafka.TopicPartition{Topic: &topic, Partition: partition, Offset: kafka.Offset(r.Offset)}
+	report.Timestamp, report.TimestampType = r.Timestamp, kafka.TimestampCreateTime
+	channel := deliveryChan
+	if channel == nil && p.deliveryReports {
+		channel = p.events
+	}
+	p.queue = append(p.queue, delivery{report: &report, channel: channel})
+	p.outstanding++
+	p.cond.Broadcast()
+	return nil
+}
+
+// deliver sends the delivery reports in order, until the producer is closed
+func (p *confluentProducer) deliver() {
+	defer p.wg.Done()
+	for {
+		p.mx.Lock()
+		for len(p.queue) == 0 && !p.closed {
+			p.cond.Wait()
+		}
+		if p.closed {
+			p.mx.Unlock()
+			return
+		}
+		d := p.queue[0]
+		p.queue = p.queue[1:]
+		p.mx.Unlock()
+
+		if d.channel != nil {
+			select {
+			case d.channel <- d.report:
+			case <-p.done:
+			}
+		}
+
+		p.mx.Lock()
+		p.outstanding--
+		p.cond.Broadcast()
+		p.mx.Unlock()
+	}
+}
+
+// produceFromChannel produces the messages of the ProduceChannel, errors are reported on the Events channel
+func (p *confluentProducer) produceFromChannel() {
+	defer p.wg.Done()
+	for msg := range p.produceChannel {
+		if err := p.Produce(msg, nil); err != nil {
+			report := *msg
+			report.TopicPartition.Error = err
+			select {
+			case p.events <- &report:
+			case <-p.done:
+			}
+		}
+	}
+}
+
+func (p *confluentProducer) ProduceChannel() chan *kafka.Message {
+	return p.produceChannel
+}
+
+func (p *confluentProducer) Events() chan kafka.Event {
+	return p.events
+}
+
+func (p *confluentProducer) Len() int {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	return len(p.produceChannel) + len(p.events) + p.outstanding
+}
+
+func (p *confluentProducer) Flush(timeoutMs int) int {
+	deadline := time.Now().Add(time.Duration(timeoutMs) * time.Millisecond)
+	for {
+		n := p.Len()
+		if n == 0 || !time.Now().Before(deadline) {
+			return n
+		}
+		time.Sleep(flushInterval)
+	}
+}
+
+// Close stops the producer, the delivery reports not sent yet are dropped and an open transaction is aborted.
+func (p *confluentProducer) Close() {
+	p.closeOnce.Do(func() {
+		close(p.produceChannel)
+		p.mx.Lock()
+		p.closed = true
+		close(p.done)
+		p.cond.Broadcast()
+		if p.transaction != nil {
+			p.cluster.endTransaction(p.transaction, false)
+			p.transaction = nil
+		}
+		p.mx.Unlock()
+		p.wg.Wait()
+		close(p.events)
+	})
+}
+
+func (p *confluentProducer) GetFatalError() error {
+	return nil
+}
+
+func (p *confluentProducer) InitTransactions(ctx context.Context) error {
+	if p.transactionalID == "" {
+		return kafka.NewError(kafka.ErrNotConfigured, "The Transactional API requires transactional.id to be configured", false)
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.initialized = true
+	return nil
+}
+
+func (p *confluentProducer) BeginTransaction() error {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if !p.initialized || p.transaction != nil {
+		return kafka.NewError(kafka.ErrState, "transactions are not initialized or a transaction is in progress", false)
+	}
+	p.transaction = &transaction{offsets: make(map[string]map[topicPartition]int64)}
+	return nil
+}
+
+func (p *confluentProducer) SendOffsetsToTransaction(ctx context.Context, offsets []kafka.TopicPartition, consumerMetadata *kafka.ConsumerGroupMetadata) error {
+	groupID, ok := p.cluster.groupOf(consumerMetadata)
+	if !ok {
+		return kafka.NewError(kafka.ErrInvalidArg, "unknown consumer group metadata", false)
+	}
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if p.transaction == nil {
+		return kafka.NewError(kafka.ErrState, "no transaction in progress", false)
+	}
+	if p.transaction.offsets[groupID] == nil {
+		p.transaction.offsets[groupID] = make(map[topicPartition]int64)
+	}
+	for _, tp := range offsets {
+		if tp.Offset >= 0 {
+			p.transaction.offsets[groupID][toTopicPartition(tp)] = int64(tp.Offset)
+		}
+	}
+	return nil
+}
+
+// CommitTransaction waits for the delivery reports of the transaction before committing it, like the kafka client flushes
+func (p *confluentProducer) CommitTransaction(ctx context.Context) error {
+	for {
+		p.mx.Lock()
+		if p.transaction == nil {
+			p.mx.Unlock()
+			return kafka.NewError(kafka.ErrState, "no transaction in progress", false)
+		}
+		if p.outstanding == 0 {
+			p.cluster.endTransaction(p.transaction, true)
+			p.transaction = nil
+			p.mx.Unlock()
+			return nil
+		}
+		p.mx.Unlock()
+		select {
+		case <-ctx.Done():
+			return kafka.NewError(kafka.ErrTimedOut, ctx.Err().Error(), false)
+		case <-time.After(flushInterval):
+		}
+	}
+}
+
+func (p *confluentProducer) AbortTransaction(ctx context.Context) error {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if p.transaction == nil {
+		return kafka.NewError(kafka.ErrState, "no transaction in progress", false)
+	}
+	p.cluster.endTransaction(p.transaction, false)
+	p.transaction = nil
+	return nil
+}
+
+func toTopicPartition(tp kafka.TopicPartition) topicPartition {
+	var topic string
+	if tp.Topic != nil {
+		topic = *tp.Topic
+	}
+	return topicPartition{topic: topic, partition: tp.Partition}
+}
+
+func toKafkaMessage(r *Record) *kafka.Message {
+	topic := r.Topic
+	m := &kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: r.Partition, Offset: kafka.Offset(r.Offset)},
+		Key:            r.Key,
+		Value:          r.Value,
+		Timestamp:      r.Timestamp,
+		TimestampType:  kafka.TimestampCreateTime,
+	}
+	for _, h := range r.Headers {
+		m.Headers = append(m.Headers, kafka.Header{Key: h.Key, Value: h.Value})
+	}
+	return m
+}
+
+func configString(configMap *kafka.ConfigMap, key string, defval string) string {
+	v, ok := (*configMap)[key]
+	if !ok || v == nil {
+		return defval
+	}
+	return fmt.Sprint(v)
+}
+
+func configBool(configMap *kafka.ConfigMap, key string, defval bool) bool {
+	switch v := (*configMap)[key].(type) {
+	case bool:
+		return v
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b
+		}
+	}
+	return defval
+}
+
+func configInt(configMap *kafka.ConfigMap, key string, defval int) int {
+	switch v := (*configMap)[key].(type) {
+	case int:
+		return v
+	case int32:
+		return int(v)
+	case int64:
+		return int(v)
+	case string:
+		if i, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return i
+		}
+	}
+	return defval
+}
@@ -0,0 +1,338 @@
+package kafkatest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/consumer"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
+)
+
+const waitFor = 5 * time.Second
+
+// received collects the handled messages by value
+type received struct {
+	mx       sync.Mutex
+	messages map[string]consumer.Message
+}
+
+func newReceived() *received {
+	return &received{messages: make(map[string]consumer.Message)}
+}
+
+func (r *received) handle(_ context.Context, message consumer.Message) error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.messages[string(message.Message)] = message
+	return nil
+}
+
+func (r *received) count() int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return len(r.messages)
+}
+
+func (r *received) get(value string) (consumer.Message, bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	m, ok := r.messages[value]
+	return m, ok
+}
+
+func newConsumerConfig(c *Cluster, group string, handler func(context.Context, consumer.Message) error, topics ...string) *consumer.Config {
+	config := consumer.NewConfig()
+	config.Address = []string{c.Address()}
+	config.Group = group
+	config.Topics = topics
+	config.MessageHandler = handler
+	config.ConsumerMode = consumer.PullOrdered
+	config.OffsetsInitial = consumer.OffsetOldest
+	config.CommitIntervalMs = 10
+	config.RetryDelay = time.Millisecond
+	return config
+}
+
+// startConsumer pulls with a consumer until the returned function or the test cleanup closes it
+func startConsumer(t *testing.T, config *consumer.Config) func() {
+	kc, err := consumer.New(config)
+	require.NoError(t, err)
+	go kc.Pull()
+	var once sync.Once
+	stop := func() { once.Do(func() { require.NoError(t, kc.Close()) }) }
+	t.Cleanup(stop)
+	return stop
+}
+
+func newSyncProducer(t *testing.T, c *Cluster) producer.Producer {
+	config := producer.NewConfig()
+	config.Address = []string{c.Address()}
+	p, err := producer.NewSyncProducer(config)
+	require.NoError(t, err)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func produce(t *testing.T, p producer.Producer, topic string, n int) {
+	for i := 0; i < n; i++ {
+		message := &producer.Message{Topic: topic, Key: []byte(strconv.Itoa(i)), Value: []byte(fmt.Sprintf("%s-%d", topic, i))}
+		message.AddHeader("index", strconv.Itoa(i))
+		require.NoError(t, p.Produce(context.Background(), "", message))
+	}
+}
+
+func committedAll(c *Cluster, group, topic string) bool {
+	for partition := int32(0); partition < c.Partitions(topic); partition++ {
+		if high := int64(len(c.Records(topic, partition))); high > 0 && c.CommittedOffset(group, topic, partition) != high {
+			return false
+		}
+	}
+	return true
+}
+
+func TestConsumerConsumesProducedMessages(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*consumer.Config)
+	}{
+		{name: "PullUnOrdered OnPull", configure: func(config *consumer.Config) {
+			config.ConsumerMode, config.CommitMode = consumer.PullUnOrdered, consumer.OnPull
+		}},
+		{name: "PullOrdered OnPull", configure: func(config *consumer.Config) {
+			config.ConsumerMode, config.CommitMode = consumer.PullOrdered, consumer.OnPull
+		}},
+		{name: "PullOrdered OnMessageCompletion", configure: func(config *consumer.Config) {
+			config.ConsumerMode, config.CommitMode = consumer.PullOrdered, consumer.OnMessageCompletion
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCluster(t)
+			require.NoError(t, c.CreateTopic("orders", 3))
+			produce(t, newSyncProducer(t, c), "orders", 30)
+
+			r := newReceived()
+			config := newConsumerConfig(c, "group", r.handle, "orders")
+			tt.configure(config)
+			startConsumer(t, config)
+
+			require.Eventually(t, func() bool { return r.count() == 30 }, waitFor, time.Millisecond)
+			// in PullUnOrdered mode the workers store the offsets in any order, the last stored one is committed.
+			// With OnMessageCompletion the completions during the commit of another partition are not committed,
+			// the last offset of a partition can stay uncommitted until its next message.
+			if config.ConsumerMode == consumer.PullOrdered && config.CommitMode == consumer.OnPull {
+				require.Eventually(t, func() bool { return committedAll(c, "group", "orders") }, waitFor, time.Millisecond)
+			}
+
+			m, ok := r.get("orders-7")
+			require.True(t, ok)
+			require.Equal(t, []byte("7"), m.Key)
+			require.Equal(t, "7", m.GetHeaders()["index"])
+			require.Equal(t, m.Message, c.Records("orders", m.Partition)[m.Offset].Value)
+		})
+	}
+}
+
+func TestConsumerCommitModes(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*consumer.Config)
+		// offset committed while the handler of the first message is running
+		committedWhileHandling int64
+	}{
+		{name: "OnPull", configure: func(config *consumer.Config) { config.CommitMode = consumer.OnPull }, committedWhileHandling: 1},
+		{name: "OnMessageCompletion", configure: func(config *consumer.Config) { config.CommitMode = consumer.OnMessageCompletion }, committedWhileHandling: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCluster(t)
+			for i := 0; i < 2; i++ {
+				_, err := c.Produce("orders", 0, nil, []byte(strconv.Itoa(i)))
+				require.NoError(t, err)
+			}
+
+			handling, release := make(chan struct{}), make(chan struct{})
+			r := newReceived()
+			config := newConsumerConfig(c, "group", func(ctx context.Context, message consumer.Message) error {
+				if message.Offset == 0 {
+					close(handling)
+					<-release
+				}
+				return r.handle(ctx, message)
+			}, "orders")
+			tt.configure(config)
+			startConsumer(t, config)
+
+			<-handling
+			if tt.committedWhileHandling >= 0 {
+				require.Eventually(t, func() bool {
+					return c.CommittedOffset("group", "orders", 0) == tt.committedWhileHandling
+				}, waitFor, time.Millisecond)
+			} else {
+				time.Sleep(100 * time.Millisecond) // several commit intervals
+			}
+			require.Equal(t, tt.committedWhileHandling, c.CommittedOffset("group", "orders", 0))
+
+			close(release)
+			require.Eventually(t, func() bool { return c.CommittedOffset("group", "orders", 0) == 2 }, waitFor, time.Millisecond)
+			require.Equal(t, 2, r.count())
+		})
+	}
+}
+
+func TestConsumerResumesFromCommittedOffset(t *testing.T) {
+	c := newTestCluster(t)
+	p := newSyncProducer(t, c)
+	produce(t, p, "orders", 5)
+
+	first := newReceived()
+	stop := startConsumer(t, newConsumerConfig(c, "group", first.handle, "orders"))
+	require.Eventually(t, func() bool { return committedAll(c, "group", "orders") }, waitFor, time.Millisecond)
+	stop()
+	require.Eventually(t, func() bool { return len(c.Members("group")) == 0 }, waitFor, time.Millisecond)
+
+	for i := 5; i < 8; i++ {
+		require.NoError(t, p.Produce(context.Background(), "", &producer.Message{Topic: "orders", Value: []byte(fmt.Sprintf("orders-%d", i))}))
+	}
+	second := newReceived()
+	startConsumer(t, newConsumerConfig(c, "group", second.handle, "orders"))
+	require.Eventually(t, func() bool { return second.count() == 3 }, waitFor, time.Millisecond)
+	_, ok := second.get("orders-4")
+	require.False(t, ok, "committed messages are not consumed again")
+}
+
+func TestConsumerGroupRebalance(t *testing.T) {
+	c := newTestCluster(t)
+	require.NoError(t, c.CreateTopic("orders", 4))
+	p := newSyncProducer(t, c)
+
+	first, second := newReceived(), newReceived()
+	startConsumer(t, newConsumerConfig(c, "group", first.handle, "orders"))
+	require.Eventually(t, func() bool {
+		members := c.Members("group")
+		return len(members) == 1 && len(members[0]) == 4
+	}, waitFor, time.Millisecond)
+
+	stop := startConsumer(t, newConsumerConfig(c, "group", second.handle, "orders"))
+	require.Eventually(t, func() bool {
+		members := c.Members("group")
+		return len(members) == 2 && len(members[0]) == 2 && len(members[1]) == 2
+	}, waitFor, time.Millisecond)
+
+	produce(t, p, "orders", 40)
+	require.Eventually(t, func() bool { return first.count()+second.count() == 40 }, waitFor, time.Millisecond)
+	require.NotZero(t, first.count())
+	require.NotZero(t, second.count())
+	require.Eventually(t, func() bool { return committedAll(c, "group", "orders") }, waitFor, time.Millisecond)
+
+	stop()
+	require.Eventually(t, func() bool {
+		members := c.Members("group")
+		return len(members) == 1 && len(members[0]) == 4
+	}, waitFor, time.Millisecond)
+}
+
+func TestAsyncProducerDeliveryReports(t *testing.T) {
+	c := newTestCluster(t)
+	require.NoError(t, c.CreateTopic("orders", 2))
+
+	config := producer.NewConfig()
+	config.Address = []string{c.Address()}
+	p, err := producer.NewAsyncProducer(config)
+	require.NoError(t, err)
+	defer p.Close()
+
+	for i := 0; i < 4; i++ {
+		p.ProduceChannel() <- &producer.Message{Topic: "orders", Value: []byte(strconv.Itoa(i))}
+	}
+	offsets := make(map[int32][]int64)
+	for i := 0; i < 4; i++ {
+		report := <-p.DeliveryReportChannel()
+		require.NoError(t, report.Error)
+		offsets[report.TopicPartition.Partition] = append(offsets[report.TopicPartition.Partition], report.TopicPartition.Offset)
+	}
+	require.Equal(t, map[int32][]int64{0: {0, 1}, 1: {0, 1}}, offsets)
+	require.Equal(t, 0, p.Flush(100))
+}
+
+func TestTransactionalProducer(t *testing.T) {
+	c := newTestCluster(t)
+	client, err := consumer.NewClient(&kafka.ConfigMap{"bootstrap.servers": c.Address(), "group.id": "group"})
+	require.NoError(t, err)
+	defer func() { _ = client.Close() }()
+	metadata, err := client.GetConsumerGroupMetadata()
+	require.NoError(t, err)
+
+	config := producer.NewConfig()
+	config.Address = []string{c.Address()}
+	config.TransactionalID = "orders"
+	p, err := producer.NewTransactionalProducer(config)
+	require.NoError(t, err)
+	defer p.Close()
+	ctx := context.Background()
+	require.NoError(t, p.InitTransactions(ctx))
+
+	input := "input"
+	offsets := []kafka.TopicPartition{{Topic: &input, Partition: 0, Offset: 3}}
+	require.NoError(t, p.BeginTransaction())
+	require.NoError(t, p.Produce(ctx, "", &producer.Message{Topic: "orders", Value: []byte("aborted")}))
+	require.NoError(t, p.SendOffsetsToTransaction(ctx, offsets, metadata))
+	require.NoError(t, p.AbortTransaction(ctx))
+	require.Empty(t, c.Records("orders", 0))
+	require.Equal(t, int64(-1), c.CommittedOffset("group", input, 0))
+
+	require.NoError(t, p.BeginTransaction())
+	require.NoError(t, p.Produce(ctx, "", &producer.Message{Topic: "orders", Value: []byte("committed")}))
+	require.NoError(t, p.SendOffsetsToTransaction(ctx, offsets, metadata))
+	require.NoError(t, p.CommitTransaction(ctx))
+	records := c.Records("orders", 0)
+	require.Len(t, records, 1)
+	require.Equal(t, []byte("committed"), records[0].Value)
+	require.Equal(t, int64(1), records[0].Offset, "the aborted record keeps its offset")
+	require.Equal(t, int64(3), c.CommittedOffset("group", input, 0))
+}
+
+func TestTransactionalConsumer(t *testing.T) {
+	c := newTestCluster(t)
+	require.NoError(t, c.CreateTopic("orders", 2))
+	produce(t, newSyncProducer(t, c), "orders", 10)
+
+	var failed sync.Once
+	config := newConsumerConfig(c, "group", nil, "orders")
+	config.TransactionMaxMessages = 3
+	config.TransactionInterval = 10 * time.Millisecond
+	producerConfig := producer.NewConfig()
+	producerConfig.TransactionalID = "orders-enricher"
+	tc, err := consumer.NewTransactional(config, producerConfig, func(ctx context.Context, message consumer.Message) ([]*producer.Message, error) {
+		if string(message.Message) == "orders-5" {
+			var err error
+			failed.Do(func() { err = errors.New("failed once") })
+			if err != nil {
+				return nil, err // retried within the transaction
+			}
+		}
+		return []*producer.Message{{Topic: "enriched", Key: message.Key, Value: append([]byte("enriched-"), message.Message...)}}, nil
+	})
+	require.NoError(t, err)
+	go tc.Pull()
+	defer func() { _ = tc.Close() }()
+
+	require.Eventually(t, func() bool { return committedAll(c, "group", "orders") }, waitFor, time.Millisecond)
+
+	values := make(map[string]int)
+	for _, r := range c.Records("enriched", 0) {
+		values[string(r.Value)]++
+	}
+	require.Len(t, values, 10)
+	for value, n := range values {
+		require.Equal(t, 1, n, "%s is produced exactly once", value)
+	}
+}
@@ -0,0 +1,232 @@
+package kafkatest
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+	cluster "github.com/bsm/sarama-cluster"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/kafka"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/kafka/encode"
+)
+
+// ProducerFactory returns a kafka.ProducerFactory of the kafka package whose producers publish to the cluster,
+// whatever their BrokerAddress.
+func (c *Cluster) ProducerFactory() kafka.ProducerFactory {
+	return legacyProducerFactory{cluster: c}
+}
+
+// ConsumerFactory returns a kafka.ConsumerFactory of the kafka package whose consumers consume from the cluster,
+// whatever their BrokerAddress. The consumers implement kafka.ConsumerServiceWithOrder.
+func (c *Cluster) ConsumerFactory() kafka.ConsumerFactory {
+	return legacyConsumerFactory{cluster: c}
+}
+
+type legacyProducerFactory struct {
+	cluster *Cluster
+}
+
+func (f legacyProducerFactory) GetProducerService(config kafka.ProducerConfig) (kafka.ProducerService, error) {
+	if len(config.BrokerAddress) == 0 {
+		return nil, errors.New(kafka.ErrorBrokerAddressNotProvided)
+	}
+	return &legacyProducer{cluster: f.cluster}, nil
+}
+
+func (f legacyProducerFactory) GetConfluentProducerService(config kafka.ProducerConfig) (kafka.ProducerService, error) {
+	return f.GetProducerService(config)
+}
+
+// legacyProducer publishes with a sarama sync producer connected on first use, as the producers of the kafka package
+type legacyProducer struct {
+	cluster *Cluster
+
+	mx       sync.Mutex
+	producer sarama.SyncProducer
+}
+
+func (p *legacyProducer) Push(topicName string, message string) error {
+	return p.PushEncoder(topicName, encode.GetStringEncoder(message))
+}
+
+func (p *legacyProducer) PushEncoder(topicName string, message encode.Encoder) error {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if p.producer == nil {
+		config := sarama.NewConfig()
+		config.Producer.Partitioner = sarama.NewRandomPartitioner
+		config.Producer.Return.Successes = true
+		producer, err := newSaramaSyncProducer(p.cluster, config)
+		if err != nil {
+			return err
+		}
+		p.producer = producer
+	}
+	_, _, err := p.producer.SendMessage(&sarama.ProducerMessage{Topic: topicName, Value: message})
+	return err
+}
+
+func (p *legacyProducer) CloseConnection() error {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if p.producer == nil {
+		return nil
+	}
+	err := p.producer.Close()
+	p.producer = nil
+	return err
+}
+
+type legacyConsumerFactory struct {
+	cluster *Cluster
+}
+
+func (f legacyConsumerFactory) GetConsumerService(config kafka.ConsumerConfig) (kafka.ConsumerService, error) {
+	switch {
+	case len(config.BrokerAddress) == 0:
+		return nil, errors.New(kafka.ErrorBrokerAddressNotProvided)
+	case config.GroupID == "":
+		return nil, errors.New(kafka.ErrorClientGroupIDNotProvided)
+	case len(config.Topics) == 0:
+		return nil, errors.New(kafka.ErrorTopicsNotProvided)
+	}
+	return &legacyConsumer{cluster: f.cluster, config: config}, nil
+}
+
+// legacyConsumer consumes with a sarama-cluster consumer connected on first use, as the consumers of the kafka package
+type legacyConsumer struct {
+	cluster *Cluster
+	config  kafka.ConsumerConfig
+
+	mx       sync.Mutex
+	consumer *clusterConsumer
+}
+
+// connect creates the consumer if it is not connected yet, with the configuration set by configure
+func (lc *legacyConsumer) connect(configure func(*cluster.Config)) (*clusterConsumer, error) {
+	lc.mx.Lock()
+	defer lc.mx.Unlock()
+	if lc.consumer != nil {
+		return lc.consumer, nil
+	}
+	config := cluster.NewConfig()
+	config.Consumer.Offsets.Retention = 1
+	configure(config)
+	consumer, err := newClusterConsumer(lc.cluster, lc.config.GroupID, lc.config.Topics, config)
+	if err != nil {
+		return nil, err
+	}
+	lc.consumer = consumer
+	return consumer, nil
+}
+
+// PullHandler marks the offset of each message before handling it in a goroutine, until the consumer is closed
+func (lc *legacyConsumer) PullHandler(consumerHandler kafka.ConsumerHandler) error {
+	consumer, err := lc.connect(func(*cluster.Config) {})
+	if err != nil {
+		return err
+	}
+	for message := range consumer.Messages() {
+		consumer.MarkPartitionOffset(message.Topic, message.Partition, message.Offset, "")
+		go consumerHandler(toConsumerMessage(message))
+	}
+	return nil
+}
+
+// PullHandlerSequential handles each message before marking its offset, until the consumer is closed
+func (lc *legacyConsumer) PullHandlerSequential(consumerHandler kafka.ConsumerHandler) error {
+	consumer, err := lc.connect(func(config *cluster.Config) {
+		config.Consumer.Offsets.CommitInterval = 720 * time.Hour
+	})
+	if err != nil {
+		return err
+	}
+	for message := range consumer.Messages() {
+		consumerHandler(toConsumerMessage(message))
+		consumer.MarkPartitionOffset(message.Topic, message.Partition, message.Offset, "")
+	}
+	return nil
+}
+
+// PullHandlerWithLimiter handles the messages while the limiter allows it, without marking their offsets
+func (lc *legacyConsumer) PullHandlerWithLimiter(consumerHandler kafka.ConsumerHandler, limiter kafka.Limiter) error {
+	consumer, err := lc.connect(func(*cluster.Config) {})
+	if err != nil {
+		return err
+	}
+	for {
+		if !limiter.IsConsumingAllowed() {
+			limiter.Wait()
+			continue
+		}
+		message, ok := <-consumer.Messages()
+		if !ok {
+			return nil
+		}
+		consumerHandler(toConsumerMessage(message))
+	}
+}
+
+// Connect connects the consumer with the parameters, forwarding its notifications
+func (lc *legacyConsumer) Connect(params *kafka.ConsumerKafkaInOutParams) error {
+	lc.mx.Lock()
+	connected := lc.consumer != nil
+	lc.mx.Unlock()
+	if connected {
+		return nil
+	}
+	consumer, err := lc.connect(func(config *cluster.Config) {
+		config.Consumer.Return.Errors = params.ReturnErrors
+		config.Group.Return.Notifications = params.ReturnNotifications
+		config.Consumer.Offsets.Initial = params.OffsetsInitial
+		config.Consumer.Offsets.Retention = params.Retention
+		config.Version = sarama.V1_1_0_0
+	})
+	if err != nil {
+		return err
+	}
+	go func() {
+		for err := range consumer.Errors() {
+			params.Errors <- err
+		}
+	}()
+	go func() {
+		for notification := range consumer.Notifications() {
+			params.Notifications <- fmt.Sprintf("%+v", notification)
+		}
+	}()
+	return nil
+}
+
+func (lc *legacyConsumer) MarkOffset(topic string, partition int32, offset int64) {
+	lc.mx.Lock()
+	consumer := lc.consumer
+	lc.mx.Unlock()
+	if consumer != nil {
+		consumer.MarkPartitionOffset(topic, partition, offset, "")
+	}
+}
+
+func (lc *legacyConsumer) CloseConnection() error {
+	lc.mx.Lock()
+	defer lc.mx.Unlock()
+	if lc.consumer == nil {
+		return nil
+	}
+	err := lc.consumer.Close()
+	lc.consumer = nil
+	return err
+}
+
+func toConsumerMessage(message *sarama.ConsumerMessage) kafka.ConsumerMessage {
+	return kafka.ConsumerMessage{
+		Message:             string(message.Value),
+		Offset:              message.Offset,
+		Partition:           message.Partition,
+		Topic:               message.Topic,
+		ReceivedDateTimeUTC: time.Now().UTC(),
+	}
+}
@@ -0,0 +1,449 @@
+package kafkatest
+
+import (
+	"sync"
+
+	"github.com/Shopify/sarama"
+	cluster "github.com/bsm/sarama-cluster"
+)
+
+// saramaSyncProducer implements sarama.SyncProducer on a cluster, partitioning the messages with the configured Partitioner
+type saramaSyncProducer struct {
+	cluster *Cluster
+	config  *sarama.Config
+
+	mx           sync.Mutex
+	partitioners map[string]sarama.Partitioner
+	closed       bool
+}
+
+func newSaramaSyncProducer(c *Cluster, config *sarama.Config) (*saramaSyncProducer, error) {
+	if config == nil {
+		config = sarama.NewConfig()
+		config.Producer.Return.Successes = true
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if !config.Producer.Return.Errors || !config.Producer.Return.Successes {
+		return nil, sarama.ConfigurationError("Producer.Return.Errors and Producer.Return.Successes must be true to be used in a SyncProducer")
+	}
+	return &saramaSyncProducer{
+		cluster:      c,
+		config:       config,
+		partitioners: make(map[string]sarama.Partitioner),
+	}, nil
+}
+
+func (p *saramaSyncProducer) SendMessage(msg *sarama.ProducerMessage) (int32, int64, error) {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	if p.closed {
+		return -1, -1, sarama.ErrShuttingDown
+	}
+	partitioner, ok := p.partitioners[msg.Topic]
+	if !ok {
+		partitioner = p.config.Producer.Partitioner(msg.Topic)
+		p.partitioners[msg.Topic] = partitioner
+	}
+	partition, err := partitioner.Partition(msg, p.cluster.Partitions(msg.Topic))
+	if err != nil {
+		return -1, -1, err
+	}
+	r := Record{Topic: msg.Topic, Partition: partition, Timestamp: msg.Timestamp}
+	if r.Key, err = encodeBytes(msg.Key); err != nil {
+		return -1, -1, err
+	}
+	if r.Value, err = encodeBytes(msg.Value); err != nil {
+		return -1, -1, err
+	}
+	for _, h := range msg.Headers {
+		r.Headers = append(r.Headers, Header{Key: string(h.Key), Value: h.Value})
+	}
+	if r, err = p.cluster.append(r, nil); err != nil {
+		return -1, -1, sarama.ErrInvalidPartition
+	}
+	msg.Partition, msg.Offset, msg.Timestamp = r.Partition, r.Offset, r.Timestamp
+	return r.Partition, r.Offset, nil
+}
+
+func (p *saramaSyncProducer) SendMessages(msgs []*sarama.ProducerMessage) error {
+	var errs sarama.ProducerErrors
+	for _, msg := range msgs {
+		if _, _, err := p.SendMessage(msg); err != nil {
+			errs = append(errs, &sarama.ProducerError{Msg: msg, Err: err})
+		}
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func (p *saramaSyncProducer) Close() error {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+	p.closed = true
+	return nil
+}
+
+func encodeBytes(e sarama.Encoder) ([]byte, error) {
+	if e == nil {
+		return nil, nil
+	}
+	return e.Encode()
+}
+
+// clusterConsumer implements the sarama-cluster consumer on a cluster, in the multiplexed or the partitions mode.
+// Marked offsets are committed right away.
+type clusterConsumer struct {
+	cluster       *Cluster
+	groupID       string
+	config        *cluster.Config
+	member        *member
+	messages      chan *sarama.ConsumerMessage
+	partitions    chan cluster.PartitionConsumer
+	errors        chan error
+	notifications chan *cluster.Notification
+	done          chan struct{}
+	closeOnce     sync.Once
+	wg            sync.WaitGroup
+
+	mx    sync.Mutex
+	owned map[topicPartition]*partitionConsumer
+}
+
+func newClusterConsumer(c *Cluster, groupID string, topics []string, config *cluster.Config) (*clusterConsumer, error) {
+	if config == nil {
+		config = cluster.NewConfig()
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	cc := &clusterConsumer{
+		cluster:       c,
+		groupID:       groupID,
+		config:        config,
+		messages:      make(chan *sarama.ConsumerMessage, config.ChannelBufferSize),
+		partitions:    make(chan cluster.PartitionConsumer, 1),
+		errors:        make(chan error, config.ChannelBufferSize),
+		notifications: make(chan *cluster.Notification),
+		done:          make(chan struct{}),
+		owned:         make(map[topicPartition]*partitionConsumer),
+	}
+	cc.member = c.join(groupID, topics)
+	cc.wg.Add(1)
+	go cc.rebalance()
+	return cc, nil
+}
+
+// rebalance takes the rebalance steps of the consumer until it is closed, sending a RebalanceStart notification
+// when it starts and a RebalanceOK one when it is assigned its partitions
+func (cc *clusterConsumer) rebalance() {
+	defer cc.wg.Done()
+	var notification *cluster.Notification
+	for {
+		changed := cc.cluster.changes()
+		step, tps := cc.cluster.rebalanceStep(cc.member)
+		if step != noRebalance && notification == nil {
+			notification = &cluster.Notification{Type: cluster.RebalanceStart, Current: cc.current()}
+			cc.notify(notification)
+		}
+		switch step {
+		case revokePartitions:
+			cc.releaseAll()
+			cc.cluster.release(cc.member)
+			continue
+		case assignPartitions:
+			for _, tp := range tps {
+				cc.claim(tp)
+			}
+			current := cc.current()
+			cc.notify(&cluster.Notification{
+				Type:     cluster.RebalanceOK,
+				Claimed:  difference(current, notification.Current),
+				Released: difference(notification.Current, current),
+				Current:  current,
+			})
+			notification = nil
+			continue
+		}
+		select {
+		case <-changed:
+		case <-cc.done:
+			return
+		}
+	}
+}
+
+func (cc *clusterConsumer) notify(notification *cluster.Notification) {
+	if !cc.config.Group.Return.Notifications {
+		return
+	}
+	select {
+	case cc.notifications <- notification:
+	case <-cc.done:
+	}
+}
+
+// claim starts consuming the partition from the committed offset of the group, else from Consumer.Offsets.Initial
+func (cc *clusterConsumer) claim(tp topicPartition) {
+	offset, ok := cc.cluster.committed(cc.groupID, tp)
+	initialOffset := offset
+	if !ok {
+		initialOffset = cc.config.Consumer.Offsets.Initial
+		offset = 0
+		if initialOffset == sarama.OffsetNewest {
+			offset = cc.cluster.highWatermark(tp)
+		}
+	}
+	pc := &partitionConsumer{
+		consumer:       cc,
+		topicPartition: tp,
+		initialOffset:  initialOffset,
+		marked:         -1,
+		messages:       cc.messages,
+		errors:         make(chan *sarama.ConsumerError, cc.config.ChannelBufferSize),
+		resumed:        make(chan struct{}, 1),
+		dying:          make(chan struct{}),
+		dead:           make(chan struct{}),
+	}
+	if ok {
+		pc.marked = offset
+	}
+	partitions := cc.config.Group.Mode == cluster.ConsumerModePartitions
+	if partitions {
+		pc.messages = make(chan *sarama.ConsumerMessage, cc.config.ChannelBufferSize)
+	}
+	cc.mx.Lock()
+	cc.owned[tp] = pc
+	cc.mx.Unlock()
+	go pc.consume(offset, partitions)
+	if partitions {
+		select {
+		case cc.partitions <- pc:
+		case <-cc.done:
+		}
+	}
+}
+
+// releaseAll stops consuming the owned partitions
+func (cc *clusterConsumer) releaseAll() {
+	cc.mx.Lock()
+	owned := cc.owned
+	cc.owned = make(map[topicPartition]*partitionConsumer)
+	cc.mx.Unlock()
+	for _, pc := range owned {
+		_ = pc.Close()
+	}
+}
+
+func (cc *clusterConsumer) current() map[string][]int32 {
+	cc.mx.Lock()
+	defer cc.mx.Unlock()
+	current := make(map[string][]int32)
+	for tp := range cc.owned {
+		current[tp.topic] = append(current[tp.topic], tp.partition)
+	}
+	return current
+}
+
+// difference returns the partitions of a not in b
+func difference(a, b map[string][]int32) map[string][]int32 {
+	diff := make(map[string][]int32)
+	for topic, partitions := range a {
+	PARTITIONS:
+		for _, p := range partitions {
+			for _, q := range b[topic] {
+				if p == q {
+					continue PARTITIONS
+				}
+			}
+			diff[topic] = append(diff[topic], p)
+		}
+	}
+	return diff
+}
+
+func (cc *clusterConsumer) Messages() <-chan *sarama.ConsumerMessage {
+	return cc.messages
+}
+
+func (cc *clusterConsumer) Partitions() <-chan cluster.PartitionConsumer {
+	return cc.partitions
+}
+
+func (cc *clusterConsumer) Errors() <-chan error {
+	return cc.errors
+}
+
+func (cc *clusterConsumer) Notifications() <-chan *cluster.Notification {
+	return cc.notifications
+}
+
+func (cc *clusterConsumer) MarkPartitionOffset(topic string, partition int32, offset int64, metadata string) {
+	cc.mx.Lock()
+	pc := cc.owned[topicPartition{topic, partition}]
+	cc.mx.Unlock()
+	if pc != nil {
+		pc.MarkOffset(offset, metadata)
+	}
+}
+
+func (cc *clusterConsumer) Close() error {
+	cc.closeOnce.Do(func() {
+		close(cc.done)
+		cc.wg.Wait()
+		cc.releaseAll()
+		cc.cluster.leave(cc.member)
+		close(cc.messages)
+		close(cc.partitions)
+		close(cc.errors)
+		close(cc.notifications)
+	})
+	return nil
+}
+
+// partitionConsumer consumes a partition claimed by a clusterConsumer
+type partitionConsumer struct {
+	topicPartition
+	consumer      *clusterConsumer
+	initialOffset int64
+	messages      chan *sarama.ConsumerMessage
+	errors        chan *sarama.ConsumerError
+	resumed       chan struct{}
+	dying         chan struct{}
+	dead          chan struct{}
+	closeOnce     sync.Once
+
+	mx     sync.Mutex
+	marked int64 // offset of the next message to consume
+	paused bool
+}
+
+// consume sends the messages of the partition from the offset until the partition consumer is closed.
+// In the partitions mode its channels are closed then.
+func (pc *partitionConsumer) consume(offset int64, partitions bool) {
+	defer func() {
+		if partitions {
+			close(pc.messages)
+		}
+		close(pc.errors)
+		close(pc.dead)
+	}()
+	c := pc.consumer.cluster
+	for {
+		changed := c.changes()
+		if !pc.IsPaused() {
+			r, next := c.fetch(pc.topicPartition, offset, false)
+			offset = next
+			if r != nil {
+				select {
+				case pc.messages <- toSaramaMessage(r):
+					continue
+				case <-pc.dying:
+					return
+				}
+			}
+		}
+		select {
+		case <-changed:
+		case <-pc.resumed:
+		case <-pc.dying:
+			return
+		}
+	}
+}
+
+func (pc *partitionConsumer) AsyncClose() {
+	pc.closeOnce.Do(func() {
+		close(pc.dying)
+	})
+}
+
+func (pc *partitionConsumer) Close() error {
+	pc.AsyncClose()
+	<-pc.dead
+	return nil
+}
+
+func (pc *partitionConsumer) Messages() <-chan *sarama.ConsumerMessage {
+	return pc.messages
+}
+
+func (pc *partitionConsumer) Errors() <-chan *sarama.ConsumerError {
+	return pc.errors
+}
+
+func (pc *partitionConsumer) HighWaterMarkOffset() int64 {
+	return pc.consumer.cluster.highWatermark(pc.topicPartition)
+}
+
+func (pc *partitionConsumer) Topic() string {
+	return pc.topic
+}
+
+func (pc *partitionConsumer) Partition() int32 {
+	return pc.partition
+}
+
+func (pc *partitionConsumer) InitialOffset() int64 {
+	return pc.initialOffset
+}
+
+// MarkOffset commits the offset following the processed one, if it is ahead of the committed one
+func (pc *partitionConsumer) MarkOffset(offset int64, metadata string) {
+	pc.commit(offset+1, func(next, marked int64) bool { return next > marked })
+}
+
+// ResetOffset commits the offset following the processed one, if it is not ahead of the committed one
+func (pc *partitionConsumer) ResetOffset(offset int64, metadata string) {
+	pc.commit(offset+1, func(next, marked int64) bool { return next <= marked })
+}
+
+func (pc *partitionConsumer) commit(next int64, allowed func(next, marked int64) bool) {
+	pc.mx.Lock()
+	defer pc.mx.Unlock()
+	if !allowed(next, pc.marked) {
+		return
+	}
+	pc.marked = next
+	pc.consumer.cluster.commit(pc.consumer.groupID, map[topicPartition]int64{pc.topicPartition: next})
+}
+
+func (pc *partitionConsumer) Pause() {
+	pc.mx.Lock()
+	defer pc.mx.Unlock()
+	pc.paused = true
+}
+
+func (pc *partitionConsumer) Resume() {
+	pc.mx.Lock()
+	pc.paused = false
+	pc.mx.Unlock()
+	select {
+	case pc.resumed <- struct{}{}:
+	default:
+	}
+}
+
+func (pc *partitionConsumer) IsPaused() bool {
+	pc.mx.Lock()
+	defer pc.mx.Unlock()
+	return pc.paused
+}
+
+func toSaramaMessage(r *Record) *sarama.ConsumerMessage {
+	m := &sarama.ConsumerMessage{
+		Topic:     r.Topic,
+		Partition: r.Partition,
+		Offset:    r.Offset,
+		Key:       r.Key,
+		Value:     r.Value,
+		Timestamp: r.Timestamp,
+	}
+	for _, h := range r.Headers {
+		m.Headers = append(m.Headers, &sarama.RecordHeader{Key: []byte(h.Key), Value: h.Value})
+	}
+	return m
+}
@@ -0,0 +1,253 @@
+package kafkatest
+
+import (
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/kafka"
+	saramaconsumer "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging-sarama/consumer"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging-sarama/publisher"
+)
+
+// saramaReceived collects the messages handled by the consumers of messaging-sarama by value
+type saramaReceived struct {
+	mx       sync.Mutex
+	messages map[string]saramaconsumer.Message
+}
+
+func newSaramaReceived() *saramaReceived {
+	return &saramaReceived{messages: make(map[string]saramaconsumer.Message)}
+}
+
+func (r *saramaReceived) handle(message saramaconsumer.Message) error {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.messages[string(message.Message)] = message
+	return nil
+}
+
+func (r *saramaReceived) count() int {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	return len(r.messages)
+}
+
+func (r *saramaReceived) get(value string) (saramaconsumer.Message, bool) {
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	m, ok := r.messages[value]
+	return m, ok
+}
+
+func newSaramaConsumerConfig(c *Cluster, group string, handler func(saramaconsumer.Message) error, topics ...string) saramaconsumer.Config {
+	config := saramaconsumer.NewConfig()
+	config.Address = []string{c.Address()}
+	config.Group = group
+	config.Topics = topics
+	config.MessageHandler = handler
+	config.ConsumerMode = saramaconsumer.PullOrdered
+	config.OffsetsInitial = saramaconsumer.OffsetOldest
+	config.RetryDelay = time.Millisecond
+	return config
+}
+
+// startSaramaConsumer pulls with a consumer until the returned function or the test cleanup closes it
+func startSaramaConsumer(t *testing.T, config saramaconsumer.Config) func() {
+	sc, err := saramaconsumer.New(config)
+	require.NoError(t, err)
+	go sc.Pull()
+	var once sync.Once
+	stop := func() { once.Do(func() { require.NoError(t, sc.Close()) }) }
+	t.Cleanup(stop)
+	return stop
+}
+
+func TestSaramaConsumerConsumesPublishedMessages(t *testing.T) {
+	c := newTestCluster(t)
+	require.NoError(t, c.CreateTopic("orders", 3))
+
+	// the publisher keeps its producers for the process, its sarama producer is created as it does instead
+	config := publisher.NewConfig()
+	config.Address = []string{c.Address()}
+	p, err := publisher.NewSaramaSyncProducer(config.Address, publisher.GetConfig(publisher.RegularKafkaProducer, config))
+	require.NoError(t, err)
+	defer p.Close()
+	for i := 0; i < 30; i++ {
+		_, _, err := p.SendMessage(&sarama.ProducerMessage{
+			Topic:   "orders",
+			Key:     publisher.EncodeString(strconv.Itoa(i)),
+			Value:   publisher.EncodeString("orders-" + strconv.Itoa(i)),
+			Headers: []sarama.RecordHeader{{Key: []byte("index"), Value: []byte(strconv.Itoa(i))}},
+		})
+		require.NoError(t, err)
+	}
+
+	// PullUnOrdered is left out with OnMessageCompletion, its partition states are not safe for concurrent workers
+	tests := []struct {
+		name      string
+		configure func(*saramaconsumer.Config)
+	}{
+		{name: "PullUnOrdered OnPull", configure: func(config *saramaconsumer.Config) {
+			config.ConsumerMode, config.CommitMode = saramaconsumer.PullUnOrdered, saramaconsumer.OnPull
+		}},
+		{name: "PullOrdered OnPull", configure: func(config *saramaconsumer.Config) {
+			config.ConsumerMode, config.CommitMode = saramaconsumer.PullOrdered, saramaconsumer.OnPull
+		}},
+		{name: "PullOrdered OnMessageCompletion", configure: func(config *saramaconsumer.Config) {
+			config.ConsumerMode, config.CommitMode = saramaconsumer.PullOrdered, saramaconsumer.OnMessageCompletion
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newSaramaReceived()
+			config := newSaramaConsumerConfig(c, tt.name, r.handle, "orders")
+			tt.configure(&config)
+			startSaramaConsumer(t, config)
+
+			require.Eventually(t, func() bool { return r.count() == 30 }, waitFor, time.Millisecond)
+			// with OnMessageCompletion the completions during the commit of another partition are not committed,
+			// the last offset of a partition can stay uncommitted until its next message
+			if config.CommitMode == saramaconsumer.OnPull {
+				require.Eventually(t, func() bool { return committedAll(c, tt.name, "orders") }, waitFor, time.Millisecond)
+			}
+
+			m, ok := r.get("orders-7")
+			require.True(t, ok)
+			require.Equal(t, "7", m.GetHeader("index"))
+			require.Equal(t, []byte("7"), c.Records("orders", m.Partition)[m.Offset].Key)
+		})
+	}
+}
+
+func TestSaramaConsumerCommitModes(t *testing.T) {
+	tests := []struct {
+		name       string
+		commitMode func(*saramaconsumer.Config)
+		// offset committed while the handler of the first message is running
+		committedWhileHandling int64
+	}{
+		{name: "OnPull", commitMode: func(config *saramaconsumer.Config) { config.CommitMode = saramaconsumer.OnPull }, committedWhileHandling: 1},
+		{name: "OnMessageCompletion", commitMode: func(config *saramaconsumer.Config) { config.CommitMode = saramaconsumer.OnMessageCompletion }, committedWhileHandling: -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCluster(t)
+			for i := 0; i < 2; i++ {
+				_, err := c.Produce("orders", 0, nil, []byte(strconv.Itoa(i)))
+				require.NoError(t, err)
+			}
+
+			handling, release := make(chan struct{}), make(chan struct{})
+			r := newSaramaReceived()
+			config := newSaramaConsumerConfig(c, "group", func(message saramaconsumer.Message) error {
+				if message.Offset == 0 {
+					close(handling)
+					<-release
+				}
+				return r.handle(message)
+			}, "orders")
+			tt.commitMode(&config)
+			startSaramaConsumer(t, config)
+
+			<-handling
+			if tt.committedWhileHandling >= 0 {
+				require.Eventually(t, func() bool {
+					return c.CommittedOffset("group", "orders", 0) == tt.committedWhileHandling
+				}, waitFor, time.Millisecond)
+			} else {
+				time.Sleep(100 * time.Millisecond)
+			}
+			require.Equal(t, tt.committedWhileHandling, c.CommittedOffset("group", "orders", 0))
+
+			close(release)
+			require.Eventually(t, func() bool { return c.CommittedOffset("group", "orders", 0) == 2 }, waitFor, time.Millisecond)
+			require.Equal(t, 2, r.count())
+		})
+	}
+}
+
+func TestSaramaConsumerGroupRebalance(t *testing.T) {
+	c := newTestCluster(t)
+	require.NoError(t, c.CreateTopic("orders", 4))
+
+	var notifications []string
+	var mx sync.Mutex
+	first, second := newSaramaReceived(), newSaramaReceived()
+	config := newSaramaConsumerConfig(c, "group", first.handle, "orders")
+	config.NotificationHandler = func(notification string) {
+		mx.Lock()
+		defer mx.Unlock()
+		notifications = append(notifications, notification)
+	}
+	startSaramaConsumer(t, config)
+	require.Eventually(t, func() bool {
+		members := c.Members("group")
+		return len(members) == 1 && len(members[0]) == 4
+	}, waitFor, time.Millisecond)
+
+	stop := startSaramaConsumer(t, newSaramaConsumerConfig(c, "group", second.handle, "orders"))
+	require.Eventually(t, func() bool {
+		members := c.Members("group")
+		return len(members) == 2 && len(members[0]) == 2 && len(members[1]) == 2
+	}, waitFor, time.Millisecond)
+
+	for i := 0; i < 40; i++ {
+		_, err := c.Produce("orders", int32(i%4), nil, []byte("orders-"+strconv.Itoa(i)))
+		require.NoError(t, err)
+	}
+	require.Eventually(t, func() bool { return first.count()+second.count() == 40 }, waitFor, time.Millisecond)
+	require.Equal(t, 20, first.count())
+	require.Equal(t, 20, second.count())
+	require.Eventually(t, func() bool { return committedAll(c, "group", "orders") }, waitFor, time.Millisecond)
+
+	stop()
+	require.Eventually(t, func() bool {
+		members := c.Members("group")
+		return len(members) == 1 && len(members[0]) == 4
+	}, waitFor, time.Millisecond)
+	mx.Lock()
+	defer mx.Unlock()
+	require.NotEmpty(t, notifications)
+}
+
+func TestLegacyFactories(t *testing.T) {
+	c := newTestCluster(t)
+	address := kafka.ClientConfig{BrokerAddress: []string{c.Address()}}
+
+	service, err := c.ConsumerFactory().GetConsumerService(kafka.ConsumerConfig{ClientConfig: address, GroupID: "group", Topics: []string{"orders"}})
+	require.NoError(t, err)
+	cs, ok := service.(kafka.ConsumerServiceWithOrder)
+	require.True(t, ok)
+	messages, pulled := make(chan kafka.ConsumerMessage, 2), make(chan error, 1)
+	go func() {
+		pulled <- cs.PullHandlerSequential(func(message kafka.ConsumerMessage) { messages <- message })
+	}()
+	// the consumers start from the newest offset, the messages are pushed once the partition is assigned
+	require.Eventually(t, func() bool {
+		members := c.Members("group")
+		return len(members) == 1 && len(members[0]) == 1
+	}, waitFor, time.Millisecond)
+
+	ps, err := c.ProducerFactory().GetProducerService(kafka.ProducerConfig{ClientConfig: address})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, ps.CloseConnection()) }()
+	require.NoError(t, ps.Push("orders", "first"))
+	require.NoError(t, ps.Push("orders", "second"))
+
+	for i, value := range []string{"first", "second"} {
+		message := <-messages
+		require.Equal(t, value, message.Message)
+		require.Equal(t, int64(i), message.Offset)
+	}
+	require.Eventually(t, func() bool { return c.CommittedOffset("group", "orders", 0) == 2 }, waitFor, time.Millisecond)
+	require.NoError(t, cs.CloseConnection())
+	require.NoError(t, <-pulled)
+
+	_, err = c.ConsumerFactory().GetConsumerService(kafka.ConsumerConfig{ClientConfig: address, GroupID: "group"})
+	require.EqualError(t, err, kafka.ErrorTopicsNotProvided)
+}
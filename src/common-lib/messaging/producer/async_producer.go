@@ -5,7 +5,7 @@ import (
 )
 
 type asyncProducer struct {
-	producer           *kafka.Producer
+	producer           Client
 	produceChan        chan *Message
 	deliveryReportChan chan *DeliveryReport
 	health             *Health
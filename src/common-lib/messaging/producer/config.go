@@ -70,6 +70,11 @@ type Config struct {
 	// Note: Blocks main thead when reached
 	// Default: 1000000
 	ProduceChannelSize int
+
+	// TransactionalID: identifies the producer across restarts, so the transactions of a previous instance are fenced (transactional.id)
+	// Note: Required by NewTransactionalProducer only, it must be unique per producer instance
+	// Default: None
+	TransactionalID string
 }
 
 // NewConfig - returns a configration object having default values
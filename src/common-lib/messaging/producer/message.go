@@ -22,6 +22,15 @@ func (m *Message) AddHeader(key string, value string) {
 	m.headers[key] = value
 }
 
+// GetHeaders : Retrieve a copy of the message headers
+func (m *Message) GetHeaders() map[string]string {
+	headers := make(map[string]string, len(m.headers))
+	for k, v := range m.headers {
+		headers[k] = v
+	}
+	return headers
+}
+
 func (m *Message) toKafkaMessage() *kafka.Message {
 	message := &kafka.Message{
 		Key:   m.Key,
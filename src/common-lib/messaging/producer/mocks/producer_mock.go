@@ -8,6 +8,7 @@ import (
 	context "context"
 	reflect "reflect"
 
+	kafka "github.com/confluentinc/confluent-kafka-go/kafka"
 	gomock "github.com/golang/mock/gomock"
 	producer "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
 )
@@ -295,3 +296,192 @@ func (mr *MockAsyncProducerMockRecorder) ProduceChannel() *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceChannel", reflect.TypeOf((*MockAsyncProducer)(nil).ProduceChannel))
 }
+
+// MockClient is a mock of Client interface.
+type MockClient struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientMockRecorder
+}
+
+// MockClientMockRecorder is the mock recorder for MockClient.
+type MockClientMockRecorder struct {
+	mock *MockClient
+}
+
+// NewMockClient creates a new mock instance.
+func NewMockClient(ctrl *gomock.Controller) *MockClient {
+	mock := &MockClient{ctrl: ctrl}
+	mock.recorder = &MockClientMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClient) EXPECT() *MockClientMockRecorder {
+	return m.recorder
+}
+
+// AbortTransaction mocks base method.
+func (m *MockClient) AbortTransaction(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AbortTransaction", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AbortTransaction indicates an expected call of AbortTransaction.
+func (mr *MockClientMockRecorder) AbortTransaction(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AbortTransaction", reflect.TypeOf((*MockClient)(nil).AbortTransaction), ctx)
+}
+
+// BeginTransaction mocks base method.
+func (m *MockClient) BeginTransaction() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeginTransaction")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BeginTransaction indicates an expected call of BeginTransaction.
+func (mr *MockClientMockRecorder) BeginTransaction() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeginTransaction", reflect.TypeOf((*MockClient)(nil).BeginTransaction))
+}
+
+// Close mocks base method.
+func (m *MockClient) Close() {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "Close")
+}
+
+// Close indicates an expected call of Close.
+func (mr *MockClientMockRecorder) Close() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Close", reflect.TypeOf((*MockClient)(nil).Close))
+}
+
+// CommitTransaction mocks base method.
+func (m *MockClient) CommitTransaction(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CommitTransaction", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CommitTransaction indicates an expected call of CommitTransaction.
+func (mr *MockClientMockRecorder) CommitTransaction(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CommitTransaction", reflect.TypeOf((*MockClient)(nil).CommitTransaction), ctx)
+}
+
+// Events mocks base method.
+func (m *MockClient) Events() chan kafka.Event {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Events")
+	ret0, _ := ret[0].(chan kafka.Event)
+	return ret0
+}
+
+// Events indicates an expected call of Events.
+func (mr *MockClientMockRecorder) Events() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Events", reflect.TypeOf((*MockClient)(nil).Events))
+}
+
+// Flush mocks base method.
+func (m *MockClient) Flush(timeoutMs int) int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Flush", timeoutMs)
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Flush indicates an expected call of Flush.
+func (mr *MockClientMockRecorder) Flush(timeoutMs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Flush", reflect.TypeOf((*MockClient)(nil).Flush), timeoutMs)
+}
+
+// GetFatalError mocks base method.
+func (m *MockClient) GetFatalError() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFatalError")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// GetFatalError indicates an expected call of GetFatalError.
+func (mr *MockClientMockRecorder) GetFatalError() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFatalError", reflect.TypeOf((*MockClient)(nil).GetFatalError))
+}
+
+// InitTransactions mocks base method.
+func (m *MockClient) InitTransactions(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InitTransactions", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// InitTransactions indicates an expected call of InitTransactions.
+func (mr *MockClientMockRecorder) InitTransactions(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InitTransactions", reflect.TypeOf((*MockClient)(nil).InitTransactions), ctx)
+}
+
+// Len mocks base method.
+func (m *MockClient) Len() int {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Len")
+	ret0, _ := ret[0].(int)
+	return ret0
+}
+
+// Len indicates an expected call of Len.
+func (mr *MockClientMockRecorder) Len() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Len", reflect.TypeOf((*MockClient)(nil).Len))
+}
+
+// Produce mocks base method.
+func (m *MockClient) Produce(msg *kafka.Message, deliveryChan chan kafka.Event) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Produce", msg, deliveryChan)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Produce indicates an expected call of Produce.
+func (mr *MockClientMockRecorder) Produce(msg, deliveryChan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Produce", reflect.TypeOf((*MockClient)(nil).Produce), msg, deliveryChan)
+}
+
+// ProduceChannel mocks base method.
+func (m *MockClient) ProduceChannel() chan *kafka.Message {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ProduceChannel")
+	ret0, _ := ret[0].(chan *kafka.Message)
+	return ret0
+}
+
+// ProduceChannel indicates an expected call of ProduceChannel.
+func (mr *MockClientMockRecorder) ProduceChannel() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ProduceChannel", reflect.TypeOf((*MockClient)(nil).ProduceChannel))
+}
+
+// SendOffsetsToTransaction mocks base method.
+func (m *MockClient) SendOffsetsToTransaction(ctx context.Context, offsets []kafka.TopicPartition, consumerMetadata *kafka.ConsumerGroupMetadata) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SendOffsetsToTransaction", ctx, offsets, consumerMetadata)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SendOffsetsToTransaction indicates an expected call of SendOffsetsToTransaction.
+func (mr *MockClientMockRecorder) SendOffsetsToTransaction(ctx, offsets, consumerMetadata interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendOffsetsToTransaction", reflect.TypeOf((*MockClient)(nil).SendOffsetsToTransaction), ctx, offsets, consumerMetadata)
+}
@@ -134,9 +134,47 @@ var SyncProducer = func(producerType Type, config *Config) (StatusableProducer,
 	return kafkaProducer[producerType], nil
 }
 
-func newKafkaProducer(config *Config, stateChanged func(bool)) (*kafka.Producer, error) {
+// Client describes the kafka producer used by the producers of this package, implemented by *kafka.Producer.
+type Client interface {
+	// Produce single message
+	Produce(msg *kafka.Message, deliveryChan chan kafka.Event) error
+	// ProduceChannel returns the produce *Message channel (write)
+	ProduceChannel() chan *kafka.Message
+	// Events returns the Events channel (read)
+	Events() chan kafka.Event
+	// Len returns the number of messages and requests waiting to be transmitted to the broker as well as delivery reports queued for the application
+	Len() int
+	// Flush and wait for outstanding messages and requests to complete delivery, returns the number of outstanding events
+	Flush(timeoutMs int) int
+	// Close a Producer instance
+	Close()
+	// GetFatalError returns an Error object if the client instance has raised a fatal error, else nil
+	GetFatalError() error
+	// InitTransactions initializes transactions for the producer instance
+	InitTransactions(ctx context.Context) error
+	// BeginTransaction starts a new transaction
+	BeginTransaction() error
+	// SendOffsetsToTransaction sends a list of topic partition offsets to the consumer group coordinator for consumerMetadata
+	SendOffsetsToTransaction(ctx context.Context, offsets []kafka.TopicPartition, consumerMetadata *kafka.ConsumerGroupMetadata) error
+	// CommitTransaction commits the current transaction
+	CommitTransaction(ctx context.Context) error
+	// AbortTransaction aborts the ongoing transaction
+	AbortTransaction(ctx context.Context) error
+}
+
+// NewClient creates the kafka producer of the producers.
+// It can be replaced to run the producers against another implementation, e.g. the in-memory cluster of messaging/kafkatest.
+var NewClient = func(configMap *kafka.ConfigMap) (Client, error) {
+	p, err := kafka.NewProducer(configMap)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func newKafkaProducer(config *Config, stateChanged func(bool)) (Client, error) {
 	logsChan := make(chan kafka.LogEvent, 10000)
-	p, err := kafka.NewProducer(&kafka.ConfigMap{
+	configMap := kafka.ConfigMap{
 		"bootstrap.servers":            strings.Join(config.Address, ","),
 		"request.timeout.ms":           config.TimeoutInSecond * 1000,
 		"message.max.bytes":            config.MaxMessageBytes,
@@ -154,7 +192,11 @@ func newKafkaProducer(config *Config, stateChanged func(bool)) (*kafka.Producer,
 		"go.logs.channel.enable":       true,
 		"go.logs.channel":              logsChan,
 		"debug":                        "broker",
-	})
+	}
+	if config.TransactionalID != "" {
+		configMap["transactional.id"] = config.TransactionalID
+	}
+	p, err := NewClient(&configMap)
 	if err != nil {
 		close(logsChan)
 		return nil, err
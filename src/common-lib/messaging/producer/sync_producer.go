@@ -9,7 +9,7 @@ import (
 )
 
 type syncProducer struct {
-	producer   *kafka.Producer
+	producer   Client
 	fatalError error
 	health     *Health
 }
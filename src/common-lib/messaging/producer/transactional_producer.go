@@ -0,0 +1,116 @@
+package producer
+
+import (
+	"context"
+	"errors"
+
+	"github.com/confluentinc/confluent-kafka-go/kafka"
+)
+
+// ErrTransactionalIDMissing : Error transactional producer is created without Config.TransactionalID
+var ErrTransactionalIDMissing = errors.New("Transaction:TransactionalID:Missing")
+
+// TransactionalProducer publishes messages in Kafka transactions, along with the offsets of the consumed messages they result from.
+// Only one transaction can be in progress at a time, the producer should not be used concurrently.
+type TransactionalProducer interface {
+	// InitTransactions: initializes the transactions of the producer, must be called once before any other transactional method
+	InitTransactions(ctx context.Context) error
+	// BeginTransaction: starts a new transaction
+	BeginTransaction() error
+	// Produce: enqueues messages in the current transaction, delivery errors are returned by CommitTransaction
+	Produce(ctx context.Context, transaction string, messages ...*Message) error
+	// SendOffsetsToTransaction: adds the offsets of the consumer group to the current transaction, so they are committed with the produced messages
+	SendOffsetsToTransaction(ctx context.Context, offsets []kafka.TopicPartition, metadata *kafka.ConsumerGroupMetadata) error
+	// CommitTransaction: flushes the produced messages and commits the current transaction
+	CommitTransaction(ctx context.Context) error
+	// AbortTransaction: aborts the current transaction, its messages are never seen by read_committed consumers
+	AbortTransaction(ctx context.Context) error
+	// Close
+	Close()
+	// Health returns producer's health
+	Health() (*Health, error)
+}
+
+type transactionalProducer struct {
+	producer Client
+	health   *Health
+}
+
+func (tp *transactionalProducer) InitTransactions(ctx context.Context) error {
+	return tp.producer.InitTransactions(ctx)
+}
+
+func (tp *transactionalProducer) BeginTransaction() error {
+	return tp.producer.BeginTransaction()
+}
+
+func (tp *transactionalProducer) Produce(ctx context.Context, transaction string, messages ...*Message) error {
+	if len(messages) == 0 {
+		return ErrPublishMessageNotAvailable
+	}
+	for _, message := range messages {
+		msg := message.toKafkaMessage()
+		msg.Opaque = message
+		if err := tp.producer.Produce(msg, nil); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ErrPublishSendMessageTimeout
+		}
+	}
+	return nil
+}
+
+func (tp *transactionalProducer) SendOffsetsToTransaction(ctx context.Context, offsets []kafka.TopicPartition, metadata *kafka.ConsumerGroupMetadata) error {
+	return tp.producer.SendOffsetsToTransaction(ctx, offsets, metadata)
+}
+
+func (tp *transactionalProducer) CommitTransaction(ctx context.Context) error {
+	return tp.producer.CommitTransaction(ctx)
+}
+
+func (tp *transactionalProducer) AbortTransaction(ctx context.Context) error {
+	return tp.producer.AbortTransaction(ctx)
+}
+
+func (tp *transactionalProducer) Close() {
+	tp.producer.Close()
+}
+
+func (tp *transactionalProducer) Health() (*Health, error) {
+	return tp.health, nil
+}
+
+func (tp *transactionalProducer) connStateChange(state bool) {
+	tp.health.ConnectionState = state
+}
+
+// processEvents drains the delivery reports, a failed delivery makes the transaction fail on commit
+func (tp *transactionalProducer) processEvents(ec chan kafka.Event) {
+	for ev := range ec {
+		m, ok := ev.(*kafka.Message)
+		if !ok || m.TopicPartition.Error == nil {
+			continue
+		}
+		Logger().Error(m.TopicPartition.String(), "Transaction:Delivery:Failed", "Delivery of a transactional message failed: %v", m.TopicPartition.Error)
+	}
+}
+
+// NewTransactionalProducer returns a transactional implementation of Producer, Config.TransactionalID is required.
+var NewTransactionalProducer = func(config *Config) (TransactionalProducer, error) {
+	if config.TransactionalID == "" {
+		return nil, ErrTransactionalIDMissing
+	}
+	tp := &transactionalProducer{
+		health: newHealth(),
+	}
+	tp.health.Address = config.Address
+	tp.health.ConnectionState = true
+	p, err := newKafkaProducer(config, tp.connStateChange)
+	if err != nil {
+		return nil, err
+	}
+	tp.producer = p
+	go tp.processEvents(tp.producer.Events())
+	return tp, nil
+}
@@ -0,0 +1,91 @@
+package serde
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+type avroSerde struct {
+	schemaSerde
+	codec *goavro.Codec
+
+	mx     sync.RWMutex
+	codecs map[int]*goavro.Codec // codecs of the writer schemas by ID
+}
+
+// NewAvroSerde creates a Serde of values encoded with the Avro schema.
+// Values are converted through their encoding/json form, which must match the Avro JSON encoding of the schema,
+// e.g. a non-null value of a union is wrapped in an object keyed by its type: {"string": "value"}.
+// Values are decoded with the schema they were written with, so fields added or removed by a compatible schema are handled as with encoding/json.
+func NewAvroSerde(registry Registry, schema string, config *Config) (Serde, error) {
+	s, err := newSchemaSerde(registry, config, Avro, schema)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := goavro.NewCodec(schema)
+	if err != nil {
+		return nil, err
+	}
+	return &avroSerde{schemaSerde: s, codec: codec, codecs: make(map[int]*goavro.Codec)}, nil
+}
+
+func (s *avroSerde) Serialize(ctx context.Context, topic string, value interface{}) ([]byte, error) {
+	id, err := s.schemaID(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	textual, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	native, _, err := s.codec.NativeFromTextual(textual)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := s.codec.BinaryFromNative(nil, native)
+	if err != nil {
+		return nil, err
+	}
+	return encodeWireFormat(id, payload), nil
+}
+
+func (s *avroSerde) Deserialize(ctx context.Context, topic string, data []byte, v interface{}) error {
+	id, schema, payload, err := s.writerSchema(ctx, data)
+	if err != nil {
+		return err
+	}
+	codec, err := s.writerCodec(id, schema)
+	if err != nil {
+		return err
+	}
+	native, _, err := codec.NativeFromBinary(payload)
+	if err != nil {
+		return err
+	}
+	textual, err := codec.TextualFromNative(nil, native)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(textual, v)
+}
+
+func (s *avroSerde) writerCodec(id int, schema Schema) (*goavro.Codec, error) {
+	s.mx.RLock()
+	codec, ok := s.codecs[id]
+	s.mx.RUnlock()
+	if ok {
+		return codec, nil
+	}
+
+	codec, err := goavro.NewCodec(schema.Schema)
+	if err != nil {
+		return nil, err
+	}
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.codecs[id] = codec
+	return codec, nil
+}
@@ -0,0 +1,40 @@
+package serde
+
+import (
+	"context"
+	"encoding/json"
+)
+
+type jsonSerde struct {
+	schemaSerde
+}
+
+// NewJSONSerde creates a Serde of values encoded with encoding/json and described by the JSON Schema.
+// Compatibility of the schema is checked by the registry when it is registered, values are not validated against it.
+func NewJSONSerde(registry Registry, schema string, config *Config) (Serde, error) {
+	s, err := newSchemaSerde(registry, config, JSON, schema)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonSerde{schemaSerde: s}, nil
+}
+
+func (s *jsonSerde) Serialize(ctx context.Context, topic string, value interface{}) ([]byte, error) {
+	id, err := s.schemaID(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return encodeWireFormat(id, payload), nil
+}
+
+func (s *jsonSerde) Deserialize(ctx context.Context, topic string, data []byte, v interface{}) error {
+	_, _, payload, err := s.writerSchema(ctx, data)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, v)
+}
@@ -0,0 +1,105 @@
+package serde
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type protobufSerde struct {
+	schemaSerde
+}
+
+// NewProtobufSerde creates a Serde of protobuf messages described by the .proto definition.
+// Values must be generated protobuf messages (proto.Message) of the definition.
+func NewProtobufSerde(registry Registry, schema string, config *Config) (Serde, error) {
+	s, err := newSchemaSerde(registry, config, Protobuf, schema)
+	if err != nil {
+		return nil, err
+	}
+	return &protobufSerde{schemaSerde: s}, nil
+}
+
+func (s *protobufSerde) Serialize(ctx context.Context, topic string, value interface{}) ([]byte, error) {
+	message, ok := value.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%w: %T is not a proto.Message", ErrUnsupportedValue, value)
+	}
+	id, err := s.schemaID(ctx, topic)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proto.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	indexes := encodeMessageIndexes(messageIndexes(message.ProtoReflect().Descriptor()))
+	return encodeWireFormat(id, append(indexes, payload...)), nil
+}
+
+func (s *protobufSerde) Deserialize(ctx context.Context, topic string, data []byte, v interface{}) error {
+	message, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%w: %T is not a proto.Message", ErrUnsupportedValue, v)
+	}
+	_, _, payload, err := s.writerSchema(ctx, data)
+	if err != nil {
+		return err
+	}
+	_, payload, err = decodeMessageIndexes(payload)
+	if err != nil {
+		return err
+	}
+	return proto.Unmarshal(payload, message)
+}
+
+// messageIndexes returns the path of the message in its .proto file, e.g. [1, 0] for the first message nested in the second one
+func messageIndexes(descriptor protoreflect.MessageDescriptor) []int {
+	var indexes []int
+	var d protoreflect.Descriptor = descriptor
+	for {
+		if _, ok := d.(protoreflect.MessageDescriptor); !ok {
+			break
+		}
+		indexes = append([]int{d.Index()}, indexes...)
+		d = d.Parent()
+	}
+	return indexes
+}
+
+// encodeMessageIndexes encodes the message indexes as zigzag varints preceded by their count, [0] being encoded as a single 0
+func encodeMessageIndexes(indexes []int) []byte {
+	if len(indexes) == 1 && indexes[0] == 0 {
+		return []byte{0}
+	}
+	buf := make([]byte, binary.MaxVarintLen64*(len(indexes)+1))
+	n := binary.PutVarint(buf, int64(len(indexes)))
+	for _, i := range indexes {
+		n += binary.PutVarint(buf[n:], int64(i))
+	}
+	return buf[:n]
+}
+
+func decodeMessageIndexes(data []byte) ([]int, []byte, error) {
+	count, n := binary.Varint(data)
+	if n <= 0 || count < 0 {
+		return nil, nil, ErrInvalidWireFormat
+	}
+	data = data[n:]
+	if count == 0 {
+		return []int{0}, data, nil
+	}
+	indexes := make([]int, 0, count)
+	for i := int64(0); i < count; i++ {
+		index, n := binary.Varint(data)
+		if n <= 0 {
+			return nil, nil, ErrInvalidWireFormat
+		}
+		indexes = append(indexes, int(index))
+		data = data[n:]
+	}
+	return indexes, data, nil
+}
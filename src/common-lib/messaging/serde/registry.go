@@ -0,0 +1,240 @@
+package serde
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SchemaType is the type of a schema in the schema registry
+type SchemaType string
+
+const (
+	// Avro schema, the registry default
+	Avro SchemaType = "AVRO"
+	// JSON schema (JSON Schema)
+	JSON SchemaType = "JSON"
+	// Protobuf schema (.proto definition)
+	Protobuf SchemaType = "PROTOBUF"
+)
+
+// Reference is a reference of a schema to a schema registered under another subject, e.g. an imported .proto file
+type Reference struct {
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Version int    `json:"version"`
+}
+
+// Schema is a schema stored in the schema registry
+type Schema struct {
+	Type       SchemaType
+	Schema     string
+	References []Reference
+}
+
+// RegistryError is the error returned by the schema registry
+type RegistryError struct {
+	StatusCode int    `json:"-"`
+	ErrorCode  int    `json:"error_code"`
+	Message    string `json:"message"`
+}
+
+// Error so that RegistryError implements error interface.
+func (e *RegistryError) Error() string {
+	return fmt.Sprintf("schema registry failed with status %d, error code %d: %s", e.StatusCode, e.ErrorCode, e.Message)
+}
+
+// Registry is a client of the Confluent schema registry
+type Registry interface {
+	// Register registers the schema under the subject if not registered yet and returns its ID
+	// The registry rejects a schema which is not compatible with the subject's compatibility level
+	Register(ctx context.Context, subject string, schema Schema) (int, error)
+	// Lookup returns the ID of a schema already registered under the subject
+	Lookup(ctx context.Context, subject string, schema Schema) (int, error)
+	// GetByID returns the schema of the ID
+	GetByID(ctx context.Context, id int) (Schema, error)
+	// TestCompatibility checks whether the schema is compatible with the latest version of the subject
+	TestCompatibility(ctx context.Context, subject string, schema Schema) (bool, error)
+}
+
+// RegistryConfig is the configuration of the schema registry client
+type RegistryConfig struct {
+	// URL: URL of the schema registry, e.g. http://localhost:8081
+	URL string
+
+	// Username and Password: credentials of the basic authentication, if enabled
+	Username string
+	Password string
+
+	// Timeout: Max time of a request to the schema registry
+	// Default: 10s
+	Timeout time.Duration
+
+	// Client: HTTP client used for the requests, e.g. to configure TLS
+	// Default: http.Client with Timeout
+	Client *http.Client
+}
+
+// NewRegistryConfig returns default schema registry client configuration
+func NewRegistryConfig() *RegistryConfig {
+	return &RegistryConfig{
+		Timeout: 10 * time.Second,
+	}
+}
+
+const registryContentType = "application/vnd.schemaregistry.v1+json"
+
+type registry struct {
+	config *RegistryConfig
+	client *http.Client
+
+	mx      sync.RWMutex
+	ids     map[string]int // ID by subject and schema
+	schemas map[int]Schema // schema by ID
+}
+
+// NewRegistry creates a schema registry client caching the registered schemas and their IDs, which never change in the registry
+func NewRegistry(config *RegistryConfig) (Registry, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("schema registry URL is blank")
+	}
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: config.Timeout}
+	}
+	return &registry{
+		config:  config,
+		client:  client,
+		ids:     make(map[string]int),
+		schemas: make(map[int]Schema),
+	}, nil
+}
+
+type schemaPayload struct {
+	Schema     string      `json:"schema"`
+	SchemaType SchemaType  `json:"schemaType,omitempty"`
+	References []Reference `json:"references,omitempty"`
+}
+
+func newSchemaPayload(schema Schema) schemaPayload {
+	p := schemaPayload{Schema: schema.Schema, SchemaType: schema.Type, References: schema.References}
+	if p.SchemaType == Avro {
+		p.SchemaType = "" // AVRO is the default and omitted by the registry
+	}
+	return p
+}
+
+func (r *registry) Register(ctx context.Context, subject string, schema Schema) (int, error) {
+	return r.id(ctx, "POST", "/subjects/"+url.PathEscape(subject)+"/versions", subject, schema)
+}
+
+func (r *registry) Lookup(ctx context.Context, subject string, schema Schema) (int, error) {
+	return r.id(ctx, "POST", "/subjects/"+url.PathEscape(subject), subject, schema)
+}
+
+// id returns the cached ID of the schema or gets it from the registry
+func (r *registry) id(ctx context.Context, method, path, subject string, schema Schema) (int, error) {
+	key := subject + "\x00" + string(schema.Type) + "\x00" + schema.Schema
+	r.mx.RLock()
+	id, ok := r.ids[key]
+	r.mx.RUnlock()
+	if ok {
+		return id, nil
+	}
+
+	var result struct {
+		ID int `json:"id"`
+	}
+	if err := r.do(ctx, method, path, newSchemaPayload(schema), &result); err != nil {
+		return 0, err
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.ids[key] = result.ID
+	r.schemas[result.ID] = schema
+	return result.ID, nil
+}
+
+func (r *registry) GetByID(ctx context.Context, id int) (Schema, error) {
+	r.mx.RLock()
+	schema, ok := r.schemas[id]
+	r.mx.RUnlock()
+	if ok {
+		return schema, nil
+	}
+
+	var result schemaPayload
+	if err := r.do(ctx, "GET", "/schemas/ids/"+strconv.Itoa(id), nil, &result); err != nil {
+		return Schema{}, err
+	}
+	schema = Schema{Type: result.SchemaType, Schema: result.Schema, References: result.References}
+	if schema.Type == "" {
+		schema.Type = Avro
+	}
+
+	r.mx.Lock()
+	defer r.mx.Unlock()
+	r.schemas[id] = schema
+	return schema, nil
+}
+
+func (r *registry) TestCompatibility(ctx context.Context, subject string, schema Schema) (bool, error) {
+	var result struct {
+		IsCompatible bool `json:"is_compatible"`
+	}
+	path := "/compatibility/subjects/" + url.PathEscape(subject) + "/versions/latest"
+	if err := r.do(ctx, "POST", path, newSchemaPayload(schema), &result); err != nil {
+		return false, err
+	}
+	return result.IsCompatible, nil
+}
+
+// do sends the request to the registry and decodes the response into result
+func (r *registry) do(ctx context.Context, method, path string, payload interface{}, result interface{}) error {
+	var body bytes.Buffer
+	if payload != nil {
+		if err := json.NewEncoder(&body).Encode(payload); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequest(method, strings.TrimSuffix(r.config.URL, "/")+path, &body)
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Accept", registryContentType)
+	if payload != nil {
+		req.Header.Set("Content-Type", registryContentType)
+	}
+	if r.config.Username != "" {
+		req.SetBasicAuth(r.config.Username, r.config.Password)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		registryErr := &RegistryError{StatusCode: resp.StatusCode}
+		if json.Unmarshal(data, registryErr) != nil || registryErr.Message == "" {
+			registryErr.Message = string(data)
+		}
+		return registryErr
+	}
+	return json.Unmarshal(data, result)
+}
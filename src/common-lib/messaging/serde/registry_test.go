@@ -0,0 +1,159 @@
+package serde
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRegistry is an in-memory schema registry serving the endpoints used by the client
+type fakeRegistry struct {
+	mx       sync.Mutex
+	schemas  []schemaPayload          // schema of ID i+1
+	subjects map[string][]int         // IDs of the versions of a subject
+	requests map[string]int           // number of requests by method and path prefix
+	reject   func(schemaPayload) bool // whether a registered schema is incompatible
+}
+
+func newFakeRegistry(t *testing.T) (*fakeRegistry, Registry) {
+	f := &fakeRegistry{subjects: make(map[string][]int), requests: make(map[string]int)}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+
+	config := NewRegistryConfig()
+	config.URL = server.URL
+	r, err := NewRegistry(config)
+	require.NoError(t, err)
+	return f, r
+}
+
+func (f *fakeRegistry) count(key string) int {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	return f.requests[key]
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	w.Header().Set("Content-Type", registryContentType)
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	f.requests[r.Method+" "+parts[0]]++
+
+	var payload schemaPayload
+	if r.Method == http.MethodPost {
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			writeRegistryError(w, http.StatusUnprocessableEntity, 42201, "invalid schema")
+			return
+		}
+	}
+
+	switch {
+	case r.Method == http.MethodGet && len(parts) == 3 && parts[0] == "schemas" && parts[1] == "ids":
+		id, _ := strconv.Atoi(parts[2])
+		if id < 1 || id > len(f.schemas) {
+			writeRegistryError(w, http.StatusNotFound, 40403, "Schema not found")
+			return
+		}
+		_ = json.NewEncoder(w).Encode(f.schemas[id-1])
+	case r.Method == http.MethodPost && len(parts) == 3 && parts[0] == "subjects" && parts[2] == "versions":
+		if f.reject != nil && len(f.subjects[parts[1]]) > 0 && f.reject(payload) {
+			writeRegistryError(w, http.StatusConflict, 409, "Schema being registered is incompatible with an earlier schema")
+			return
+		}
+		id := f.find(payload)
+		if id == 0 {
+			f.schemas = append(f.schemas, payload)
+			id = len(f.schemas)
+		}
+		f.subjects[parts[1]] = append(f.subjects[parts[1]], id)
+		_ = json.NewEncoder(w).Encode(map[string]int{"id": id})
+	case r.Method == http.MethodPost && len(parts) == 2 && parts[0] == "subjects":
+		for _, id := range f.subjects[parts[1]] {
+			if f.schemas[id-1].Schema == payload.Schema && f.schemas[id-1].SchemaType == payload.SchemaType {
+				_ = json.NewEncoder(w).Encode(map[string]int{"id": id})
+				return
+			}
+		}
+		writeRegistryError(w, http.StatusNotFound, 40403, "Schema not found")
+	case r.Method == http.MethodPost && parts[0] == "compatibility":
+		compatible := f.reject == nil || !f.reject(payload)
+		_ = json.NewEncoder(w).Encode(map[string]bool{"is_compatible": compatible})
+	default:
+		writeRegistryError(w, http.StatusNotFound, 404, "Not found")
+	}
+}
+
+func (f *fakeRegistry) find(payload schemaPayload) int {
+	for i, s := range f.schemas {
+		if s.Schema == payload.Schema && s.SchemaType == payload.SchemaType {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func writeRegistryError(w http.ResponseWriter, status, code int, message string) {
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{"error_code": code, "message": message})
+}
+
+func TestRegistry(t *testing.T) {
+	ctx := context.Background()
+	f, r := newFakeRegistry(t)
+	schema := Schema{Type: JSON, Schema: `{"type":"object"}`}
+
+	_, err := r.Lookup(ctx, "orders-value", schema)
+	var registryErr *RegistryError
+	require.True(t, errors.As(err, &registryErr), "Lookup() of an unregistered schema error = %v", err)
+	require.Equal(t, http.StatusNotFound, registryErr.StatusCode)
+	require.Equal(t, 40403, registryErr.ErrorCode)
+
+	id, err := r.Register(ctx, "orders-value", schema)
+	require.NoError(t, err)
+	require.Equal(t, 1, id)
+
+	id, err = r.Register(ctx, "orders-value", schema)
+	require.NoError(t, err)
+	require.Equal(t, 1, id)
+	require.Equal(t, 2, f.count("POST subjects"), "registered schema should be cached after the failed lookup and the registration")
+
+	got, err := r.GetByID(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, schema, got)
+	require.Equal(t, 0, f.count("GET schemas"), "registered schema should be cached")
+
+	compatible, err := r.TestCompatibility(ctx, "orders-value", Schema{Type: JSON, Schema: `{"type":"object","properties":{}}`})
+	require.NoError(t, err)
+	require.True(t, compatible)
+}
+
+func TestRegistry_GetByID(t *testing.T) {
+	ctx := context.Background()
+	f, r := newFakeRegistry(t)
+	f.schemas = []schemaPayload{{Schema: `"string"`}}
+
+	for i := 0; i < 2; i++ {
+		got, err := r.GetByID(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, Schema{Type: Avro, Schema: `"string"`}, got, "schema type should default to AVRO")
+	}
+	require.Equal(t, 1, f.count("GET schemas"), "schema should be cached")
+
+	_, err := r.GetByID(ctx, 2)
+	require.Error(t, err)
+}
+
+func TestNewRegistry(t *testing.T) {
+	_, err := NewRegistry(NewRegistryConfig())
+	require.Error(t, err, "URL is required")
+}
@@ -0,0 +1,172 @@
+// Package serde provides schema registry aware serializers and deserializers of Kafka messages.
+// Messages are framed with the Confluent wire format: a magic byte, the 4 bytes schema ID and the payload.
+package serde
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/consumer"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
+)
+
+var (
+	// ErrInvalidWireFormat : Error data is not framed with the Confluent wire format
+	ErrInvalidWireFormat = errors.New("Serde:WireFormat:Invalid")
+
+	// ErrSchemaTypeMismatch : Error the schema of the data is not of the type of the deserializer
+	ErrSchemaTypeMismatch = errors.New("Serde:SchemaType:Mismatch")
+
+	// ErrUnsupportedValue : Error the value cannot be handled by the serializer
+	ErrUnsupportedValue = errors.New("Serde:Value:Unsupported")
+)
+
+const (
+	magicByte    byte = 0
+	headerLength      = 5 // magic byte and schema ID
+)
+
+// Serializer serializes values of a topic
+type Serializer interface {
+	// Serialize serializes the value in the Confluent wire format
+	Serialize(ctx context.Context, topic string, value interface{}) ([]byte, error)
+}
+
+// Deserializer deserializes values of a topic
+type Deserializer interface {
+	// Deserialize deserializes the data in the Confluent wire format into v, which must be a pointer
+	Deserialize(ctx context.Context, topic string, data []byte, v interface{}) error
+}
+
+// Serde is both Serializer and Deserializer
+type Serde interface {
+	Serializer
+	Deserializer
+}
+
+// SubjectNameStrategy returns the subject of the schema of a topic's keys or values
+type SubjectNameStrategy func(topic string, isKey bool) string
+
+// TopicNameStrategy is the default SubjectNameStrategy: <topic>-key or <topic>-value
+func TopicNameStrategy(topic string, isKey bool) string {
+	if isKey {
+		return topic + "-key"
+	}
+	return topic + "-value"
+}
+
+// Config is the configuration of a serde
+type Config struct {
+	// AutoRegister: whether the schema is registered on first use, otherwise it must already be registered under the subject
+	// Default: true
+	AutoRegister bool
+
+	// IsKey: whether the serde handles message keys instead of values
+	// Default: false
+	IsKey bool
+
+	// SubjectNameStrategy: subject of the schema of a topic
+	// Default: TopicNameStrategy
+	SubjectNameStrategy SubjectNameStrategy
+
+	// References: references of the schema to schemas registered under other subjects
+	References []Reference
+}
+
+// NewConfig returns default serde configuration
+func NewConfig() *Config {
+	return &Config{
+		AutoRegister:        true,
+		SubjectNameStrategy: TopicNameStrategy,
+	}
+}
+
+// schemaSerde holds what all serdes share: the writer schema, its registration and the wire format
+type schemaSerde struct {
+	registry Registry
+	config   *Config
+	schema   Schema
+}
+
+func newSchemaSerde(registry Registry, config *Config, schemaType SchemaType, schema string) (schemaSerde, error) {
+	if registry == nil || schema == "" {
+		return schemaSerde{}, fmt.Errorf("registry and schema are required")
+	}
+	if config == nil {
+		config = NewConfig()
+	}
+	if config.SubjectNameStrategy == nil {
+		config.SubjectNameStrategy = TopicNameStrategy
+	}
+	return schemaSerde{
+		registry: registry,
+		config:   config,
+		schema:   Schema{Type: schemaType, Schema: schema, References: config.References},
+	}, nil
+}
+
+// schemaID returns the ID of the schema for the topic, registering it if AutoRegister is set
+func (s *schemaSerde) schemaID(ctx context.Context, topic string) (int, error) {
+	subject := s.config.SubjectNameStrategy(topic, s.config.IsKey)
+	if s.config.AutoRegister {
+		return s.registry.Register(ctx, subject, s.schema)
+	}
+	return s.registry.Lookup(ctx, subject, s.schema)
+}
+
+// writerSchema returns the schema the data was written with and the data without the wire format header
+func (s *schemaSerde) writerSchema(ctx context.Context, data []byte) (int, Schema, []byte, error) {
+	id, payload, err := decodeWireFormat(data)
+	if err != nil {
+		return 0, Schema{}, nil, err
+	}
+	schema, err := s.registry.GetByID(ctx, id)
+	if err != nil {
+		return 0, Schema{}, nil, fmt.Errorf("failed to get schema %d: %w", id, err)
+	}
+	if schema.Type != s.schema.Type {
+		return 0, Schema{}, nil, fmt.Errorf("%w: schema %d is %s, expected %s", ErrSchemaTypeMismatch, id, schema.Type, s.schema.Type)
+	}
+	return id, schema, payload, nil
+}
+
+// encodeWireFormat frames the payload with the magic byte and the schema ID
+func encodeWireFormat(id int, payload []byte) []byte {
+	data := make([]byte, headerLength, headerLength+len(payload))
+	data[0] = magicByte
+	binary.BigEndian.PutUint32(data[1:headerLength], uint32(id))
+	return append(data, payload...)
+}
+
+// decodeWireFormat returns the schema ID and the payload of framed data
+func decodeWireFormat(data []byte) (int, []byte, error) {
+	if len(data) < headerLength || data[0] != magicByte {
+		return 0, nil, ErrInvalidWireFormat
+	}
+	return int(binary.BigEndian.Uint32(data[1:headerLength])), data[headerLength:], nil
+}
+
+// NewMessage returns a producer message with the value serialized for the topic
+func NewMessage(ctx context.Context, serializer Serializer, topic string, key []byte, value interface{}) (*producer.Message, error) {
+	data, err := serializer.Serialize(ctx, topic, value)
+	if err != nil {
+		return nil, err
+	}
+	return &producer.Message{Topic: topic, Key: key, Value: data}, nil
+}
+
+// ProduceValue serializes the value for the topic and produces it
+func ProduceValue(ctx context.Context, p producer.Producer, transaction string, serializer Serializer, topic string, key []byte, value interface{}) error {
+	message, err := NewMessage(ctx, serializer, topic, key, value)
+	if err != nil {
+		return err
+	}
+	return p.Produce(ctx, transaction, message)
+}
+
+// Decode deserializes the value of a consumed message into v, which must be a pointer
+func Decode(ctx context.Context, deserializer Deserializer, message consumer.Message, v interface{}) error {
+	return deserializer.Deserialize(ctx, message.Topic, message.Message, v)
+}
@@ -0,0 +1,209 @@
+package serde
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/consumer"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer"
+	mock_producer "gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/messaging/producer/mocks"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type order struct {
+	ID     string  `json:"id"`
+	Amount float64 `json:"amount"`
+}
+
+const (
+	orderJSONSchema = `{"type":"object","properties":{"id":{"type":"string"},"amount":{"type":"number"}}}`
+	orderAvroSchema = `{"type":"record","name":"Order","fields":[{"name":"id","type":"string"},{"name":"amount","type":"double"}]}`
+	// orderAvroSchemaV2 adds a field with a default, which is backward compatible
+	orderAvroSchemaV2 = `{"type":"record","name":"Order","fields":[{"name":"id","type":"string"},{"name":"amount","type":"double"},{"name":"currency","type":"string","default":"USD"}]}`
+)
+
+func TestWireFormat(t *testing.T) {
+	data := encodeWireFormat(258, []byte("payload"))
+	require.Equal(t, []byte{0, 0, 0, 1, 2, 'p', 'a', 'y', 'l', 'o', 'a', 'd'}, data)
+
+	id, payload, err := decodeWireFormat(data)
+	require.NoError(t, err)
+	require.Equal(t, 258, id)
+	require.Equal(t, []byte("payload"), payload)
+
+	for _, invalid := range [][]byte{nil, {0, 0, 0}, {1, 0, 0, 0, 1, 'x'}} {
+		_, _, err = decodeWireFormat(invalid)
+		require.True(t, errors.Is(err, ErrInvalidWireFormat))
+	}
+}
+
+func TestJSONSerde(t *testing.T) {
+	ctx := context.Background()
+	f, r := newFakeRegistry(t)
+	s, err := NewJSONSerde(r, orderJSONSchema, nil)
+	require.NoError(t, err)
+
+	data, err := s.Serialize(ctx, "orders", order{ID: "1", Amount: 9.5})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 1}, data[:headerLength])
+	require.Equal(t, `{"id":"1","amount":9.5}`, string(data[headerLength:]))
+	require.Equal(t, []int{1}, f.subjects["orders-value"])
+
+	var got order
+	require.NoError(t, s.Deserialize(ctx, "orders", data, &got))
+	require.Equal(t, order{ID: "1", Amount: 9.5}, got)
+
+	_, err = s.Serialize(ctx, "orders", func() {})
+	require.Error(t, err, "value not supported by encoding/json")
+}
+
+func TestSerde_config(t *testing.T) {
+	ctx := context.Background()
+	f, r := newFakeRegistry(t)
+
+	config := NewConfig()
+	config.AutoRegister = false
+	s, err := NewJSONSerde(r, orderJSONSchema, config)
+	require.NoError(t, err)
+	_, err = s.Serialize(ctx, "orders", order{})
+	var registryErr *RegistryError
+	require.True(t, errors.As(err, &registryErr), "unregistered schema without AutoRegister error = %v", err)
+
+	config = NewConfig()
+	config.IsKey = true
+	config.SubjectNameStrategy = func(topic string, isKey bool) string {
+		return "com.example." + TopicNameStrategy(topic, isKey)
+	}
+	s, err = NewJSONSerde(r, orderJSONSchema, config)
+	require.NoError(t, err)
+	_, err = s.Serialize(ctx, "orders", order{})
+	require.NoError(t, err)
+	require.Contains(t, f.subjects, "com.example.orders-key")
+
+	f.reject = func(p schemaPayload) bool { return strings.Contains(p.Schema, "required") }
+	s, err = NewJSONSerde(r, `{"type":"object","required":["id"]}`, config)
+	require.NoError(t, err)
+	_, err = s.Serialize(ctx, "orders", order{})
+	require.True(t, errors.As(err, &registryErr), "incompatible schema error = %v", err)
+	require.Equal(t, 409, registryErr.StatusCode)
+
+	_, err = NewJSONSerde(nil, orderJSONSchema, nil)
+	require.Error(t, err, "registry is required")
+}
+
+func TestDeserialize_schemaTypeMismatch(t *testing.T) {
+	ctx := context.Background()
+	_, r := newFakeRegistry(t)
+	j, err := NewJSONSerde(r, orderJSONSchema, nil)
+	require.NoError(t, err)
+	a, err := NewAvroSerde(r, orderAvroSchema, nil)
+	require.NoError(t, err)
+
+	data, err := j.Serialize(ctx, "orders", order{ID: "1"})
+	require.NoError(t, err)
+	var got order
+	err = a.Deserialize(ctx, "orders", data, &got)
+	require.True(t, errors.Is(err, ErrSchemaTypeMismatch), "Deserialize() error = %v", err)
+
+	err = a.Deserialize(ctx, "orders", []byte(`{"id":"1"}`), &got)
+	require.True(t, errors.Is(err, ErrInvalidWireFormat), "Deserialize() error = %v", err)
+}
+
+func TestAvroSerde(t *testing.T) {
+	ctx := context.Background()
+	_, r := newFakeRegistry(t)
+	s, err := NewAvroSerde(r, orderAvroSchema, nil)
+	require.NoError(t, err)
+
+	data, err := s.Serialize(ctx, "orders", order{ID: "1", Amount: 9.5})
+	require.NoError(t, err)
+	// "1" is a string of length 1 (zigzag 2), 9.5 a little-endian double
+	require.Equal(t, []byte{0, 0, 0, 0, 1, 2, '1', 0, 0, 0, 0, 0, 0, 0x23, 0x40}, data)
+
+	var got order
+	require.NoError(t, s.Deserialize(ctx, "orders", data, &got))
+	require.Equal(t, order{ID: "1", Amount: 9.5}, got)
+
+	// data written with a newer schema is decoded with it
+	v2, err := NewAvroSerde(r, orderAvroSchemaV2, nil)
+	require.NoError(t, err)
+	data, err = v2.Serialize(ctx, "orders", map[string]interface{}{"id": "2", "amount": 1, "currency": "EUR"})
+	require.NoError(t, err)
+	got = order{}
+	require.NoError(t, s.Deserialize(ctx, "orders", data, &got))
+	require.Equal(t, order{ID: "2", Amount: 1}, got)
+
+	_, err = s.Serialize(ctx, "orders", map[string]interface{}{"id": 1})
+	require.Error(t, err, "value not matching the schema")
+
+	_, err = NewAvroSerde(r, `{"type":"unknown"}`, nil)
+	require.Error(t, err, "invalid schema")
+}
+
+func TestProtobufSerde(t *testing.T) {
+	ctx := context.Background()
+	_, r := newFakeRegistry(t)
+	s, err := NewProtobufSerde(r, `syntax = "proto3"; package google.protobuf; message Timestamp { int64 seconds = 1; int32 nanos = 2; }`, nil)
+	require.NoError(t, err)
+
+	data, err := s.Serialize(ctx, "events", &timestamppb.Timestamp{Seconds: 42})
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 1, 0}, data[:headerLength+1], "first message of the file is encoded as a single 0")
+
+	var got timestamppb.Timestamp
+	require.NoError(t, s.Deserialize(ctx, "events", data, &got))
+	require.Equal(t, int64(42), got.Seconds)
+
+	_, err = s.Serialize(ctx, "events", order{})
+	require.True(t, errors.Is(err, ErrUnsupportedValue))
+	require.True(t, errors.Is(s.Deserialize(ctx, "events", data, &order{}), ErrUnsupportedValue))
+}
+
+func TestMessageIndexes(t *testing.T) {
+	// StringValue is not the first message of wrappers.proto
+	indexes := messageIndexes((&wrapperspb.StringValue{}).ProtoReflect().Descriptor())
+	require.Len(t, indexes, 1)
+	require.NotEqual(t, 0, indexes[0])
+
+	for _, tt := range [][]int{{0}, {3}, {1, 0, 2}} {
+		encoded := encodeMessageIndexes(tt)
+		decoded, rest, err := decodeMessageIndexes(append(encoded, 'x'))
+		require.NoError(t, err)
+		require.Equal(t, tt, decoded)
+		require.Equal(t, []byte("x"), rest)
+	}
+	require.Equal(t, []byte{2, 6}, encodeMessageIndexes([]int{3}), "count and index are zigzag varints")
+
+	_, _, err := decodeMessageIndexes(nil)
+	require.True(t, errors.Is(err, ErrInvalidWireFormat))
+}
+
+func TestProduceValueAndDecode(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	_, r := newFakeRegistry(t)
+	s, err := NewJSONSerde(r, orderJSONSchema, nil)
+	require.NoError(t, err)
+
+	var produced *producer.Message
+	p := mock_producer.NewMockProducer(ctrl)
+	p.EXPECT().Produce(gomock.Any(), "tx", gomock.Any()).
+		DoAndReturn(func(ctx context.Context, transaction string, messages ...*producer.Message) error {
+			produced = messages[0]
+			return nil
+		})
+	require.NoError(t, ProduceValue(ctx, p, "tx", s, "orders", []byte("key"), order{ID: "1", Amount: 2}))
+	require.Equal(t, "orders", produced.Topic)
+	require.Equal(t, []byte("key"), produced.Key)
+
+	var got order
+	require.NoError(t, Decode(ctx, s, consumer.Message{Topic: produced.Topic, Message: produced.Value}, &got))
+	require.Equal(t, order{ID: "1", Amount: 2}, got)
+}
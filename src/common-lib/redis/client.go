@@ -118,6 +118,10 @@ type Client interface {
 	// Keys: receive all keys according to specified pattern
 	Keys(pattern string) ([]string, error)
 	TTL(key string) (time.Duration, error)
+	// SetNX: Set the value of a key with expiry, only if the key does not exist
+	SetNX(key string, value interface{}, duration time.Duration) (bool, error)
+	// Eval: Execute a Lua script server side
+	Eval(script string, keys []string, args ...interface{}) (interface{}, error)
 }
 
 // Pipeliner : Redis Client's Pipeliner interface
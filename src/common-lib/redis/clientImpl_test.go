@@ -594,3 +594,44 @@ func TestTTL(t *testing.T) {
 		t.Error("invalid ttl")
 	}
 }
+
+func TestSetNX(t *testing.T) {
+	cl := clientImpl{
+		client: newTestRedis(t),
+		config: &Config{},
+	}
+
+	ok, err := cl.SetNX("lock", "owner-1", 10*time.Second)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = cl.SetNX("lock", "owner-2", 10*time.Second)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	value, err := cl.Get("lock")
+	assert.NoError(t, err)
+	assert.Equal(t, "owner-1", value)
+}
+
+func TestEval(t *testing.T) {
+	cl := clientImpl{
+		client: newTestRedis(t),
+		config: &Config{},
+	}
+	script := `if redis.call("get", KEYS[1]) == ARGV[1] then return redis.call("del", KEYS[1]) else return 0 end`
+
+	assert.NoError(t, cl.Set("lock", "owner-1"))
+
+	result, err := cl.Eval(script, []string{"lock"}, "owner-2")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), result)
+
+	result, err = cl.Eval(script, []string{"lock"}, "owner-1")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), result)
+
+	exists, err := cl.Exists("lock")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), exists)
+}
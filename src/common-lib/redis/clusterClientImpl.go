@@ -355,3 +355,40 @@ func (c *clusterClientImpl) TTL(key string) (time.Duration, error) {
 
 	return result, err
 }
+
+// SetNX: Set the value of a key with expiry, only if the key does not exist
+func (c *clusterClientImpl) SetNX(key string, value interface{}, duration time.Duration) (bool, error) {
+	var result bool
+
+	err := circuit.Do(c.config.CommandName, c.config.CircuitBreaker.Enabled, func() error {
+		var execErr error
+		result, execErr = c.clusterClient.SetNX(key, value, duration).Result()
+		return execErr
+	}, nil)
+
+	return result, err
+}
+
+// Eval: Execute a Lua script server side
+func (c *clusterClientImpl) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	var (
+		result interface{}
+		err    error
+	)
+
+	breakerErr := circuit.Do(c.config.CommandName, c.config.CircuitBreaker.Enabled, func() error {
+		var execErr error
+		result, execErr = c.clusterClient.Eval(script, keys, args...).Result()
+		if execErr == redis.Nil {
+			err = redis.Nil
+			execErr = nil
+		}
+		return execErr
+	}, nil)
+
+	if breakerErr != nil {
+		return result, breakerErr
+	}
+
+	return result, err
+}
@@ -112,6 +112,26 @@ func (mr *MockClientMockRecorder) Delete(key ...interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockClient)(nil).Delete), key...)
 }
 
+// Eval mocks base method.
+func (m *MockClient) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{script, keys}
+	for _, a := range args {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Eval", varargs...)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Eval indicates an expected call of Eval.
+func (mr *MockClientMockRecorder) Eval(script, keys interface{}, args ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{script, keys}, args...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Eval", reflect.TypeOf((*MockClient)(nil).Eval), varargs...)
+}
+
 // Exists mocks base method.
 func (m *MockClient) Exists(key string) (int64, error) {
 	m.ctrl.T.Helper()
@@ -370,6 +390,21 @@ func (mr *MockClientMockRecorder) Set(key, value interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockClient)(nil).Set), key, value)
 }
 
+// SetNX mocks base method.
+func (m *MockClient) SetNX(key string, value interface{}, duration time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetNX", key, value, duration)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetNX indicates an expected call of SetNX.
+func (mr *MockClientMockRecorder) SetNX(key, value, duration interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetNX", reflect.TypeOf((*MockClient)(nil).SetNX), key, value, duration)
+}
+
 // SetWithExpiry mocks base method.
 func (m *MockClient) SetWithExpiry(key string, value interface{}, duration time.Duration) error {
 	m.ctrl.T.Helper()
@@ -23,6 +23,7 @@ const (
 	errExtraBracketFound string = "Error in filter query | Location: %v | Error: Extra bracket found"
 	errAccept            string = "Error in filter query | Location: %v | Error: %v"
 	errGetCommandWrapper string = "Error in filter query | Location: %v | Error: %v"
+	errValidate          string = "Error in filter query | Location: %v | Error: %v"
 )
 
 var (
@@ -44,6 +45,7 @@ type Strategy struct {
 }
 
 // Parse : parses a given query by tokenizing and uses the supplied converter.
+// The filter is checked by the converter if it is a command.Validator.
 func (t Strategy) Parse(cnv command.Converter, query string, mapper func(string) string) (*filter.Filter, error) {
 	result := filter.New("")
 	brackets := stack.New()
@@ -79,5 +81,12 @@ func (t Strategy) Parse(cnv command.Converter, query string, mapper func(string)
 		return nil, fmt.Errorf(errExtraBracketFound, strings.Join(words, " "))
 	}
 
+	if v, ok := cnv.(command.Validator); ok {
+		if err := v.Validate(result); err != nil {
+			//nolint:goerr113
+			return nil, fmt.Errorf(errValidate, query, err)
+		}
+	}
+
 	return result, nil
 }
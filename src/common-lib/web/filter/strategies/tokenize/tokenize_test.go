@@ -1,6 +1,7 @@
 package tokenize
 
 import (
+	"errors"
 	"reflect"
 	"testing"
 
@@ -170,3 +171,24 @@ func TestTokenStrategy_Parse(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenStrategy_Parse_validator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	v := command.NewMockValidator(ctrl)
+	cnv := struct {
+		command.Converter
+		command.Validator
+	}{sql.GetConverter(), v}
+
+	v.EXPECT().Validate(gomock.Any()).Return(nil)
+	if _, err := GetStrategy().Parse(cnv, "partnerId = \"ABCD\"", mapper); err != nil {
+		t.Errorf("TokenStrategy.Parse() error = %v", err)
+	}
+
+	v.EXPECT().Validate(gomock.Any()).Return(errors.New("invalid"))
+	if _, err := GetStrategy().Parse(cnv, "partnerId = \"ABCD\"", mapper); err == nil {
+		t.Error("TokenStrategy.Parse() expected the validation error")
+	}
+}
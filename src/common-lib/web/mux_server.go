@@ -35,6 +35,10 @@ func (mcfg *muxConfig) SetupRoutes(routes []*RouteConfig) {
 		mcfg.router.HandleFunc(mcfg.serverCfg.URLPathPrefix+route.URLPathSuffix, handler.handleFunc)
 	}
 
+	if doc := mcfg.serverCfg.APIDoc; doc != nil {
+		doc.AddRoutes(mcfg.serverCfg.URLPathPrefix, routes)
+	}
+
 	staticFileDirectory := mcfg.serverCfg.StaticFileDirectory
 	if strings.TrimSpace(staticFileDirectory) != "" {
 		path := rest.FilePath(mcfg.serverCfg.URLPathPrefix)
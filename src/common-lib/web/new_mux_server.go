@@ -101,6 +101,9 @@ func (mcfg *newMuxConfig) ShutDown(ctx context.Context) error {
 
 // Start implementation of Server interface for newMuxConfig
 func (mcfg *newMuxConfig) Start(ctx context.Context) error {
+	if doc := mcfg.serverCfg.APIDoc; doc != nil {
+		doc.Serve(mcfg.router, mcfg.serverCfg.URLPathPrefix)
+	}
 	staticFileDirectory := mcfg.serverCfg.StaticFileDirectory
 	if strings.TrimSpace(staticFileDirectory) != "" {
 		path := rest.FilePath(mcfg.serverCfg.URLPathPrefix)
@@ -0,0 +1,492 @@
+package web
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	openAPIVersion = "3.0.3"
+	// DefaultAPIDocPath is the path the OpenAPI document is served at when APIDoc.Path is empty
+	DefaultAPIDocPath = "/openapi.json"
+	// bearerAuth is the name of the security scheme used for routes requiring permissions
+	bearerAuth   = "bearerAuth"
+	schemaRefFmt = "#/components/schemas/"
+)
+
+// Parameter locations supported by the OpenAPI document
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+var routeVarRegexp = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Operation describes a route method in the OpenAPI document
+type Operation struct {
+	ID          string
+	Summary     string
+	Description string
+	Tags        []string
+	Params      []Param
+	// Request is a sample value of the request body type, e.g. Ticket{}; nil means no body
+	Request interface{}
+	// Responses are sample values of the response body types by status code; a nil value documents a response without body.
+	// A 200 response without body is documented when it is empty.
+	Responses map[int]interface{}
+	// Permissions are the permission names required by the route, the same ones passed to middleware.Permission.AssertHandler
+	Permissions []string
+	// AllPermissions tells that all of the Permissions are required, otherwise any of them is enough
+	AllPermissions bool
+	Deprecated     bool
+}
+
+// Param describes a path, query or header parameter of an Operation
+type Param struct {
+	Name        string
+	In          string
+	Description string
+	Required    bool
+	// Type is a sample value of the parameter type; nil means string
+	Type interface{}
+}
+
+// APIInfo is the info section of the OpenAPI document
+type APIInfo struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// APIDoc collects the documented routes and generates an OpenAPI 3 document out of them
+type APIDoc struct {
+	// Path is the path the document is served at, relative to the URLPathPrefix of the server
+	Path    string
+	Info    APIInfo
+	Servers []string
+
+	mu     sync.RWMutex
+	routes map[string]map[string]*Operation
+}
+
+// NewAPIDoc creates an empty APIDoc served at DefaultAPIDocPath
+func NewAPIDoc(title, version string) *APIDoc {
+	return &APIDoc{
+		Path:   DefaultAPIDocPath,
+		Info:   APIInfo{Title: title, Version: version},
+		routes: make(map[string]map[string]*Operation),
+	}
+}
+
+// Add documents an operation of a route for the given HTTP method
+func (d *APIDoc) Add(route, method string, op *Operation) {
+	if op == nil {
+		return
+	}
+	route = routeVarRegexp.ReplaceAllString(route, "{$1}")
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.routes == nil {
+		d.routes = make(map[string]map[string]*Operation)
+	}
+	if d.routes[route] == nil {
+		d.routes[route] = make(map[string]*Operation)
+	}
+	d.routes[route][strings.ToLower(method)] = op
+}
+
+// AddRoutes documents the Operations of the route configs, prefix is the URLPathPrefix of the server
+func (d *APIDoc) AddRoutes(prefix string, routes []*RouteConfig) {
+	for _, route := range routes {
+		for method, op := range route.Operations {
+			d.Add(prefix+route.URLPathSuffix, method, op)
+		}
+	}
+}
+
+// AddFunc registers the handler on the router and documents the route; a route without methods is documented as GET
+func (d *APIDoc) AddFunc(router Router, route string, handleFunc HTTPHandlerFunc, op *Operation, methods ...string) {
+	router.AddFunc(route, handleFunc, methods...)
+	d.document(route, op, methods)
+}
+
+// AddHandle registers the handler on the router and documents the route; a route without methods is documented as GET
+func (d *APIDoc) AddHandle(router Router, route string, handler http.Handler, op *Operation, methods ...string) {
+	router.AddHandle(route, handler, methods...)
+	d.document(route, op, methods)
+}
+
+func (d *APIDoc) document(route string, op *Operation, methods []string) {
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet}
+	}
+	for _, method := range methods {
+		d.Add(route, method, op)
+	}
+}
+
+// Serve registers the document handler on the router at the document Path
+func (d *APIDoc) Serve(router Router, prefix string) {
+	router.AddHandle(prefix+d.path(), d, http.MethodGet)
+}
+
+func (d *APIDoc) path() string {
+	if d.Path == "" {
+		return DefaultAPIDocPath
+	}
+	return d.Path
+}
+
+// ServeHTTP writes the OpenAPI document as JSON
+func (d *APIDoc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, err := d.JSON()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+// JSON generates the OpenAPI document
+func (d *APIDoc) JSON() ([]byte, error) {
+	return json.Marshal(d.Document())
+}
+
+// Document generates the OpenAPI document as a value ready to be marshaled to JSON or YAML
+func (d *APIDoc) Document() interface{} {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	g := &schemaGenerator{schemas: make(map[string]*schema), names: make(map[reflect.Type]string)}
+	doc := document{
+		OpenAPI: openAPIVersion,
+		Info:    d.Info,
+		Paths:   make(map[string]map[string]*operation, len(d.routes)),
+	}
+	for _, url := range d.Servers {
+		doc.Servers = append(doc.Servers, server{URL: url})
+	}
+
+	// the schemas are generated in a stable order, so the names given on a collision don't change between the calls
+	routes := make([]string, 0, len(d.routes))
+	for route := range d.routes {
+		routes = append(routes, route)
+	}
+	sort.Strings(routes)
+
+	secured := false
+	for _, route := range routes {
+		ops := d.routes[route]
+		methods := make([]string, 0, len(ops))
+		for method := range ops {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+
+		item := make(map[string]*operation, len(ops))
+		for _, method := range methods {
+			op := ops[method]
+			item[method] = g.operation(route, op)
+			secured = secured || len(op.Permissions) > 0
+		}
+		doc.Paths[route] = item
+	}
+
+	doc.Components.Schemas = g.schemas
+	if secured {
+		doc.Components.SecuritySchemes = map[string]securityScheme{
+			bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+		}
+	}
+	return doc
+}
+
+type document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       APIInfo                          `json:"info"`
+	Servers    []server                         `json:"servers,omitempty"`
+	Paths      map[string]map[string]*operation `json:"paths"`
+	Components components                       `json:"components"`
+}
+
+type server struct {
+	URL string `json:"url"`
+}
+
+type components struct {
+	Schemas         map[string]*schema        `json:"schemas,omitempty"`
+	SecuritySchemes map[string]securityScheme `json:"securitySchemes,omitempty"`
+}
+
+type securityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type operation struct {
+	OperationID string                `json:"operationId,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []parameter           `json:"parameters,omitempty"`
+	RequestBody *requestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	Permissions *permissions          `json:"x-permissions,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+type permissions struct {
+	Names      []string `json:"names"`
+	Validation string   `json:"validation"`
+}
+
+type parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *schema `json:"schema"`
+}
+
+type requestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]mediaType `json:"content"`
+}
+
+type response struct {
+	Description string               `json:"description"`
+	Content     map[string]mediaType `json:"content,omitempty"`
+}
+
+type mediaType struct {
+	Schema *schema `json:"schema"`
+}
+
+type schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	AdditionalProperties *schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+}
+
+func jsonContent(s *schema) map[string]mediaType {
+	return map[string]mediaType{"application/json": {Schema: s}}
+}
+
+// schemaGenerator builds the schemas of Go types, named struct types are added to the components and referenced
+type schemaGenerator struct {
+	schemas map[string]*schema
+	names   map[reflect.Type]string
+}
+
+func (g *schemaGenerator) operation(route string, op *Operation) *operation {
+	o := &operation{
+		OperationID: op.ID,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Tags:        op.Tags,
+		Deprecated:  op.Deprecated,
+		Responses:   make(map[string]*response),
+	}
+
+	declared := make(map[string]bool)
+	for _, p := range op.Params {
+		in := p.In
+		if in == "" {
+			in = InQuery
+		}
+		declared[in+p.Name] = true
+		o.Parameters = append(o.Parameters, parameter{
+			Name:        p.Name,
+			In:          in,
+			Description: p.Description,
+			Required:    p.Required || in == InPath,
+			Schema:      g.paramSchema(p.Type),
+		})
+	}
+	for _, match := range routeVarRegexp.FindAllStringSubmatch(route, -1) {
+		if !declared[InPath+match[1]] {
+			o.Parameters = append(o.Parameters, parameter{Name: match[1], In: InPath, Required: true, Schema: &schema{Type: "string"}})
+		}
+	}
+
+	if op.Request != nil {
+		o.RequestBody = &requestBody{Required: true, Content: jsonContent(g.schemaOf(reflect.TypeOf(op.Request)))}
+	}
+
+	codes := make([]int, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		body := op.Responses[code]
+		res := &response{Description: http.StatusText(code)}
+		if body != nil {
+			res.Content = jsonContent(g.schemaOf(reflect.TypeOf(body)))
+		}
+		o.Responses[strconv.Itoa(code)] = res
+	}
+	if len(o.Responses) == 0 {
+		o.Responses[strconv.Itoa(http.StatusOK)] = &response{Description: http.StatusText(http.StatusOK)}
+	}
+
+	if len(op.Permissions) > 0 {
+		o.Security = []map[string][]string{{bearerAuth: op.Permissions}}
+		o.Permissions = &permissions{Names: op.Permissions, Validation: "anyOf"}
+		if op.AllPermissions {
+			o.Permissions.Validation = "allOf"
+		}
+	}
+	return o
+}
+
+func (g *schemaGenerator) paramSchema(sample interface{}) *schema {
+	if sample == nil {
+		return &schema{Type: "string"}
+	}
+	return g.schemaOf(reflect.TypeOf(sample))
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+func (g *schemaGenerator) schemaOf(t reflect.Type) *schema {
+	if t.Kind() == reflect.Ptr {
+		s := g.schemaOf(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	}
+
+	switch {
+	case t == timeType:
+		return &schema{Type: "string", Format: "date-time"}
+	case t == rawMessageType:
+		return &schema{}
+	case t.Implements(textMarshalerType) || reflect.PtrTo(t).Implements(textMarshalerType):
+		return &schema{Type: "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &schema{Type: "boolean"}
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64, reflect.Uintptr:
+		return &schema{Type: "integer", Format: "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &schema{Type: "integer", Format: "int32"}
+	case reflect.Float32:
+		return &schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &schema{Type: "string", Format: "byte"}
+		}
+		return &schema{Type: "array", Items: g.schemaOf(t.Elem())}
+	case reflect.Map:
+		return &schema{Type: "object", AdditionalProperties: g.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		return &schema{Ref: schemaRefFmt + g.component(t)}
+	}
+	return &schema{}
+}
+
+// component registers the schema of a named struct type and returns its name in the components
+func (g *schemaGenerator) component(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, taken := g.schemas[name]; taken {
+		name = path.Base(t.PkgPath()) + name
+	}
+	for i := 2; ; i++ {
+		if _, taken := g.schemas[name]; !taken {
+			break
+		}
+		name = t.Name() + strconv.Itoa(i)
+	}
+
+	g.names[t] = name
+	// reserve the name before generating the fields, so recursive types are referenced
+	g.schemas[name] = &schema{}
+	*g.schemas[name] = *g.structSchema(t)
+	return name
+}
+
+func (g *schemaGenerator) structSchema(t reflect.Type) *schema {
+	s := &schema{Type: "object", Properties: make(map[string]*schema)}
+	g.addFields(s, t)
+	sort.Strings(s.Required)
+	return s
+}
+
+// addFields adds the fields of the struct the way encoding/json marshals them, embedded structs are flattened
+func (g *schemaGenerator) addFields(s *schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts := tag, ""
+		if idx := strings.Index(tag, ","); idx >= 0 {
+			name, opts = tag[:idx], tag[idx+1:]
+		}
+
+		ft := f.Type
+		if f.Anonymous && name == "" {
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.addFields(s, ft)
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		fs := g.schemaOf(ft)
+		if strings.Contains(opts, "string") && fs.Type != "" && fs.Type != "object" && fs.Type != "array" {
+			fs = &schema{Type: "string"}
+		}
+		s.Properties[name] = fs
+		if !strings.Contains(opts, "omitempty") && ft.Kind() != reflect.Ptr {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type docBase struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type docTicket struct {
+	docBase
+	Title    string            `json:"title"`
+	Priority *int              `json:"priority,omitempty"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Parent   *docTicket        `json:"parent,omitempty"`
+	Children []docTicket       `json:"children"`
+	Secret   string            `json:"-"`
+	internal string
+}
+
+type docError struct {
+	Message string `json:"message"`
+}
+
+func docJSON(t *testing.T, doc *APIDoc) map[string]interface{} {
+	data, err := doc.JSON()
+	require.NoError(t, err)
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(data, &got))
+	return got
+}
+
+func docPath(t *testing.T, doc map[string]interface{}, keys ...string) interface{} {
+	var v interface{} = doc
+	for _, key := range keys {
+		m, ok := v.(map[string]interface{})
+		require.True(t, ok, "%v is not an object", keys)
+		v, ok = m[key]
+		require.True(t, ok, "%v not found", keys)
+	}
+	return v
+}
+
+func TestAPIDoc_Document(t *testing.T) {
+	doc := NewAPIDoc("tickets", "1.0")
+	doc.Add("/tickets/{id:[0-9]+}", http.MethodGet, &Operation{
+		Summary:     "get ticket",
+		Tags:        []string{"tickets"},
+		Params:      []Param{{Name: "expand", In: InQuery, Type: true}},
+		Responses:   map[int]interface{}{http.StatusOK: docTicket{}, http.StatusNotFound: docError{}},
+		Permissions: []string{"Ticket.Read", "Ticket.Manage"},
+	})
+	doc.Add("/tickets", http.MethodPost, &Operation{
+		Request:        &docTicket{},
+		Responses:      map[int]interface{}{http.StatusCreated: docTicket{}, http.StatusNoContent: nil},
+		Permissions:    []string{"Ticket.Manage"},
+		AllPermissions: true,
+	})
+	doc.Add("/health", http.MethodGet, &Operation{})
+	doc.Add("/ignored", http.MethodGet, nil)
+
+	got := docJSON(t, doc)
+	assert.Equal(t, "3.0.3", got["openapi"])
+	assert.Equal(t, map[string]interface{}{"title": "tickets", "version": "1.0"}, got["info"])
+	assert.Len(t, got["paths"], 3)
+
+	get := docPath(t, got, "paths", "/tickets/{id}", "get")
+	assert.Equal(t, "get ticket", docPath(t, get.(map[string]interface{}), "summary"))
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"name": "expand", "in": "query", "schema": map[string]interface{}{"type": "boolean"}},
+		map[string]interface{}{"name": "id", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string"}},
+	}, docPath(t, get.(map[string]interface{}), "parameters"))
+	assert.Equal(t, "#/components/schemas/docTicket",
+		docPath(t, get.(map[string]interface{}), "responses", "200", "content", "application/json", "schema", "$ref"))
+	assert.Equal(t, "Not Found", docPath(t, get.(map[string]interface{}), "responses", "404", "description"))
+	assert.Equal(t, []interface{}{map[string]interface{}{"bearerAuth": []interface{}{"Ticket.Read", "Ticket.Manage"}}},
+		docPath(t, get.(map[string]interface{}), "security"))
+	assert.Equal(t, "anyOf", docPath(t, get.(map[string]interface{}), "x-permissions", "validation"))
+
+	post := docPath(t, got, "paths", "/tickets", "post").(map[string]interface{})
+	assert.Equal(t, true, docPath(t, post, "requestBody", "required"))
+	assert.Equal(t, "allOf", docPath(t, post, "x-permissions", "validation"))
+	assert.Equal(t, map[string]interface{}{"description": "No Content"}, docPath(t, post, "responses", "204"))
+
+	health := docPath(t, got, "paths", "/health", "get").(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"200": map[string]interface{}{"description": "OK"}}, health["responses"])
+	assert.NotContains(t, health, "security")
+
+	assert.Equal(t, map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+		docPath(t, got, "components", "securitySchemes", "bearerAuth"))
+}
+
+func TestAPIDoc_schemas(t *testing.T) {
+	doc := NewAPIDoc("tickets", "1.0")
+	doc.Add("/tickets", http.MethodGet, &Operation{Responses: map[int]interface{}{http.StatusOK: []docTicket{}}})
+
+	got := docJSON(t, doc)
+	assert.Equal(t, map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"$ref": "#/components/schemas/docTicket"},
+	}, docPath(t, got, "paths", "/tickets", "get", "responses", "200", "content", "application/json", "schema"))
+
+	ticket := docPath(t, got, "components", "schemas", "docTicket")
+	assert.Equal(t, map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"id":        map[string]interface{}{"type": "string"},
+			"createdAt": map[string]interface{}{"type": "string", "format": "date-time"},
+			"title":     map[string]interface{}{"type": "string"},
+			"priority":  map[string]interface{}{"type": "integer", "format": "int64", "nullable": true},
+			"labels": map[string]interface{}{
+				"type":                 "object",
+				"additionalProperties": map[string]interface{}{"type": "string"},
+			},
+			"parent": map[string]interface{}{"$ref": "#/components/schemas/docTicket"},
+			"children": map[string]interface{}{
+				"type":  "array",
+				"items": map[string]interface{}{"$ref": "#/components/schemas/docTicket"},
+			},
+		},
+		"required": []interface{}{"children", "createdAt", "id", "title"},
+	}, ticket)
+}
+
+func TestAPIDoc_schemaNameCollision(t *testing.T) {
+	shared := docError{}
+	type docError struct {
+		Code int `json:"code"`
+	}
+	doc := NewAPIDoc("tickets", "1.0")
+	doc.Add("/b", http.MethodGet, &Operation{Responses: map[int]interface{}{http.StatusOK: shared}})
+	doc.Add("/a", http.MethodPost, &Operation{Responses: map[int]interface{}{http.StatusOK: shared}})
+	doc.Add("/a", http.MethodGet, &Operation{Responses: map[int]interface{}{http.StatusNotFound: shared, http.StatusOK: docError{}}})
+
+	// the routes, methods and status codes are taken in order, so the first one keeps the name whatever the map order is
+	for i := 0; i < 20; i++ {
+		got := docJSON(t, doc)
+		assert.Equal(t, "#/components/schemas/docError",
+			docPath(t, got, "paths", "/a", "get", "responses", "200", "content", "application/json", "schema", "$ref"))
+		assert.Equal(t, "#/components/schemas/webdocError",
+			docPath(t, got, "paths", "/b", "get", "responses", "200", "content", "application/json", "schema", "$ref"))
+	}
+}
+
+func TestAPIDoc_AddRoutes(t *testing.T) {
+	doc := NewAPIDoc("tickets", "1.0")
+	doc.AddRoutes("/api", []*RouteConfig{
+		{URLPathSuffix: "/tickets/{id}", Operations: map[string]*Operation{
+			http.MethodGet:    {Summary: "get"},
+			http.MethodDelete: {Summary: "delete"},
+		}},
+		{URLPathSuffix: "/undocumented"},
+	})
+
+	got := docJSON(t, doc)
+	assert.Len(t, got["paths"], 1)
+	assert.Equal(t, "get", docPath(t, got, "paths", "/api/tickets/{id}", "get", "summary"))
+	assert.Equal(t, "delete", docPath(t, got, "paths", "/api/tickets/{id}", "delete", "summary"))
+}
+
+func TestAPIDoc_Serve(t *testing.T) {
+	router := &gorillaRouter{mux.NewRouter()}
+	doc := NewAPIDoc("tickets", "1.0")
+	doc.Path = "/docs/openapi.json"
+	doc.AddFunc(router, "/api/tickets", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+	}, &Operation{Summary: "list"})
+	doc.Serve(router, "/api")
+
+	rw := httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/tickets", nil))
+	assert.Equal(t, http.StatusAccepted, rw.Code)
+
+	rw = httptest.NewRecorder()
+	router.ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/docs/openapi.json", nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+	assert.Equal(t, "application/json", rw.Header().Get("Content-Type"))
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &got))
+	assert.Equal(t, "list", docPath(t, got, "paths", "/api/tickets", "get", "summary"))
+}
+
+func TestMuxConfig_SetupRoutes_APIDoc(t *testing.T) {
+	doc := NewAPIDoc("tickets", "1.0")
+	server := ServerFactoryImpl{}.GetServer(&ServerConfig{URLPathPrefix: "/api", APIDoc: doc})
+	server.SetupRoutes([]*RouteConfig{
+		{URLPathSuffix: "/tickets", Operations: map[string]*Operation{http.MethodGet: {Summary: "list"}}},
+	})
+
+	rw := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api"+DefaultAPIDocPath, nil))
+	assert.Equal(t, http.StatusOK, rw.Code)
+
+	var got map[string]interface{}
+	require.NoError(t, json.Unmarshal(rw.Body.Bytes(), &got))
+	assert.Equal(t, "list", docPath(t, got, "paths", "/api/tickets", "get", "summary"))
+}
+
+type catchAllResource struct {
+	Get405
+	Post405
+	Put405
+	Delete405
+	Others405
+}
+
+func TestMuxConfig_SetupRoutes_APIDocCatchAll(t *testing.T) {
+	doc := NewAPIDoc("tickets", "1.0")
+	server := ServerFactoryImpl{}.GetServer(&ServerConfig{URLPathPrefix: "/api", APIDoc: doc})
+	server.SetupRoutes([]*RouteConfig{
+		{URLPathSuffix: "/tickets", Operations: map[string]*Operation{http.MethodGet: {Summary: "list"}}},
+	})
+	server.SetupRoutes([]*RouteConfig{{URLPathSuffix: "/{path:.*}", Res: catchAllResource{}}})
+
+	rw := httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api"+DefaultAPIDocPath, nil))
+	assert.Equal(t, http.StatusOK, rw.Code, "the document should not be shadowed by the catch-all route")
+
+	rw = httptest.NewRecorder()
+	server.GetRouter().ServeHTTP(rw, httptest.NewRequest(http.MethodGet, "/api/unknown", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rw.Code)
+
+	docRoutes := 0
+	require.NoError(t, server.GetRouter().Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		if path, _ := route.GetPathTemplate(); path == "/api"+DefaultAPIDocPath {
+			docRoutes++
+		}
+		return nil
+	}))
+	assert.Equal(t, 1, docRoutes, "the document route should be registered once")
+}
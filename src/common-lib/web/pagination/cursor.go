@@ -1,15 +1,61 @@
 package pagination
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
+)
+
+// Direction : the direction of the page a cursor points to.
+type Direction string
+
+const (
+	// Next : the cursor points to the page after its record.
+	Next Direction = "next"
+	// Prev : the cursor points to the page before its record.
+	Prev Direction = "prev"
+)
+
+const signatureSeparator = "."
+
+var (
+	// ErrInvalidCursor : the cursor is malformed or its signature does not match.
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrCursorExpired : the cursor is past its expiry.
+	ErrCursorExpired = errors.New("cursor expired")
+	// ErrCursorMismatch : the cursor was issued for a different sort order or filter.
+	ErrCursorMismatch = errors.New("cursor was issued for a different sort order or filter")
 )
 
 // Cursor - a generic cursor for all entities
+// Values holds the values of all the sort fields when there are several, OrderingKey the value of a single sort field.
+// Direction, SortBy, FilterHash and ExpiresAt are empty for the cursors without them, which are read as next page cursors.
 type Cursor struct {
-	UniqueID    string `json:"uniqueId"`
-	OrderingKey string `json:"orderingKey"`
+	UniqueID    string    `json:"uniqueId"`
+	OrderingKey string    `json:"orderingKey"`
+	Values      []string  `json:"values,omitempty"`
+	Direction   Direction `json:"direction,omitempty"`
+	SortBy      string    `json:"sortBy,omitempty"`
+	FilterHash  string    `json:"filterHash,omitempty"`
+	ExpiresAt   int64     `json:"expiresAt,omitempty"`
+}
+
+// Signer : signs the cursors with an HMAC key so they cannot be forged or tampered with,
+// and makes them expire after the TTL if it is positive.
+type Signer struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewSigner : returns a signer of the cursors with the key, a zero ttl makes the cursors never expire.
+func NewSigner(key []byte, ttl time.Duration) *Signer {
+	return &Signer{key: key, ttl: ttl, now: time.Now}
 }
 
 func (c *Cursor) Encode() (string, error) {
@@ -32,3 +78,51 @@ func (c *Cursor) Decode(encoded string) error {
 	}
 	return nil
 }
+
+// Encode : encodes the cursor with its expiry and signature.
+func (s *Signer) Encode(c Cursor) (string, error) {
+	if s.ttl > 0 {
+		c.ExpiresAt = s.now().Add(s.ttl).Unix()
+	}
+	encoded, err := c.Encode()
+	if err != nil {
+		return "", err
+	}
+	return encoded + signatureSeparator + s.sign(encoded), nil
+}
+
+// Decode : decodes the cursor, verifying its signature and expiry.
+func (s *Signer) Decode(signed string) (Cursor, error) {
+	var c Cursor
+	i := strings.LastIndex(signed, signatureSeparator)
+	if i < 0 || !hmac.Equal([]byte(signed[i+1:]), []byte(s.sign(signed[:i]))) {
+		return c, ErrInvalidCursor
+	}
+	if err := c.Decode(signed[:i]); err != nil {
+		return c, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if c.ExpiresAt != 0 && s.now().Unix() >= c.ExpiresAt {
+		return c, ErrCursorExpired
+	}
+	return c, nil
+}
+
+func (s *Signer) sign(encoded string) string {
+	mac := hmac.New(sha256.New, s.key)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// direction returns the direction of the cursor, Next if it has none.
+func (c *Cursor) direction() Direction {
+	if c.Direction == Prev {
+		return Prev
+	}
+	return Next
+}
+
+// hashFilter returns a short hash of the filter query carried by the cursors.
+func hashFilter(query string) string {
+	sum := sha256.Sum256([]byte(query))
+	return base64.RawURLEncoding.EncodeToString(sum[:12])
+}
@@ -1,6 +1,11 @@
 package pagination
 
-import "testing"
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
 
 var (
 	companyID   = "01d3b488-b123-4411-8cd4-7955d4b5a412"
@@ -89,9 +94,72 @@ func TestCursor_Decode(t *testing.T) {
 			if err := c.Decode(tt.args.encoded); (err != nil) != tt.wantErr {
 				t.Errorf("Cursor.Decode() error = %v, wantErr %v", err, tt.wantErr)
 			}
-			if *c != tt.want {
+			if !reflect.DeepEqual(*c, tt.want) {
 				t.Errorf("Cursor.Decode() got = %v, want %v", c, tt.want)
 			}
 		})
 	}
 }
+
+func TestSigner(t *testing.T) {
+	now := time.Date(2021, 5, 6, 14, 0, 0, 0, time.UTC)
+	signer := NewSigner([]byte("secret"), time.Minute)
+	signer.now = func() time.Time { return now }
+	signed, _ := signer.Encode(Cursor{UniqueID: companyID, OrderingKey: companyName, Direction: Prev})
+	unsigned, _ := testCursor.Encode()
+
+	tests := []struct {
+		name    string
+		encoded string
+		after   time.Duration
+		key     string
+		want    Cursor
+		wantErr error
+	}{
+		{
+			name:    "success",
+			encoded: signed,
+			key:     "secret",
+			want:    Cursor{UniqueID: companyID, OrderingKey: companyName, Direction: Prev, ExpiresAt: now.Add(time.Minute).Unix()},
+		},
+		{
+			name:    "expired",
+			encoded: signed,
+			after:   time.Minute,
+			key:     "secret",
+			wantErr: ErrCursorExpired,
+		},
+		{
+			name:    "wrong key",
+			encoded: signed,
+			key:     "other",
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "tampered",
+			encoded: "x" + signed[1:],
+			key:     "secret",
+			wantErr: ErrInvalidCursor,
+		},
+		{
+			name:    "unsigned",
+			encoded: unsigned,
+			key:     "secret",
+			wantErr: ErrInvalidCursor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewSigner([]byte(tt.key), time.Minute)
+			s.now = func() time.Time { return now.Add(tt.after) }
+			got, err := s.Decode(tt.encoded)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Signer.Decode() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Signer.Decode() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
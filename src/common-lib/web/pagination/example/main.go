@@ -158,6 +158,7 @@ func main() {
 	expectedCursor := pagination.Cursor{
 		UniqueID:    c2.ID,
 		OrderingKey: c2.CreatedDate,
+		SortBy:      sortBy,
 	}
 	expectedCursorEnc, _ := expectedCursor.Encode()
 	expectedNextPageLink := fmt.Sprintf(u, limit, expectedCursorEnc, sortBy)
@@ -14,9 +14,11 @@ import (
 
 const (
 	nextLink = `<%v?%v>; rel="next"`
-	// prevLink = `<%v?%v>; rel="prev"`
+	prevLink = `<%v?%v>; rel="prev"`
 
 	nextLinkHeader = "Link"
+	linkSeparator  = ", "
+	cursorParam    = "cursor"
 )
 
 // Pageable: An interface which needs to be implemnted by an entity which needs pagination
@@ -30,6 +32,39 @@ type Pageable interface {
 	SortingVal() *string
 }
 
+// Option : an option of the cursors, the same options should be passed to GetFilter and Paginate.
+type Option func(*options)
+
+type options struct {
+	signer     *Signer
+	filterHash string
+}
+
+// WithSigner : signs the cursors with the signer and rejects the cursors that are not signed by it or have expired.
+func WithSigner(s *Signer) Option {
+	return func(o *options) {
+		o.signer = s
+	}
+}
+
+// WithFilter : binds the cursors to the filter query of the request, a cursor issued for another filter is rejected.
+func WithFilter(query string) Option {
+	return func(o *options) {
+		o.filterHash = ""
+		if query != "" {
+			o.filterHash = hashFilter(query)
+		}
+	}
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
 // PaginationParams is the struct defining the params expected for pagination
 type PaginationParams struct {
 	Limit  int
@@ -52,15 +87,27 @@ func NewParams(limit int, cursor string, sortBy *string) *PaginationParams {
 }
 
 // GetFilter : Gets the filter for pagination. This filter can be converted to SQL using a SQL Converter
+// A cursor to the previous page fetches the records in the reverse order, Paginate restores the order.
 func GetFilter(paginate *PaginationParams, defaultSortByField string, uniqueIDField string, prefixFilter *filter.Filter, cnv command.Converter,
-	fieldMapper func(string) string) (*filter.Filter, error) {
+	fieldMapper func(string) string, opts ...Option) (*filter.Filter, error) {
 	//just return the prefix filter if paginate is nil
 	if paginate == nil {
 		return prefixFilter, nil
 	}
 
+	//decode the cursor first, as its direction decides the order
+	var cursor *Cursor
+	if paginate.Limit > 0 && len(paginate.Cursor) != 0 {
+		c, err := newOptions(opts).decode(paginate.Cursor, transformSortBy(paginate.sortBy))
+		if err != nil {
+			return nil, err
+		}
+		cursor = &c
+	}
+	reverse := cursor != nil && cursor.direction() == Prev
+
 	//make order by filter
-	of, sortBy, err := makeOrderByFilter(paginate, defaultSortByField, uniqueIDField, cnv, fieldMapper)
+	of, sortBy, err := makeOrderByFilter(paginate, defaultSortByField, uniqueIDField, reverse, cnv, fieldMapper)
 	if err != nil {
 		return prefixFilter, err
 	}
@@ -81,9 +128,9 @@ func GetFilter(paginate *PaginationParams, defaultSortByField string, uniqueIDFi
 	)
 
 	//make cursor filter
-	if len(paginate.Cursor) != 0 {
+	if cursor != nil {
 
-		cf, err := makeCursorFilter(paginate, sortBy, uniqueIDField, cnv, fieldMapper)
+		cf, err := makeCursorFilter(cursor, sortBy, uniqueIDField, cnv, fieldMapper)
 		if err != nil {
 			return nil, err
 		}
@@ -131,56 +178,161 @@ func GetFilter(paginate *PaginationParams, defaultSortByField string, uniqueIDFi
 	return prefixFilter, nil
 }
 
-// Paginate : Truncates the one extra record (limit + 1) returned by GetFilter. Also sets the next page link in the response header.
-func Paginate(w http.ResponseWriter, r http.Request, records Pageable, limit int, customSortBy *string) error {
+// Paginate : Truncates the one extra record (limit + 1) returned by GetFilter. Also sets the next and previous page links in the response header.
+// The records of a previous page are reversed back to the requested order.
+func Paginate(w http.ResponseWriter, r http.Request, records Pageable, limit int, customSortBy *string, opts ...Option) error {
 
 	if reflect.ValueOf(records).Kind() != reflect.Ptr {
 		return fmt.Errorf("paginate exepects records to be a pointer to the slice")
 	}
+	if limit <= 0 {
+		return nil
+	}
+
+	o := newOptions(opts)
+	sortBy := transformSortBy(customSortBy)
+
+	//the cursor of the request tells the direction of this page
+	var cursor *Cursor
+	if encoded := r.URL.Query().Get(cursorParam); encoded != "" {
+		c, err := o.decode(encoded, sortBy)
+		if err != nil {
+			return err
+		}
+		cursor = &c
+	}
+	prev := cursor != nil && cursor.direction() == Prev
 
 	//Getting more companies than the limit implies we have more pages to follow
 	rv := reflect.ValueOf(records).Elem()
 	length := rv.Len()
+	more := length > limit
 
-	if (limit > 0) && (length > limit) {
+	if more {
 		// truncate the (limit + 1)st record which was fetched to check if more pages left
 		rv.SetLen(length - 1)
+	}
+	if prev {
+		reverse(rv)
+	}
+	if rv.Len() == 0 {
+		return nil
+	}
 
-		//append link in response header
-		err := setNextLink(w, r, records, limit, transformSortBy(customSortBy))
+	//a previous page always has a next page, the one it was requested from
+	var links []string
+	if more || prev {
+		link, err := makeLink(r, records, sortBy, Next, o)
 		if err != nil {
 			return fmt.Errorf("failed to set next page link with error: %v", err)
 		}
+		links = append(links, link)
+	}
+	if (more && prev) || (cursor != nil && !prev) {
+		link, err := makeLink(r, records, sortBy, Prev, o)
+		if err != nil {
+			return fmt.Errorf("failed to set previous page link with error: %v", err)
+		}
+		links = append(links, link)
+	}
+
+	//append links in response header
+	if len(links) > 0 {
+		w.Header().Set(nextLinkHeader, strings.Join(links, linkSeparator))
 	}
 	return nil
 }
 
-// setNextLink : sets the next link for pagination in the response header
-func setNextLink(w http.ResponseWriter, r http.Request, records Pageable, limit int, customSortBy *string) error {
+// makeLink : makes the link to the page after the last record or before the first record
+func makeLink(r http.Request, records Pageable, sortBy *string, dir Direction, o *options) (string, error) {
 
-	//customSortBy can consist of both a field and order (asc, desc), separated by a space.
-	//we only need the sort field for the cursor.
-	var sortField *string
-	if customSortBy != nil {
-		sortField = &strings.Split(*customSortBy, " ")[0]
+	//sortBy can consist of both fields and orders (asc, desc).
+	//we only need the sort fields for the cursor.
+	var fields []string
+	if sortBy != nil {
+		fields = sortFields(*sortBy)
 	}
 
-	//get encoded cursor
-	cursor, err := makeCursor(records, sortField)
+	pattern := nextLink
+	if dir == Prev {
+		pattern = prevLink
+		records = firstRecord(records)
+	}
+
+	c, err := makeCursor(records, fields)
 	if err != nil {
-		return fmt.Errorf("failed to get cursor with error: %v", err)
+		return "", fmt.Errorf("failed to get cursor with error: %v", err)
+	}
+	if dir == Prev {
+		c.Direction = Prev
+	}
+	if sortBy != nil {
+		c.SortBy = *sortBy
 	}
+	c.FilterHash = o.filterHash
 
-	//get next link
-	nextLink, err := makeNextLink(w, r, limit, cursor, customSortBy)
+	//encode the cursor
+	var encoded string
+	if o.signer != nil {
+		encoded, err = o.signer.Encode(c)
+	} else {
+		encoded, err = c.Encode()
+	}
 	if err != nil {
-		return fmt.Errorf("failed to make next link: %v", err)
+		return "", fmt.Errorf("failed to encode cursor with error: %v", err.Error())
 	}
 
-	//set next link
-	w.Header().Set(nextLinkHeader, nextLink)
+	query, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return "", err
+	}
+	query.Set(cursorParam, encoded)
+	return fmt.Sprintf(pattern, r.URL.Path, query.Encode()), nil
+}
 
-	return nil
+// decode : decodes the cursor of a request and checks it was issued for the sort order and filter of the request.
+// An unsigned cursor without a sort order and filter hash is accepted as is.
+func (o *options) decode(encoded string, sortBy *string) (Cursor, error) {
+	var (
+		c   Cursor
+		err error
+	)
+	if o.signer != nil {
+		c, err = o.signer.Decode(encoded)
+	} else if err = c.Decode(encoded); err != nil {
+		err = fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if err != nil {
+		return c, fmt.Errorf("failed to decode the cursor in request with error: %w", err)
+	}
+
+	if o.signer == nil && c.SortBy == "" && c.FilterHash == "" {
+		return c, nil
+	}
+	requested := ""
+	if sortBy != nil {
+		requested = *sortBy
+	}
+	if c.SortBy != requested || c.FilterHash != o.filterHash {
+		return c, ErrCursorMismatch
+	}
+	return c, nil
+}
+
+// firstRecord : returns a pageable of the first record
+func firstRecord(records Pageable) Pageable {
+	rv := reflect.ValueOf(records).Elem()
+	first := reflect.New(rv.Type())
+	first.Elem().Set(rv.Slice(0, 1))
+	return first.Interface().(Pageable)
+}
+
+// reverse : reverses the slice in place
+func reverse(rv reflect.Value) {
+	swap := reflect.Swapper(rv.Interface())
+	for i, j := 0, rv.Len()-1; i < j; i, j = i+1, j-1 {
+		swap(i, j)
+	}
 }
 
 // infer the protocol from the request
@@ -188,19 +340,33 @@ func protocol(r http.Request) string {
 	return r.URL.Scheme
 }
 
-// makeCursor : returns an encoded cursor, given the unique id, last record, sort by
-func makeCursor(entity Pageable, customSortBy *string) (string, error) {
+// makeCursor : returns a cursor, given the unique id, last record, sort fields
+// The value of a single sort field is the ordering key, the values of several sort fields are all kept.
+func makeCursor(entity Pageable, fields []string) (Cursor, error) {
 
 	if entity == nil {
-		return "", fmt.Errorf("nil entity provided to makeCursor")
+		return Cursor{}, fmt.Errorf("nil entity provided to makeCursor")
 	}
 
-	var (
-		cursor  string
-		sortVal string
-	)
+	var sortVals []string
+
+	if len(fields) == 0 {
+
+		//use the default sorting key
+		sv := entity.SortingVal()
+		sortVals = []string{*sv}
 
-	if customSortBy != nil {
+	} else if sp, ok := entity.(SortablePageable); ok {
+
+		for _, field := range fields {
+			sortVal := ""
+			if sv := sp.SortingValOf(field); sv != nil {
+				sortVal = *sv
+			}
+			sortVals = append(sortVals, sortVal)
+		}
+
+	} else {
 
 		//get the last record
 		val := reflect.ValueOf(entity).Elem()
@@ -209,44 +375,37 @@ func makeCursor(entity Pageable, customSortBy *string) (string, error) {
 		//get a map from the struct to lookup a sortBy field dynamically
 		eb, err := json.Marshal(rec)
 		if err != nil {
-			return "", fmt.Errorf("failed to marshal entity with error: %v", err.Error())
+			return Cursor{}, fmt.Errorf("failed to marshal entity with error: %v", err.Error())
 		}
 
 		entityMap := make(map[string]interface{})
 		err = json.Unmarshal(eb, &entityMap)
 		if err != nil {
-			return "", fmt.Errorf("failed to unmarshal entity with error: %v", err.Error())
+			return Cursor{}, fmt.Errorf("failed to unmarshal entity with error: %v", err.Error())
 		}
 
-		// find the sort key in the struct map
-		sortVal = fmt.Sprintf("%v", entityMap[*customSortBy])
-
-	} else {
-
-		//use the default sorting key
-		sv := entity.SortingVal()
-		sortVal = *sv
+		// find the sort keys in the struct map
+		for _, field := range fields {
+			sortVals = append(sortVals, fmt.Sprintf("%v", entityMap[field]))
+		}
 	}
 
 	//make the next cursor
 	uv := entity.UniqueVal()
 
 	c := Cursor{
-		UniqueID:    *uv,
-		OrderingKey: sortVal,
+		UniqueID: *uv,
 	}
-
-	//encode the cursor
-	cursor, err := c.Encode()
-	if err != nil {
-		return "", fmt.Errorf("failed to encode cursor with error: %v", err.Error())
+	if len(sortVals) == 1 {
+		c.OrderingKey = sortVals[0]
+	} else {
+		c.Values = sortVals
 	}
-
-	return cursor, nil
+	return c, nil
 }
 
 // makes order by filter
-func makeOrderByFilter(paginate *PaginationParams, defaultSortByField string, uniqueIDField string, cnv command.Converter, fieldMapper func(string) string) (*filter.Filter, string, error) {
+func makeOrderByFilter(paginate *PaginationParams, defaultSortByField string, uniqueIDField string, reverse bool, cnv command.Converter, fieldMapper func(string) string) (*filter.Filter, string, error) {
 
 	//default to default sort by
 	sortBy := defaultSortByField
@@ -260,8 +419,32 @@ func makeOrderByFilter(paginate *PaginationParams, defaultSortByField string, un
 		sortBy = *transformSortBy(paginate.sortBy)
 	}
 
-	//insert the uniqueIDField into sortBy
-	sortBy = addUniqueFieldToSortBy(sortBy, uniqueIDField)
+	if hasSortOrderPerKey(sortBy) {
+
+		//insert the uniqueIDField into the sort keys, in the order of the last key
+		keys := parseSortKeys(sortBy)
+		if !sortByContainsUniqueField(strings.Join(sortFields(sortBy), sortFieldDelimiter), uniqueIDField) {
+			keys = append(keys, SortKey{Field: uniqueIDField, Desc: keys[len(keys)-1].Desc})
+		}
+
+		//a previous page is fetched in the reverse order
+		if reverse {
+			for i := range keys {
+				keys[i].Desc = !keys[i].Desc
+			}
+		}
+		sortBy = formatSortKeys(keys)
+
+	} else {
+
+		//insert the uniqueIDField into sortBy
+		sortBy = addUniqueFieldToSortBy(sortBy, uniqueIDField)
+
+		//a previous page is fetched in the reverse order
+		if reverse {
+			sortBy = reverseSortBy(sortBy)
+		}
+	}
 	of, err := cnv.GetOrderByFilter(sortBy, fieldMapper)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to create ORDER BY filter for sortBy: %v with error: %v", sortBy, err)
@@ -327,6 +510,15 @@ func addUniqueFieldToSortBy(sortBy, uniqueIDField string) string {
 	return sortByWithUniqueField
 }
 
+// reverseSortBy : swaps the sort order of sortBy
+func reverseSortBy(sortBy string) string {
+	sortOptions := strings.Split(sortBy, " ")
+	if getSortOrder(sortOptions) == "desc" {
+		return sortOptions[0] + " asc"
+	}
+	return sortOptions[0] + " desc"
+}
+
 func sortByContainsUniqueField(sortByFields string, uniqueField string) bool {
 	fields := strings.Split(sortByFields, ",")
 	for _, field := range fields {
@@ -339,23 +531,24 @@ func sortByContainsUniqueField(sortByFields string, uniqueField string) bool {
 }
 
 // makes cursor filter
-func makeCursorFilter(paginate *PaginationParams, sortBy string, uniqueIDField string, cnv command.Converter, fieldMapper func(string) string) (*filter.Filter, error) {
+func makeCursorFilter(c *Cursor, sortBy string, uniqueIDField string, cnv command.Converter, fieldMapper func(string) string) (*filter.Filter, error) {
 
-	if (paginate == nil) || ((paginate != nil) && (len(paginate.Cursor) == 0)) {
-		return nil, fmt.Errorf("empty cursor. cannot make a filter:%+v", paginate)
+	if c == nil {
+		return nil, fmt.Errorf("empty cursor. cannot make a filter")
 	}
 
-	c := &Cursor{}
-	err := c.Decode(paginate.Cursor)
+	//sortBy can consist of both fields and orders (asc, desc).
+	//we use the fields for the command property and the order for operator.
+	keys := parseSortKeys(sortBy)
+	vals, err := cursorValues(c, keys, uniqueIDField)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode the cursor in request with error: %v", err)
+		return nil, err
 	}
 
-	//sortBy can consist of both a field and order (asc, desc), separated by a space.
-	//we use the fields option for the command property and the order option for operator.
-	sortOptions := strings.Split(sortBy, " ")
-	sortFields := sortOptions[0]
-	sortOrder := getSortOrder(sortOptions)
+	//the sort keys in mixed orders can't be compared as a row value
+	if hasMixedSortOrders(keys) {
+		return makeKeysetFilter(keys, vals, cnv, fieldMapper)
+	}
 
 	//we're using the user specified sortBy in defining cursor.
 	//The pagination logic can go for a toss if the caller specifies a diff
@@ -363,17 +556,13 @@ func makeCursorFilter(paginate *PaginationParams, sortBy string, uniqueIDField s
 	//To curb this, the next link should also have the same sortBy as the first request.
 	//Filter company records greater than (companyName, companyID)
 
-	cmd := command.New(sortFields, getOrderOperator(sortOrder), "")
+	cmd := command.New(strings.Join(sortFields(sortBy), sortFieldDelimiter), getOrderOperator(keys[0].order()), "")
 	companyCursorFilter, err := cnv.DoForCommandWithValue(cmd, fieldMapper)
 	if err != nil {
 		return nil, fmt.Errorf("command: %+v | failed to create cursor filter with error:%v", cmd, err)
 	}
 
-	if sortFields == uniqueIDField {
-		return companyCursorFilter.CopyWithNewVals(c.OrderingKey), nil
-	}
-
-	return companyCursorFilter.CopyWithNewVals(c.OrderingKey, c.UniqueID), nil
+	return companyCursorFilter.CopyWithNewVals(vals...), nil
 
 }
 
@@ -413,13 +602,3 @@ func makeLimitFilter(paginate *PaginationParams, cnv command.Converter) (*filter
 	}
 	return lf, nil
 }
-
-// make the next link for pagination
-func makeNextLink(w http.ResponseWriter, r http.Request, limit int, cursor string, sortBy *string) (string, error) {
-	queryWithCursor, err := url.ParseQuery(r.URL.RawQuery)
-	if err != nil {
-		return "", err
-	}
-	queryWithCursor.Set("cursor", cursor)
-	return fmt.Sprintf(nextLink, r.URL.Path, queryWithCursor.Encode()), nil
-}
@@ -1,6 +1,7 @@
 package pagination
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +9,7 @@ import (
 	"reflect"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter"
@@ -24,10 +26,12 @@ var (
 )
 
 var fieldMap = map[string]string{
-	"name":    "name",
-	"id":      "id",
-	"email":   "email",
-	"address": "address",
+	"name":      "name",
+	"id":        "id",
+	"email":     "email",
+	"address":   "address",
+	"status":    "status",
+	"updatedAt": "updated_at",
 }
 
 var mapper = func(key string) string {
@@ -359,6 +363,7 @@ func TestPaginate(t *testing.T) {
 				c := Cursor{
 					UniqueID:    c2.ID,
 					OrderingKey: c2.Name,
+					SortBy:      "name",
 				}
 				encoded, _ := c.Encode()
 				myURL = fmt.Sprintf(myURL, url.QueryEscape(encoded))
@@ -388,6 +393,7 @@ func TestPaginate(t *testing.T) {
 				c := Cursor{
 					UniqueID:    c2.ID,
 					OrderingKey: c2.Name,
+					SortBy:      "name desc",
 				}
 				encoded, _ := c.Encode()
 				myURL = fmt.Sprintf(myURL, url.QueryEscape(encoded))
@@ -413,6 +419,272 @@ func TestPaginate(t *testing.T) {
 	}
 }
 
+func TestGetFilter_cursor(t *testing.T) {
+	signer := NewSigner([]byte("secret"), time.Hour)
+	sortByName := "name"
+	sortByNameDesc := "name desc"
+	signed := func(c Cursor) string {
+		encoded, _ := signer.Encode(c)
+		return encoded
+	}
+	tests := []struct {
+		name    string
+		cursor  string
+		sortBy  *string
+		opts    []Option
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "previous page",
+			cursor: signed(Cursor{OrderingKey: companyName, UniqueID: companyID, Direction: Prev, SortBy: sortByName}),
+			sortBy: &sortByName,
+			opts:   []Option{WithSigner(signer)},
+			want:   "(name,id) < (%v,%v) order by name desc, id desc limit %v",
+		},
+		{
+			name:   "previous page in descending order",
+			cursor: signed(Cursor{OrderingKey: companyName, UniqueID: companyID, Direction: Prev, SortBy: sortByNameDesc}),
+			sortBy: &sortByNameDesc,
+			opts:   []Option{WithSigner(signer)},
+			want:   "(name,id) > (%v,%v) order by name asc, id asc limit %v",
+		},
+		{
+			name:   "same filter",
+			cursor: signed(Cursor{OrderingKey: companyName, UniqueID: companyID, SortBy: sortByName, FilterHash: hashFilter("name : acme")}),
+			sortBy: &sortByName,
+			opts:   []Option{WithSigner(signer), WithFilter("name : acme")},
+			want:   "(name,id) > (%v,%v) order by name, id limit %v",
+		},
+		{
+			name:    "different sort order",
+			cursor:  signed(Cursor{OrderingKey: companyName, UniqueID: companyID, SortBy: sortByName}),
+			sortBy:  &sortByNameDesc,
+			opts:    []Option{WithSigner(signer)},
+			wantErr: ErrCursorMismatch,
+		},
+		{
+			name:    "different filter",
+			cursor:  signed(Cursor{OrderingKey: companyName, UniqueID: companyID, SortBy: sortByName, FilterHash: hashFilter("name : acme")}),
+			sortBy:  &sortByName,
+			opts:    []Option{WithSigner(signer), WithFilter("name : globex")},
+			wantErr: ErrCursorMismatch,
+		},
+		{
+			name: "unsigned",
+			cursor: func() string {
+				encoded, _ := testCursor.Encode()
+				return encoded
+			}(),
+			sortBy:  &sortByName,
+			opts:    []Option{WithSigner(signer)},
+			wantErr: ErrInvalidCursor,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetFilter(NewParams(limit, tt.cursor, tt.sortBy), sortBy, uniqueField, nil, sql.GetConverter(), mapper, tt.opts...)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("GetFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr != nil {
+				return
+			}
+			want := filter.New(tt.want, companyName, companyID, strconv.Itoa(limit+1))
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("GetFilter() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestPaginate_links(t *testing.T) {
+	signer := NewSigner([]byte("secret"), 0)
+	opts := []Option{WithSigner(signer)}
+	c1 := &Company{"1", "ABC Corp"}
+	c2 := &Company{"2", "DEF Corp"}
+	c3 := &Company{"3", "GHI Corp"}
+	cursor := func(c *Company, dir Direction) string {
+		cur := Cursor{UniqueID: c.ID, OrderingKey: c.Name, SortBy: "name"}
+		if dir == Prev {
+			cur.Direction = Prev
+		}
+		encoded, _ := signer.Encode(cur)
+		return url.QueryEscape(encoded)
+	}
+	link := func(c *Company, dir Direction) string {
+		return fmt.Sprintf(`</companies?cursor=%v&limit=2&sortBy=name>; rel="%v"`, cursor(c, dir), dir)
+	}
+	tests := []struct {
+		name     string
+		cursor   string
+		records  Companies
+		want     Companies
+		wantLink string
+	}{
+		{
+			name:     "next page with more",
+			cursor:   cursor(c1, Next),
+			records:  Companies{c1, c2, c3},
+			want:     Companies{c1, c2},
+			wantLink: link(c2, Next) + ", " + link(c1, Prev),
+		},
+		{
+			name:     "last page",
+			cursor:   cursor(c1, Next),
+			records:  Companies{c2, c3},
+			want:     Companies{c2, c3},
+			wantLink: link(c2, Prev),
+		},
+		{
+			name:     "previous page with more",
+			cursor:   cursor(c3, Prev),
+			records:  Companies{c3, c2, c1},
+			want:     Companies{c2, c3},
+			wantLink: link(c3, Next) + ", " + link(c2, Prev),
+		},
+		{
+			name:     "first page",
+			cursor:   cursor(c3, Prev),
+			records:  Companies{c2, c1},
+			want:     Companies{c1, c2},
+			wantLink: link(c2, Next),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, "https://somewhere.com/companies?limit=2&sortBy=name&cursor="+tt.cursor, nil)
+			sortBy := "name"
+			err := Paginate(w, *r, &tt.records, 2, &sortBy, opts...)
+			if err != nil {
+				t.Errorf("Paginate() error = %v", err)
+				return
+			}
+			assert.Equal(t, tt.want, tt.records)
+			assert.Equal(t, tt.wantLink, w.Header().Get(nextLinkHeader))
+		})
+	}
+}
+
+func TestPaginate_invalidCursor(t *testing.T) {
+	r, _ := http.NewRequest(http.MethodGet, "https://somewhere.com/companies?limit=2&cursor=abc", nil)
+	records := Companies{{"1", "ABC Corp"}}
+	err := Paginate(httptest.NewRecorder(), *r, &records, 2, nil, WithSigner(NewSigner([]byte("secret"), 0)))
+	assert.True(t, errors.Is(err, ErrInvalidCursor))
+}
+
+func TestGetFilter_sortKeys(t *testing.T) {
+	updatedAt := "2021-05-06T14:49:37Z"
+	cursor := func(dir Direction) string {
+		c := Cursor{UniqueID: companyID, Values: []string{"New", updatedAt}, Direction: dir}
+		encoded, _ := c.Encode()
+		return encoded
+	}
+	tests := []struct {
+		name       string
+		paginate   *PaginationParams
+		want       string
+		wantValues []interface{}
+		wantErr    bool
+	}{
+		{
+			name:       "mixed sort orders",
+			paginate:   NewSortParams(limit, cursor(Next), SortKey{Field: "status"}, SortKey{Field: "updatedAt", Desc: true}),
+			want:       "( ( (status) > (%v) ) OR ( (status) = (%v) AND (updated_at) < (%v) ) OR ( (status) = (%v) AND (updated_at) = (%v) AND (id) < (%v) ) ) order by status asc, updated_at desc, id desc limit %v",
+			wantValues: []interface{}{"New", "New", updatedAt, "New", updatedAt, companyID, strconv.Itoa(limit + 1)},
+		},
+		{
+			name:       "mixed sort orders previous page",
+			paginate:   NewSortParams(limit, cursor(Prev), SortKey{Field: "status"}, SortKey{Field: "updatedAt", Desc: true}, SortKey{Field: "id"}),
+			want:       "( ( (status) < (%v) ) OR ( (status) = (%v) AND (updated_at) > (%v) ) OR ( (status) = (%v) AND (updated_at) = (%v) AND (id) < (%v) ) ) order by status desc, updated_at asc, id desc limit %v",
+			wantValues: []interface{}{"New", "New", updatedAt, "New", updatedAt, companyID, strconv.Itoa(limit + 1)},
+		},
+		{
+			name:       "same sort order",
+			paginate:   NewSortParams(limit, cursor(Next), SortKey{Field: "status", Desc: true}, SortKey{Field: "updatedAt", Desc: true}),
+			want:       "(status,updated_at,id) < (%v,%v,%v) order by status desc, updated_at desc, id desc limit %v",
+			wantValues: []interface{}{"New", updatedAt, companyID, strconv.Itoa(limit + 1)},
+		},
+		{
+			name: "first page",
+			paginate: func() *PaginationParams {
+				sortBy := "status asc, updatedAt desc"
+				return NewParams(limit, "", &sortBy)
+			}(),
+			want:       "order by status asc, updated_at desc, id desc limit %v",
+			wantValues: []interface{}{strconv.Itoa(limit + 1)},
+		},
+		{
+			name:     "missing cursor value",
+			paginate: NewSortParams(limit, cursor(Next), SortKey{Field: "status"}, SortKey{Field: "name", Desc: true}, SortKey{Field: "updatedAt"}),
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetFilter(tt.paginate, sortBy, uniqueField, nil, sql.GetConverter(), mapper)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetFilter() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			assert.Equal(t, tt.want, got.GetQuery())
+			assert.Equal(t, tt.wantValues, got.GetValues())
+		})
+	}
+}
+
+func TestPaginate_sortKeys(t *testing.T) {
+	c1 := &Company{"1", "ABC Corp"}
+	c2 := &Company{"2", "DEF Corp"}
+	records := Companies{c1, c2}
+	sortBy := "name desc,id asc"
+	r, _ := http.NewRequest(http.MethodGet, "https://somewhere.com/companies?limit=1", nil)
+	w := httptest.NewRecorder()
+
+	err := Paginate(w, *r, &records, 1, &sortBy)
+	assert.Nil(t, err)
+
+	c := Cursor{UniqueID: c1.ID, Values: []string{c1.Name, c1.ID}, SortBy: sortBy}
+	encoded, _ := c.Encode()
+	want := fmt.Sprintf(`</companies?cursor=%v&limit=1>; rel="next"`, url.QueryEscape(encoded))
+	assert.Equal(t, want, w.Header().Get(nextLinkHeader))
+}
+
+func TestPaginationParams_SortKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		sortBy string
+		want   []SortKey
+	}{
+		{
+			name:   "sort order per key",
+			sortBy: "status asc, updatedAt desc,id",
+			want:   []SortKey{{Field: "status"}, {Field: "updatedAt", Desc: true}, {Field: "id"}},
+		},
+		{
+			name:   "sort order for all keys",
+			sortBy: "name, id desc",
+			want:   []SortKey{{Field: "name", Desc: true}, {Field: "id", Desc: true}},
+		},
+		{
+			name:   "no sort order",
+			sortBy: "name",
+			want:   []SortKey{{Field: "name"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortBy := tt.sortBy
+			assert.Equal(t, tt.want, NewParams(limit, "", &sortBy).SortKeys())
+		})
+	}
+}
+
 func TestTransformSortBy(t *testing.T) {
 	var testCases = []struct {
 		name     string
@@ -0,0 +1,199 @@
+package pagination
+
+import (
+	"fmt"
+	"strings"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter"
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/filter/command"
+)
+
+const (
+	sortFieldDelimiter = ","
+	sortOrderAsc       = "asc"
+	sortOrderDesc      = "desc"
+)
+
+// SortKey : a sort field and its sort order.
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// SortablePageable : a Pageable which returns the value of any sort field for the last record.
+// The sort values of a Pageable which does not implement it are looked up by their json field names.
+type SortablePageable interface {
+	Pageable
+
+	// returns the value of the sort field for the last record from the
+	// slice of records which is the pageable entity
+	SortingValOf(field string) *string
+}
+
+// NewSortParams will return pagination params sorted by the ordered list of sort keys,
+// each with its own sort order, e.g. status asc, updatedAt desc, id asc.
+func NewSortParams(limit int, cursor string, keys ...SortKey) *PaginationParams {
+	var sortBy *string
+	if len(keys) > 0 {
+		s := formatSortKeys(keys)
+		sortBy = &s
+	}
+	return NewParams(limit, cursor, sortBy)
+}
+
+// SortKeys : returns the sort keys of sortBy, nil if there is no sortBy
+func (pp *PaginationParams) SortKeys() []SortKey {
+	sortBy := transformSortBy(pp.sortBy)
+	if sortBy == nil {
+		return nil
+	}
+	return parseSortKeys(*sortBy)
+}
+
+func (k SortKey) order() string {
+	if k.Desc {
+		return sortOrderDesc
+	}
+	return sortOrderAsc
+}
+
+// parseSortKeys parses a sortBy of comma-delimited fields. Either each field is followed by its own
+// sort order, e.g. "status asc,updatedAt desc", or the sort order of the last field applies to all, e.g. "name,id desc".
+func parseSortKeys(sortBy string) []SortKey {
+	if !hasSortOrderPerKey(sortBy) {
+		sortOptions := strings.Split(sortBy, " ")
+		desc := getSortOrder(sortOptions) == sortOrderDesc
+		fields := strings.Split(sortOptions[0], sortFieldDelimiter)
+		keys := make([]SortKey, len(fields))
+		for i, field := range fields {
+			keys[i] = SortKey{Field: strings.TrimSpace(field), Desc: desc}
+		}
+		return keys
+	}
+
+	sortFields := strings.Split(sortBy, sortFieldDelimiter)
+	keys := make([]SortKey, 0, len(sortFields))
+	for _, sortField := range sortFields {
+		options := strings.Fields(sortField)
+		if len(options) == 0 {
+			continue
+		}
+		keys = append(keys, SortKey{
+			Field: options[0],
+			Desc:  len(options) > 1 && strings.ToLower(options[1]) == sortOrderDesc,
+		})
+	}
+	return keys
+}
+
+// formatSortKeys formats the sort keys with a sort order per field, as understood by the converters
+func formatSortKeys(keys []SortKey) string {
+	sortFields := make([]string, len(keys))
+	for i, key := range keys {
+		sortFields[i] = key.Field + " " + key.order()
+	}
+	return strings.Join(sortFields, sortFieldDelimiter)
+}
+
+// hasSortOrderPerKey tells if a field other than the last one of sortBy has a sort order
+func hasSortOrderPerKey(sortBy string) bool {
+	sortFields := strings.Split(sortBy, sortFieldDelimiter)
+	for _, sortField := range sortFields[:len(sortFields)-1] {
+		if len(strings.Fields(sortField)) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasMixedSortOrders tells if the sort keys are not all in the same sort order
+func hasMixedSortOrders(keys []SortKey) bool {
+	for _, key := range keys {
+		if key.Desc != keys[0].Desc {
+			return true
+		}
+	}
+	return false
+}
+
+// sortFields returns the fields of sortBy
+func sortFields(sortBy string) []string {
+	keys := parseSortKeys(sortBy)
+	fields := make([]string, len(keys))
+	for i, key := range keys {
+		fields[i] = key.Field
+	}
+	return fields
+}
+
+// cursorValues returns the cursor value of every sort key, the unique id field
+// added to the sort keys after the cursor values takes the unique id of the cursor
+func cursorValues(c *Cursor, keys []SortKey, uniqueIDField string) ([]interface{}, error) {
+	values := c.Values
+	if len(values) == 0 {
+		values = []string{c.OrderingKey}
+	}
+
+	vals := make([]interface{}, len(keys))
+	for i, key := range keys {
+		switch {
+		case i < len(values):
+			vals[i] = values[i]
+		case key.Field == uniqueIDField:
+			vals[i] = c.UniqueID
+		default:
+			return nil, fmt.Errorf("%w: no value for sort field %v", ErrCursorMismatch, key.Field)
+		}
+	}
+	return vals, nil
+}
+
+// makeKeysetFilter makes the keyset predicate of sort keys in mixed sort orders,
+// e.g. for status asc, updatedAt desc, id asc:
+// (status > ?) OR (status = ? AND updatedAt < ?) OR (status = ? AND updatedAt = ? AND id > ?)
+func makeKeysetFilter(keys []SortKey, vals []interface{}, cnv command.Converter, fieldMapper func(string) string) (*filter.Filter, error) {
+	terms := make([]*filter.Filter, 0, len(keys))
+	for i := range keys {
+		conditions := make([]*filter.Filter, 0, i+1)
+		for j, key := range keys[:i+1] {
+			operator := string(command.Eq)
+			if j == i {
+				operator = getOrderOperator(key.order())
+			}
+
+			cmd := command.New(key.Field, operator, "")
+			f, err := cnv.DoForCommandWithValue(cmd, fieldMapper)
+			if err != nil {
+				return nil, fmt.Errorf("command: %+v | failed to create cursor filter with error:%v", cmd, err)
+			}
+			conditions = append(conditions, f.CopyWithNewVals(vals[j]))
+		}
+
+		term, err := enclose(cnv.AND(conditions...), cnv)
+		if err != nil {
+			return nil, err
+		}
+		terms = append(terms, term)
+	}
+
+	or := cnv.OR(terms...)
+	if or == nil {
+		return nil, fmt.Errorf("converter does not support OR, cannot page sort keys in mixed sort orders: %+v", keys)
+	}
+	return enclose(or, cnv)
+}
+
+// enclose encloses the filter in parentheses
+func enclose(f *filter.Filter, cnv command.Converter) (*filter.Filter, error) {
+	lhs, err := cnv.DoForCommandWithoutValue(command.New("", string(command.LHS), ""), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cursor filter with error:%v", err)
+	}
+	rhs, err := cnv.DoForCommandWithoutValue(command.New("", string(command.RHS), ""), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cursor filter with error:%v", err)
+	}
+
+	enclosed := lhs.Add(f).Add(rhs)
+	return &enclosed, nil
+}
@@ -19,6 +19,15 @@ func storageKey(group, key string, timestamp int64) string {
 	return fmt.Sprintf("%s:%s:%d", group, key, timestamp)
 }
 
+// atomicStorageKey puts the group and the key into a hash tag, so the windows of one key share a Redis cluster slot
+func atomicStorageKey(group, key string, timestamp int64) string {
+	return fmt.Sprintf("{%s:%s}:%d", group, key, timestamp)
+}
+
+func stateKey(group, key, algorithm string) string {
+	return fmt.Sprintf("%s:%s:%s", group, key, algorithm)
+}
+
 func increment(storage Storage, key string, interval int64) (int64, error) {
 	c, err := storage.Incr(key)
 	if err != nil {
@@ -31,7 +40,11 @@ func increment(storage Storage, key string, interval int64) (int64, error) {
 }
 
 func touch(storage Storage, key string, interval int64) error {
-	res, err := storage.Expire(key, time.Second*time.Duration(interval*expireMultiplier))
+	return expire(storage, key, time.Second*time.Duration(interval*expireMultiplier))
+}
+
+func expire(storage Storage, key string, duration time.Duration) error {
+	res, err := storage.Expire(key, duration)
 	if err != nil {
 		return err
 	}
@@ -53,6 +66,26 @@ func count(storage Storage, key string) (int64, error) {
 	return c, nil
 }
 
+func state(storage Storage, key string) (string, error) {
+	v, err := storage.Get(key)
+	if err != nil {
+		if isNotFoundError(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	switch s := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	default:
+		return "", fmt.Errorf("invalid data type, value: %#v", s)
+	}
+}
+
 func toInt64(value interface{}) (int64, error) {
 	switch v := value.(type) {
 	case int64:
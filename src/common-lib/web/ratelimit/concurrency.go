@@ -0,0 +1,236 @@
+package ratelimit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// AIMDMode string key for the additive increase multiplicative decrease adaptive limit
+	AIMDMode = "aimd"
+	// GradientMode string key for the gradient adaptive limit
+	GradientMode = "gradient"
+
+	retryAfterDefault   int64   = 1 // seconds
+	maxLimitMultiplier  int64   = 10
+	backoffRatioDefault float64 = 0.9
+	toleranceDefault    float64 = 1.5
+)
+
+// ErrOverloaded is passed to the Responder when the in-flight limit of the group is reached
+var ErrOverloaded = errors.New("too many requests in progress, please try later")
+
+type (
+	// ConcurrencyConfig contains parameters for the concurrency limiter
+	ConcurrencyConfig struct {
+		// Enabled - limiter is enabled or not
+		Enabled bool `json:"enabled"`
+
+		// RetryAfterSec - Retry-After of the rejected requests in seconds, defaults to 1
+		RetryAfterSec int64 `json:"retryAfterSec"`
+
+		// Groups - in-flight limits configuration for target groups
+		Groups map[string]*ConcurrencyGroupConfig `json:"groups"`
+	}
+
+	// ConcurrencyGroupConfig structure for holding group params
+	ConcurrencyGroupConfig struct {
+		// Limit - max number of requests in progress, the initial limit in the adaptive mode
+		Limit int64 `json:"limit"`
+
+		// Adaptive - the limit follows the handler latency when it is set
+		Adaptive *AdaptiveConfig `json:"adaptive,omitempty"`
+	}
+
+	// AdaptiveConfig structure for holding adaptive limit params
+	AdaptiveConfig struct {
+		// Mode - aimd or gradient
+		Mode string `json:"mode"`
+
+		// MinLimit - the limit is never lowered below it, defaults to 1
+		MinLimit int64 `json:"minLimit"`
+
+		// MaxLimit - the limit is never raised above it, defaults to 10 * limit
+		MaxLimit int64 `json:"maxLimit"`
+
+		// LatencyThresholdMs - aimd: a request slower than it lowers the limit
+		LatencyThresholdMs int64 `json:"latencyThresholdMs"`
+
+		// BackoffRatio - aimd: the limit is multiplied by it on the slow requests, defaults to 0.9
+		BackoffRatio float64 `json:"backoffRatio"`
+
+		// Tolerance - gradient: how many times the short term latency may exceed the long term one, defaults to 1.5
+		Tolerance float64 `json:"tolerance"`
+	}
+
+	// ConcurrencyLimiter limits the number of requests in progress per group, the limits are local to the instance
+	ConcurrencyLimiter struct {
+		rp         Responder
+		enabled    bool
+		retryAfter time.Duration
+		groups     map[string]*concurrencyGroup
+		now        func() time.Time
+	}
+
+	concurrencyGroup struct {
+		mu       sync.Mutex
+		inflight int64
+		limiter  limitAlgorithm
+	}
+)
+
+// NewConcurrencyLimiter returns the concurrency limiter or an error when the configuration is not valid
+func NewConcurrencyLimiter(responder Responder, cfg ConcurrencyConfig) (*ConcurrencyLimiter, error) {
+	if responder == nil {
+		return nil, errNilResponder
+	}
+	populateDefaultConcurrencyValue(&cfg)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*concurrencyGroup, len(cfg.Groups))
+	for name, g := range cfg.Groups {
+		groups[name] = &concurrencyGroup{limiter: newLimitAlgorithm(g.Limit, g.Adaptive)}
+	}
+	return &ConcurrencyLimiter{
+		rp:         responder,
+		enabled:    cfg.Enabled,
+		retryAfter: time.Duration(cfg.RetryAfterSec) * time.Second,
+		groups:     groups,
+		now:        time.Now,
+	}, nil
+}
+
+// Limit rejects the request with 503 Service Unavailable when the group has too many requests in progress
+func (c *ConcurrencyLimiter) Limit(next http.HandlerFunc, group string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		g, ok := c.groups[group]
+		if !c.enabled || !ok {
+			next(w, r)
+			return
+		}
+
+		inflight, limit, ok := g.acquire()
+		if !ok {
+			quota := &Quota{Limit: limit, RetryAfter: c.retryAfter}
+			w.Header().Set(headerRetryAfter, fmt.Sprintf("%d", seconds(c.retryAfter)))
+			c.rp.RespondRateLimit(Response{
+				Error:      ErrOverloaded,
+				StatusCode: http.StatusServiceUnavailable,
+				Quota:      quota,
+			}, r, w)
+			return
+		}
+
+		start := c.now()
+		defer func() {
+			g.release(c.now().Sub(start), inflight)
+		}()
+		next(w, r)
+	}
+}
+
+// CurrentLimit returns the current in-flight limit of the group, 0 for an unknown group
+func (c *ConcurrencyLimiter) CurrentLimit(group string) int64 {
+	g, ok := c.groups[group]
+	if !ok {
+		return 0
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.limiter.limit()
+}
+
+func (g *concurrencyGroup) acquire() (inflight, limit int64, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	limit = g.limiter.limit()
+	if g.inflight >= limit {
+		return g.inflight, limit, false
+	}
+	g.inflight++
+	return g.inflight, limit, true
+}
+
+func (g *concurrencyGroup) release(latency time.Duration, inflight int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.inflight--
+	g.limiter.sample(latency, inflight)
+}
+
+func (cfg *ConcurrencyConfig) validate() error {
+	if cfg.RetryAfterSec < 0 {
+		return fmt.Errorf("retry after cannot be negative: '%d'", cfg.RetryAfterSec)
+	}
+	for group, c := range cfg.Groups {
+		if err := validateLimit(c.Limit, group, ""); err != nil {
+			return err
+		}
+		if err := c.Adaptive.validate(group, c.Limit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *AdaptiveConfig) validate(group string, limit int64) error {
+	if c == nil {
+		return nil
+	}
+	if c.MinLimit < limitMin || c.MinLimit > limit || c.MaxLimit < limit {
+		return fmt.Errorf("not valid adaptive limits for group: %s. The value should be %d <= minLimit(%d) <= limit(%d) <= maxLimit(%d)",
+			group, limitMin, c.MinLimit, limit, c.MaxLimit)
+	}
+
+	switch c.Mode {
+	case AIMDMode:
+		if c.LatencyThresholdMs <= 0 {
+			return fmt.Errorf("latency threshold should be positive for group: %s", group)
+		}
+		if c.BackoffRatio <= 0 || c.BackoffRatio >= 1 {
+			return fmt.Errorf("not valid backoff ratio: '%v' for group: %s. The value should be between 0 and 1", c.BackoffRatio, group)
+		}
+	case GradientMode:
+		if c.Tolerance < 1 {
+			return fmt.Errorf("not valid tolerance: '%v' for group: %s. The value cannot be less than 1", c.Tolerance, group)
+		}
+	default:
+		return fmt.Errorf("unsupported adaptive mode %s for group: %s", c.Mode, group)
+	}
+	return nil
+}
+
+func populateDefaultConcurrencyValue(cfg *ConcurrencyConfig) {
+	if cfg.RetryAfterSec == 0 {
+		cfg.RetryAfterSec = retryAfterDefault
+	}
+	// the groups are copied, so the defaults don't change the caller's configuration
+	groups := make(map[string]*ConcurrencyGroupConfig, len(cfg.Groups))
+	for name, g := range cfg.Groups {
+		gc := *g
+		groups[name] = &gc
+		if g.Adaptive == nil {
+			continue
+		}
+		a := *g.Adaptive
+		gc.Adaptive = &a
+		if a.MinLimit == 0 {
+			a.MinLimit = limitMin
+		}
+		if a.MaxLimit == 0 {
+			a.MaxLimit = g.Limit * maxLimitMultiplier
+		}
+		if a.BackoffRatio == 0 {
+			a.BackoffRatio = backoffRatioDefault
+		}
+		if a.Tolerance == 0 {
+			a.Tolerance = toleranceDefault
+		}
+	}
+	cfg.Groups = groups
+}
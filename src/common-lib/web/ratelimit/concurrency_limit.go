@@ -0,0 +1,114 @@
+package ratelimit
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// gradientLongWindow - number of samples in the long term latency average
+	gradientLongWindow = 600
+	// gradientShortWindow - number of samples in the short term latency average
+	gradientShortWindow = 10
+	// gradientSmoothing - weight of the new limit, smooths out the latency spikes
+	gradientSmoothing = 0.2
+)
+
+type (
+	// limitAlgorithm calculates the in-flight limit from the handler latency
+	limitAlgorithm interface {
+		limit() int64
+		// sample is called after every request with its latency and the number of in-flight requests at its start
+		sample(latency time.Duration, inflight int64)
+	}
+
+	fixedLimit int64
+
+	// aimdLimit increases the limit by one while the requests are fast and multiplies it by backoff otherwise
+	aimdLimit struct {
+		current   float64
+		min, max  float64
+		threshold time.Duration
+		backoff   float64
+	}
+
+	// gradientLimit compares the short and the long term latency averages, the limit is lowered when
+	// the short term latency grows over the long term one multiplied by tolerance
+	gradientLimit struct {
+		current   float64
+		min, max  float64
+		tolerance float64
+		longRTT   float64
+		shortRTT  float64
+	}
+)
+
+func newLimitAlgorithm(limit int64, cfg *AdaptiveConfig) limitAlgorithm {
+	if cfg == nil {
+		return fixedLimit(limit)
+	}
+	switch cfg.Mode {
+	case AIMDMode:
+		return &aimdLimit{
+			current:   float64(limit),
+			min:       float64(cfg.MinLimit),
+			max:       float64(cfg.MaxLimit),
+			threshold: time.Duration(cfg.LatencyThresholdMs) * time.Millisecond,
+			backoff:   cfg.BackoffRatio,
+		}
+	default:
+		return &gradientLimit{
+			current:   float64(limit),
+			min:       float64(cfg.MinLimit),
+			max:       float64(cfg.MaxLimit),
+			tolerance: cfg.Tolerance,
+		}
+	}
+}
+
+func (l fixedLimit) limit() int64 {
+	return int64(l)
+}
+
+func (l fixedLimit) sample(_ time.Duration, _ int64) {}
+
+func (l *aimdLimit) limit() int64 {
+	return int64(l.current)
+}
+
+func (l *aimdLimit) sample(latency time.Duration, inflight int64) {
+	switch {
+	case latency > l.threshold:
+		l.current = math.Max(l.min, math.Floor(l.current*l.backoff))
+	case float64(inflight)*2 >= l.current:
+		// the limit is grown only while it is actually used
+		l.current = math.Min(l.max, l.current+1)
+	}
+}
+
+func (l *gradientLimit) limit() int64 {
+	return int64(l.current)
+}
+
+func (l *gradientLimit) sample(latency time.Duration, inflight int64) {
+	rtt := float64(latency)
+	if l.longRTT == 0 {
+		l.longRTT, l.shortRTT = rtt, rtt
+	} else {
+		l.longRTT += (rtt - l.longRTT) / gradientLongWindow
+		l.shortRTT += (rtt - l.shortRTT) / gradientShortWindow
+	}
+
+	// the long term average recovers faster after a latency spike
+	if l.shortRTT > 0 && l.longRTT/l.shortRTT > 2 {
+		l.longRTT *= 0.95
+	}
+	if float64(inflight)*2 < l.current || l.shortRTT == 0 {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.tolerance*l.longRTT/l.shortRTT))
+	next := l.current*gradient + math.Sqrt(l.current)
+	next = l.current*(1-gradientSmoothing) + next*gradientSmoothing
+	l.current = math.Max(l.min, math.Min(l.max, next))
+}
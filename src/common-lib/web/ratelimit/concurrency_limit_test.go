@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_fixedLimit(t *testing.T) {
+	l := newLimitAlgorithm(5, nil)
+	l.sample(time.Hour, 5)
+	require.Equal(t, int64(5), l.limit())
+}
+
+func Test_aimdLimit(t *testing.T) {
+	l := newLimitAlgorithm(10, &AdaptiveConfig{
+		Mode:               AIMDMode,
+		MinLimit:           5,
+		MaxLimit:           11,
+		LatencyThresholdMs: 100,
+		BackoffRatio:       0.5,
+	})
+
+	l.sample(10*time.Millisecond, 2)
+	require.Equal(t, int64(10), l.limit(), "the limit is not used")
+
+	l.sample(10*time.Millisecond, 5)
+	require.Equal(t, int64(11), l.limit())
+
+	l.sample(10*time.Millisecond, 11)
+	require.Equal(t, int64(11), l.limit(), "max limit")
+
+	l.sample(time.Second, 11)
+	require.Equal(t, int64(5), l.limit())
+
+	l.sample(time.Second, 5)
+	require.Equal(t, int64(5), l.limit(), "min limit")
+}
+
+func Test_gradientLimit(t *testing.T) {
+	newLimit := func() limitAlgorithm {
+		return newLimitAlgorithm(10, &AdaptiveConfig{
+			Mode:      GradientMode,
+			MinLimit:  2,
+			MaxLimit:  20,
+			Tolerance: 1.5,
+		})
+	}
+
+	t.Run("Steady latency", func(t *testing.T) {
+		l := newLimit()
+		for i := 0; i < 100; i++ {
+			l.sample(10*time.Millisecond, l.limit())
+		}
+		require.Equal(t, int64(20), l.limit())
+	})
+
+	t.Run("Latency grows", func(t *testing.T) {
+		l := newLimit()
+		for i := 0; i < 20; i++ {
+			l.sample(10*time.Millisecond, l.limit())
+		}
+		grown := l.limit()
+		for i := 0; i < 50; i++ {
+			l.sample(time.Second, l.limit())
+		}
+		require.Less(t, l.limit(), grown)
+		// the min gradient 0.5 and the sqrt(limit) queue size settle at 4
+		require.Equal(t, int64(4), l.limit())
+	})
+
+	t.Run("The limit is not used", func(t *testing.T) {
+		l := newLimit()
+		for i := 0; i < 20; i++ {
+			l.sample(10*time.Millisecond, 1)
+		}
+		require.Equal(t, int64(10), l.limit())
+	})
+}
@@ -0,0 +1,176 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewConcurrencyLimiter(t *testing.T) {
+	invalidLimit := mockConcurrencyConfig()
+	invalidLimit.Groups[mockGroup].Limit = 0
+
+	invalidRetryAfter := mockConcurrencyConfig()
+	invalidRetryAfter.RetryAfterSec = -1
+
+	aimd := mockConcurrencyConfig()
+	aimd.Groups[mockGroup].Adaptive = &AdaptiveConfig{Mode: AIMDMode, LatencyThresholdMs: 100}
+
+	gradient := mockConcurrencyConfig()
+	gradient.Groups[mockGroup].Adaptive = &AdaptiveConfig{Mode: GradientMode}
+
+	invalidMode := mockConcurrencyConfig()
+	invalidMode.Groups[mockGroup].Adaptive = &AdaptiveConfig{Mode: "unknownMode"}
+
+	invalidMinLimit := mockConcurrencyConfig()
+	invalidMinLimit.Groups[mockGroup].Adaptive = &AdaptiveConfig{Mode: GradientMode, MinLimit: 3}
+
+	invalidMaxLimit := mockConcurrencyConfig()
+	invalidMaxLimit.Groups[mockGroup].Adaptive = &AdaptiveConfig{Mode: GradientMode, MaxLimit: 1}
+
+	invalidThreshold := mockConcurrencyConfig()
+	invalidThreshold.Groups[mockGroup].Adaptive = &AdaptiveConfig{Mode: AIMDMode}
+
+	invalidBackoff := mockConcurrencyConfig()
+	invalidBackoff.Groups[mockGroup].Adaptive = &AdaptiveConfig{Mode: AIMDMode, LatencyThresholdMs: 100, BackoffRatio: 1.5}
+
+	invalidTolerance := mockConcurrencyConfig()
+	invalidTolerance.Groups[mockGroup].Adaptive = &AdaptiveConfig{Mode: GradientMode, Tolerance: 0.5}
+
+	tt := []struct {
+		name   string
+		config ConcurrencyConfig
+		expErr bool
+	}{
+		{name: "Valid", config: mockConcurrencyConfig(), expErr: false},
+		{name: "Valid: aimd", config: aimd, expErr: false},
+		{name: "Valid: gradient", config: gradient, expErr: false},
+		{name: "Invalid: limit", config: invalidLimit, expErr: true},
+		{name: "Invalid: retry after", config: invalidRetryAfter, expErr: true},
+		{name: "Invalid: mode", config: invalidMode, expErr: true},
+		{name: "Invalid: min limit", config: invalidMinLimit, expErr: true},
+		{name: "Invalid: max limit", config: invalidMaxLimit, expErr: true},
+		{name: "Invalid: latency threshold", config: invalidThreshold, expErr: true},
+		{name: "Invalid: backoff ratio", config: invalidBackoff, expErr: true},
+		{name: "Invalid: tolerance", config: invalidTolerance, expErr: true},
+	}
+
+	for _, tc := range tt {
+		cfg := tc.config
+		expErr := tc.expErr
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewConcurrencyLimiter(mockResponder{}, cfg)
+			require.Equal(t, expErr, err != nil, err)
+		})
+	}
+
+	t.Run("Responder is nil", func(t *testing.T) {
+		_, err := NewConcurrencyLimiter(nil, mockConcurrencyConfig())
+		require.Error(t, err)
+	})
+
+	t.Run("Defaults don't change the config", func(t *testing.T) {
+		_, err := NewConcurrencyLimiter(mockResponder{}, gradient)
+		require.NoError(t, err)
+		require.Equal(t, &AdaptiveConfig{Mode: GradientMode}, gradient.Groups[mockGroup].Adaptive)
+	})
+}
+
+func TestConcurrencyLimiter_Limit(t *testing.T) {
+	c, err := NewConcurrencyLimiter(mockResponder{}, mockConcurrencyConfig())
+	require.NoError(t, err)
+
+	started, unblock := make(chan struct{}, 2), make(chan struct{})
+	handler := c.Limit(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	}, mockGroup)
+
+	var wg sync.WaitGroup
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, mockTestURL, nil))
+			codes <- w.Result().StatusCode
+		}()
+		<-started
+	}
+
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, mockTestURL, nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Result().StatusCode)
+	require.Equal(t, "1", w.Result().Header.Get(headerRetryAfter))
+
+	close(unblock)
+	wg.Wait()
+	close(codes)
+	for code := range codes {
+		require.Equal(t, http.StatusOK, code)
+	}
+
+	w = httptest.NewRecorder()
+	handler(w, httptest.NewRequest(http.MethodGet, mockTestURL, nil))
+	require.Equal(t, http.StatusOK, w.Result().StatusCode)
+}
+
+func TestConcurrencyLimiter_Limit_PassThrough(t *testing.T) {
+	disabled := mockConcurrencyConfig()
+	disabled.Enabled = false
+	disabled.Groups[mockGroup].Limit = 1
+
+	tt := []struct {
+		name   string
+		config ConcurrencyConfig
+		group  string
+	}{
+		{name: "Disabled", config: disabled, group: mockGroup},
+		{name: "Group doesn't exist", config: mockConcurrencyConfig(), group: "some_group"},
+	}
+
+	for _, tc := range tt {
+		cfg := tc.config
+		group := tc.group
+		t.Run(tc.name, func(t *testing.T) {
+			c, err := NewConcurrencyLimiter(mockResponder{}, cfg)
+			require.NoError(t, err)
+
+			var handler http.HandlerFunc
+			calls := 0
+			handler = c.Limit(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls < 3 {
+					handler(httptest.NewRecorder(), r)
+				}
+				w.WriteHeader(http.StatusOK)
+			}, group)
+
+			w := httptest.NewRecorder()
+			handler(w, httptest.NewRequest(http.MethodGet, mockTestURL, nil))
+			require.Equal(t, http.StatusOK, w.Result().StatusCode)
+			require.Equal(t, 3, calls)
+		})
+	}
+}
+
+func TestConcurrencyLimiter_CurrentLimit(t *testing.T) {
+	c, err := NewConcurrencyLimiter(mockResponder{}, mockConcurrencyConfig())
+	require.NoError(t, err)
+	require.Equal(t, int64(2), c.CurrentLimit(mockGroup))
+	require.Equal(t, int64(0), c.CurrentLimit("some_group"))
+}
+
+func mockConcurrencyConfig() ConcurrencyConfig {
+	return ConcurrencyConfig{
+		Enabled: true,
+		Groups: map[string]*ConcurrencyGroupConfig{
+			mockGroup: {Limit: 2},
+		},
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"sync"
 	"time"
 )
@@ -12,6 +14,10 @@ import (
 const (
 	// SlidingWindowAlgorithm string key for the sliding window algorithm
 	SlidingWindowAlgorithm = "slidingWindow"
+	// TokenBucketAlgorithm string key for the token bucket algorithm
+	TokenBucketAlgorithm = "tokenBucket"
+	// GCRAAlgorithm string key for the generic cell rate algorithm
+	GCRAAlgorithm = "gcra"
 
 	configStorageKey = "ratelimit_config"
 
@@ -52,22 +58,63 @@ type (
 
 	// GroupConfig structure for holding group params
 	GroupConfig struct {
-		// Overrides - contains limit for the unique endpoint key in the current group
+		// Overrides - contains limit for the unique endpoint key in the current group,
+		// a composite key "partner:user" built by a KeyFunc uses the "partner" override when it has no own one
 		Overrides map[string]int64 `json:"overrides"`
 
 		// Limit - contains limit for the current group
 		Limit int64 `json:"limit"`
+
+		// Algorithm - overrides Config.Algorithm for the current group
+		Algorithm string `json:"algorithm,omitempty"`
+
+		// TokenBucket - parameters of the tokenBucket algorithm
+		TokenBucket *TokenBucketConfig `json:"tokenBucket,omitempty"`
+
+		// GCRA - parameters of the gcra algorithm
+		GCRA *GCRAConfig `json:"gcra,omitempty"`
+	}
+
+	// TokenBucketConfig structure for holding token bucket params
+	TokenBucketConfig struct {
+		// Burst - capacity of the bucket, defaults to the limit
+		Burst int64 `json:"burst"`
+
+		// RefillPerSec - number of tokens added to the bucket per second, defaults to limit / intervalInSec
+		RefillPerSec float64 `json:"refillPerSec"`
+	}
+
+	// GCRAConfig structure for holding generic cell rate algorithm params
+	GCRAConfig struct {
+		// Burst - number of requests allowed at once, defaults to the limit
+		Burst int64 `json:"burst"`
+
+		// EmissionIntervalMs - time in milliseconds needed to regain one request, defaults to intervalInSec / limit
+		EmissionIntervalMs int64 `json:"emissionIntervalMs"`
 	}
 
 	countParams struct {
 		Now      int64
+		NowNano  int64
 		Group    string
 		Key      string
 		Interval int64
-		Limit    int64
+		// Limit - for tokenBucket and gcra it is the burst size
+		Limit int64
+		// Rate - requests per second regained by tokenBucket and gcra
+		Rate float64
+	}
+
+	countResult struct {
+		// Count - number of requests including the current one, the request is rejected when it is greater than the limit
+		Count int64
+		// Reset - time until the quota is fully restored
+		Reset time.Duration
+		// RetryAfter - time until the next request can be allowed, it is set for the rejected requests only
+		RetryAfter time.Duration
 	}
 
-	countGetter func(storage Storage, params countParams) (int64, error)
+	countGetter func(storage Storage, params countParams) (countResult, error)
 
 	cachedConfig struct {
 		config *Config
@@ -75,18 +122,103 @@ type (
 	}
 )
 
+// limit returns the override of the key or the group limit
 func (cfg *Config) limit(group, key string) int64 {
 	g, ok := cfg.Groups[group]
 	if !ok {
 		return 0
 	}
-	limit, ok := g.Overrides[key]
-	if ok {
+	if limit, ok := g.Overrides[key]; ok {
 		return limit
 	}
 	return g.Limit
 }
 
+// prefixLimit returns the limit of a key built by a KeyFunc, "partner:user" falls back to the "partner" override
+func (cfg *Config) prefixLimit(group, key string) int64 {
+	g, ok := cfg.Groups[group]
+	if !ok {
+		return 0
+	}
+	for k := key; k != ""; {
+		if limit, ok := g.Overrides[k]; ok {
+			return limit
+		}
+		i := strings.LastIndex(k, keySeparator)
+		if i < 0 {
+			break
+		}
+		k = k[:i]
+	}
+	return g.Limit
+}
+
+func (cfg *Config) algorithm(group string) string {
+	g, ok := cfg.Groups[group]
+	if !ok || g.Algorithm == "" {
+		return cfg.Algorithm
+	}
+	return g.Algorithm
+}
+
+// params returns the count params of the key, the overrides of the key prefixes apply when prefixes is set
+func (cfg *Config) params(group, key string, prefixes bool, now time.Time) countParams {
+	p := countParams{
+		Now:      now.Unix(),
+		NowNano:  now.UnixNano(),
+		Group:    group,
+		Key:      key,
+		Interval: cfg.IntervalInSec,
+		Limit:    cfg.limit(group, key),
+	}
+	if prefixes {
+		p.Limit = cfg.prefixLimit(group, key)
+	}
+	g, ok := cfg.Groups[group]
+	if !ok {
+		return p
+	}
+
+	// the burst and the rate of the group are scaled by the override of the key
+	scale := 1.0
+	if g.Limit > 0 {
+		scale = float64(p.Limit) / float64(g.Limit)
+	}
+
+	switch cfg.algorithm(group) {
+	case TokenBucketAlgorithm:
+		p.Rate = float64(p.Limit) / float64(p.Interval)
+		if g.TokenBucket != nil {
+			if g.TokenBucket.Burst > 0 {
+				p.Limit = scaleBurst(g.TokenBucket.Burst, scale)
+			}
+			if g.TokenBucket.RefillPerSec > 0 {
+				p.Rate = g.TokenBucket.RefillPerSec * scale
+			}
+		}
+	case GCRAAlgorithm:
+		p.Rate = float64(p.Limit) / float64(p.Interval)
+		if g.GCRA != nil {
+			if g.GCRA.Burst > 0 {
+				p.Limit = scaleBurst(g.GCRA.Burst, scale)
+			}
+			if g.GCRA.EmissionIntervalMs > 0 {
+				p.Rate = float64(time.Second/time.Millisecond) / float64(g.GCRA.EmissionIntervalMs) * scale
+			}
+		}
+	}
+	return p
+}
+
+// scaleBurst returns the burst scaled by the override, at least one request is allowed
+func scaleBurst(burst int64, scale float64) int64 {
+	scaled := int64(math.Round(float64(burst) * scale))
+	if scaled < limitMin {
+		return limitMin
+	}
+	return scaled
+}
+
 func (cfg *Config) validate() error {
 	if cfg.IntervalInSec < intervalMin || cfg.IntervalInSec > intervalMax {
 		return fmt.Errorf("not valid interval: '%d'. The value should be between %d and %d",
@@ -102,6 +234,17 @@ func (cfg *Config) validate() error {
 				return err
 			}
 		}
+		if c.Algorithm != "" {
+			if _, err := resolveCountGetter(c.Algorithm); err != nil {
+				return fmt.Errorf("%v for group: %s", err, group)
+			}
+		}
+		if err := c.TokenBucket.validate(group); err != nil {
+			return err
+		}
+		if err := c.GCRA.validate(group); err != nil {
+			return err
+		}
 	}
 
 	if _, err := resolveCountGetter(cfg.Algorithm); err != nil {
@@ -111,6 +254,32 @@ func (cfg *Config) validate() error {
 	return nil
 }
 
+func (c *TokenBucketConfig) validate(group string) error {
+	if c == nil {
+		return nil
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("token bucket burst cannot be negative for group: %s", group)
+	}
+	if c.RefillPerSec < 0 || math.IsNaN(c.RefillPerSec) || math.IsInf(c.RefillPerSec, 0) {
+		return fmt.Errorf("not valid token bucket refill rate: '%v' for group: %s", c.RefillPerSec, group)
+	}
+	return nil
+}
+
+func (c *GCRAConfig) validate(group string) error {
+	if c == nil {
+		return nil
+	}
+	if c.Burst < 0 {
+		return fmt.Errorf("gcra burst cannot be negative for group: %s", group)
+	}
+	if c.EmissionIntervalMs < 0 {
+		return fmt.Errorf("gcra emission interval cannot be negative for group: %s", group)
+	}
+	return nil
+}
+
 func validateLimit(limit int64, group, key string) error {
 	if limit < limitMin {
 		s := fmt.Sprintf("limit cannot be less that %d for group: %s", limitMin, group)
@@ -126,6 +295,10 @@ func resolveCountGetter(algorithm string) (countGetter, error) {
 	switch algorithm {
 	case SlidingWindowAlgorithm:
 		return slidingWindow, nil
+	case TokenBucketAlgorithm:
+		return tokenBucket, nil
+	case GCRAAlgorithm:
+		return gcra, nil
 	default:
 		return nil, fmt.Errorf("unsupported algorithm %s", algorithm)
 	}
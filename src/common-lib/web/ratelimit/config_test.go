@@ -21,6 +21,7 @@ func TestConfig_limit(t *testing.T) {
 		name     string
 		group    string
 		key      string
+		prefixes bool
 		expected int64
 	}{
 		{
@@ -35,6 +36,26 @@ func TestConfig_limit(t *testing.T) {
 			key:      mockKey,
 			expected: 15,
 		},
+		{
+			name:     "Internal group: Overrides by key prefix",
+			group:    mockGroup,
+			key:      mockKey + ":42",
+			prefixes: true,
+			expected: 15,
+		},
+		{
+			name:     "Internal group: Static key not matching an override by prefix",
+			group:    mockGroup,
+			key:      mockKey + ":42",
+			expected: 10,
+		},
+		{
+			name:     "Internal group: Key without overrides",
+			group:    mockGroup,
+			key:      "321:" + mockKey,
+			prefixes: true,
+			expected: 10,
+		},
 		{
 			name:     "Group doesn't exist",
 			group:    "some_group",
@@ -44,11 +65,13 @@ func TestConfig_limit(t *testing.T) {
 	}
 
 	for _, tc := range tt {
-		group := tc.group
-		key := tc.key
-		expected := tc.expected
+		tc := tc
 		t.Run(tc.name, func(t *testing.T) {
-			require.Equal(t, expected, cfg.limit(group, key))
+			if tc.prefixes {
+				require.Equal(t, tc.expected, cfg.prefixLimit(tc.group, tc.key))
+				return
+			}
+			require.Equal(t, tc.expected, cfg.limit(tc.group, tc.key))
 		})
 	}
 }
@@ -66,6 +89,26 @@ func TestConfig_validate(t *testing.T) {
 	invalidAlgorithm := mockConfig()
 	invalidAlgorithm.Algorithm = "invalidAlgorithm"
 
+	validGroupAlgorithm := mockConfig()
+	validGroupAlgorithm.Groups[mockGroup].Algorithm = TokenBucketAlgorithm
+	validGroupAlgorithm.Groups[mockGroup].TokenBucket = &TokenBucketConfig{Burst: 5, RefillPerSec: 0.5}
+	validGroupAlgorithm.Groups[mockGroup].GCRA = &GCRAConfig{Burst: 5, EmissionIntervalMs: 200}
+
+	invalidGroupAlgorithm := mockConfig()
+	invalidGroupAlgorithm.Groups[mockGroup].Algorithm = "invalidAlgorithm"
+
+	invalidTokenBucketBurst := mockConfig()
+	invalidTokenBucketBurst.Groups[mockGroup].TokenBucket = &TokenBucketConfig{Burst: -1}
+
+	invalidTokenBucketRefill := mockConfig()
+	invalidTokenBucketRefill.Groups[mockGroup].TokenBucket = &TokenBucketConfig{RefillPerSec: -1}
+
+	invalidGCRABurst := mockConfig()
+	invalidGCRABurst.Groups[mockGroup].GCRA = &GCRAConfig{Burst: -1}
+
+	invalidGCRAEmissionInterval := mockConfig()
+	invalidGCRAEmissionInterval.Groups[mockGroup].GCRA = &GCRAConfig{EmissionIntervalMs: -1}
+
 	tt := []struct {
 		name   string
 		config Config
@@ -96,6 +139,36 @@ func TestConfig_validate(t *testing.T) {
 			config: invalidAlgorithm,
 			expErr: true,
 		},
+		{
+			name:   "Valid: group algorithm",
+			config: validGroupAlgorithm,
+			expErr: false,
+		},
+		{
+			name:   "Invalid: group algorithm",
+			config: invalidGroupAlgorithm,
+			expErr: true,
+		},
+		{
+			name:   "Invalid: token bucket burst",
+			config: invalidTokenBucketBurst,
+			expErr: true,
+		},
+		{
+			name:   "Invalid: token bucket refill",
+			config: invalidTokenBucketRefill,
+			expErr: true,
+		},
+		{
+			name:   "Invalid: gcra burst",
+			config: invalidGCRABurst,
+			expErr: true,
+		},
+		{
+			name:   "Invalid: gcra emission interval",
+			config: invalidGCRAEmissionInterval,
+			expErr: true,
+		},
 	}
 
 	for _, tc := range tt {
@@ -118,6 +191,16 @@ func Test_resolveCountGetter(t *testing.T) {
 			algorithm: SlidingWindowAlgorithm,
 			expErr:    false,
 		},
+		{
+			name:      TokenBucketAlgorithm,
+			algorithm: TokenBucketAlgorithm,
+			expErr:    false,
+		},
+		{
+			name:      GCRAAlgorithm,
+			algorithm: GCRAAlgorithm,
+			expErr:    false,
+		},
 		{
 			name:      "Algorithm doesn't exist",
 			algorithm: "unknownAlgorithm",
@@ -135,6 +218,96 @@ func Test_resolveCountGetter(t *testing.T) {
 	}
 }
 
+func TestConfig_params(t *testing.T) {
+	now := time.Unix(1646042799, 500)
+	tt := []struct {
+		name      string
+		algorithm string
+		tb        *TokenBucketConfig
+		gcra      *GCRAConfig
+		key       string
+		expLimit  int64
+		expRate   float64
+	}{
+		{
+			name:      SlidingWindowAlgorithm,
+			algorithm: "",
+			key:       mockKey,
+			expLimit:  15,
+			expRate:   0,
+		},
+		{
+			name:      "Token bucket: defaults",
+			algorithm: TokenBucketAlgorithm,
+			key:       mockKey,
+			expLimit:  15,
+			expRate:   0.5,
+		},
+		{
+			name:      "Token bucket: custom",
+			algorithm: TokenBucketAlgorithm,
+			tb:        &TokenBucketConfig{Burst: 3, RefillPerSec: 2},
+			expLimit:  3,
+			expRate:   2,
+		},
+		{
+			name:      "GCRA: defaults",
+			algorithm: GCRAAlgorithm,
+			expLimit:  10,
+			expRate:   float64(10) / float64(intervalMin),
+		},
+		{
+			name:      "GCRA: custom",
+			algorithm: GCRAAlgorithm,
+			gcra:      &GCRAConfig{Burst: 4, EmissionIntervalMs: 250},
+			expLimit:  4,
+			expRate:   4,
+		},
+		{
+			name:      "Token bucket: custom with override",
+			algorithm: TokenBucketAlgorithm,
+			tb:        &TokenBucketConfig{Burst: 4, RefillPerSec: 2},
+			key:       mockKey,
+			expLimit:  6,
+			expRate:   3,
+		},
+		{
+			name:      "GCRA: custom with override",
+			algorithm: GCRAAlgorithm,
+			gcra:      &GCRAConfig{Burst: 4, EmissionIntervalMs: 250},
+			key:       mockKey,
+			expLimit:  6,
+			expRate:   6,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := mockConfig()
+			cfg.Groups[mockGroup].Algorithm = tc.algorithm
+			cfg.Groups[mockGroup].TokenBucket = tc.tb
+			cfg.Groups[mockGroup].GCRA = tc.gcra
+
+			p := cfg.params(mockGroup, tc.key, false, now)
+			require.Equal(t, now.Unix(), p.Now)
+			require.Equal(t, now.UnixNano(), p.NowNano)
+			require.Equal(t, cfg.IntervalInSec, p.Interval)
+			require.Equal(t, tc.expLimit, p.Limit)
+			require.Equal(t, tc.expRate, p.Rate)
+		})
+	}
+}
+
+func TestConfig_algorithm(t *testing.T) {
+	cfg := mockConfig()
+	require.Equal(t, SlidingWindowAlgorithm, cfg.algorithm(mockGroup))
+	require.Equal(t, SlidingWindowAlgorithm, cfg.algorithm("some_group"))
+
+	cfg.Groups[mockGroup].Algorithm = GCRAAlgorithm
+	require.Equal(t, GCRAAlgorithm, cfg.algorithm(mockGroup))
+}
+
 func Test_populateDefaultConfigValue(t *testing.T) {
 	cfg := mockConfig()
 	cfg.InMemoryCacheTTL = inMemoryCacheTTLMin - 1
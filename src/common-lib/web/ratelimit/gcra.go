@@ -0,0 +1,87 @@
+package ratelimit
+
+import (
+	"strconv"
+	"time"
+)
+
+// gcra keeps the theoretical arrival time (TAT) in nanoseconds under the state key. A request is allowed while
+// the TAT does not run further ahead of now than p.Limit emission intervals, one interval is 1/p.Rate seconds.
+// The state is read and updated in one step when the storage implements AtomicStorage
+func gcra(storage Storage, p countParams) (countResult, error) {
+	key := stateKey(p.Group, p.Key, GCRAAlgorithm)
+	emission := int64(float64(time.Second) / p.Rate)
+	if emission < 1 {
+		emission = 1
+	}
+	tolerance := emission * p.Limit
+
+	var (
+		tat     int64
+		allowed bool
+		err     error
+	)
+	if s, ok := storage.(AtomicStorage); ok {
+		tat, allowed, err = s.GCRATake(key, emission, tolerance, p.NowNano)
+	} else {
+		tat, allowed, err = gcraTake(storage, key, emission, tolerance, p.NowNano)
+	}
+	if err != nil {
+		return countResult{}, err
+	}
+
+	if !allowed {
+		return countResult{
+			Count:      p.Limit + 1,
+			Reset:      time.Duration(tat - p.NowNano),
+			RetryAfter: time.Duration(tat + emission - tolerance - p.NowNano),
+		}, nil
+	}
+	return countResult{
+		Count: p.Limit - (p.NowNano+tolerance-tat)/emission,
+		Reset: time.Duration(tat - p.NowNano),
+	}, nil
+}
+
+// gcraTake reads and updates the TAT with separate calls, for the storages without AtomicStorage
+func gcraTake(storage Storage, key string, emission, tolerance, now int64) (int64, bool, error) {
+	raw, err := state(storage, key)
+	if err != nil {
+		return 0, false, err
+	}
+	tat, allowed, err := advanceTAT(raw, emission, tolerance, now)
+	if err != nil || !allowed {
+		return tat, allowed, err
+	}
+	if err = storage.Set(key, strconv.FormatInt(tat, 10)); err != nil {
+		return 0, false, err
+	}
+	if err = expire(storage, key, gcraTTL(tat, now)); err != nil {
+		return 0, false, err
+	}
+	return tat, true, nil
+}
+
+// advanceTAT moves the TAT kept in the raw state by one emission interval when the request is allowed,
+// it returns the TAT which is never behind now
+func advanceTAT(raw string, emission, tolerance, now int64) (int64, bool, error) {
+	tat := now
+	if raw != "" {
+		var err error
+		if tat, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return 0, false, err
+		}
+	}
+	if tat < now {
+		tat = now
+	}
+	if tat+emission-now > tolerance {
+		return tat, false, nil
+	}
+	return tat + emission, true, nil
+}
+
+// gcraTTL keeps the state until the TAT is reached
+func gcraTTL(tat, now int64) time.Duration {
+	return time.Duration(tat-now).Truncate(time.Second) + time.Second
+}
@@ -0,0 +1,151 @@
+package ratelimit
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"gitlab.kksharmadevdev.com/platform/platform-api-model/clients/model/Golang/resourceModel/auth"
+)
+
+const (
+	// keySeparator joins the parts of a composite key, overrides are looked up by the key prefixes as well
+	keySeparator = ":"
+
+	forwardedForHeader = "X-Forwarded-For"
+)
+
+// KeyFunc builds the rate limit key from the request
+type KeyFunc func(r *http.Request) (string, error)
+
+// StaticKey returns the same key for every request
+func StaticKey(key string) KeyFunc {
+	return func(_ *http.Request) (string, error) {
+		return key, nil
+	}
+}
+
+// PartnerKey returns the partner ID of the caller from the request context. It is set by the authorization
+// middleware once the bearer token is validated, so the route should be behind the token validation
+// and the permission decoding (middleware.Permission.DecodeHandler)
+func PartnerKey() KeyFunc {
+	return ContextKey(auth.PartnerIDKey)
+}
+
+// UserKey returns the user ID of the caller from the request context, see PartnerKey
+func UserKey() KeyFunc {
+	return ContextKey(auth.UserIDKey)
+}
+
+// PartnerUserKey returns "partnerID:userID", a partner override applies to every user of the partner
+func PartnerUserKey() KeyFunc {
+	return CompositeKey(PartnerKey(), UserKey())
+}
+
+// ContextKey returns the string value of the request context by ctxKey.
+// The bearer token itself is never decoded here, as its claims can't be trusted before the token is validated
+func ContextKey(ctxKey interface{}) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		if v, ok := r.Context().Value(ctxKey).(string); ok && v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("request context has no %v, the route must be behind the token validation", ctxKey)
+	}
+}
+
+// HeaderKey returns the value of the request header
+func HeaderKey(name string) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		if v := r.Header.Get(name); v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("request has no %s header", name)
+	}
+}
+
+// IPKey returns the client IP address taken from the remote address of the connection. X-Forwarded-For is taken
+// into account only for the connections from the trusted proxies (IP addresses or CIDR ranges): the client is the last
+// address of the header which isn't a trusted proxy, so the addresses the client put into the header are ignored
+func IPKey(trustedProxies ...string) KeyFunc {
+	trusted := make([]*net.IPNet, 0, len(trustedProxies))
+	for _, proxy := range trustedProxies {
+		if !strings.Contains(proxy, "/") {
+			if ip := net.ParseIP(proxy); ip != nil && ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return func(_ *http.Request) (string, error) {
+				return "", fmt.Errorf("invalid trusted proxy: %v", err)
+			}
+		}
+		trusted = append(trusted, ipNet)
+	}
+	isTrusted := func(ip net.IP) bool {
+		for _, ipNet := range trusted {
+			if ipNet.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(r *http.Request) (string, error) {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			host = r.RemoteAddr
+		}
+		ip := net.ParseIP(host)
+		if ip == nil {
+			return "", fmt.Errorf("invalid remote address %s", r.RemoteAddr)
+		}
+		if !isTrusted(ip) {
+			return ip.String(), nil
+		}
+
+		forwarded := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+		for i := len(forwarded) - 1; i >= 0; i-- {
+			addr := strings.TrimSpace(forwarded[i])
+			if addr == "" {
+				continue
+			}
+			if ip = net.ParseIP(addr); ip == nil {
+				return "", fmt.Errorf("invalid %s address %s", forwardedForHeader, addr)
+			}
+			if !isTrusted(ip) {
+				break
+			}
+		}
+		return ip.String(), nil
+	}
+}
+
+// RouteVarKey returns the value of the gorilla/mux route variable
+func RouteVarKey(name string) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		if v := mux.Vars(r)[name]; v != "" {
+			return v, nil
+		}
+		return "", fmt.Errorf("request has no %s route variable", name)
+	}
+}
+
+// CompositeKey joins the keys returned by fns with ":"
+func CompositeKey(fns ...KeyFunc) KeyFunc {
+	return func(r *http.Request) (string, error) {
+		parts := make([]string, 0, len(fns))
+		for _, fn := range fns {
+			k, err := fn(r)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, k)
+		}
+		return strings.Join(parts, keySeparator), nil
+	}
+}
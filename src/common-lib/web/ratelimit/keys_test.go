@@ -0,0 +1,191 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"gitlab.kksharmadevdev.com/platform/platform-api-model/clients/model/Golang/resourceModel/auth"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/authorization/token"
+)
+
+const (
+	mockPartnerID = "50001"
+	mockUserID    = "42"
+)
+
+func TestKeyFunc(t *testing.T) {
+	tt := []struct {
+		name     string
+		keyFn    KeyFunc
+		request  func(t *testing.T) *http.Request
+		expected string
+		expErr   bool
+	}{
+		{
+			name:     "Static",
+			keyFn:    StaticKey(mockKey),
+			request:  mockRequest,
+			expected: mockKey,
+		},
+		{
+			name:    "Partner: bearer token is not trusted",
+			keyFn:   PartnerKey(),
+			request: mockRequestWithToken(mockPartnerID, mockUserID),
+			expErr:  true,
+		},
+		{
+			name:  "Partner: context",
+			keyFn: PartnerKey(),
+			request: func(t *testing.T) *http.Request {
+				r := mockRequest(t)
+				return r.WithContext(context.WithValue(r.Context(), auth.PartnerIDKey, "50002"))
+			},
+			expected: "50002",
+		},
+		{
+			name:    "Partner: no context",
+			keyFn:   PartnerKey(),
+			request: mockRequest,
+			expErr:  true,
+		},
+		{
+			name:    "User: empty context value",
+			keyFn:   UserKey(),
+			request: mockRequestWithIDs(mockPartnerID, ""),
+			expErr:  true,
+		},
+		{
+			name:     "Partner and user",
+			keyFn:    PartnerUserKey(),
+			request:  mockRequestWithIDs(mockPartnerID, mockUserID),
+			expected: mockPartnerID + ":" + mockUserID,
+		},
+		{
+			name:     "Header",
+			keyFn:    HeaderKey("X-Client-ID"),
+			request:  mockRequestWithHeader("X-Client-ID", "client"),
+			expected: "client",
+		},
+		{
+			name:    "Header: missing",
+			keyFn:   HeaderKey("X-Client-ID"),
+			request: mockRequest,
+			expErr:  true,
+		},
+		{
+			name:  "IP",
+			keyFn: IPKey(),
+			request: func(t *testing.T) *http.Request {
+				r := mockRequest(t)
+				r.RemoteAddr = "10.0.0.1:1234"
+				return r
+			},
+			expected: "10.0.0.1",
+		},
+		{
+			name:     "IP: forwarded by untrusted address",
+			keyFn:    IPKey("10.0.0.0/8"),
+			request:  mockForwardedRequest("192.168.0.1:1234", "1.2.3.4"),
+			expected: "192.168.0.1",
+		},
+		{
+			name:     "IP: forwarded by trusted proxies",
+			keyFn:    IPKey("10.0.0.0/8", "192.168.0.1"),
+			request:  mockForwardedRequest("192.168.0.1:1234", "6.6.6.6, 1.2.3.4, 10.0.0.2"),
+			expected: "1.2.3.4",
+		},
+		{
+			name:     "IP: no forwarded address",
+			keyFn:    IPKey("192.168.0.1"),
+			request:  mockForwardedRequest("192.168.0.1:1234", ""),
+			expected: "192.168.0.1",
+		},
+		{
+			name:    "IP: invalid forwarded address",
+			keyFn:   IPKey("192.168.0.1"),
+			request: mockForwardedRequest("192.168.0.1:1234", "unknown"),
+			expErr:  true,
+		},
+		{
+			name:    "IP: invalid trusted proxy",
+			keyFn:   IPKey("invalid"),
+			request: mockRequest,
+			expErr:  true,
+		},
+		{
+			name:  "Route variable",
+			keyFn: RouteVarKey("partnerID"),
+			request: func(t *testing.T) *http.Request {
+				return mux.SetURLVars(mockRequest(t), map[string]string{"partnerID": mockPartnerID})
+			},
+			expected: mockPartnerID,
+		},
+		{
+			name:    "Route variable: missing",
+			keyFn:   RouteVarKey("partnerID"),
+			request: mockRequest,
+			expErr:  true,
+		},
+		{
+			name:  "Composite",
+			keyFn: CompositeKey(RouteVarKey("partnerID"), StaticKey(mockKey)),
+			request: func(t *testing.T) *http.Request {
+				return mux.SetURLVars(mockRequest(t), map[string]string{"partnerID": mockPartnerID})
+			},
+			expected: mockPartnerID + ":" + mockKey,
+		},
+	}
+
+	for _, tc := range tt {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.keyFn(tc.request(t))
+			require.Equal(t, tc.expErr, err != nil, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func mockRequest(_ *testing.T) *http.Request {
+	return httptest.NewRequest(http.MethodGet, mockTestURL, nil)
+}
+
+func mockRequestWithHeader(name, value string) func(t *testing.T) *http.Request {
+	return func(t *testing.T) *http.Request {
+		r := mockRequest(t)
+		r.Header.Set(name, value)
+		return r
+	}
+}
+
+func mockRequestWithIDs(partnerID, userID string) func(t *testing.T) *http.Request {
+	return func(t *testing.T) *http.Request {
+		r := mockRequest(t)
+		ctx := context.WithValue(r.Context(), auth.PartnerIDKey, partnerID)
+		return r.WithContext(context.WithValue(ctx, auth.UserIDKey, userID))
+	}
+}
+
+func mockForwardedRequest(remoteAddr, forwardedFor string) func(t *testing.T) *http.Request {
+	return func(t *testing.T) *http.Request {
+		r := mockRequestWithHeader(forwardedForHeader, forwardedFor)(t)
+		r.RemoteAddr = remoteAddr
+		return r
+	}
+}
+
+func mockRequestWithToken(partnerID, userID string) func(t *testing.T) *http.Request {
+	return func(t *testing.T) *http.Request {
+		claims := token.AuthClaims{CustomClaims: token.CustomClaims{PartnerID: partnerID, UserID: userID}}
+		jwtToken, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+		require.NoError(t, err)
+		return mockRequestWithHeader(auth.AuthorizationHeader, fmt.Sprintf("%s %s", auth.BearerHeader, jwtToken))(t)
+	}
+}
@@ -0,0 +1,160 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryStorage is an in-memory Storage, it is meant for tests and single instance services
+type MemoryStorage struct {
+	mu      sync.Mutex
+	values  map[string]interface{}
+	expires map[string]time.Time
+	now     func() time.Time
+}
+
+// NewMemoryStorage returns an empty MemoryStorage
+func NewMemoryStorage() *MemoryStorage {
+	return &MemoryStorage{
+		values:  make(map[string]interface{}),
+		expires: make(map[string]time.Time),
+		now:     time.Now,
+	}
+}
+
+// Get returns the value stored by the key, an empty string and a not found error are returned for a missing key
+func (m *MemoryStorage) Get(key string) (interface{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	v, ok := m.get(key)
+	if !ok {
+		return "", fmt.Errorf("key %s not found", key)
+	}
+	return v, nil
+}
+
+// Set stores the value by the key and removes its TTL
+func (m *MemoryStorage) Set(key string, value interface{}) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = value
+	delete(m.expires, key)
+	return nil
+}
+
+// Incr increments the integer value stored by the key, a missing key is treated as 0
+func (m *MemoryStorage) Incr(key string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	c, err := m.intValue(key)
+	if err != nil {
+		return 0, err
+	}
+	c++
+	m.values[key] = c
+	return c, nil
+}
+
+// Expire sets TTL for the key, false is returned when the key doesn't exist
+func (m *MemoryStorage) Expire(key string, duration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.get(key); !ok {
+		return false, nil
+	}
+	m.expires[key] = m.now().Add(duration)
+	return true, nil
+}
+
+// SlidingWindowIncr implements AtomicStorage
+func (m *MemoryStorage) SlidingWindowIncr(currentKey, prevKey string, prevWeight float64, limit int64, ttl time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	current, err := m.intValue(currentKey)
+	if err != nil {
+		return 0, err
+	}
+	if current > limit {
+		return current, nil
+	}
+	prev, err := m.intValue(prevKey)
+	if err != nil {
+		return 0, err
+	}
+	windowCount := current + int64(math.Ceil(float64(prev)*prevWeight))
+	if windowCount > limit {
+		return windowCount, nil
+	}
+	m.values[currentKey] = current + 1
+	m.expires[currentKey] = m.now().Add(ttl)
+	return windowCount + 1, nil
+}
+
+// TokenBucketTake implements AtomicStorage
+func (m *MemoryStorage) TokenBucketTake(key string, capacity, rate float64, now int64) (float64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	raw, err := m.stringValue(key)
+	if err != nil {
+		return 0, false, err
+	}
+	tokens, next, allowed, err := takeToken(raw, capacity, rate, now)
+	if err != nil || !allowed {
+		return tokens, allowed, err
+	}
+	m.values[key] = next
+	m.expires[key] = m.now().Add(tokenBucketTTL(capacity, tokens, rate))
+	return tokens, true, nil
+}
+
+// GCRATake implements AtomicStorage
+func (m *MemoryStorage) GCRATake(key string, emission, tolerance, now int64) (int64, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	raw, err := m.stringValue(key)
+	if err != nil {
+		return 0, false, err
+	}
+	tat, allowed, err := advanceTAT(raw, emission, tolerance, now)
+	if err != nil || !allowed {
+		return tat, allowed, err
+	}
+	m.values[key] = strconv.FormatInt(tat, 10)
+	m.expires[key] = m.now().Add(gcraTTL(tat, now))
+	return tat, true, nil
+}
+
+func (m *MemoryStorage) stringValue(key string) (string, error) {
+	v, ok := m.get(key)
+	if !ok {
+		return "", nil
+	}
+	switch s := v.(type) {
+	case string:
+		return s, nil
+	case []byte:
+		return string(s), nil
+	default:
+		return "", fmt.Errorf("invalid data type, value: %#v", s)
+	}
+}
+
+func (m *MemoryStorage) intValue(key string) (int64, error) {
+	v, ok := m.get(key)
+	if !ok {
+		return 0, nil
+	}
+	return toInt64(v)
+}
+
+func (m *MemoryStorage) get(key string) (interface{}, bool) {
+	if exp, ok := m.expires[key]; ok && !m.now().Before(exp) {
+		delete(m.values, key)
+		delete(m.expires, key)
+	}
+	v, ok := m.values[key]
+	return v, ok
+}
@@ -0,0 +1,62 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStorage(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStorage()
+	store.now = func() time.Time { return now }
+
+	v, err := store.Get(mockKey)
+	require.Error(t, err)
+	require.True(t, isNotFoundError(err))
+	require.Equal(t, "", v)
+
+	ok, err := store.Expire(mockKey, time.Second)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	c, err := store.Incr(mockKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), c)
+
+	require.NoError(t, store.Set(mockKey, "41"))
+	c, err = store.Incr(mockKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), c)
+
+	ok, err = store.Expire(mockKey, time.Second)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	v, err = store.Get(mockKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(42), v)
+
+	now = now.Add(time.Second)
+	_, err = store.Get(mockKey)
+	require.Error(t, err)
+}
+
+func TestMemoryStorage_SlidingWindowIncr(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStorage()
+	store.now = func() time.Time { return now }
+	require.NoError(t, store.Set("prev", "8"))
+
+	for _, expected := range []int64{3, 4, 4} {
+		got, err := store.SlidingWindowIncr("current", "prev", 0.25, 3, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, expected, got)
+	}
+
+	now = now.Add(time.Minute)
+	got, err := store.SlidingWindowIncr("current", "prev", 0, 3, time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), got)
+}
@@ -1,5 +1,5 @@
 // Code generated by MockGen. DO NOT EDIT.
-// Source: gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/web/ratelimit (interfaces: Limiter,Storage)
+// Source: gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/web/ratelimit (interfaces: Limiter,Storage,AtomicStorage,ScriptStorage)
 
 // Package mock is a generated GoMock package.
 package mock
@@ -49,6 +49,57 @@ func (mr *MockLimiterMockRecorder) CheckRateLimit(arg0, arg1, arg2 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRateLimit", reflect.TypeOf((*MockLimiter)(nil).CheckRateLimit), arg0, arg1, arg2)
 }
 
+// MockKeyLimiter is a mock of KeyLimiter interface.
+type MockKeyLimiter struct {
+	ctrl     *gomock.Controller
+	recorder *MockKeyLimiterMockRecorder
+}
+
+// MockKeyLimiterMockRecorder is the mock recorder for MockKeyLimiter.
+type MockKeyLimiterMockRecorder struct {
+	mock *MockKeyLimiter
+}
+
+// NewMockKeyLimiter creates a new mock instance.
+func NewMockKeyLimiter(ctrl *gomock.Controller) *MockKeyLimiter {
+	mock := &MockKeyLimiter{ctrl: ctrl}
+	mock.recorder = &MockKeyLimiterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockKeyLimiter) EXPECT() *MockKeyLimiterMockRecorder {
+	return m.recorder
+}
+
+// CheckRateLimit mocks base method.
+func (m *MockKeyLimiter) CheckRateLimit(arg0 http.HandlerFunc, arg1, arg2 string) http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRateLimit", arg0, arg1, arg2)
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// CheckRateLimit indicates an expected call of CheckRateLimit.
+func (mr *MockKeyLimiterMockRecorder) CheckRateLimit(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRateLimit", reflect.TypeOf((*MockKeyLimiter)(nil).CheckRateLimit), arg0, arg1, arg2)
+}
+
+// CheckRateLimitFunc mocks base method.
+func (m *MockKeyLimiter) CheckRateLimitFunc(arg0 http.HandlerFunc, arg1 string, arg2 func(*http.Request) (string, error)) http.HandlerFunc {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CheckRateLimitFunc", arg0, arg1, arg2)
+	ret0, _ := ret[0].(http.HandlerFunc)
+	return ret0
+}
+
+// CheckRateLimitFunc indicates an expected call of CheckRateLimitFunc.
+func (mr *MockKeyLimiterMockRecorder) CheckRateLimitFunc(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CheckRateLimitFunc", reflect.TypeOf((*MockKeyLimiter)(nil).CheckRateLimitFunc), arg0, arg1, arg2)
+}
+
 // MockStorage is a mock of Storage interface.
 type MockStorage struct {
 	ctrl     *gomock.Controller
@@ -130,3 +181,234 @@ func (mr *MockStorageMockRecorder) Set(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockStorage)(nil).Set), arg0, arg1)
 }
+
+// MockAtomicStorage is a mock of AtomicStorage interface.
+type MockAtomicStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockAtomicStorageMockRecorder
+}
+
+// MockAtomicStorageMockRecorder is the mock recorder for MockAtomicStorage.
+type MockAtomicStorageMockRecorder struct {
+	mock *MockAtomicStorage
+}
+
+// NewMockAtomicStorage creates a new mock instance.
+func NewMockAtomicStorage(ctrl *gomock.Controller) *MockAtomicStorage {
+	mock := &MockAtomicStorage{ctrl: ctrl}
+	mock.recorder = &MockAtomicStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAtomicStorage) EXPECT() *MockAtomicStorageMockRecorder {
+	return m.recorder
+}
+
+// Expire mocks base method.
+func (m *MockAtomicStorage) Expire(arg0 string, arg1 time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockAtomicStorageMockRecorder) Expire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockAtomicStorage)(nil).Expire), arg0, arg1)
+}
+
+// GCRATake mocks base method.
+func (m *MockAtomicStorage) GCRATake(arg0 string, arg1, arg2, arg3 int64) (int64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GCRATake", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GCRATake indicates an expected call of GCRATake.
+func (mr *MockAtomicStorageMockRecorder) GCRATake(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GCRATake", reflect.TypeOf((*MockAtomicStorage)(nil).GCRATake), arg0, arg1, arg2, arg3)
+}
+
+// Get mocks base method.
+func (m *MockAtomicStorage) Get(arg0 string) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockAtomicStorageMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockAtomicStorage)(nil).Get), arg0)
+}
+
+// Incr mocks base method.
+func (m *MockAtomicStorage) Incr(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockAtomicStorageMockRecorder) Incr(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockAtomicStorage)(nil).Incr), arg0)
+}
+
+// Set mocks base method.
+func (m *MockAtomicStorage) Set(arg0 string, arg1 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockAtomicStorageMockRecorder) Set(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockAtomicStorage)(nil).Set), arg0, arg1)
+}
+
+// SlidingWindowIncr mocks base method.
+func (m *MockAtomicStorage) SlidingWindowIncr(arg0, arg1 string, arg2 float64, arg3 int64, arg4 time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SlidingWindowIncr", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SlidingWindowIncr indicates an expected call of SlidingWindowIncr.
+func (mr *MockAtomicStorageMockRecorder) SlidingWindowIncr(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SlidingWindowIncr", reflect.TypeOf((*MockAtomicStorage)(nil).SlidingWindowIncr), arg0, arg1, arg2, arg3, arg4)
+}
+
+// TokenBucketTake mocks base method.
+func (m *MockAtomicStorage) TokenBucketTake(arg0 string, arg1, arg2 float64, arg3 int64) (float64, bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TokenBucketTake", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(float64)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// TokenBucketTake indicates an expected call of TokenBucketTake.
+func (mr *MockAtomicStorageMockRecorder) TokenBucketTake(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TokenBucketTake", reflect.TypeOf((*MockAtomicStorage)(nil).TokenBucketTake), arg0, arg1, arg2, arg3)
+}
+
+// MockScriptStorage is a mock of ScriptStorage interface.
+type MockScriptStorage struct {
+	ctrl     *gomock.Controller
+	recorder *MockScriptStorageMockRecorder
+}
+
+// MockScriptStorageMockRecorder is the mock recorder for MockScriptStorage.
+type MockScriptStorageMockRecorder struct {
+	mock *MockScriptStorage
+}
+
+// NewMockScriptStorage creates a new mock instance.
+func NewMockScriptStorage(ctrl *gomock.Controller) *MockScriptStorage {
+	mock := &MockScriptStorage{ctrl: ctrl}
+	mock.recorder = &MockScriptStorageMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScriptStorage) EXPECT() *MockScriptStorageMockRecorder {
+	return m.recorder
+}
+
+// Expire mocks base method.
+func (m *MockScriptStorage) Expire(arg0 string, arg1 time.Duration) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Expire", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Expire indicates an expected call of Expire.
+func (mr *MockScriptStorageMockRecorder) Expire(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expire", reflect.TypeOf((*MockScriptStorage)(nil).Expire), arg0, arg1)
+}
+
+// Eval mocks base method.
+func (m *MockScriptStorage) Eval(arg0 string, arg1 []string, arg2 ...interface{}) (interface{}, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{arg0, arg1}
+	for _, a := range arg2 {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Eval", varargs...)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Eval indicates an expected call of Eval.
+func (mr *MockScriptStorageMockRecorder) Eval(arg0, arg1 interface{}, arg2 ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{arg0, arg1}, arg2...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Eval", reflect.TypeOf((*MockScriptStorage)(nil).Eval), varargs...)
+}
+
+// Get mocks base method.
+func (m *MockScriptStorage) Get(arg0 string) (interface{}, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", arg0)
+	ret0, _ := ret[0].(interface{})
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockScriptStorageMockRecorder) Get(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockScriptStorage)(nil).Get), arg0)
+}
+
+// Incr mocks base method.
+func (m *MockScriptStorage) Incr(arg0 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Incr", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Incr indicates an expected call of Incr.
+func (mr *MockScriptStorageMockRecorder) Incr(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Incr", reflect.TypeOf((*MockScriptStorage)(nil).Incr), arg0)
+}
+
+// Set mocks base method.
+func (m *MockScriptStorage) Set(arg0 string, arg1 interface{}) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Set", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Set indicates an expected call of Set.
+func (mr *MockScriptStorageMockRecorder) Set(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockScriptStorage)(nil).Set), arg0, arg1)
+}
@@ -1,4 +1,4 @@
-//go:generate mockgen -package mock -destination=mock/mocks.go . Limiter,Storage
+//go:generate mockgen -package mock -destination=mock/mocks.go . Limiter,KeyLimiter,Storage,AtomicStorage,ScriptStorage
 
 package ratelimit
 
@@ -26,6 +26,13 @@ type (
 		CheckRateLimit(next http.HandlerFunc, group, key string) http.HandlerFunc
 	}
 
+	// KeyLimiter is a Limiter which builds the key from the request
+	KeyLimiter interface {
+		Limiter
+		// CheckRateLimitFunc checks the number of requests per time interval for the key built by keyFn, see KeyFunc
+		CheckRateLimitFunc(next http.HandlerFunc, group string, keyFn func(r *http.Request) (string, error)) http.HandlerFunc
+	}
+
 	// Storage is an interface for store usage
 	Storage interface {
 		Get(key string) (interface{}, error)
@@ -34,6 +41,28 @@ type (
 		Expire(key string, duration time.Duration) (bool, error)
 	}
 
+	// AtomicStorage is a Storage which is able to check and increment the counters in one round trip,
+	// the limiter uses it automatically when the storage passed to Init implements it
+	AtomicStorage interface {
+		Storage
+		// SlidingWindowIncr adds the previous window count multiplied by prevWeight (rounded up) to the current window count,
+		// increments the current window and sets its TTL when the sum is not greater than limit.
+		// It returns the sum including the current request
+		SlidingWindowIncr(currentKey, prevKey string, prevWeight float64, limit int64, ttl time.Duration) (int64, error)
+		// TokenBucketTake refills the token bucket kept under the key up to now (in nanoseconds) with rate tokens per second,
+		// up to capacity, and takes a token when there is one. It returns the tokens left and whether a token was taken
+		TokenBucketTake(key string, capacity, rate float64, now int64) (float64, bool, error)
+		// GCRATake advances the theoretical arrival time kept under the key by emission when it doesn't run further
+		// ahead of now than tolerance (all in nanoseconds). It returns the arrival time and whether it was advanced
+		GCRATake(key string, emission, tolerance, now int64) (int64, bool, error)
+	}
+
+	// ScriptStorage is a Storage which is able to execute Lua scripts, redis.Client implements it
+	ScriptStorage interface {
+		Storage
+		Eval(script string, keys []string, args ...interface{}) (interface{}, error)
+	}
+
 	// Responder is an interface to return http response back to the caller
 	Responder interface {
 		// RespondRateLimit handle the status code and the error received from the limiter
@@ -44,10 +73,24 @@ type (
 	Response struct {
 		Error      error
 		StatusCode int
+		// Quota is filled in when the request was counted, it is also written to the response headers
+		Quota *Quota
+	}
+
+	// Quota describes the rate limit state of the group and the key
+	Quota struct {
+		// Limit - number of requests allowed by the quota
+		Limit int64
+		// Remaining - number of requests left in the quota
+		Remaining int64
+		// Reset - time until the quota is fully restored
+		Reset time.Duration
+		// RetryAfter - time until the next request can be allowed, it is set for the rejected requests only
+		RetryAfter time.Duration
 	}
 
 	middleware interface {
-		Limiter
+		KeyLimiter
 		setConfig(w http.ResponseWriter, r *http.Request)
 		getConfig(w http.ResponseWriter, r *http.Request)
 		setEnabled(w http.ResponseWriter, r *http.Request)
@@ -60,7 +103,8 @@ type (
 	}
 )
 
-// Init initializes the Limiter instance or returns an error when something goes wrong
+// Init initializes the Limiter instance or returns an error when something goes wrong.
+// A storage able to run Lua scripts, like the Redis client, is wrapped into RedisStorage to count atomically
 func Init(ctx context.Context, responder Responder, storage Storage, config Config) error {
 	cfg := &config
 	l, err := newLimiter(responder, storage, cfg)
@@ -77,6 +121,11 @@ func Middleware() Limiter {
 	return limiterMW
 }
 
+// KeyMiddleware returns the initialized Limiter instance able to build the keys from the request
+func KeyMiddleware() KeyLimiter {
+	return limiterMW
+}
+
 func newLimiter(responder Responder, storage Storage, cfg *Config) (middleware, error) {
 	if responder == nil {
 		return nil, errNilResponder
@@ -84,6 +133,11 @@ func newLimiter(responder Responder, storage Storage, cfg *Config) (middleware,
 	if storage == nil {
 		return nil, errNilStorage
 	}
+	if s, ok := storage.(ScriptStorage); ok {
+		if _, ok = storage.(AtomicStorage); !ok {
+			storage = NewRedisStorage(s)
+		}
+	}
 	l := &middlewareImpl{
 		storage: storage,
 		rp:      responder,
@@ -94,8 +148,18 @@ func newLimiter(responder Responder, storage Storage, cfg *Config) (middleware,
 	return l, nil
 }
 
-// CheckRateLimit checks the number of requests per time interval
+// CheckRateLimit checks the number of requests per time interval, the key matches its own override only
 func (l *middlewareImpl) CheckRateLimit(next http.HandlerFunc, group, key string) http.HandlerFunc {
+	return l.checkRateLimit(next, group, StaticKey(key), false)
+}
+
+// CheckRateLimitFunc checks the number of requests per time interval for the key built from the request,
+// a composite key falls back to the overrides of its prefixes
+func (l *middlewareImpl) CheckRateLimitFunc(next http.HandlerFunc, group string, keyFn func(r *http.Request) (string, error)) http.HandlerFunc {
+	return l.checkRateLimit(next, group, keyFn, true)
+}
+
+func (l *middlewareImpl) checkRateLimit(next http.HandlerFunc, group string, keyFn func(r *http.Request) (string, error), prefixes bool) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		cfg, err := config(l.storage)
 		if err != nil {
@@ -112,18 +176,16 @@ func (l *middlewareImpl) CheckRateLimit(next http.HandlerFunc, group, key string
 			return
 		}
 
-		limit := cfg.limit(group, key)
-		current, err := l.count(
-			l.storage,
-			countParams{
-				Now:      time.Now().Unix(),
-				Group:    group,
-				Key:      key,
-				Interval: cfg.IntervalInSec,
-				Limit:    limit,
-			},
-		)
+		key, err := keyFn(r)
+		if err != nil {
+			l.rp.RespondRateLimit(Response{
+				Error:      fmt.Errorf("rate limit key: %v", err),
+				StatusCode: http.StatusBadRequest,
+			}, r, w)
+			return
+		}
 
+		count, err := l.counter(cfg, group)
 		if err != nil {
 			l.rp.RespondRateLimit(Response{
 				Error:      err,
@@ -132,19 +194,53 @@ func (l *middlewareImpl) CheckRateLimit(next http.HandlerFunc, group, key string
 			return
 		}
 
-		if current > limit {
+		params := cfg.params(group, key, prefixes, time.Now())
+		res, err := count(l.storage, params)
+		if err != nil {
+			l.rp.RespondRateLimit(Response{
+				Error:      err,
+				StatusCode: http.StatusInternalServerError,
+			}, r, w)
+			return
+		}
+
+		quota := newQuota(params.Limit, res)
+		quotaHeaders(w, quota)
+		if res.Count > params.Limit {
 			l.rp.RespondRateLimit(Response{
 				Error:      errors.New("allowed amount of API calls exceeded, please try later"),
 				StatusCode: http.StatusTooManyRequests,
+				Quota:      quota,
 			}, r, w)
 			return
 		}
 
-		limitsHeader(w, limit, limit-current)
 		next(w, r)
 	}
 }
 
+func newQuota(limit int64, res countResult) *Quota {
+	remaining := limit - res.Count
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &Quota{
+		Limit:      limit,
+		Remaining:  remaining,
+		Reset:      res.Reset,
+		RetryAfter: res.RetryAfter,
+	}
+}
+
+// counter returns the count getter of the group algorithm, the default one is used when the group has no own algorithm
+func (l *middlewareImpl) counter(cfg *Config, group string) (countGetter, error) {
+	algorithm := cfg.algorithm(group)
+	if algorithm == cfg.Algorithm {
+		return l.count, nil
+	}
+	return resolveCountGetter(algorithm)
+}
+
 func (l *middlewareImpl) setConfig(w http.ResponseWriter, r *http.Request) {
 	txID := transactionID(r)
 	cfg := new(Config)
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
@@ -39,6 +40,22 @@ func TestInit(t *testing.T) {
 	require.IsType(t, limiter, got)
 }
 
+func TestInit_WithScriptStorage(t *testing.T) {
+	defer mockLimiterMW(nil)()
+	defer mockInMemoryConfig(nil)()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockScriptStorage(ctrl)
+	client.EXPECT().Set(configStorageKey, gomock.Any()).Return(nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	require.NoError(t, Init(ctx, mockResponder{}, client, mockConfig()))
+	l, ok := Middleware().(*middlewareImpl)
+	require.True(t, ok)
+	require.IsType(t, &RedisStorage{}, l.storage, "the client is used to count atomically")
+}
+
 func TestInit_WithError(t *testing.T) {
 	cfg := mockConfig()
 	store, finish := mockStorage(t)
@@ -79,6 +96,10 @@ func Test_middlewareImpl_CheckRateLimit(t *testing.T) {
 	require.Equal(t, http.StatusOK, res.StatusCode)
 	require.Equal(t, expLimitHeader, res.Header.Get(headerLimit))
 	require.Equal(t, expRemainingHeader, res.Header.Get(headerRemaining))
+	require.Equal(t, expLimitHeader, res.Header.Get(headerRateLimitLimit))
+	require.Equal(t, expRemainingHeader, res.Header.Get(headerRateLimitRemaining))
+	require.Equal(t, "0", res.Header.Get(headerRateLimitReset))
+	require.Empty(t, res.Header.Get(headerRetryAfter))
 }
 
 func Test_middlewareImpl_CheckRateLimit_WithBadRequest(t *testing.T) {
@@ -98,13 +119,103 @@ func Test_middlewareImpl_CheckRateLimit_WithTooManyRequests(t *testing.T) {
 	cfg.Groups[mockGroup].Overrides[mockKey] = 1
 	store, finish := mockStorageWithConfig(t, cfg, 0, 1)
 	defer finish()
-	handler := setupLimiter(store, mockCountGetter(10, nil))
+	var quota *Quota
+	limiter := &middlewareImpl{
+		storage: store,
+		rp: responderFunc(func(rp Response, _ *http.Request, w http.ResponseWriter) {
+			quota = rp.Quota
+			w.WriteHeader(rp.StatusCode)
+		}),
+		count: func(_ Storage, _ countParams) (countResult, error) {
+			return countResult{Count: 10, Reset: 90 * time.Second, RetryAfter: 1500 * time.Millisecond}, nil
+		},
+	}
+	handler := limiter.CheckRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, mockGroup, mockKey)
 	r := httptest.NewRequest(http.MethodGet, mockTestURL, nil)
 	w := httptest.NewRecorder()
 
 	handler(w, r)
 
-	require.Equal(t, http.StatusTooManyRequests, w.Result().StatusCode)
+	res := w.Result()
+	require.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	require.Equal(t, &Quota{Limit: 1, Remaining: 0, Reset: 90 * time.Second, RetryAfter: 1500 * time.Millisecond}, quota)
+	require.Equal(t, "0", res.Header.Get(headerRateLimitRemaining))
+	require.Equal(t, "90", res.Header.Get(headerRateLimitReset))
+	require.Equal(t, "2", res.Header.Get(headerRetryAfter))
+}
+
+func Test_middlewareImpl_CheckRateLimit_WithGroupAlgorithm(t *testing.T) {
+	cfg := mockConfig()
+	cfg.Groups[mockGroup].Algorithm = TokenBucketAlgorithm
+	cfg.Groups[mockGroup].TokenBucket = &TokenBucketConfig{Burst: 2, RefillPerSec: 0.001}
+	defer mockInMemoryConfig(&cfg)()
+
+	limiter := &middlewareImpl{
+		storage: NewMemoryStorage(),
+		rp:      mockResponder{},
+		count:   mockCountGetter(0, errors.New("default algorithm should not be used")),
+	}
+	handler := limiter.CheckRateLimit(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, mockGroup, "321")
+
+	for _, expected := range []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests} {
+		w := httptest.NewRecorder()
+		handler(w, httptest.NewRequest(http.MethodGet, mockTestURL, nil))
+		require.Equal(t, expected, w.Result().StatusCode)
+	}
+}
+
+func Test_middlewareImpl_CheckRateLimitFunc(t *testing.T) {
+	cfg := mockConfig()
+	cfg.Groups[mockGroup].Overrides = map[string]int64{"noisy": 1}
+	defer mockInMemoryConfig(&cfg)()
+
+	var keys []string
+	limiter := &middlewareImpl{
+		storage: NewMemoryStorage(),
+		rp:      mockResponder{},
+		count: func(_ Storage, p countParams) (countResult, error) {
+			keys = append(keys, p.Key)
+			return countResult{Count: 2}, nil
+		},
+	}
+	handler := limiter.CheckRateLimitFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, mockGroup, HeaderKey("X-Partner"))
+
+	for _, tc := range []struct {
+		partner  string
+		expected int
+	}{
+		{partner: "quiet", expected: http.StatusOK},
+		{partner: "noisy", expected: http.StatusTooManyRequests},
+		{partner: "", expected: http.StatusBadRequest},
+	} {
+		r := httptest.NewRequest(http.MethodGet, mockTestURL, nil)
+		if tc.partner != "" {
+			r.Header.Set("X-Partner", tc.partner)
+		}
+		w := httptest.NewRecorder()
+		handler(w, r)
+		require.Equal(t, tc.expected, w.Result().StatusCode, tc.partner)
+	}
+	require.Equal(t, []string{"quiet", "noisy"}, keys)
+
+	// a static key matches its own override only
+	next := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}
+	for key, expected := range map[string]int{"noisy": http.StatusTooManyRequests, "noisy:1": http.StatusOK} {
+		w := httptest.NewRecorder()
+		limiter.CheckRateLimit(next, mockGroup, key)(w, httptest.NewRequest(http.MethodGet, mockTestURL, nil))
+		require.Equal(t, expected, w.Result().StatusCode, key)
+	}
+	w := httptest.NewRecorder()
+	limiter.CheckRateLimitFunc(next, mockGroup, StaticKey("noisy:1"))(w, httptest.NewRequest(http.MethodGet, mockTestURL, nil))
+	require.Equal(t, http.StatusTooManyRequests, w.Result().StatusCode, "a key built by a KeyFunc uses the override of its prefix")
 }
 
 func Test_middlewareImpl_getConfig(t *testing.T) {
@@ -246,11 +357,17 @@ func mockStorageWithConfig(t *testing.T, cfg Config, setTimes, getTimes int) (*m
 }
 
 func mockCountGetter(count int64, err error) countGetter {
-	return func(_ Storage, _ countParams) (int64, error) {
-		return count, err
+	return func(_ Storage, _ countParams) (countResult, error) {
+		return countResult{Count: count}, err
 	}
 }
 
+type responderFunc func(rp Response, r *http.Request, w http.ResponseWriter)
+
+func (f responderFunc) RespondRateLimit(rp Response, r *http.Request, w http.ResponseWriter) {
+	f(rp, r, w)
+}
+
 func (mr mockResponder) RespondRateLimit(rp Response, _ *http.Request, w http.ResponseWriter) {
 	w.WriteHeader(rp.StatusCode)
 }
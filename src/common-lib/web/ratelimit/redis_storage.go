@@ -0,0 +1,148 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// slidingWindowScript is the Lua version of slidingWindow, see AtomicStorage.SlidingWindowIncr
+const slidingWindowScript = `
+local limit = tonumber(ARGV[2])
+local current = tonumber(redis.call('GET', KEYS[1]) or '0')
+if current > limit then
+	return current
+end
+local prev = tonumber(redis.call('GET', KEYS[2]) or '0')
+local count = current + math.ceil(prev * tonumber(ARGV[1]))
+if count > limit then
+	return count
+end
+redis.call('INCR', KEYS[1])
+redis.call('PEXPIRE', KEYS[1], ARGV[3])
+return count + 1
+`
+
+// tokenBucketScript is the Lua version of takeToken, see AtomicStorage.TokenBucketTake.
+// The numbers are returned as strings, Redis would truncate them to integers
+const tokenBucketScript = `
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local tokens, last = capacity, ARGV[3]
+local raw = redis.call('GET', KEYS[1])
+if raw then
+	local sep = string.find(raw, ':', 1, true)
+	if not sep then
+		return redis.error_reply('invalid token bucket state: ' .. raw)
+	end
+	tokens = tonumber(string.sub(raw, 1, sep - 1))
+	last = string.sub(raw, sep + 1)
+end
+local elapsed = tonumber(ARGV[3]) - tonumber(last)
+if elapsed > 0 then
+	tokens = math.min(capacity, tokens + elapsed / 1e9 * rate)
+	last = ARGV[3]
+end
+if tokens < 1 then
+	return {0, string.format('%.17g', tokens)}
+end
+tokens = tokens - 1
+redis.call('SET', KEYS[1], string.format('%.17g', tokens) .. ':' .. last, 'PX', math.floor((capacity - tokens) / rate) * 1000 + 1000)
+return {1, string.format('%.17g', tokens)}
+`
+
+// gcraScript is the Lua version of advanceTAT, see AtomicStorage.GCRATake
+const gcraScript = `
+local emission = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local tat = tonumber(redis.call('GET', KEYS[1]) or ARGV[3])
+if tat < now then
+	tat = now
+end
+if tat + emission - now > tolerance then
+	return {0, string.format('%.0f', tat)}
+end
+tat = tat + emission
+redis.call('SET', KEYS[1], string.format('%.0f', tat), 'PX', math.floor((tat - now) / 1e9) * 1000 + 1000)
+return {1, string.format('%.0f', tat)}
+`
+
+// RedisStorage is an AtomicStorage on top of the Redis client, the counters are checked and incremented by a Lua script
+type RedisStorage struct {
+	ScriptStorage
+}
+
+// NewRedisStorage returns RedisStorage for the Redis client
+func NewRedisStorage(client ScriptStorage) *RedisStorage {
+	return &RedisStorage{ScriptStorage: client}
+}
+
+// SlidingWindowIncr implements AtomicStorage
+func (s *RedisStorage) SlidingWindowIncr(currentKey, prevKey string, prevWeight float64, limit int64, ttl time.Duration) (int64, error) {
+	res, err := s.Eval(
+		slidingWindowScript,
+		[]string{currentKey, prevKey},
+		strconv.FormatFloat(prevWeight, 'f', -1, 64),
+		limit,
+		int64(ttl/time.Millisecond),
+	)
+	if err != nil {
+		return 0, err
+	}
+	return toInt64(res)
+}
+
+// TokenBucketTake implements AtomicStorage
+func (s *RedisStorage) TokenBucketTake(key string, capacity, rate float64, now int64) (float64, bool, error) {
+	res, err := s.Eval(
+		tokenBucketScript,
+		[]string{key},
+		strconv.FormatFloat(capacity, 'f', -1, 64),
+		strconv.FormatFloat(rate, 'f', -1, 64),
+		now,
+	)
+	if err != nil {
+		return 0, false, err
+	}
+	allowed, value, err := takeResult(res)
+	if err != nil {
+		return 0, false, err
+	}
+	tokens, err := strconv.ParseFloat(value, 64)
+	return tokens, allowed, err
+}
+
+// GCRATake implements AtomicStorage
+func (s *RedisStorage) GCRATake(key string, emission, tolerance, now int64) (int64, bool, error) {
+	res, err := s.Eval(gcraScript, []string{key}, emission, tolerance, now)
+	if err != nil {
+		return 0, false, err
+	}
+	allowed, value, err := takeResult(res)
+	if err != nil {
+		return 0, false, err
+	}
+	tat, err := strconv.ParseInt(value, 10, 64)
+	return tat, allowed, err
+}
+
+// takeResult parses the {allowed, value} reply of the scripts
+func takeResult(res interface{}) (bool, string, error) {
+	values, ok := res.([]interface{})
+	if !ok || len(values) != 2 {
+		return false, "", fmt.Errorf("unexpected script result: %#v", res)
+	}
+	allowed, err := toInt64(values[0])
+	if err != nil {
+		return false, "", err
+	}
+	switch v := values[1].(type) {
+	case string:
+		return allowed == 1, v, nil
+	case []byte:
+		return allowed == 1, string(v), nil
+	default:
+		return false, "", fmt.Errorf("unexpected script result: %#v", res)
+	}
+}
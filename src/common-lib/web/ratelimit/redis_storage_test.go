@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis"
+	"github.com/go-redis/redis"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/web/ratelimit/mock"
+)
+
+type goRedisStorage struct {
+	*redis.Client
+}
+
+func TestRedisStorage_SlidingWindowIncr(t *testing.T) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	store := NewRedisStorage(goRedisStorage{client})
+
+	currentKey := atomicStorageKey(mockGroup, mockKey, 8)
+	prevKey := atomicStorageKey(mockGroup, mockKey, 4)
+	require.NoError(t, client.Set(prevKey, 8, 0).Err())
+
+	for _, expected := range []int64{3, 4, 4} {
+		got, err := store.SlidingWindowIncr(currentKey, prevKey, 0.25, 3, time.Minute)
+		require.NoError(t, err)
+		require.Equal(t, expected, got)
+	}
+
+	c, err := count(store, currentKey)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), c)
+	require.Equal(t, time.Minute, mr.TTL(currentKey))
+}
+
+func TestRedisStorage_SlidingWindowIncr_WithError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockScriptStorage(ctrl)
+	errExpected := errors.New("some error")
+	client.EXPECT().Eval(slidingWindowScript, []string{"current", "prev"}, "0.5", int64(10), int64(60000)).Return(nil, errExpected)
+
+	_, err := NewRedisStorage(client).SlidingWindowIncr("current", "prev", 0.5, 10, time.Minute)
+	require.EqualError(t, err, errExpected.Error())
+}
+
+func TestRedisStorage_TokenBucketTake(t *testing.T) {
+	testRedisStorageAlgorithm(t, tokenBucket, TokenBucketAlgorithm)
+}
+
+func TestRedisStorage_GCRATake(t *testing.T) {
+	testRedisStorageAlgorithm(t, gcra, GCRAAlgorithm)
+}
+
+// testRedisStorageAlgorithm checks that the Lua script of the algorithm counts the same as MemoryStorage
+func testRedisStorageAlgorithm(t *testing.T, count countGetter, algorithm string) {
+	mr, err := miniredis.Run()
+	require.NoError(t, err)
+	defer mr.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	store := NewRedisStorage(goRedisStorage{client})
+	memory := NewMemoryStorage()
+
+	p := mockBucketParams()
+	for _, step := range []time.Duration{0, 0, 0, 0, time.Second / 2, time.Second / 2, 0, time.Hour, 0} {
+		p.NowNano += int64(step)
+		expected, err := count(memory, p)
+		require.NoError(t, err)
+		got, err := count(store, p)
+		require.NoError(t, err)
+		require.Equal(t, expected, got)
+	}
+	require.Equal(t, 3*time.Second, mr.TTL(stateKey(p.Group, p.Key, algorithm)), "the state is kept until 2 more tokens are regained")
+}
+
+func TestRedisStorage_TakeWithError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	client := mock.NewMockScriptStorage(ctrl)
+	errExpected := errors.New("some error")
+	client.EXPECT().Eval(tokenBucketScript, []string{"key"}, "3", "1", int64(10)).Return(nil, errExpected)
+	client.EXPECT().Eval(gcraScript, []string{"key"}, int64(1), int64(3), int64(10)).Return([]interface{}{int64(1)}, nil)
+
+	_, _, err := NewRedisStorage(client).TokenBucketTake("key", 3, 1, 10)
+	require.EqualError(t, err, errExpected.Error())
+	_, _, err = NewRedisStorage(client).GCRATake("key", 1, 3, 10)
+	require.Error(t, err)
+}
+
+func (s goRedisStorage) Get(key string) (interface{}, error) {
+	return s.Client.Get(key).Result()
+}
+
+func (s goRedisStorage) Set(key string, value interface{}) error {
+	return s.Client.Set(key, value, 0).Err()
+}
+
+func (s goRedisStorage) Incr(key string) (int64, error) {
+	return s.Client.Incr(key).Result()
+}
+
+func (s goRedisStorage) Expire(key string, duration time.Duration) (bool, error) {
+	return s.Client.Expire(key, duration).Result()
+}
+
+func (s goRedisStorage) Eval(script string, keys []string, args ...interface{}) (interface{}, error) {
+	return s.Client.Eval(script, keys, args...).Result()
+}
@@ -1,8 +1,74 @@
 package ratelimit
 
-import "math"
+import (
+	"math"
+	"time"
+)
 
-func slidingWindow(storage Storage, p countParams) (int64, error) {
+func slidingWindow(storage Storage, p countParams) (countResult, error) {
+	var (
+		c   int64
+		err error
+	)
+	if s, ok := storage.(AtomicStorage); ok {
+		c, err = atomicSlidingWindow(s, p)
+	} else {
+		c, err = slidingWindowCount(storage, p)
+	}
+	if err != nil {
+		return countResult{}, err
+	}
+
+	// the current window count turns into the weighted previous one at the end of the window
+	res := countResult{
+		Count: c,
+		Reset: time.Second * time.Duration(windowTimestamp(p.Now, p.Interval)+p.Interval-p.Now),
+	}
+	if c > p.Limit {
+		if res.RetryAfter, err = slidingWindowRetryAfter(storage, p); err != nil {
+			return countResult{}, err
+		}
+	}
+	return res, nil
+}
+
+// slidingWindowRetryAfter returns the time until ceil(prev * weight) + current drops below the limit,
+// so the next request is allowed. The previous window keeps its whole weight at the end of the current one
+func slidingWindowRetryAfter(storage Storage, p countParams) (time.Duration, error) {
+	key := storageKey
+	if _, ok := storage.(AtomicStorage); ok {
+		key = atomicStorageKey
+	}
+	ts := windowTimestamp(p.Now, p.Interval)
+	current, err := count(storage, key(p.Group, p.Key, ts))
+	if err != nil {
+		return 0, err
+	}
+	prev, err := count(storage, key(p.Group, p.Key, ts-p.Interval))
+	if err != nil {
+		return 0, err
+	}
+	if current >= p.Limit {
+		// nothing is allowed until the current window turns into the previous one
+		ts, prev, current = ts+p.Interval, current, 0
+	}
+
+	allowed := p.Limit - current - 1
+	var elapsed int64
+	if prev > allowed {
+		elapsed = (p.Interval*(prev-allowed) + prev - 1) / prev
+	}
+	// windowElapsedCount rounds the float weight, it may take one more second
+	for elapsed < p.Interval && int64(math.Ceil(float64(prev)*windowElapsedPercent(ts+elapsed, ts, p.Interval))) > allowed {
+		elapsed++
+	}
+	if ts+elapsed < p.Now {
+		return 0, nil
+	}
+	return time.Second * time.Duration(ts+elapsed-p.Now), nil
+}
+
+func slidingWindowCount(storage Storage, p countParams) (int64, error) {
 	ts := windowTimestamp(p.Now, p.Interval)
 	key := storageKey(p.Group, p.Key, ts)
 	current, err := count(storage, key)
@@ -27,6 +93,18 @@ func slidingWindow(storage Storage, p countParams) (int64, error) {
 	return windowCount + 1, nil
 }
 
+func atomicSlidingWindow(storage AtomicStorage, p countParams) (int64, error) {
+	ts := windowTimestamp(p.Now, p.Interval)
+	prevTS := windowTimestamp(p.Now-p.Interval, p.Interval)
+	return storage.SlidingWindowIncr(
+		atomicStorageKey(p.Group, p.Key, ts),
+		atomicStorageKey(p.Group, p.Key, prevTS),
+		windowElapsedPercent(p.Now, ts, p.Interval),
+		p.Limit,
+		time.Second*time.Duration(p.Interval*expireMultiplier),
+	)
+}
+
 func windowElapsedCount(storage Storage, windowTS int64, p countParams) (int64, error) {
 	key := storageKey(p.Group, p.Key, windowTimestamp(p.Now-p.Interval, p.Interval))
 	c, err := count(storage, key)
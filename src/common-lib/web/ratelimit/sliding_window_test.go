@@ -4,7 +4,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+
+	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/web/ratelimit/mock"
 )
 
 func Test_slidingWindow(t *testing.T) {
@@ -24,7 +27,9 @@ func Test_slidingWindow(t *testing.T) {
 		expected := int64(5)
 		got, err := slidingWindow(store, p)
 		require.NoError(t, err)
-		require.Equal(t, expected, got)
+		require.Equal(t, expected, got.Count)
+		require.Equal(t, time.Second, got.Reset)
+		require.Zero(t, got.RetryAfter)
 
 	})
 
@@ -33,12 +38,122 @@ func Test_slidingWindow(t *testing.T) {
 		p.Limit = 2
 		expected := int64(3)
 
-		store.EXPECT().Get(currentKey).Return(expected, nil)
+		store.EXPECT().Get(currentKey).Return(expected, nil).Times(2)
+		store.EXPECT().Get(prevKey).Return("0", nil)
 
 		got, err := slidingWindow(store, p)
 		require.NoError(t, err)
-		require.Equal(t, expected, got)
+		require.Equal(t, expected, got.Count)
+		require.Equal(t, time.Second, got.Reset)
+		// the current window weighs 3/4 after one second of the next one
+		require.Equal(t, 4*time.Second, got.RetryAfter)
 	})
+
+	t.Run("Atomic storage", func(t *testing.T) {
+		ctrl := gomock.NewController(t)
+		defer ctrl.Finish()
+		atomicStore := mock.NewMockAtomicStorage(ctrl)
+		p := mockParams()
+		ts := windowTimestamp(p.Now, p.Interval)
+		expected := int64(5)
+
+		atomicStore.EXPECT().SlidingWindowIncr(
+			atomicStorageKey(p.Group, p.Key, ts),
+			atomicStorageKey(p.Group, p.Key, ts-p.Interval),
+			windowElapsedPercent(p.Now, ts, p.Interval),
+			p.Limit,
+			time.Second*time.Duration(p.Interval*expireMultiplier),
+		).Return(expected, nil)
+
+		got, err := slidingWindow(atomicStore, p)
+		require.NoError(t, err)
+		require.Equal(t, expected, got.Count)
+	})
+}
+
+func Test_atomicSlidingWindow(t *testing.T) {
+	store := NewMemoryStorage()
+	p := mockParams()
+	p.Limit = 3
+	ts := windowTimestamp(p.Now, p.Interval)
+	require.NoError(t, store.Set(atomicStorageKey(p.Group, p.Key, ts-p.Interval), int64(8)))
+
+	// three quarters of the window have elapsed, so a quarter of the previous window count is taken into account
+	for _, expected := range []int64{3, 4, 4} {
+		got, err := slidingWindow(store, p)
+		require.NoError(t, err)
+		require.Equal(t, expected, got.Count)
+	}
+
+	c, err := count(store, atomicStorageKey(p.Group, p.Key, ts))
+	require.NoError(t, err)
+	require.Equal(t, int64(2), c)
+}
+
+func Test_slidingWindowRetryAfter(t *testing.T) {
+	tests := []struct {
+		name       string
+		storage    func() Storage
+		prev       int64
+		allowed    int // requests allowed before the rejected one
+		retryAfter time.Duration
+	}{
+		{
+			name:       "Previous window",
+			storage:    func() Storage { return struct{ Storage }{NewMemoryStorage()} },
+			prev:       8,
+			retryAfter: 2 * time.Second,
+		},
+		{
+			name:       "Previous window: atomic storage",
+			storage:    func() Storage { return NewMemoryStorage() },
+			prev:       8,
+			retryAfter: 2 * time.Second,
+		},
+		{
+			name:       "Current window",
+			storage:    func() Storage { return struct{ Storage }{NewMemoryStorage()} },
+			allowed:    3,
+			retryAfter: 5 * time.Second,
+		},
+		{
+			name:       "Current window: atomic storage",
+			storage:    func() Storage { return NewMemoryStorage() },
+			allowed:    3,
+			retryAfter: 5 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			store := tt.storage()
+			p := mockParams()
+			p.Limit = 3
+			ts := windowTimestamp(p.Now, p.Interval)
+			p.Now = ts + 1
+			key := storageKey
+			if _, ok := store.(AtomicStorage); ok {
+				key = atomicStorageKey
+			}
+			require.NoError(t, store.Set(key(p.Group, p.Key, ts-p.Interval), tt.prev))
+
+			for i := 0; i < tt.allowed; i++ {
+				got, err := slidingWindow(store, p)
+				require.NoError(t, err)
+				require.LessOrEqual(t, got.Count, p.Limit)
+			}
+			got, err := slidingWindow(store, p)
+			require.NoError(t, err)
+			require.Greater(t, got.Count, p.Limit)
+			require.Equal(t, tt.retryAfter, got.RetryAfter)
+
+			retry := p
+			retry.Now = p.Now + int64(got.RetryAfter/time.Second)
+			got, err = slidingWindow(store, retry)
+			require.NoError(t, err)
+			require.LessOrEqual(t, got.Count, p.Limit, "a request at RetryAfter should be allowed")
+		})
+	}
 }
 
 func mockParams() countParams {
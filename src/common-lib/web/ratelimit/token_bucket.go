@@ -0,0 +1,112 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// tokenBucket keeps "tokens:lastRefillNano" under the state key. The bucket holds up to p.Limit tokens
+// and regains p.Rate tokens per second, every request takes one token.
+// The state is read and updated in one step when the storage implements AtomicStorage
+func tokenBucket(storage Storage, p countParams) (countResult, error) {
+	key := stateKey(p.Group, p.Key, TokenBucketAlgorithm)
+	capacity := float64(p.Limit)
+
+	var (
+		tokens  float64
+		allowed bool
+		err     error
+	)
+	if s, ok := storage.(AtomicStorage); ok {
+		tokens, allowed, err = s.TokenBucketTake(key, capacity, p.Rate, p.NowNano)
+	} else {
+		tokens, allowed, err = tokenBucketTake(storage, key, capacity, p.Rate, p.NowNano)
+	}
+	if err != nil {
+		return countResult{}, err
+	}
+
+	if !allowed {
+		return countResult{
+			Count:      p.Limit + 1,
+			Reset:      refillDuration(capacity-tokens, p.Rate),
+			RetryAfter: refillDuration(1-tokens, p.Rate),
+		}, nil
+	}
+	return countResult{
+		Count: p.Limit - int64(math.Floor(tokens)),
+		Reset: refillDuration(capacity-tokens, p.Rate),
+	}, nil
+}
+
+// tokenBucketTake reads and updates the bucket with separate calls, for the storages without AtomicStorage
+func tokenBucketTake(storage Storage, key string, capacity, rate float64, now int64) (float64, bool, error) {
+	raw, err := state(storage, key)
+	if err != nil {
+		return 0, false, err
+	}
+	tokens, next, allowed, err := takeToken(raw, capacity, rate, now)
+	if err != nil || !allowed {
+		return tokens, allowed, err
+	}
+	if err = storage.Set(key, next); err != nil {
+		return 0, false, err
+	}
+	if err = expire(storage, key, tokenBucketTTL(capacity, tokens, rate)); err != nil {
+		return 0, false, err
+	}
+	return tokens, true, nil
+}
+
+// takeToken refills the bucket kept in the raw state up to now and takes a token when there is one,
+// it returns the tokens left in the bucket and its new state
+func takeToken(raw string, capacity, rate float64, now int64) (tokens float64, next string, allowed bool, err error) {
+	tokens, last := capacity, now
+	if raw != "" {
+		if tokens, last, err = parseTokenBucketState(raw); err != nil {
+			return 0, "", false, err
+		}
+	}
+	if elapsed := now - last; elapsed > 0 {
+		tokens = math.Min(capacity, tokens+time.Duration(elapsed).Seconds()*rate)
+		last = now
+	}
+	if tokens < 1 {
+		return tokens, "", false, nil
+	}
+
+	tokens--
+	return tokens, formatTokenBucketState(tokens, last), true, nil
+}
+
+// tokenBucketTTL keeps the state until the bucket is full again
+func tokenBucketTTL(capacity, tokens, rate float64) time.Duration {
+	return refillDuration(capacity-tokens, rate).Truncate(time.Second) + time.Second
+}
+
+func refillDuration(tokens, rate float64) time.Duration {
+	return time.Duration(tokens / rate * float64(time.Second))
+}
+
+func formatTokenBucketState(tokens float64, last int64) string {
+	return strconv.FormatFloat(tokens, 'f', -1, 64) + ":" + strconv.FormatInt(last, 10)
+}
+
+func parseTokenBucketState(raw string) (float64, int64, error) {
+	parts := strings.Split(raw, ":")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid token bucket state: %s", raw)
+	}
+	tokens, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	last, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	return tokens, last, nil
+}
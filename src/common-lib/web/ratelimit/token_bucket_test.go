@@ -0,0 +1,126 @@
+package ratelimit
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_tokenBucket(t *testing.T) {
+	t.Run("Burst then refill", func(t *testing.T) {
+		store := NewMemoryStorage()
+		p := mockBucketParams()
+
+		for i := int64(1); i <= p.Limit; i++ {
+			got, err := tokenBucket(store, p)
+			require.NoError(t, err)
+			require.Equal(t, i, got.Count)
+		}
+
+		got, err := tokenBucket(store, p)
+		require.NoError(t, err)
+		require.Equal(t, p.Limit+1, got.Count)
+		require.Equal(t, time.Second, got.RetryAfter)
+		require.Equal(t, 3*time.Second, got.Reset)
+
+		p.NowNano += int64(time.Second)
+		got, err = tokenBucket(store, p)
+		require.NoError(t, err)
+		require.Equal(t, p.Limit, got.Count)
+		require.Zero(t, got.RetryAfter)
+
+		got, err = tokenBucket(store, p)
+		require.NoError(t, err)
+		require.Equal(t, p.Limit+1, got.Count)
+	})
+
+	t.Run("Refill is capped by burst", func(t *testing.T) {
+		store := NewMemoryStorage()
+		p := mockBucketParams()
+
+		_, err := tokenBucket(store, p)
+		require.NoError(t, err)
+
+		p.NowNano += int64(time.Hour)
+		got, err := tokenBucket(store, p)
+		require.NoError(t, err)
+		require.Equal(t, int64(1), got.Count)
+	})
+
+	t.Run("Invalid state", func(t *testing.T) {
+		store := NewMemoryStorage()
+		p := mockBucketParams()
+		require.NoError(t, store.Set(stateKey(p.Group, p.Key, TokenBucketAlgorithm), "invalid"))
+
+		_, err := tokenBucket(store, p)
+		require.Error(t, err)
+	})
+
+	t.Run("Concurrent requests", func(t *testing.T) {
+		store := NewMemoryStorage()
+		p := mockBucketParams()
+
+		var (
+			wg      sync.WaitGroup
+			allowed int64
+		)
+		for i := 0; i < 20; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				got, err := tokenBucket(store, p)
+				if err == nil && got.Count <= p.Limit {
+					atomic.AddInt64(&allowed, 1)
+				}
+			}()
+		}
+		wg.Wait()
+		require.Equal(t, p.Limit, allowed)
+	})
+
+	t.Run("Storage without atomic update", func(t *testing.T) {
+		store := plainStorage{NewMemoryStorage()}
+		p := mockBucketParams()
+
+		for i := int64(1); i <= p.Limit+1; i++ {
+			got, err := tokenBucket(store, p)
+			require.NoError(t, err)
+			require.Equal(t, i, got.Count)
+		}
+	})
+
+	t.Run("Storage error", func(t *testing.T) {
+		store, finish := mockStorage(t)
+		defer finish()
+		p := mockBucketParams()
+		errExpected := errors.New("some error")
+		store.EXPECT().Get(stateKey(p.Group, p.Key, TokenBucketAlgorithm)).Return(nil, errExpected)
+
+		_, err := tokenBucket(store, p)
+		require.EqualError(t, err, errExpected.Error())
+	})
+}
+
+func Test_tokenBucketState(t *testing.T) {
+	tokens, last, err := parseTokenBucketState(formatTokenBucketState(2.5, 1646042799))
+	require.NoError(t, err)
+	require.Equal(t, 2.5, tokens)
+	require.Equal(t, int64(1646042799), last)
+}
+
+// plainStorage hides the AtomicStorage methods of the storage
+type plainStorage struct {
+	Storage
+}
+
+func mockBucketParams() countParams {
+	p := mockParams()
+	p.NowNano = p.Now * int64(time.Second)
+	p.Limit = 3
+	p.Rate = 1
+	return p
+}
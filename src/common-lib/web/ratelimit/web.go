@@ -4,15 +4,20 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"time"
 
 	"gitlab.kksharmadevdev.com/platform/platform-common-lib/src/v6/utils"
 )
 
 const (
-	headerLimit       = "X-RateLimit-Limit"
-	headerRemaining   = "X-RateLimit-Remaining"
-	headerContentType = "Content-Type"
-	applicationJSON   = "application/json"
+	headerLimit              = "X-RateLimit-Limit"
+	headerRemaining          = "X-RateLimit-Remaining"
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRetryAfter         = "Retry-After"
+	headerContentType        = "Content-Type"
+	applicationJSON          = "application/json"
 )
 
 type (
@@ -81,7 +86,24 @@ func renderJSON(w http.ResponseWriter, status int, response interface{}, transac
 	_, _ = w.Write(data)
 }
 
-func limitsHeader(w http.ResponseWriter, limit, remaining int64) {
-	w.Header().Set(headerLimit, fmt.Sprintf("%d", limit))
-	w.Header().Set(headerRemaining, fmt.Sprintf("%d", remaining))
+// quotaHeaders sets the headers from the IETF RateLimit header fields draft, the durations are rounded up to seconds
+func quotaHeaders(w http.ResponseWriter, q *Quota) {
+	limit := fmt.Sprintf("%d", q.Limit)
+	remaining := fmt.Sprintf("%d", q.Remaining)
+	w.Header().Set(headerLimit, limit)
+	w.Header().Set(headerRemaining, remaining)
+	w.Header().Set(headerRateLimitLimit, limit)
+	w.Header().Set(headerRateLimitRemaining, remaining)
+	w.Header().Set(headerRateLimitReset, fmt.Sprintf("%d", seconds(q.Reset)))
+	if q.RetryAfter > 0 {
+		w.Header().Set(headerRetryAfter, fmt.Sprintf("%d", seconds(q.RetryAfter)))
+	}
+}
+
+func seconds(d time.Duration) int64 {
+	s := int64(d / time.Second)
+	if d%time.Second > 0 {
+		s++
+	}
+	return s
 }
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -76,6 +77,21 @@ func TestSetEnabled_WithInternalServerError(t *testing.T) {
 	require.Equal(t, http.StatusInternalServerError, w.Result().StatusCode)
 }
 
+func Test_quotaHeaders(t *testing.T) {
+	w := httptest.NewRecorder()
+	quotaHeaders(w, &Quota{Limit: 10, Remaining: 4, Reset: 2500 * time.Millisecond})
+
+	require.Equal(t, "10", w.Header().Get(headerLimit))
+	require.Equal(t, "4", w.Header().Get(headerRemaining))
+	require.Equal(t, "10", w.Header().Get(headerRateLimitLimit))
+	require.Equal(t, "4", w.Header().Get(headerRateLimitRemaining))
+	require.Equal(t, "3", w.Header().Get(headerRateLimitReset))
+	require.Empty(t, w.Header().Get(headerRetryAfter))
+
+	quotaHeaders(w, &Quota{Limit: 10, Remaining: 0, Reset: time.Minute, RetryAfter: time.Second})
+	require.Equal(t, "1", w.Header().Get(headerRetryAfter))
+}
+
 func mockLimiterMW(mw middleware) func() {
 	orig := limiterMW
 	limiterMW = mw
@@ -90,6 +106,12 @@ func (m mockMiddlewareNoContent) CheckRateLimit(_ http.HandlerFunc, _, _ string)
 	}
 }
 
+func (m mockMiddlewareNoContent) CheckRateLimitFunc(_ http.HandlerFunc, _ string, _ func(r *http.Request) (string, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
 func (m mockMiddlewareNoContent) setConfig(w http.ResponseWriter, _ *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -17,6 +17,8 @@ type RouteConfig struct {
 	URLPathSuffix string
 	URLVars       []string
 	Res           Resource
+	// Operations documents the route in the APIDoc of the server by HTTP method, e.g. http.MethodGet
+	Operations map[string]*Operation
 }
 
 // Resource interface is an extension of Route.
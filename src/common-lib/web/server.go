@@ -28,6 +28,9 @@ type ServerConfig struct {
 	StaticFileDirectory  string
 	APIversion           string
 	TracingConfig        *tracing.Config
+	// APIDoc is served at URLPathPrefix + APIDoc.Path when set, routes of SetupRoutes are documented in it.
+	// The Path is read when the server is created
+	APIDoc *APIDoc
 }
 
 // Server interface sets up routes, handlers and listening.
@@ -73,5 +76,9 @@ func (ServerFactoryImpl) GetServer(cfg *ServerConfig) Server {
 		},
 		tracingCfg: cfg.TracingConfig,
 	}
+	if doc := cfg.APIDoc; doc != nil {
+		// the document is served ahead of the application routes, so a catch-all route doesn't shadow it
+		mcfg.router.Handle(cfg.URLPathPrefix+doc.path(), doc).Methods(http.MethodGet)
+	}
 	return &mcfg
 }